@@ -0,0 +1,480 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/haro87/dokerb/pkg/apiserver"
+	"github.com/haro87/dokerb/pkg/client"
+	"github.com/haro87/dokerb/pkg/datastore"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+const usage = `Usage: doker [flags] <command> [arguments]
+
+Commands:
+  create                  create a new session and print its token
+  remove                  remove the session
+  join <name>             join the session as a user, print its ID and store its secret
+  leave <user>            leave the session by user ID or unique name
+  users                   list the users of the session
+  tasks                   list the tasks of the session
+  add-task <id> [summary] add a new task to the session
+  estimate <id>           interactively submit a b/m/w estimate for a task
+  estimates               list all user estimates of the session
+  result <id>             show the averaged estimate and max distance users
+
+Flags:
+`
+
+// cli holds everything a command needs to talk to the
+// server and to the user
+type cli struct {
+	client  *client.Client
+	server  string
+	token   string
+	user    string
+	secret  string
+	json    bool
+	in      *bufio.Reader
+	out     io.Writer
+	command string
+	args    []string
+
+	credentialsPath string
+	creds           *credentials
+}
+
+// resultOutput represents the output of the result command
+type resultOutput struct {
+	TaskID            string   `json:"id"`
+	Effort            float64  `json:"effort"`
+	StandardDeviation float64  `json:"standarddeviation"`
+	Hint              string   `json:"hint,omitempty"`
+	MissingUsers      []string `json:"missing"`
+	DistanceUsers     []string `json:"distance"`
+}
+
+func run(args []string, in io.Reader, out io.Writer) error {
+	c, err := newCli(args, in, out)
+	if err != nil {
+		return err
+	}
+
+	// Act on behalf of the configured or the only joined user
+	if c.token != "" && c.command != "create" && c.command != "join" {
+		if err := c.actAs(c.user); err != nil {
+			return err
+		}
+	}
+
+	switch c.command {
+	case "create":
+		return c.create()
+	case "remove":
+		return c.remove()
+	case "join":
+		return c.join()
+	case "leave":
+		return c.leave()
+	case "users":
+		return c.users()
+	case "tasks":
+		return c.tasks()
+	case "add-task":
+		return c.addTask()
+	case "estimate":
+		return c.estimate()
+	case "estimates":
+		return c.estimates()
+	case "result":
+		return c.result()
+	default:
+		return fmt.Errorf("Unknown command: %s", c.command)
+	}
+}
+
+func newCli(args []string, in io.Reader, out io.Writer) (*cli, error) {
+	fs := flag.NewFlagSet("doker", flag.ContinueOnError)
+	fs.SetOutput(out)
+	fs.Usage = func() {
+		fmt.Fprint(out, usage)
+		fs.PrintDefaults()
+	}
+
+	server := fs.String("server", apiserver.GetEnv("DOKER_SERVER", "http://127.0.0.1:5000"),
+		"URL of the Doker server (env DOKER_SERVER)")
+	token := fs.String("session", apiserver.GetEnv("DOKER_SESSION", ""),
+		"session token (env DOKER_SESSION)")
+	user := fs.String("user", apiserver.GetEnv("DOKER_USER", ""),
+		"user ID or unique name used for estimates (env DOKER_USER)")
+	secret := fs.String("secret", apiserver.GetEnv("DOKER_SECRET", ""),
+		"secret of the user, if not stored when joining (env DOKER_SECRET)")
+	credentialsPath := fs.String("credentials", apiserver.GetEnv("DOKER_CREDENTIALS", defaultCredentialsPath()),
+		"file the secrets of joined users are stored in (env DOKER_CREDENTIALS)")
+	asJSON := fs.Bool("json", false, "print the output as JSON")
+
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if fs.NArg() < 1 {
+		fs.Usage()
+		return nil, fmt.Errorf("No command provided")
+	}
+
+	cl, err := client.NewClient(*server)
+	if err != nil {
+		return nil, err
+	}
+
+	return &cli{
+		client:          cl,
+		server:          *server,
+		token:           *token,
+		user:            *user,
+		secret:          *secret,
+		json:            *asJSON,
+		in:              bufio.NewReader(in),
+		out:             out,
+		command:         fs.Arg(0),
+		args:            fs.Args()[1:],
+		credentialsPath: *credentialsPath,
+	}, nil
+}
+
+func (c *cli) create() error {
+	token, err := c.client.CreateSession()
+	if err != nil {
+		return err
+	}
+	if c.json {
+		return c.printJSON(map[string]string{"token": token})
+	}
+	fmt.Fprintln(c.out, token)
+	return nil
+}
+
+func (c *cli) remove() error {
+	if err := c.requireSession(); err != nil {
+		return err
+	}
+	if err := c.client.RemoveSession(c.token); err != nil {
+		return err
+	}
+	creds, err := c.credentials()
+	if err != nil {
+		return err
+	}
+	if err := creds.removeSession(c.server, c.token); err != nil {
+		return err
+	}
+	return c.printOk()
+}
+
+func (c *cli) join() error {
+	name, err := c.requireSessionAndArg("user name")
+	if err != nil {
+		return err
+	}
+	id, secret, err := c.client.JoinSession(c.token, name)
+	if err != nil {
+		return err
+	}
+	if secret != "" {
+		creds, err := c.credentials()
+		if err != nil {
+			return err
+		}
+		cred := credential{Server: c.server, Session: c.token, ID: id, Name: name, Secret: secret}
+		if err := creds.add(cred); err != nil {
+			return err
+		}
+	}
+	if c.json {
+		return c.printJSON(map[string]string{"id": id})
+	}
+	fmt.Fprintln(c.out, id)
+	return nil
+}
+
+func (c *cli) leave() error {
+	user, err := c.requireSessionAndArg("user ID or name")
+	if err != nil {
+		return err
+	}
+	if c.user == "" {
+		if err := c.actAs(user); err != nil {
+			return err
+		}
+	}
+	if err := c.client.LeaveSession(c.token, user); err != nil {
+		return err
+	}
+
+	// The secret of the user is revoked when leaving
+	creds, err := c.credentials()
+	if err != nil {
+		return err
+	}
+	if cred, ok := creds.find(c.server, c.token, user); ok {
+		if err := creds.remove(c.server, c.token, cred.ID); err != nil {
+			return err
+		}
+	}
+	return c.printOk()
+}
+
+func (c *cli) users() error {
+	if err := c.requireSession(); err != nil {
+		return err
+	}
+	users, err := c.client.GetUsers(c.token)
+	if err != nil {
+		return err
+	}
+	if c.json {
+		return c.printJSON(users)
+	}
+
+	w := tabwriter.NewWriter(c.out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tNAME\tROLE")
+	for _, u := range users {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", u.ID, u.Name, u.Role)
+	}
+	return w.Flush()
+}
+
+func (c *cli) tasks() error {
+	if err := c.requireSession(); err != nil {
+		return err
+	}
+	tasks, err := c.client.GetTasks(c.token)
+	if err != nil {
+		return err
+	}
+	if c.json {
+		return c.printJSON(tasks)
+	}
+
+	w := tabwriter.NewWriter(c.out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tSUMMARY\tEFFORT\tSTD DEV")
+	for _, t := range tasks {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", t.ID, t.Summary, formatFloat(t.Effort), formatFloat(t.StandardDeviation))
+	}
+	return w.Flush()
+}
+
+func (c *cli) addTask() error {
+	id, err := c.requireSessionAndArg("task ID")
+	if err != nil {
+		return err
+	}
+	summary := strings.Join(c.args[1:], " ")
+	if err := c.client.AddTask(c.token, id, summary); err != nil {
+		return err
+	}
+	return c.printOk()
+}
+
+func (c *cli) estimate() error {
+	id, err := c.requireSessionAndArg("task ID")
+	if err != nil {
+		return err
+	}
+
+	user := c.user
+	if user == "" {
+		creds, err := c.credentials()
+		if err != nil {
+			return err
+		}
+		if cred, ok := creds.find(c.server, c.token, ""); ok {
+			user = cred.ID
+		} else if user, err = c.prompt("User name: "); err != nil {
+			return err
+		}
+		if err := c.actAs(user); err != nil {
+			return err
+		}
+	}
+
+	var values [3]float64
+	for i, label := range []string{"Best case", "Most likely case", "Worst case"} {
+		if values[i], err = c.promptFloat(label + ": "); err != nil {
+			return err
+		}
+	}
+
+	est := datastore.Estimate{
+		TaskID:         id,
+		UserName:       user,
+		BestCase:       values[0],
+		MostLikelyCase: values[1],
+		WorstCase:      values[2],
+	}
+	if err := c.client.AddEstimate(c.token, est); err != nil {
+		return err
+	}
+	return c.printOk()
+}
+
+func (c *cli) estimates() error {
+	if err := c.requireSession(); err != nil {
+		return err
+	}
+	ests, err := c.client.GetEstimates(c.token)
+	if err != nil {
+		return err
+	}
+	if c.json {
+		return c.printJSON(ests)
+	}
+
+	w := tabwriter.NewWriter(c.out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "TASK\tUSER\tB\tM\tW")
+	for _, e := range ests {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", e.TaskID, e.UserName,
+			formatFloat(e.BestCase), formatFloat(e.MostLikelyCase), formatFloat(e.WorstCase))
+	}
+	return w.Flush()
+}
+
+func (c *cli) result() error {
+	id, err := c.requireSessionAndArg("task ID")
+	if err != nil {
+		return err
+	}
+
+	avg, err := c.client.GetAverageEstimate(c.token, id)
+	if err != nil {
+		return err
+	}
+	users, err := c.client.GetUsersWithMaxDistance(c.token, id)
+	if err != nil {
+		return err
+	}
+
+	res := resultOutput{
+		TaskID:            id,
+		Effort:            avg.Estimate.Effort,
+		StandardDeviation: avg.Estimate.StandardDeviation,
+		Hint:              avg.Hint,
+		MissingUsers:      avg.Users,
+		DistanceUsers:     users,
+	}
+	if c.json {
+		return c.printJSON(res)
+	}
+
+	w := tabwriter.NewWriter(c.out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "TASK\tEFFORT\tSTD DEV\tMAX DISTANCE USERS")
+	fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", res.TaskID, formatFloat(res.Effort),
+		formatFloat(res.StandardDeviation), strings.Join(nonEmpty(res.DistanceUsers), ", "))
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if res.Hint != "" {
+		fmt.Fprintf(c.out, "\nWarning: %s: %s\n", res.Hint, strings.Join(res.MissingUsers, ", "))
+	}
+	return nil
+}
+
+// actAs makes the client send requests on behalf of the user, the
+// secret is taken from the flag or else from the stored credentials
+func (c *cli) actAs(user string) error {
+	secret := c.secret
+	if secret == "" {
+		creds, err := c.credentials()
+		if err != nil {
+			return err
+		}
+		if cred, ok := creds.find(c.server, c.token, user); ok {
+			user, secret = cred.ID, cred.Secret
+		}
+	}
+	c.client.SetUser(user, secret)
+	return nil
+}
+
+// credentials loads the stored credentials on first use
+func (c *cli) credentials() (*credentials, error) {
+	if c.creds == nil {
+		creds, err := loadCredentials(c.credentialsPath)
+		if err != nil {
+			return nil, err
+		}
+		c.creds = creds
+	}
+	return c.creds, nil
+}
+
+func (c *cli) requireSession() error {
+	if c.token == "" {
+		return fmt.Errorf("No session token provided, use --session or DOKER_SESSION")
+	}
+	return nil
+}
+
+func (c *cli) requireSessionAndArg(name string) (string, error) {
+	if err := c.requireSession(); err != nil {
+		return "", err
+	}
+	if len(c.args) < 1 || c.args[0] == "" {
+		return "", fmt.Errorf("Command %s requires a %s", c.command, name)
+	}
+	return c.args[0], nil
+}
+
+func (c *cli) prompt(label string) (string, error) {
+	fmt.Fprint(c.out, label)
+	line, err := c.in.ReadString('\n')
+	line = strings.TrimSpace(line)
+	if err != nil && (err != io.EOF || line == "") {
+		return "", fmt.Errorf("Unable to read input for: %s", strings.TrimSuffix(label, ": "))
+	}
+	return line, nil
+}
+
+func (c *cli) promptFloat(label string) (float64, error) {
+	line, err := c.prompt(label)
+	if err != nil {
+		return 0, err
+	}
+	v, err := strconv.ParseFloat(line, 64)
+	if err != nil {
+		return 0, fmt.Errorf("Invalid number: %s", line)
+	}
+	return v, nil
+}
+
+func (c *cli) printOk() error {
+	if c.json {
+		return c.printJSON(map[string]string{"message": "ok"})
+	}
+	fmt.Fprintln(c.out, "ok")
+	return nil
+}
+
+func (c *cli) printJSON(v interface{}) error {
+	enc := json.NewEncoder(c.out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', 2, 64)
+}
+
+func nonEmpty(values []string) []string {
+	var res []string
+	for _, v := range values {
+		if v != "" {
+			res = append(res, v)
+		}
+	}
+	return res
+}
@@ -0,0 +1,223 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/genjidb/genji"
+	"github.com/haro87/dokerb/pkg/apiserver"
+	"github.com/haro87/dokerb/pkg/client"
+	"github.com/haro87/dokerb/pkg/datastore"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+)
+
+var server, credentialsPath string
+
+func setupTestCaseForRealServer(t *testing.T, withSecrets bool) func(t *testing.T) {
+	td, _ := ioutil.TempDir("", "doker-test")
+	db, _ := genji.Open(td + "/my.db")
+	db = db.WithContext(context.Background())
+	ds, _ := datastore.NewGenjiDatastore(db)
+
+	var opts []apiserver.Option
+	if withSecrets {
+		ss, _ := datastore.NewGenjiSecretStore(db)
+		opts = append(opts, apiserver.WithSecrets(ss))
+	}
+
+	app := apiserver.NewServer(&apiserver.Config{}, ds, nil, opts...).Start()
+	ln, _ := net.Listen("tcp", "127.0.0.1:0")
+	go app.Listener(ln)
+
+	server = "http://" + ln.Addr().String()
+	credentialsPath = td + "/credentials.json"
+
+	return func(t *testing.T) {
+		http.DefaultClient.CloseIdleConnections()
+		app.Shutdown()
+		db.Close()
+		os.RemoveAll(td)
+		server, credentialsPath = "", ""
+	}
+}
+
+func runCommand(input string, args ...string) (string, error) {
+	var out bytes.Buffer
+	err := run(append([]string{"--server", server, "--credentials", credentialsPath}, args...), strings.NewReader(input), &out)
+	return out.String(), err
+}
+
+func TestRunFailsDueToMissingCommand(t *testing.T) {
+	var out bytes.Buffer
+	err := run([]string{}, strings.NewReader(""), &out)
+	assert.Equal(t, "No command provided", err.Error())
+	assert.Contains(t, out.String(), "Usage: doker")
+}
+
+func TestRunFailsDueToUnknownCommand(t *testing.T) {
+	var out bytes.Buffer
+	err := run([]string{"fly"}, strings.NewReader(""), &out)
+	assert.Equal(t, "Unknown command: fly", err.Error())
+}
+
+func TestRunFailsDueToMissingSession(t *testing.T) {
+	os.Unsetenv("DOKER_SESSION")
+	var out bytes.Buffer
+	err := run([]string{"tasks"}, strings.NewReader(""), &out)
+	assert.Equal(t, "No session token provided, use --session or DOKER_SESSION", err.Error())
+}
+
+func TestRunFailsDueToMissingArgument(t *testing.T) {
+	var out bytes.Buffer
+	err := run([]string{"--session", "12345", "join"}, strings.NewReader(""), &out)
+	assert.Equal(t, "Command join requires a user name", err.Error())
+}
+
+func TestRunUsesSessionFromEnvironment(t *testing.T) {
+	setupAndTearDown := setupTestCaseForRealServer(t, false)
+	defer setupAndTearDown(t)
+
+	token, err := runCommand("", "create")
+	assert.NoError(t, err)
+
+	os.Setenv("DOKER_SESSION", strings.TrimSpace(token))
+	defer os.Unsetenv("DOKER_SESSION")
+
+	out, err := runCommand("", "join", "Tigger")
+	assert.NoError(t, err)
+	assert.Len(t, strings.TrimSpace(out), 16)
+}
+
+func TestEstimateFailsDueToInvalidNumber(t *testing.T) {
+	setupAndTearDown := setupTestCaseForRealServer(t, false)
+	defer setupAndTearDown(t)
+
+	_, err := runCommand("Tigger\nabc\n", "--session", "12345", "estimate", "TEST01")
+	assert.Equal(t, "Invalid number: abc", err.Error())
+}
+
+func TestEstimateFailsDueToMissingInput(t *testing.T) {
+	setupAndTearDown := setupTestCaseForRealServer(t, false)
+	defer setupAndTearDown(t)
+
+	_, err := runCommand("Tigger\n1\n", "--session", "12345", "estimate", "TEST01")
+	assert.Equal(t, "Unable to read input for: Most likely case", err.Error())
+}
+
+func TestSessionWorkflowSuccess(t *testing.T) {
+	setupAndTearDown := setupTestCaseForRealServer(t, false)
+	defer setupAndTearDown(t)
+
+	out, err := runCommand("", "create")
+	assert.NoError(t, err)
+	token := strings.TrimSpace(out)
+	assert.Len(t, token, 32)
+
+	_, err = runCommand("", "--session", token, "join", "Tigger")
+	assert.NoError(t, err)
+	_, err = runCommand("", "--session", token, "join", "Rabbit")
+	assert.NoError(t, err)
+
+	out, err = runCommand("", "--session", token, "add-task", "TEST01", "a", "sample", "task")
+	assert.NoError(t, err)
+	assert.Equal(t, "ok\n", out)
+
+	out, err = runCommand("", "--session", token, "tasks")
+	assert.NoError(t, err)
+	assert.Contains(t, out, "TEST01")
+	assert.Contains(t, out, "a sample task")
+
+	out, err = runCommand("Tigger\n1\n2\n3\n", "--session", token, "estimate", "TEST01")
+	assert.NoError(t, err)
+	assert.Contains(t, out, "User name: Best case: Most likely case: Worst case: ok")
+
+	_, err = runCommand("3\n4\n5\n", "--session", token, "--user", "Rabbit", "estimate", "TEST01")
+	assert.NoError(t, err)
+
+	_, err = runCommand("3\n4\n5\n", "--session", token, "--user", "Rabbit", "estimate", "TEST01")
+	assert.Equal(t, "Specified estimate already exists", err.Error())
+
+	out, err = runCommand("", "--session", token, "result", "TEST01")
+	assert.NoError(t, err)
+	assert.Contains(t, out, "MAX DISTANCE USERS")
+	assert.Contains(t, out, "3.00")
+	assert.Contains(t, out, "Rabbit, Tigger")
+
+	out, err = runCommand("", "--session", token, "--json", "result", "TEST01")
+	assert.NoError(t, err)
+	var res resultOutput
+	assert.NoError(t, json.Unmarshal([]byte(out), &res))
+	assert.Equal(t, "TEST01", res.TaskID)
+	assert.Equal(t, 3.0, res.Effort)
+	assert.Equal(t, []string{"Rabbit", "Tigger"}, res.DistanceUsers)
+
+	out, err = runCommand("", "--session", token, "--json", "users")
+	assert.NoError(t, err)
+	var users []client.User
+	assert.NoError(t, json.Unmarshal([]byte(out), &users))
+	assert.Len(t, users, 2)
+	assert.Equal(t, "Tigger", users[0].Name)
+	assert.Equal(t, "Rabbit", users[1].Name)
+
+	out, err = runCommand("", "--session", token, "estimates")
+	assert.NoError(t, err)
+	assert.Contains(t, out, "Rabbit")
+
+	_, err = runCommand("", "--session", token, "leave", "Rabbit")
+	assert.NoError(t, err)
+
+	_, err = runCommand("", "--session", token, "remove")
+	assert.NoError(t, err)
+}
+
+func TestSessionWorkflowWithSecrets(t *testing.T) {
+	setupAndTearDown := setupTestCaseForRealServer(t, true)
+	defer setupAndTearDown(t)
+
+	out, err := runCommand("", "create")
+	assert.NoError(t, err)
+	token := strings.TrimSpace(out)
+
+	out, err = runCommand("", "--session", token, "join", "Tigger")
+	assert.NoError(t, err)
+	tigger := strings.TrimSpace(out)
+	_, err = runCommand("", "--session", token, "add-task", "TEST01")
+	assert.NoError(t, err)
+
+	// The only joined user estimates without being asked for
+	out, err = runCommand("1\n2\n3\n", "--session", token, "estimate", "TEST01")
+	assert.NoError(t, err)
+	assert.Equal(t, "Best case: Most likely case: Worst case: ok\n", out)
+
+	_, err = runCommand("", "--session", token, "join", "Rabbit")
+	assert.NoError(t, err)
+	out, err = runCommand("Rabbit\n3\n4\n5\n", "--session", token, "estimate", "TEST01")
+	assert.NoError(t, err)
+	assert.Contains(t, out, "User name: ")
+	_, err = runCommand("", "--session", token, "--user", "Rabbit", "--json", "estimates")
+	assert.NoError(t, err)
+
+	_, err = runCommand("", "--session", token, "--user", "Rabbit", "--secret", "guess", "leave", "Rabbit")
+	assert.Equal(t, "Header X-Doker-Secret must contain the secret of user: Rabbit", err.Error())
+	_, err = runCommand("", "--session", token, "--user", tigger, "leave", "Rabbit")
+	assert.Equal(t, "Only the user or the moderator may change user: Rabbit", err.Error())
+	_, err = runCommand("", "--session", token, "leave", "Rabbit")
+	assert.NoError(t, err)
+
+	creds, err := loadCredentials(credentialsPath)
+	assert.NoError(t, err)
+	assert.Len(t, creds.entries, 1)
+	assert.Equal(t, tigger, creds.entries[0].ID)
+
+	_, err = runCommand("", "--session", token, "remove")
+	assert.NoError(t, err)
+	creds, err = loadCredentials(credentialsPath)
+	assert.NoError(t, err)
+	assert.Empty(t, creds.entries)
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// credential represents a user joined via the CLI together with the
+// secret it got from the server
+type credential struct {
+	Server  string `json:"server"`
+	Session string `json:"session"`
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Secret  string `json:"secret"`
+}
+
+// credentials keeps the users joined via the CLI in a file readable
+// only by its owner, so that later commands can act on their behalf
+type credentials struct {
+	path    string
+	entries []credential
+}
+
+// defaultCredentialsPath returns the file credentials are kept in,
+// if not configured otherwise
+func defaultCredentialsPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ".doker-credentials.json"
+	}
+	return filepath.Join(dir, "doker", "credentials.json")
+}
+
+// loadCredentials reads the credentials from the file, a missing
+// file contains no credentials
+func loadCredentials(path string) (*credentials, error) {
+	c := &credentials{path: path}
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Unable to read credentials: %s", err)
+	}
+	if err := json.Unmarshal(data, &c.entries); err != nil {
+		return nil, fmt.Errorf("Unable to read credentials: %s", err)
+	}
+	return c, nil
+}
+
+// find returns the credential of the user of the session, which is
+// referenced by ID or name. Without user the only credential of the
+// session is returned.
+func (c *credentials) find(server, session, user string) (credential, bool) {
+	var found []credential
+	for _, e := range c.entries {
+		if e.Server != server || e.Session != session {
+			continue
+		}
+		if user == "" || e.ID == user || e.Name == user {
+			found = append(found, e)
+		}
+	}
+	if len(found) != 1 {
+		return credential{}, false
+	}
+	return found[0], true
+}
+
+// add stores the credential, replacing one of the same user
+func (c *credentials) add(cred credential) error {
+	c.drop(cred.Server, cred.Session, cred.ID)
+	c.entries = append(c.entries, cred)
+	return c.save()
+}
+
+// remove forgets the credential of the user with the provided ID
+func (c *credentials) remove(server, session, id string) error {
+	if !c.drop(server, session, id) {
+		return nil
+	}
+	return c.save()
+}
+
+// removeSession forgets the credentials of all users of the session
+func (c *credentials) removeSession(server, session string) error {
+	kept := c.entries[:0]
+	for _, e := range c.entries {
+		if e.Server != server || e.Session != session {
+			kept = append(kept, e)
+		}
+	}
+	if len(kept) == len(c.entries) {
+		return nil
+	}
+	c.entries = kept
+	return c.save()
+}
+
+func (c *credentials) drop(server, session, id string) bool {
+	kept := c.entries[:0]
+	for _, e := range c.entries {
+		if e.Server != server || e.Session != session || e.ID != id {
+			kept = append(kept, e)
+		}
+	}
+	dropped := len(kept) != len(c.entries)
+	c.entries = kept
+	return dropped
+}
+
+func (c *credentials) save() error {
+	data, err := json.MarshalIndent(c.entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0700); err != nil {
+		return fmt.Errorf("Unable to store credentials: %s", err)
+	}
+	if err := ioutil.WriteFile(c.path, data, 0600); err != nil {
+		return fmt.Errorf("Unable to store credentials: %s", err)
+	}
+	return nil
+}
@@ -51,21 +51,21 @@ var doc = `{
                 }
             }
         },
-        "/sessions": {
-            "post": {
-                "description": "Creates a new Doker session and responds with the corresponding token",
+        "/portfolios": {
+            "get": {
+                "description": "Gets the names of all portfolios, neither their IDs nor their sessions are revealed",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "session"
+                    "portfolio"
                 ],
-                "summary": "Create a new Doker session",
+                "summary": "Get all portfolios",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/apiserver.GeneralResponse"
+                            "$ref": "#/definitions/apiserver.PortfoliosResponse"
                         }
                     },
                     "500": {
@@ -75,25 +75,28 @@ var doc = `{
                         }
                     }
                 }
-            }
-        },
-        "/sessions/{token}": {
-            "delete": {
-                "description": "Deletes a existing Doker session based on the provided token",
+            },
+            "post": {
+                "description": "Creates a named portfolio grouping several existing sessions. The route of the response contains the secret ID of the portfolio, which is required to get, change or remove it.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "session"
+                    "portfolio"
                 ],
-                "summary": "Delete a existing Doker session",
+                "summary": "Create a portfolio",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Session Token",
-                        "name": "token",
-                        "in": "path",
-                        "required": true
+                        "description": "Portfolio",
+                        "name": "portfolio",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.Portfolio"
+                        }
                     }
                 ],
                 "responses": {
@@ -103,6 +106,12 @@ var doc = `{
                             "$ref": "#/definitions/apiserver.GeneralResponse"
                         }
                     },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -112,30 +121,36 @@ var doc = `{
                 }
             }
         },
-        "/sessions/{token}/estimates": {
+        "/portfolios/{id}": {
             "get": {
-                "description": "Gets all estimates of all existing users of all existing tasks inside a existing session",
+                "description": "Gets the finalized tasks of all sessions of a portfolio together with the total effort per session and overall, efforts are converted into the requested unit or the configured default unit. The standard deviation of a total assumes the tasks to be independent. Pending counts tasks estimated by users but not finalized, unestimated counts tasks without any estimate. Sessions removed since are flagged and counted as removed.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "estimate"
+                    "portfolio"
                 ],
-                "summary": "Get the estimates of all users for all tasks",
+                "summary": "Get the summary of a portfolio",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Session Token",
-                        "name": "token",
+                        "description": "Portfolio ID",
+                        "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Unit to convert into, one of hours, days or weeks",
+                        "name": "unit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/apiserver.PerUserEstimateResponse"
+                            "$ref": "#/definitions/apiserver.PortfolioResponse"
                         }
                     },
                     "400": {
@@ -144,6 +159,12 @@ var doc = `{
                             "$ref": "#/definitions/apiserver.ErrorResponse"
                         }
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -152,30 +173,33 @@ var doc = `{
                     }
                 }
             },
-            "post": {
-                "description": "Adds a estimate of a existing user of a existing task inside a existing session",
+            "put": {
+                "description": "Replaces the name and sessions of an existing portfolio",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "estimate"
+                    "portfolio"
                 ],
-                "summary": "Add the estimate of a user for a task",
+                "summary": "Save a portfolio",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Session Token",
-                        "name": "token",
+                        "description": "Portfolio ID",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "New Estimate",
-                        "name": "estimate",
+                        "description": "Portfolio",
+                        "name": "portfolio",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/apiserver.PerUserEstimate"
+                            "$ref": "#/definitions/apiserver.Portfolio"
                         }
                     }
                 ],
@@ -192,6 +216,12 @@ var doc = `{
                             "$ref": "#/definitions/apiserver.ErrorResponse"
                         }
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -199,29 +229,20 @@ var doc = `{
                         }
                     }
                 }
-            }
-        },
-        "/sessions/{token}/estimates/{id}": {
-            "get": {
-                "description": "Gets the average estimate of all existing users of a existing task inside a existing session",
+            },
+            "delete": {
+                "description": "Removes the portfolio with the specified ID, its sessions are kept",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "estimate"
+                    "portfolio"
                 ],
-                "summary": "Get the average estimate of all users for a specific task",
+                "summary": "Remove a portfolio",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Session Token",
-                        "name": "token",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Task ID",
+                        "description": "Portfolio ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -231,11 +252,11 @@ var doc = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/apiserver.CalcEstimate"
+                            "$ref": "#/definitions/apiserver.GeneralResponse"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/apiserver.ErrorResponse"
                         }
@@ -249,37 +270,34 @@ var doc = `{
                 }
             }
         },
-        "/sessions/{token}/estimates/{id}/users/distance": {
-            "get": {
-                "description": "Gets the users with max distance in their estimates of a existing task inside a existing session",
+        "/sessions": {
+            "post": {
+                "description": "Creates a new Doker session, optionally from a saved template, and responds with the corresponding token. If secrets are enabled, the response contains the secrets of the users of the template.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "estimate"
+                    "session"
                 ],
-                "summary": "Get the users with max distance between their estimates for a specific task",
+                "summary": "Create a new Doker session",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Session Token",
-                        "name": "token",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Task ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Template to create the session from",
+                        "name": "session",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.NewSession"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/apiserver.UsersResponse"
+                            "$ref": "#/definitions/apiserver.SessionResponse"
                         }
                     },
                     "400": {
@@ -288,6 +306,18 @@ var doc = `{
                             "$ref": "#/definitions/apiserver.ErrorResponse"
                         }
                     },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -297,16 +327,16 @@ var doc = `{
                 }
             }
         },
-        "/sessions/{token}/estimates/{user}/{id}": {
+        "/sessions/{token}": {
             "delete": {
-                "description": "Removes a estimate of a existing user of a existing task inside a existing session",
+                "description": "Deletes a existing Doker session based on the provided token",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "estimate"
+                    "session"
                 ],
-                "summary": "Remove the estimate of a user for a task",
+                "summary": "Delete a existing Doker session",
                 "parameters": [
                     {
                         "type": "string",
@@ -314,20 +344,6 @@ var doc = `{
                         "name": "token",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "User Name",
-                        "name": "user",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Task ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
                     }
                 ],
                 "responses": {
@@ -343,6 +359,12 @@ var doc = `{
                             "$ref": "#/definitions/apiserver.ErrorResponse"
                         }
                     },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -352,16 +374,16 @@ var doc = `{
                 }
             }
         },
-        "/sessions/{token}/tasks": {
+        "/sessions/{token}/audit": {
             "get": {
-                "description": "Gets all tasks of an existing session",
+                "description": "Gets the mutations of a session in the order they happened, including the user or IP they were requested by and the values before and after. In anonymous sessions users and estimates are shown as for estimates, the IP is left out for unrevealed actors.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "task"
+                    "session"
                 ],
-                "summary": "Get the tasks of a session",
+                "summary": "Get the audit log of a session",
                 "parameters": [
                     {
                         "type": "string",
@@ -369,13 +391,43 @@ var doc = `{
                         "name": "token",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID or name of the requesting user",
+                        "name": "X-Doker-User",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only return entries of this type, e.g. task.finalized",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of entries to skip",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Max number of entries to return, defaults to 100",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/apiserver.TaskResponse"
+                            "$ref": "#/definitions/apiserver.AuditResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.ErrorResponse"
                         }
                     },
                     "500": {
@@ -385,16 +437,21 @@ var doc = `{
                         }
                     }
                 }
-            },
+            }
+        },
+        "/sessions/{token}/clone": {
             "post": {
-                "description": "Adds a new (non-existing) task to an existing session",
+                "description": "Creates a new session with the users and settings of an existing session in a single step, tasks without a final estimate are copied if requested. If secrets are enabled, the response contains new secrets for the users of the clone.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "task"
+                    "session"
                 ],
-                "summary": "Add a new task to a existing session",
+                "summary": "Clone a session",
                 "parameters": [
                     {
                         "type": "string",
@@ -404,12 +461,11 @@ var doc = `{
                         "required": true
                     },
                     {
-                        "description": "New Task",
-                        "name": "task",
+                        "description": "Clone options",
+                        "name": "clone",
                         "in": "body",
-                        "required": true,
                         "schema": {
-                            "$ref": "#/definitions/apiserver.Task"
+                            "$ref": "#/definitions/apiserver.CloneSession"
                         }
                     }
                 ],
@@ -417,7 +473,7 @@ var doc = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/apiserver.GeneralResponse"
+                            "$ref": "#/definitions/apiserver.SessionResponse"
                         }
                     },
                     "400": {
@@ -426,6 +482,18 @@ var doc = `{
                             "$ref": "#/definitions/apiserver.ErrorResponse"
                         }
                     },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -435,16 +503,16 @@ var doc = `{
                 }
             }
         },
-        "/sessions/{token}/tasks/{id}": {
-            "put": {
-                "description": "Updates a estimate of a existing task inside a existing session",
+        "/sessions/{token}/estimates": {
+            "get": {
+                "description": "Gets all estimates of all existing users of all existing tasks inside a existing session, in anonymous sessions names are only revealed to the moderator and the owner of the estimate",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "task"
+                    "estimate"
                 ],
-                "summary": "Update the estimate of a task",
+                "summary": "Get the estimates of all users for all tasks",
                 "parameters": [
                     {
                         "type": "string",
@@ -455,26 +523,22 @@ var doc = `{
                     },
                     {
                         "type": "string",
-                        "description": "ID of the task",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "ID or name of the requesting user",
+                        "name": "X-Doker-User",
+                        "in": "header"
                     },
                     {
-                        "description": "New Estimate",
-                        "name": "estimate",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/apiserver.Estimate"
-                        }
+                        "type": "string",
+                        "description": "Unit to convert into, one of hours, days or weeks",
+                        "name": "unit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/apiserver.GeneralResponse"
+                            "$ref": "#/definitions/apiserver.PerUserEstimateResponse"
                         }
                     },
                     "400": {
@@ -491,15 +555,15 @@ var doc = `{
                     }
                 }
             },
-            "delete": {
-                "description": "Removes a existing task from an existing session",
+            "post": {
+                "description": "Adds a estimate of a existing user of a existing task inside a existing session, the user is referenced by ID or unique name. Observers can't provide estimates. The optional rationale may have up to 1000 and each of the up to 10 assumptions up to 200 characters, control characters are removed.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "task"
+                    "estimate"
                 ],
-                "summary": "Remove a task from a session",
+                "summary": "Add the estimate of a user for a task",
                 "parameters": [
                     {
                         "type": "string",
@@ -508,12 +572,26 @@ var doc = `{
                         "in": "path",
                         "required": true
                     },
+                    {
+                        "description": "New Estimate",
+                        "name": "estimate",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.PerUserEstimate"
+                        }
+                    },
                     {
                         "type": "string",
-                        "description": "ID of the task",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "ID or name of the requesting user",
+                        "name": "X-Doker-User",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Secret of the requesting user, if secrets are enabled",
+                        "name": "X-Doker-Secret",
+                        "in": "header"
                     }
                 ],
                 "responses": {
@@ -523,6 +601,42 @@ var doc = `{
                             "$ref": "#/definitions/apiserver.GeneralResponse"
                         }
                     },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -532,16 +646,130 @@ var doc = `{
                 }
             }
         },
-        "/sessions/{token}/tasks/{id}/estimate": {
+        "/sessions/{token}/estimates/{id}": {
+            "get": {
+                "description": "Gets the average estimate of all existing users of a existing task inside a existing session in the unit of the session or the requested unit, hours, days and weeks are converted into each other. Observers aren't expected to provide estimates and, if presence is enabled, only users who are online or idle, unless absent users are requested.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "estimate"
+                ],
+                "summary": "Get the average estimate of all users for a specific task",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Session Token",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Unit to convert into, one of hours, days or weeks",
+                        "name": "unit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Also expect estimates of offline users",
+                        "name": "absent",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.CalcEstimate"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/sessions/{token}/estimates/{id}/users/distance": {
+            "get": {
+                "description": "Gets the users with max distance in their estimates of a existing task inside a existing session together with their estimates, including rationale and assumptions, in anonymous sessions names are only revealed to the moderator and the owner of the estimate",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "estimate"
+                ],
+                "summary": "Get the users with max distance between their estimates for a specific task",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Session Token",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID or name of the requesting user",
+                        "name": "X-Doker-User",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.DistanceResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/sessions/{token}/estimates/{user}/{id}": {
             "delete": {
-                "description": "Removes the estimate from an existing task",
+                "description": "Removes a estimate of a existing user of a existing task inside a existing session",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "task"
+                    "estimate"
                 ],
-                "summary": "Delete the estimate from a task",
+                "summary": "Remove the estimate of a user for a task",
                 "parameters": [
                     {
                         "type": "string",
@@ -552,10 +780,29 @@ var doc = `{
                     },
                     {
                         "type": "string",
-                        "description": "ID of the task",
+                        "description": "ID or unique name of the user",
+                        "name": "user",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Task ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID or name of the requesting user",
+                        "name": "X-Doker-User",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Secret of the requesting user, if secrets are enabled",
+                        "name": "X-Doker-Secret",
+                        "in": "header"
                     }
                 ],
                 "responses": {
@@ -565,6 +812,30 @@ var doc = `{
                             "$ref": "#/definitions/apiserver.GeneralResponse"
                         }
                     },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -574,16 +845,69 @@ var doc = `{
                 }
             }
         },
-        "/sessions/{token}/users": {
+        "/sessions/{token}/export": {
             "get": {
-                "description": "Gets all users of an existing session",
+                "description": "Gets the users, tasks, estimates and, if enabled, the comments of an existing session at once in the unit of the session or the requested unit. In anonymous sessions users are shown as for estimates.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "user"
+                    "session"
                 ],
-                "summary": "Get the users of an existing session",
+                "summary": "Export a session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Session Token",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID or name of the requesting user",
+                        "name": "X-Doker-User",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Unit to convert into, one of hours, days or weeks",
+                        "name": "unit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.ExportResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/sessions/{token}/lifecycle": {
+            "get": {
+                "description": "Gets the lifecycle state of an existing session, which is one of open, estimating, closed or archived, and how it changed. Sessions are open unless changed.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "session"
+                ],
+                "summary": "Get the lifecycle state of a session",
                 "parameters": [
                     {
                         "type": "string",
@@ -597,7 +921,13 @@ var doc = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/apiserver.UsersResponse"
+                            "$ref": "#/definitions/apiserver.LifecycleResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.ErrorResponse"
                         }
                     },
                     "500": {
@@ -608,15 +938,18 @@ var doc = `{
                     }
                 }
             },
-            "post": {
-                "description": "Adds a new (non-existing) user to an existing session",
+            "put": {
+                "description": "Changes the lifecycle state of a session from open to estimating or closed, from estimating to closed and from closed to archived. Closed and archived sessions reject all changes of users, tasks and estimates, while they can still be read. Every change must be requested on behalf of a user of the session. As closed sessions are meant to stay as they are, closing, archiving and reopening a session by changing it to open may only be done by the moderator, so a moderator has to be set before. Reopening requires a reason. Without moderator any user may start estimating, once a moderator is set only the moderator may change the state.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "user"
+                    "session"
                 ],
-                "summary": "Add a new user to a existing session",
+                "summary": "Change the lifecycle state of a session",
                 "parameters": [
                     {
                         "type": "string",
@@ -626,12 +959,25 @@ var doc = `{
                         "required": true
                     },
                     {
-                        "description": "New User",
-                        "name": "user",
+                        "type": "string",
+                        "description": "ID or name of the requesting user",
+                        "name": "X-Doker-User",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Secret of the requesting user, if secrets are enabled",
+                        "name": "X-Doker-Secret",
+                        "in": "header"
+                    },
+                    {
+                        "description": "New state",
+                        "name": "change",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/apiserver.User"
+                            "$ref": "#/definitions/apiserver.StateChange"
                         }
                     }
                 ],
@@ -639,7 +985,31 @@ var doc = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/apiserver.GeneralResponse"
+                            "$ref": "#/definitions/apiserver.LifecycleResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.ErrorResponse"
                         }
                     },
                     "500": {
@@ -651,16 +1021,16 @@ var doc = `{
                 }
             }
         },
-        "/sessions/{token}/users/{name}": {
-            "delete": {
-                "description": "Removes a existing user from an existing session",
+        "/sessions/{token}/presence": {
+            "get": {
+                "description": "Gets whether the users of an existing session are online, idle or offline together with the time of their last heartbeat. In anonymous sessions users are shown as for estimates.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "user"
                 ],
-                "summary": "Remove a user from a session",
+                "summary": "Get the presence of the users of a session",
                 "parameters": [
                     {
                         "type": "string",
@@ -671,17 +1041,22 @@ var doc = `{
                     },
                     {
                         "type": "string",
-                        "description": "Name of the user",
-                        "name": "name",
-                        "in": "path",
-                        "required": true
+                        "description": "ID or name of the requesting user",
+                        "name": "X-Doker-User",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/apiserver.GeneralResponse"
+                            "$ref": "#/definitions/apiserver.PresenceResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.ErrorResponse"
                         }
                     },
                     "500": {
@@ -692,54 +1067,2484 @@ var doc = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "apiserver.CalcEstimate": {
+        },
+        "/sessions/{token}/settings": {
+            "get": {
+                "description": "Gets the settings of an existing session, anonymity is off and the configured default unit is used unless changed",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "session"
+                ],
+                "summary": "Get the settings of a session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Session Token",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.SettingsResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Updates the settings of an existing session. In anonymous sessions estimates are returned with pseudonyms or without names to everyone except the moderator and the owner of the estimate. The moderator is referenced by ID or unique name and stored by ID. Once a moderator is set, only the moderator may change the settings. If a deck is set, estimates must use the values of its cards.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "session"
+                ],
+                "summary": "Update the settings of a session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Session Token",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID or name of the requesting user",
+                        "name": "X-Doker-User",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Settings",
+                        "name": "settings",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.Settings"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.GeneralResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/sessions/{token}/state": {
+            "get": {
+                "description": "Gets the users, tasks and estimates of a session by replaying its events up to the requested sequence number or time, without either all events are replayed. Removed sessions can be replayed as well. In anonymous sessions names are only revealed to the moderator and the owner of the estimate.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "session"
+                ],
+                "summary": "Get the state of a session as of an earlier event",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Session Token",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Sequence number of the last event to replay",
+                        "name": "seq",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Time of the last event to replay in RFC 3339 format",
+                        "name": "at",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.SessionStateResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/sessions/{token}/tasks": {
+            "get": {
+                "description": "Gets all tasks of an existing session, final estimates are converted into the requested unit",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "task"
+                ],
+                "summary": "Get the tasks of a session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Session Token",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Unit to convert into, one of hours, days or weeks",
+                        "name": "unit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.TaskResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Adds a new (non-existing) task to an existing session",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "task"
+                ],
+                "summary": "Add a new task to a existing session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Session Token",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New Task",
+                        "name": "task",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.Task"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.GeneralResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/sessions/{token}/tasks/{id}": {
+            "put": {
+                "description": "Updates a estimate of a existing task inside a existing session",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "task"
+                ],
+                "summary": "Update the estimate of a task",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Session Token",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of the task",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New Estimate",
+                        "name": "estimate",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.Estimate"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.GeneralResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Removes a existing task from an existing session",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "task"
+                ],
+                "summary": "Remove a task from a session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Session Token",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of the task",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.GeneralResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/sessions/{token}/tasks/{id}/comments": {
+            "get": {
+                "description": "Gets the comments of a task in the order they were added, in anonymous sessions authors are only revealed to the moderator and to themselves",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "comment"
+                ],
+                "summary": "Get the comments of a task",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Session Token",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID or name of the requesting user",
+                        "name": "X-Doker-User",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.CommentsResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Adds a comment of the requesting user to an existing task, e.g. to explain an outlier estimate. The comment may refer to the estimate of a user for the task and to the estimation round it was written in.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "comment"
+                ],
+                "summary": "Add a comment to a task",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Session Token",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID or name of the author",
+                        "name": "X-Doker-User",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Comment",
+                        "name": "comment",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.Comment"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.GeneralResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/sessions/{token}/tasks/{id}/comments/{comment}": {
+            "put": {
+                "description": "Replaces the text of a comment, only the author may edit the comment",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "comment"
+                ],
+                "summary": "Edit a comment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Session Token",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comment ID",
+                        "name": "comment",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID or name of the author",
+                        "name": "X-Doker-User",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Comment, only the text is used",
+                        "name": "text",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.Comment"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.GeneralResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Removes a comment, only the author and the moderator may remove the comment",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "comment"
+                ],
+                "summary": "Remove a comment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Session Token",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comment ID",
+                        "name": "comment",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID or name of the author or moderator",
+                        "name": "X-Doker-User",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.GeneralResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/sessions/{token}/tasks/{id}/estimate": {
+            "delete": {
+                "description": "Removes the estimate from an existing task",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "task"
+                ],
+                "summary": "Delete the estimate from a task",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Session Token",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of the task",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.GeneralResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/sessions/{token}/tasks/{id}/progress": {
+            "get": {
+                "description": "Gets which users of an existing session submitted an estimate for a task without revealing the estimates, ready is set once all users did. Observers aren't expected to provide estimates. In anonymous sessions users are shown as for estimates.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "estimate"
+                ],
+                "summary": "Get the voting progress of a specific task",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Session Token",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID or name of the requesting user",
+                        "name": "X-Doker-User",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.ProgressResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/sessions/{token}/tasks/{id}/sync": {
+            "get": {
+                "description": "Gets the outcome of the latest sync of the task to the issue tracker, which is unsynced if the task was never synced",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tracker"
+                ],
+                "summary": "Get the sync status of a task",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Session Token",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.SyncStatusResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/sessions/{token}/users": {
+            "get": {
+                "description": "Gets all users of an existing session with their ID, profile and role, which is either estimator or observer",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Get the users of an existing session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Session Token",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.SessionUsersResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Adds a new user to an existing session either as estimator or as observer, which watches the session without providing estimates. The user gets an ID, so several users may share a name. If secrets are enabled, the user gets a secret, which is only returned once and must be sent in the X-Doker-Secret header whenever the X-Doker-User header references the user.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Add a new user to a existing session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Session Token",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New User",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.User"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.JoinResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/sessions/{token}/users/{id}": {
+            "put": {
+                "description": "Updates the name, avatar, email or role of an existing user of an existing session, the ID and the estimates of the user are kept",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Update a user of a session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Session Token",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of the user",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Updated User",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.User"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID or name of the requesting user",
+                        "name": "X-Doker-User",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Secret of the requesting user, if secrets are enabled",
+                        "name": "X-Doker-Secret",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.GeneralResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/sessions/{token}/users/{user}": {
+            "delete": {
+                "description": "Removes a existing user from an existing session",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Remove a user from a session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Session Token",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID or unique name of the user",
+                        "name": "user",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID or name of the requesting user",
+                        "name": "X-Doker-User",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Secret of the requesting user, if secrets are enabled",
+                        "name": "X-Doker-Secret",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.GeneralResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/sessions/{token}/users/{user}/heartbeat": {
+            "post": {
+                "description": "Marks an existing user of an existing session as online, clients are expected to send heartbeats periodically while the session is open. Users without heartbeat become idle and later offline after the configured timeouts.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Send a heartbeat of a user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Session Token",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID or unique name of the user",
+                        "name": "user",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID or name of the requesting user",
+                        "name": "X-Doker-User",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Secret of the requesting user, if secrets are enabled",
+                        "name": "X-Doker-Secret",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.GeneralResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/sessions/{token}/webhooks": {
+            "get": {
+                "description": "Gets all webhooks registered for the session, without their secrets",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhook"
+                ],
+                "summary": "Get all webhooks",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Session Token",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.WebhooksResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Registers a webhook receiving the specified events of the session as signed JSON, all events are sent if none are specified. Webhooks must not target loopback, private or link-local addresses, unless allowed in the config.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhook"
+                ],
+                "summary": "Register a webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Session Token",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Webhook",
+                        "name": "webhook",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.Webhook"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.GeneralResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/sessions/{token}/webhooks/deliveries": {
+            "get": {
+                "description": "Gets the delivery log of all webhooks of the session in the order the deliveries happened",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhook"
+                ],
+                "summary": "Get webhook deliveries",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Session Token",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of deliveries to skip",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Max number of deliveries to return, defaults to 100",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.DeliveriesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/sessions/{token}/webhooks/{id}": {
+            "delete": {
+                "description": "Removes the webhook with the specified ID from the session",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhook"
+                ],
+                "summary": "Remove a webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Session Token",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Webhook ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.GeneralResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/templates": {
+            "get": {
+                "description": "Gets all saved session templates",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "template"
+                ],
+                "summary": "Get all session templates",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.TemplatesResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Saves a named template with the users, tasks and settings new sessions start with, replacing an existing template with the same name",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "template"
+                ],
+                "summary": "Save a session template",
+                "parameters": [
+                    {
+                        "description": "Template",
+                        "name": "template",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.SessionTemplate"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.GeneralResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/templates/{name}": {
+            "get": {
+                "description": "Gets the session template with the specified name",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "template"
+                ],
+                "summary": "Get a session template",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Template Name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.TemplateResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Removes the session template with the specified name, sessions created from it are kept",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "template"
+                ],
+                "summary": "Remove a session template",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Template Name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.GeneralResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apiserver.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "apiserver.AuditEntry": {
+            "type": "object",
+            "properties": {
+                "actor": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "Tigger"
+                },
+                "actorid": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "tigger"
+                },
+                "after": {
+                    "type": "object"
+                },
+                "before": {
+                    "type": "object"
+                },
+                "ip": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "192.0.2.1"
+                },
+                "time": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "2021-01-14T15:04:05Z"
+                },
+                "type": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "task.finalized"
+                }
+            }
+        },
+        "apiserver.AuditResponse": {
+            "type": "object",
+            "properties": {
+                "entries": {
+                    "type": "array",
+                    "format": "[]AuditEntry",
+                    "items": {
+                        "$ref": "#/definitions/apiserver.AuditEntry"
+                    }
+                },
+                "message": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "ok"
+                },
+                "total": {
+                    "type": "integer",
+                    "format": "int",
+                    "example": 42
+                }
+            }
+        },
+        "apiserver.CalcEstimate": {
+            "type": "object",
+            "properties": {
+                "conversions": {
+                    "type": "array",
+                    "format": "[]UnitEstimate",
+                    "items": {
+                        "$ref": "#/definitions/apiserver.UnitEstimate"
+                    }
+                },
+                "estimate": {
+                    "format": "Estimate",
+                    "$ref": "#/definitions/apiserver.Estimate"
+                },
+                "hint": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "not all users provided estimates"
+                },
+                "message": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "warning"
+                },
+                "unit": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "hours"
+                },
+                "users": {
+                    "type": "array",
+                    "format": "[]string",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "Tigger"
+                    ]
+                }
+            }
+        },
+        "apiserver.Card": {
+            "type": "object",
+            "properties": {
+                "label": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "M"
+                },
+                "value": {
+                    "type": "number",
+                    "format": "float64",
+                    "example": 3
+                }
+            }
+        },
+        "apiserver.CloneSession": {
+            "type": "object",
+            "properties": {
+                "tasks": {
+                    "type": "boolean",
+                    "format": "bool",
+                    "example": true
+                }
+            }
+        },
+        "apiserver.Comment": {
+            "type": "object",
+            "properties": {
+                "estimate": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "3f2a9c1e7b4d8e60"
+                },
+                "round": {
+                    "type": "integer",
+                    "format": "int",
+                    "example": 2
+                },
+                "text": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "Login needs a password reset as well"
+                }
+            }
+        },
+        "apiserver.CommentInfo": {
+            "type": "object",
+            "properties": {
+                "author": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "Tigger"
+                },
+                "authorid": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "3f2a9c1e7b4d8e60"
+                },
+                "edited": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "2021-01-14T15:10:00Z"
+                },
+                "estimate": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "3f2a9c1e7b4d8e60"
+                },
+                "id": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "9d1c0e2f3a4b5c6d"
+                },
+                "round": {
+                    "type": "integer",
+                    "format": "int",
+                    "example": 2
+                },
+                "task": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "TEST01"
+                },
+                "text": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "Login needs a password reset as well"
+                },
+                "time": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "2021-01-14T15:04:05Z"
+                }
+            }
+        },
+        "apiserver.CommentsResponse": {
+            "type": "object",
+            "properties": {
+                "comments": {
+                    "type": "array",
+                    "format": "[]CommentInfo",
+                    "items": {
+                        "$ref": "#/definitions/apiserver.CommentInfo"
+                    }
+                },
+                "message": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "ok"
+                }
+            }
+        },
+        "apiserver.DeliveriesResponse": {
+            "type": "object",
+            "properties": {
+                "deliveries": {
+                    "type": "array",
+                    "format": "[]datastore.Delivery",
+                    "items": {
+                        "$ref": "#/definitions/datastore.Delivery"
+                    }
+                },
+                "message": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "ok"
+                },
+                "total": {
+                    "type": "integer",
+                    "format": "int",
+                    "example": 42
+                }
+            }
+        },
+        "apiserver.DistanceResponse": {
+            "type": "object",
+            "properties": {
+                "estimates": {
+                    "type": "array",
+                    "format": "[]datastore.Estimate",
+                    "items": {
+                        "$ref": "#/definitions/datastore.Estimate"
+                    }
+                },
+                "message": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "ok"
+                },
+                "users": {
+                    "type": "array",
+                    "format": "[]string",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "Tigger",
+                        "Rabbit"
+                    ]
+                }
+            }
+        },
+        "apiserver.DocEntry": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "GitHub"
+                },
+                "url": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "https://github.com/HaRo87"
+                }
+            }
+        },
+        "apiserver.DocResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "ok"
+                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/apiserver.DocEntry"
+                    }
+                }
+            }
+        },
+        "apiserver.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "error"
+                },
+                "reason": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "oops, something went wrong"
+                }
+            }
+        },
+        "apiserver.Estimate": {
+            "type": "object",
+            "properties": {
+                "effort": {
+                    "type": "number",
+                    "format": "float64",
+                    "example": 1.5
+                },
+                "standarddeviation": {
+                    "type": "number",
+                    "format": "float64",
+                    "example": 0.2
+                }
+            }
+        },
+        "apiserver.ExportResponse": {
+            "type": "object",
+            "properties": {
+                "comments": {
+                    "type": "array",
+                    "format": "[]CommentInfo",
+                    "items": {
+                        "$ref": "#/definitions/apiserver.CommentInfo"
+                    }
+                },
+                "estimates": {
+                    "type": "array",
+                    "format": "[]datastore.Estimate",
+                    "items": {
+                        "$ref": "#/definitions/datastore.Estimate"
+                    }
+                },
+                "message": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "ok"
+                },
+                "tasks": {
+                    "type": "array",
+                    "format": "[]datastore.Task",
+                    "items": {
+                        "$ref": "#/definitions/datastore.Task"
+                    }
+                },
+                "unit": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "hours"
+                },
+                "users": {
+                    "type": "array",
+                    "format": "[]User",
+                    "items": {
+                        "$ref": "#/definitions/apiserver.User"
+                    }
+                }
+            }
+        },
+        "apiserver.GeneralResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "ok"
+                },
+                "route": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "/sessions/token"
+                }
+            }
+        },
+        "apiserver.JoinResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "ok"
+                },
+                "route": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "/sessions/token/users/3f2a9c1e7b4d8e60"
+                },
+                "secret": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "9b1c4e7a2d5f8a3c6e9b2d4f7a1c3e5b"
+                }
+            }
+        },
+        "apiserver.LifecycleResponse": {
+            "type": "object",
+            "properties": {
+                "changes": {
+                    "type": "array",
+                    "format": "[]StateChangeInfo",
+                    "items": {
+                        "$ref": "#/definitions/apiserver.StateChangeInfo"
+                    }
+                },
+                "message": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "ok"
+                },
+                "state": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "closed"
+                }
+            }
+        },
+        "apiserver.NewSession": {
+            "type": "object",
+            "properties": {
+                "template": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "sprint"
+                }
+            }
+        },
+        "apiserver.Participant": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "3f2a9c1e7b4d8e60"
+                },
+                "lastseen": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "2021-01-14T15:04:05Z"
+                },
+                "name": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "Tigger"
+                },
+                "state": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "online"
+                }
+            }
+        },
+        "apiserver.PerUserEstimate": {
+            "type": "object",
+            "properties": {
+                "assumptions": {
+                    "type": "array",
+                    "format": "[]string",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "SSO is out of scope"
+                    ]
+                },
+                "b": {
+                    "type": "number",
+                    "format": "float64",
+                    "example": 1.5
+                },
+                "id": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "TEST01"
+                },
+                "m": {
+                    "type": "number",
+                    "format": "float64",
+                    "example": 2
+                },
+                "rationale": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "Login needs a password reset as well"
+                },
+                "user": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "Tigger"
+                },
+                "userid": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "3f2a9c1e7b4d8e60"
+                },
+                "w": {
+                    "type": "number",
+                    "format": "float64",
+                    "example": 3.6
+                }
+            }
+        },
+        "apiserver.PerUserEstimateResponse": {
+            "type": "object",
+            "properties": {
+                "estimates": {
+                    "type": "array",
+                    "format": "[]datastore.Estimate",
+                    "items": {
+                        "$ref": "#/definitions/datastore.Estimate"
+                    }
+                },
+                "message": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "ok"
+                },
+                "unit": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "hours"
+                }
+            }
+        },
+        "apiserver.Portfolio": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "roadmap"
+                },
+                "sessions": {
+                    "type": "array",
+                    "format": "[]string",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "eaf27c59ecdf0db4e165c4f940e176ec"
+                    ]
+                }
+            }
+        },
+        "apiserver.PortfolioResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "ok"
+                },
+                "portfolio": {
+                    "format": "PortfolioSummary",
+                    "$ref": "#/definitions/apiserver.PortfolioSummary"
+                }
+            }
+        },
+        "apiserver.PortfolioSummary": {
+            "type": "object",
+            "properties": {
+                "effort": {
+                    "type": "number",
+                    "format": "float64",
+                    "example": 43
+                },
+                "finalized": {
+                    "type": "integer",
+                    "format": "int",
+                    "example": 12
+                },
+                "name": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "roadmap"
+                },
+                "pending": {
+                    "type": "integer",
+                    "format": "int",
+                    "example": 4
+                },
+                "removed": {
+                    "type": "integer",
+                    "format": "int",
+                    "example": 0
+                },
+                "sessions": {
+                    "type": "array",
+                    "format": "[]SessionSummary",
+                    "items": {
+                        "$ref": "#/definitions/apiserver.SessionSummary"
+                    }
+                },
+                "standarddeviation": {
+                    "type": "number",
+                    "format": "float64",
+                    "example": 3.1
+                },
+                "unestimated": {
+                    "type": "integer",
+                    "format": "int",
+                    "example": 6
+                },
+                "unit": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "days"
+                }
+            }
+        },
+        "apiserver.PortfoliosResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "ok"
+                },
+                "portfolios": {
+                    "type": "array",
+                    "format": "[]string",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "roadmap"
+                    ]
+                }
+            }
+        },
+        "apiserver.PresenceResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "ok"
+                },
+                "participants": {
+                    "type": "array",
+                    "format": "[]Participant",
+                    "items": {
+                        "$ref": "#/definitions/apiserver.Participant"
+                    }
+                }
+            }
+        },
+        "apiserver.ProgressResponse": {
+            "type": "object",
+            "properties": {
+                "estimated": {
+                    "type": "integer",
+                    "format": "int",
+                    "example": 3
+                },
+                "message": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "ok"
+                },
+                "missing": {
+                    "type": "integer",
+                    "format": "int",
+                    "example": 1
+                },
+                "ready": {
+                    "type": "boolean",
+                    "format": "bool",
+                    "example": false
+                },
+                "total": {
+                    "type": "integer",
+                    "format": "int",
+                    "example": 4
+                },
+                "users": {
+                    "type": "array",
+                    "format": "[]UserProgress",
+                    "items": {
+                        "$ref": "#/definitions/apiserver.UserProgress"
+                    }
+                }
+            }
+        },
+        "apiserver.SessionResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "ok"
+                },
+                "route": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "/sessions/token"
+                },
+                "secrets": {
+                    "type": "array",
+                    "format": "[]UserSecret",
+                    "items": {
+                        "$ref": "#/definitions/apiserver.UserSecret"
+                    }
+                }
+            }
+        },
+        "apiserver.SessionStateResponse": {
+            "type": "object",
+            "properties": {
+                "estimates": {
+                    "type": "array",
+                    "format": "[]datastore.Estimate",
+                    "items": {
+                        "$ref": "#/definitions/datastore.Estimate"
+                    }
+                },
+                "message": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "ok"
+                },
+                "removed": {
+                    "type": "boolean",
+                    "format": "bool",
+                    "example": false
+                },
+                "seq": {
+                    "type": "integer",
+                    "format": "int",
+                    "example": 12
+                },
+                "tasks": {
+                    "type": "array",
+                    "format": "[]datastore.Task",
+                    "items": {
+                        "$ref": "#/definitions/datastore.Task"
+                    }
+                },
+                "time": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "2021-01-14T15:04:05.123Z"
+                },
+                "token": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "eaf27c59ecdf0db4e165c4f940e176ec"
+                },
+                "unit": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "hours"
+                },
+                "users": {
+                    "type": "array",
+                    "format": "[]User",
+                    "items": {
+                        "$ref": "#/definitions/apiserver.User"
+                    }
+                }
+            }
+        },
+        "apiserver.SessionSummary": {
+            "type": "object",
+            "properties": {
+                "effort": {
+                    "type": "number",
+                    "format": "float64",
+                    "example": 21.5
+                },
+                "pending": {
+                    "type": "integer",
+                    "format": "int",
+                    "example": 2
+                },
+                "removed": {
+                    "type": "boolean",
+                    "format": "bool",
+                    "example": false
+                },
+                "standarddeviation": {
+                    "type": "number",
+                    "format": "float64",
+                    "example": 2.4
+                },
+                "tasks": {
+                    "type": "array",
+                    "format": "[]datastore.Task",
+                    "items": {
+                        "$ref": "#/definitions/datastore.Task"
+                    }
+                },
+                "token": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "eaf27c59ecdf0db4e165c4f940e176ec"
+                },
+                "unestimated": {
+                    "type": "integer",
+                    "format": "int",
+                    "example": 3
+                }
+            }
+        },
+        "apiserver.SessionTemplate": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "sprint"
+                },
+                "settings": {
+                    "format": "Settings",
+                    "$ref": "#/definitions/apiserver.Settings"
+                },
+                "tasks": {
+                    "type": "array",
+                    "format": "[]Task",
+                    "items": {
+                        "$ref": "#/definitions/apiserver.Task"
+                    }
+                },
+                "users": {
+                    "type": "array",
+                    "format": "[]string",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "Tigger",
+                        "Rabbit"
+                    ]
+                }
+            }
+        },
+        "apiserver.SessionUsersResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "ok"
+                },
+                "users": {
+                    "type": "array",
+                    "format": "[]User",
+                    "items": {
+                        "$ref": "#/definitions/apiserver.User"
+                    }
+                }
+            }
+        },
+        "apiserver.Settings": {
+            "type": "object",
+            "properties": {
+                "anonymity": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "pseudonyms"
+                },
+                "cards": {
+                    "type": "array",
+                    "format": "[]Card",
+                    "items": {
+                        "$ref": "#/definitions/apiserver.Card"
+                    }
+                },
+                "deck": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "fibonacci"
+                },
+                "moderator": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "Tigger"
+                },
+                "unit": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "hours"
+                }
+            }
+        },
+        "apiserver.SettingsResponse": {
             "type": "object",
             "properties": {
-                "estimate": {
-                    "format": "Estimate",
-                    "$ref": "#/definitions/apiserver.Estimate"
+                "message": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "ok"
                 },
-                "hint": {
+                "settings": {
+                    "format": "Settings",
+                    "$ref": "#/definitions/apiserver.Settings"
+                }
+            }
+        },
+        "apiserver.StateChange": {
+            "type": "object",
+            "properties": {
+                "reason": {
                     "type": "string",
                     "format": "string",
-                    "example": "not all users provided estimates"
+                    "example": "Planning is complete"
+                },
+                "state": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "closed"
+                }
+            }
+        },
+        "apiserver.StateChangeInfo": {
+            "type": "object",
+            "properties": {
+                "actor": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "3f2a9c1e7b4d8e60"
+                },
+                "from": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "estimating"
+                },
+                "reason": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "Planning is complete"
+                },
+                "time": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "2021-01-14T15:04:05Z"
                 },
+                "to": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "closed"
+                }
+            }
+        },
+        "apiserver.SyncStatusResponse": {
+            "type": "object",
+            "properties": {
                 "message": {
                     "type": "string",
                     "format": "string",
-                    "example": "warning"
+                    "example": "ok"
                 },
-                "users": {
-                    "type": "array",
-                    "format": "[]string",
-                    "items": {
-                        "type": "string"
-                    },
-                    "example": [
-                        "Tigger"
-                    ]
+                "sync": {
+                    "format": "datastore.SyncStatus",
+                    "$ref": "#/definitions/datastore.SyncStatus"
                 }
             }
         },
-        "apiserver.DocEntry": {
+        "apiserver.Task": {
             "type": "object",
             "properties": {
-                "name": {
+                "id": {
                     "type": "string",
                     "format": "string",
-                    "example": "GitHub"
+                    "example": "TEST01"
                 },
-                "url": {
+                "summary": {
                     "type": "string",
                     "format": "string",
-                    "example": "https://github.com/HaRo87"
+                    "example": "a sample task"
                 }
             }
         },
-        "apiserver.DocResponse": {
+        "apiserver.TaskResponse": {
             "type": "object",
             "properties": {
                 "message": {
@@ -747,30 +3552,52 @@ var doc = `{
                     "format": "string",
                     "example": "ok"
                 },
-                "results": {
+                "tasks": {
                     "type": "array",
+                    "format": "[]datastore.Task",
                     "items": {
-                        "$ref": "#/definitions/apiserver.DocEntry"
+                        "$ref": "#/definitions/datastore.Task"
                     }
+                },
+                "unit": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "hours"
                 }
             }
         },
-        "apiserver.ErrorResponse": {
+        "apiserver.TemplateResponse": {
             "type": "object",
             "properties": {
                 "message": {
                     "type": "string",
                     "format": "string",
-                    "example": "error"
+                    "example": "ok"
                 },
-                "reason": {
+                "template": {
+                    "format": "SessionTemplate",
+                    "$ref": "#/definitions/apiserver.SessionTemplate"
+                }
+            }
+        },
+        "apiserver.TemplatesResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
                     "type": "string",
                     "format": "string",
-                    "example": "oops, something went wrong"
+                    "example": "ok"
+                },
+                "templates": {
+                    "type": "array",
+                    "format": "[]SessionTemplate",
+                    "items": {
+                        "$ref": "#/definitions/apiserver.SessionTemplate"
+                    }
                 }
             }
         },
-        "apiserver.Estimate": {
+        "apiserver.UnitEstimate": {
             "type": "object",
             "properties": {
                 "effort": {
@@ -782,87 +3609,137 @@ var doc = `{
                     "type": "number",
                     "format": "float64",
                     "example": 0.2
+                },
+                "unit": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "days"
                 }
             }
         },
-        "apiserver.GeneralResponse": {
+        "apiserver.User": {
             "type": "object",
             "properties": {
-                "message": {
+                "avatar": {
                     "type": "string",
                     "format": "string",
-                    "example": "ok"
+                    "example": "https://example.com/tigger.png"
                 },
-                "route": {
+                "email": {
                     "type": "string",
                     "format": "string",
-                    "example": "/sessions/token"
+                    "example": "tigger@example.com"
+                },
+                "id": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "3f2a9c1e7b4d8e60"
+                },
+                "name": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "Tigger"
+                },
+                "role": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "observer"
                 }
             }
         },
-        "apiserver.PerUserEstimate": {
+        "apiserver.UserProgress": {
             "type": "object",
             "properties": {
-                "b": {
-                    "type": "number",
-                    "format": "float64",
-                    "example": 1.5
+                "estimated": {
+                    "type": "boolean",
+                    "format": "bool",
+                    "example": true
                 },
                 "id": {
                     "type": "string",
                     "format": "string",
-                    "example": "TEST01"
+                    "example": "3f2a9c1e7b4d8e60"
                 },
-                "m": {
-                    "type": "number",
-                    "format": "float64",
-                    "example": 2
+                "name": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "Tigger"
+                }
+            }
+        },
+        "apiserver.UserSecret": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "3f2a9c1e7b4d8e60"
                 },
-                "user": {
+                "name": {
                     "type": "string",
                     "format": "string",
                     "example": "Tigger"
                 },
-                "w": {
-                    "type": "number",
-                    "format": "float64",
-                    "example": 3.6
+                "secret": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "9b1c4e7a2d5f8a3c6e9b2d4f7a1c3e5b"
                 }
             }
         },
-        "apiserver.PerUserEstimateResponse": {
+        "apiserver.Webhook": {
             "type": "object",
             "properties": {
-                "estimates": {
+                "events": {
                     "type": "array",
-                    "format": "[]datastore.Estimate",
+                    "format": "[]string",
                     "items": {
-                        "$ref": "#/definitions/datastore.Estimate"
-                    }
+                        "type": "string"
+                    },
+                    "example": [
+                        "task.finalized",
+                        "session.removed"
+                    ]
                 },
-                "message": {
+                "secret": {
                     "type": "string",
                     "format": "string",
-                    "example": "ok"
+                    "example": "s3cr3t"
+                },
+                "url": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "https://bot.example.com/doker"
                 }
             }
         },
-        "apiserver.Task": {
+        "apiserver.WebhookInfo": {
             "type": "object",
             "properties": {
+                "events": {
+                    "type": "array",
+                    "format": "[]string",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "task.finalized",
+                        "session.removed"
+                    ]
+                },
                 "id": {
                     "type": "string",
                     "format": "string",
-                    "example": "TEST01"
+                    "example": "3f2a9c0d1e4b5a6f"
                 },
-                "summary": {
+                "url": {
                     "type": "string",
                     "format": "string",
-                    "example": "a sample task"
+                    "example": "https://bot.example.com/doker"
                 }
             }
         },
-        "apiserver.TaskResponse": {
+        "apiserver.WebhooksResponse": {
             "type": "object",
             "properties": {
                 "message": {
@@ -870,58 +3747,71 @@ var doc = `{
                     "format": "string",
                     "example": "ok"
                 },
-                "tasks": {
+                "webhooks": {
                     "type": "array",
-                    "format": "[]datastore.Task",
+                    "format": "[]WebhookInfo",
                     "items": {
-                        "$ref": "#/definitions/datastore.Task"
+                        "$ref": "#/definitions/apiserver.WebhookInfo"
                     }
                 }
             }
         },
-        "apiserver.User": {
+        "datastore.Delivery": {
             "type": "object",
             "properties": {
-                "name": {
-                    "type": "string",
-                    "format": "string",
-                    "example": "Tigger"
+                "attempts": {
+                    "type": "integer"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "event": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "statusCode": {
+                    "type": "integer"
+                },
+                "time": {
+                    "type": "string"
+                },
+                "webhookID": {
+                    "type": "string"
                 }
             }
         },
-        "apiserver.UsersResponse": {
+        "datastore.Estimate": {
             "type": "object",
             "properties": {
-                "message": {
-                    "type": "string",
-                    "format": "string",
-                    "example": "ok"
-                },
-                "users": {
+                "assumptions": {
                     "type": "array",
-                    "format": "[]string",
                     "items": {
                         "type": "string"
-                    },
-                    "example": [
-                        "Tigger",
-                        "Rabbit"
-                    ]
-                }
-            }
-        },
-        "datastore.Estimate": {
-            "type": "object",
-            "properties": {
+                    }
+                },
                 "bestCase": {
                     "type": "number"
                 },
                 "mostLikelyCase": {
                     "type": "number"
                 },
+                "orphaned": {
+                    "type": "boolean"
+                },
+                "rationale": {
+                    "type": "string"
+                },
                 "taskID": {
                     "type": "string"
                 },
+                "userID": {
+                    "type": "string"
+                },
                 "userName": {
                     "type": "string"
                 },
@@ -930,12 +3820,35 @@ var doc = `{
                 }
             }
         },
+        "datastore.SyncStatus": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "request": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "taskID": {
+                    "type": "string"
+                },
+                "time": {
+                    "type": "string"
+                }
+            }
+        },
         "datastore.Task": {
             "type": "object",
             "properties": {
                 "effort": {
                     "type": "number"
                 },
+                "finalized": {
+                    "type": "boolean"
+                },
                 "id": {
                     "type": "string"
                 },
@@ -9,6 +9,7 @@ require (
 	github.com/genjidb/genji v0.9.0
 	github.com/gofiber/fiber/v2 v2.3.2
 	github.com/klauspost/compress v1.11.4 // indirect
+	github.com/prometheus/client_golang v1.9.0
 	github.com/stretchr/testify v1.6.1
 	github.com/swaggo/swag v1.7.0
 	go.uber.org/multierr v1.6.0 // indirect
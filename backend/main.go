@@ -2,36 +2,198 @@ package main
 
 import (
 	"context"
-	"fmt"
+	"flag"
 	"github.com/genjidb/genji"
 	"github.com/haro87/dokerb/pkg/apiserver"
 	"github.com/haro87/dokerb/pkg/datastore"
-	"log"
+	"github.com/haro87/dokerb/pkg/tracker"
+	"github.com/haro87/dokerb/pkg/webhook"
+	"go.uber.org/zap"
 	"os"
 	"os/signal"
+	"syscall"
+	"time"
 )
 
 func main() {
-	// Parse config path from environment variable.
-	configPath := apiserver.GetEnv("CONFIG_PATH", "configs/apiserver.yml")
+	// Use a default logger until the configured one is available.
+	if bootstrap, err := zap.NewProduction(); err == nil {
+		zap.ReplaceGlobals(bootstrap)
+	}
 
-	// Create new config.
-	config, err := apiserver.NewConfig(configPath)
+	// Create new config from defaults, config file, environment and flags.
+	config, printConfig, err := apiserver.LoadConfig(os.Args[1:])
+	if err == flag.ErrHelp {
+		return
+	}
 	apiserver.ErrChecker(err)
 
+	if printConfig {
+		apiserver.ErrChecker(config.Print(os.Stdout))
+		return
+	}
+
+	// Create new logger.
+	logger, err := apiserver.NewLogger(config)
+	apiserver.ErrChecker(err)
+	defer logger.Sync()
+	zap.ReplaceGlobals(logger)
+
 	db, err := genji.Open(config.Database.Location)
 	if err != nil {
-		panic(fmt.Sprintf("Unable to create new database at: %s", config.Database.Location))
+		logger.Fatal("Unable to create new database",
+			zap.String("location", config.Database.Location), zap.Error(err))
 	}
 	db = db.WithContext(context.Background())
 	defer db.Close()
 
-	gds, gerr := datastore.NewGenjiDatastore(db)
-	if gerr != nil {
-		panic("Unable to create new datastore")
+	// Migrate sessions, which still identify users by name only.
+	if err := datastore.MigrateUsers(db); err != nil {
+		logger.Fatal("Unable to migrate users", zap.Error(err))
+	}
+
+	// Create datastore, which keeps the history of all sessions
+	// as events if event sourcing is enabled.
+	var gds datastore.DataStore
+	var replayer datastore.SessionReplayer
+	if config.Database.EventSourced {
+		es, err := datastore.NewEventSourcedDatastore(db,
+			datastore.WithEventLogger(logger.Named("datastore")),
+			datastore.WithEventRedactedTokens(config.Logger.RedactTokens),
+			datastore.WithEventRemovalPolicy(config.Database.RemovalPolicy),
+		)
+		if err != nil {
+			logger.Fatal("Unable to create new event-sourced datastore", zap.Error(err))
+		}
+		gds, replayer = es, es
+	} else {
+		gds, err = datastore.NewGenjiDatastore(db,
+			datastore.WithLogger(logger.Named("datastore")),
+			datastore.WithRedactedTokens(config.Logger.RedactTokens),
+			datastore.WithRemovalPolicy(config.Database.RemovalPolicy),
+		)
+		if err != nil {
+			logger.Fatal("Unable to create new datastore", zap.Error(err))
+		}
+	}
+	// Create webhook store and start sending session events.
+	hooks, err := datastore.NewGenjiWebhookStore(db)
+	if err != nil {
+		logger.Fatal("Unable to create new webhook store", zap.Error(err))
+	}
+	dispatcher := webhook.NewDispatcher(hooks,
+		webhook.WithLogger(logger.Named("webhook")),
+		webhook.WithClient(webhook.NewClient(config.Webhooks.Timeout, config.Webhooks.AllowPrivateTargets)),
+		webhook.WithWorkers(config.Webhooks.Workers),
+		webhook.WithRetries(config.Webhooks.MaxAttempts, config.Webhooks.Backoff),
+		webhook.WithQueueSize(config.Webhooks.QueueSize),
+	)
+	dispatcher.Start()
+
+	// Create settings store for anonymous estimation.
+	settings, err := datastore.NewGenjiSettingsStore(db)
+	if err != nil {
+		logger.Fatal("Unable to create new settings store", zap.Error(err))
+	}
+
+	// Create template store for session templates and cloning.
+	templates, err := datastore.NewGenjiTemplateStore(db,
+		datastore.WithTemplateEvents(config.Database.EventSourced),
+	)
+	if err != nil {
+		logger.Fatal("Unable to create new template store", zap.Error(err))
+	}
+
+	portfolios, err := datastore.NewGenjiPortfolioStore(db)
+	if err != nil {
+		logger.Fatal("Unable to create new portfolio store", zap.Error(err))
+	}
+
+	audit, err := datastore.NewGenjiAuditStore(db)
+	if err != nil {
+		logger.Fatal("Unable to create new audit store", zap.Error(err))
+	}
+
+	comments, err := datastore.NewGenjiCommentStore(db)
+	if err != nil {
+		logger.Fatal("Unable to create new comment store", zap.Error(err))
+	}
+
+	lifecycles, err := datastore.NewGenjiLifecycleStore(db)
+	if err != nil {
+		logger.Fatal("Unable to create new lifecycle store", zap.Error(err))
+	}
+
+	// Create secret store, so that users prove who they are.
+	secrets, err := datastore.NewGenjiSecretStore(db)
+	if err != nil {
+		logger.Fatal("Unable to create new secret store", zap.Error(err))
+	}
+
+	opts := []apiserver.Option{
+		apiserver.WithWebhooks(hooks, dispatcher),
+		apiserver.WithSettings(settings),
+		apiserver.WithTemplates(templates),
+		apiserver.WithPortfolios(portfolios),
+		apiserver.WithAudit(audit),
+		apiserver.WithComments(comments),
+		apiserver.WithLifecycle(lifecycles),
+		apiserver.WithSecrets(secrets),
+	}
+	if replayer != nil {
+		opts = append(opts, apiserver.WithReplay(replayer))
+	}
+
+	// Sync finalized tasks to the issue tracker, if enabled.
+	var syncer *tracker.Syncer
+	if config.Tracker.Enabled {
+		syncs, err := datastore.NewGenjiSyncStore(db)
+		if err != nil {
+			logger.Fatal("Unable to create new sync store", zap.Error(err))
+		}
+		sink, err := tracker.NewRESTSink(config.Tracker.RESTConfig())
+		if err != nil {
+			logger.Fatal("Unable to create tracker sink", zap.Error(err))
+		}
+		syncer = tracker.NewSyncer(syncs, sink,
+			tracker.WithLogger(logger.Named("tracker")),
+			tracker.WithDryRun(config.Tracker.DryRun),
+			tracker.WithQueueSize(config.Tracker.QueueSize),
+		)
+		syncer.Start()
+		opts = append(opts, apiserver.WithTracker(syncs, syncer))
 	}
+
+	// Load certificates, if TLS is enabled.
+	var certs *apiserver.CertReloader
+	if config.Server.TLS.Enabled() {
+		certs, err = apiserver.NewCertReloader(config.Server.TLS.CertFile, config.Server.TLS.KeyFile)
+		if err != nil {
+			logger.Fatal("Unable to load certificates", zap.Error(err))
+		}
+
+		go func() {
+			sighup := make(chan os.Signal, 1)
+			signal.Notify(sighup, syscall.SIGHUP) // reload certificates on SIGHUP
+			for range sighup {
+				if err := certs.Reload(); err != nil {
+					logger.Error("Certificate reload failed", zap.Error(err))
+					continue
+				}
+				logger.Info("Certificates reloaded")
+			}
+		}()
+	}
+
 	// Create new server.
-	server := apiserver.NewServer(config, gds).Start()
+	api := apiserver.NewServer(config, gds, logger.Named("apiserver"), opts...)
+	server := api.Start()
+
+	// Start metrics server, if enabled.
+	metricsServer := api.StartMetrics()
+
+	// Start HTTP to HTTPS redirect server, if enabled.
+	redirectServer := api.StartRedirect()
 
 	// Create channel for idle connections.
 	idleConnsClosed := make(chan struct{})
@@ -41,19 +203,38 @@ func main() {
 		signal.Notify(sigint, os.Interrupt) // catch OS signals
 		<-sigint
 
-		// We received an interrupt signal, shut down.
+		// We received an interrupt signal, stop being ready and give
+		// the readiness probe time to notice before shutting down.
+		logger.Info("Shutting down", zap.Duration("delay", config.Server.ShutdownDelay))
+		api.SetReady(false)
+		time.Sleep(config.Server.ShutdownDelay)
 		if err := server.Shutdown(); err != nil {
 			// Error from closing listeners, or context timeout:
-			log.Printf("API server Shutdown: %v", err)
+			logger.Error("API server shutdown failed", zap.Error(err))
+		}
+
+		if metricsServer != nil {
+			if err := metricsServer.Shutdown(context.Background()); err != nil {
+				logger.Error("Metrics server shutdown failed", zap.Error(err))
+			}
+		}
+
+		if redirectServer != nil {
+			if err := redirectServer.Shutdown(context.Background()); err != nil {
+				logger.Error("Redirect server shutdown failed", zap.Error(err))
+			}
+		}
+
+		dispatcher.Stop()
+		if syncer != nil {
+			syncer.Stop()
 		}
 
 		close(idleConnsClosed)
 	}()
 
 	// Start API server.
-	apiserver.ErrChecker(
-		server.Listen(config.Server.Host + ":" + config.Server.Port),
-	)
+	apiserver.ErrChecker(api.Listen(server, certs))
 
 	<-idleConnsClosed
 }
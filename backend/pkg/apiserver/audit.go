@@ -0,0 +1,223 @@
+package apiserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"github.com/haro87/dokerb/pkg/datastore"
+	"strconv"
+)
+
+// defaultAuditLimit is the page size of the audit log, if
+// no limit is requested
+const defaultAuditLimit = 100
+
+// AuditEntry represents a single mutation of a session, Before and
+// After are null if there was no value. In anonymous sessions actors,
+// users and estimates are only revealed as for estimates.
+type AuditEntry struct {
+	Type    string          `json:"type" example:"task.finalized" format:"string"`
+	Actor   string          `json:"actor" example:"Tigger" format:"string"`
+	ActorID string          `json:"actorid" example:"tigger" format:"string"`
+	IP      string          `json:"ip" example:"192.0.2.1" format:"string"`
+	Time    string          `json:"time" example:"2021-01-14T15:04:05Z" format:"string"`
+	Before  json.RawMessage `json:"before" swaggertype:"object"`
+	After   json.RawMessage `json:"after" swaggertype:"object"`
+}
+
+// AuditResponse represents the get audit log response, Total is
+// the number of matching entries on all pages
+type AuditResponse struct {
+	Message string       `json:"message" example:"ok" format:"string"`
+	Total   int          `json:"total" example:"42" format:"int"`
+	Entries []AuditEntry `json:"entries" format:"[]AuditEntry"`
+}
+
+// WithAudit enables the audit log of all session mutations,
+// which is kept in the provided store
+func WithAudit(store datastore.AuditStore) Option {
+	return func(s *APIServer) {
+		s.audit = store
+	}
+}
+
+// actorStore is implemented by datastores recording who
+// mutated a session
+type actorStore interface {
+	WithActor(actor, ip string) datastore.DataStore
+}
+
+// forActor returns the datastore acting on behalf of the user the
+// request was sent by, if the datastore records actors
+func forActor(c *fiber.Ctx, store datastore.DataStore) datastore.DataStore {
+	if a, ok := store.(actorStore); ok {
+		return a.WithActor(c.Get(HeaderUser), c.IP())
+	}
+	return store
+}
+
+// auditRoutes registers the routes for inspecting the audit log
+func auditRoutes(app *fiber.App, store datastore.DataStore, audit datastore.AuditStore, settings sessionSettings) {
+	APIGroup := app.Group("/api")
+
+	addGetAuditRoute(APIGroup, store, audit, settings)
+}
+
+// Adding the get audit log route
+// @Summary Get the audit log of a session
+// @Description Gets the mutations of a session in the order they happened, including the user or IP they were requested by and the values before and after. In anonymous sessions users and estimates are shown as for estimates, the IP is left out for unrevealed actors.
+// @Tags session
+// @Produce  json
+// @Param token path string true "Session Token"
+// @Param X-Doker-User header string false "ID or name of the requesting user"
+// @Param type query string false "Only return entries of this type, e.g. task.finalized"
+// @Param offset query int false "Number of entries to skip"
+// @Param limit query int false "Max number of entries to return, defaults to 100"
+// @Success 200 {object} AuditResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /sessions/{token}/audit [get]
+func addGetAuditRoute(api fiber.Router, store datastore.DataStore, audit datastore.AuditStore, settings sessionSettings) {
+	api.Get("/sessions/:token/audit", func(c *fiber.Ctx) error {
+		query, err := auditQuery(c)
+
+		if err != nil {
+			return sendError(c, 400, err)
+		}
+
+		// The audit log outlives the session, without users
+		// nobody but the moderator is revealed
+		users, err := store.GetUsers(c.Params("token"))
+
+		if err != nil {
+			users = []datastore.User{}
+		}
+
+		v, err := newViewer(c, settings, users)
+
+		if err != nil {
+			return sendError(c, 500, err)
+		}
+
+		entries, total, err := audit.GetAuditEntries(c.Params("token"), query)
+
+		if err != nil {
+			return sendError(c, 500, err)
+		}
+
+		res := []AuditEntry{}
+		for _, e := range entries {
+			res = append(res, v.auditEntry(e))
+		}
+
+		data := AuditResponse{
+			Message: "ok",
+			Total:   total,
+			Entries: res,
+		}
+		return c.Status(200).JSON(data)
+	})
+}
+
+func auditQuery(c *fiber.Ctx) (datastore.AuditQuery, error) {
+	query := datastore.AuditQuery{Type: c.Query("type")}
+
+	if query.Type != "" && !contains(datastore.AuditTypes, query.Type) {
+		return query, fmt.Errorf("Unknown audit type: %s", query.Type)
+	}
+
+	var err error
+	query.Offset, query.Limit, err = page(c, defaultAuditLimit)
+	return query, err
+}
+
+// page returns the offset and limit requested by the query
+// parameters of the same name, with the provided default limit
+func page(c *fiber.Ctx, defaultLimit int) (int, int, error) {
+	offset, limit := 0, defaultLimit
+
+	var err error
+	if v := c.Query("offset"); v != "" {
+		if offset, err = strconv.Atoi(v); err != nil || offset < 0 {
+			return 0, 0, fmt.Errorf("Offset must be a number >= 0, provided: %s", v)
+		}
+	}
+	if v := c.Query("limit"); v != "" {
+		if limit, err = strconv.Atoi(v); err != nil || limit < 1 {
+			return 0, 0, fmt.Errorf("Limit must be a number >= 1, provided: %s", v)
+		}
+	}
+	return offset, limit, nil
+}
+
+// auditEntry returns the entry as seen by the viewer, unrevealed
+// actors are anonymized and their IP is left out
+func (v viewer) auditEntry(e datastore.AuditEntry) AuditEntry {
+	res := AuditEntry{
+		Type:    e.Type,
+		Actor:   e.Actor,
+		ActorID: e.ActorID,
+		IP:      e.IP,
+		Time:    e.Time,
+		Before:  rawJSON(v.auditValue(e.Type, e.Before)),
+		After:   rawJSON(v.auditValue(e.Type, e.After)),
+	}
+	if !v.reveals(e.ActorID) {
+		res.Actor, res.ActorID, res.IP = "", "", ""
+		if e.ActorID != "" {
+			res.Actor = v.settings.Anonymize(e.ActorID, e.Actor)
+		}
+	}
+	return res
+}
+
+// auditValue returns the value of an entry of the type as seen by
+// the viewer, values which can't be decoded are left out
+func (v viewer) auditValue(auditType, value string) string {
+	if value == "" {
+		return ""
+	}
+
+	var res interface{}
+	switch auditType {
+	case datastore.AuditEstimateAdded, datastore.AuditEstimateRemoved:
+		var e datastore.Estimate
+		if err := json.Unmarshal([]byte(value), &e); err != nil {
+			return ""
+		}
+		res = v.estimates([]datastore.Estimate{e})[0]
+	case datastore.AuditUserJoined, datastore.AuditUserLeft, datastore.AuditUserUpdated:
+		var u datastore.User
+		if err := json.Unmarshal([]byte(value), &u); err != nil {
+			return ""
+		}
+		res = v.profile(u)
+	case datastore.AuditSessionRemoved:
+		var s struct {
+			Users []datastore.User `json:"users"`
+			Tasks json.RawMessage  `json:"tasks"`
+		}
+		if err := json.Unmarshal([]byte(value), &s); err != nil {
+			return ""
+		}
+		for i, u := range s.Users {
+			s.Users[i] = v.profile(u)
+		}
+		res = s
+	default:
+		return value
+	}
+
+	b, err := json.Marshal(res)
+	if err != nil {
+		return ""
+	}
+	return string(b)
+}
+
+func rawJSON(v string) json.RawMessage {
+	if v == "" {
+		return nil
+	}
+	return json.RawMessage(v)
+}
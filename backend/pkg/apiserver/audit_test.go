@@ -0,0 +1,144 @@
+package apiserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/haro87/dokerb/pkg/datastore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+)
+
+func TestMutationsAreAudited(t *testing.T) {
+	m := new(datastore.MockDatastore)
+	m.On("JoinSession", "12345", datastore.User{Name: "Tigger"}).Return("tigger", nil)
+	m.On("JoinSession", "12345", datastore.User{Name: "Pooh"}).Return("", fmt.Errorf("Unable to add user"))
+	m.On("GetUsers", "12345").Return(testUsers("Tigger"), nil)
+	as := new(datastore.MockAuditStore)
+	as.On("AddAuditEntry", "12345", mock.MatchedBy(func(e datastore.AuditEntry) bool {
+		return e.Type == datastore.AuditUserJoined && e.Actor == "Rabbit" && e.IP == "0.0.0.0" && e.After == `{"ID":"tigger","Name":"Tigger","AvatarURL":"","Email":"","Role":"estimator"}`
+	})).Return(nil)
+
+	app := NewServer(&Config{}, m, nil, WithAudit(as)).Start()
+
+	res, err := app.Test(settingsRequest("POST", "/api/sessions/12345/users", "Rabbit", `{"name":"Tigger"}`), -1)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, res.StatusCode)
+
+	res, err = app.Test(settingsRequest("POST", "/api/sessions/12345/users", "Rabbit", `{"name":"Pooh"}`), -1)
+	assert.NoError(t, err)
+	assert.Equal(t, 500, res.StatusCode)
+
+	as.AssertNumberOfCalls(t, "AddAuditEntry", 1)
+}
+
+func TestGetAudit(t *testing.T) {
+	as := new(datastore.MockAuditStore)
+	as.On("GetAuditEntries", "12345", datastore.AuditQuery{Limit: defaultAuditLimit}).Return([]datastore.AuditEntry{
+		{Type: "task.finalized", Actor: "Tigger", IP: "192.0.2.1", Time: "2021-01-14T15:04:05Z", Before: `{"Effort":0}`, After: `{"Effort":2}`},
+		{Type: "user.joined", IP: "192.0.2.1", After: `"Pooh"`},
+	}, 2, nil)
+	as.On("GetAuditEntries", "12345", datastore.AuditQuery{Type: "user.left", Offset: 10, Limit: 5}).Return([]datastore.AuditEntry{}, 3, nil)
+	m := new(datastore.MockDatastore)
+	m.On("GetUsers", "12345").Return(testUsers("Tigger"), nil)
+
+	app := NewServer(&Config{}, m, nil, WithAudit(as)).Start()
+
+	res, err := app.Test(httptestRequest("GET", "/api/sessions/12345/audit", ""), -1)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, res.StatusCode)
+
+	var ar AuditResponse
+	assert.NoError(t, json.NewDecoder(res.Body).Decode(&ar))
+	assert.Equal(t, 2, ar.Total)
+	assert.Equal(t, AuditEntry{
+		Type:   "task.finalized",
+		Actor:  "Tigger",
+		IP:     "192.0.2.1",
+		Time:   "2021-01-14T15:04:05Z",
+		Before: json.RawMessage(`{"Effort":0}`),
+		After:  json.RawMessage(`{"Effort":2}`),
+	}, ar.Entries[0])
+	assert.Equal(t, json.RawMessage("null"), ar.Entries[1].Before)
+
+	res, err = app.Test(httptestRequest("GET", "/api/sessions/12345/audit?type=user.left&offset=10&limit=5", ""), -1)
+	assert.NoError(t, err)
+	assert.NoError(t, json.NewDecoder(res.Body).Decode(&ar))
+	assert.Equal(t, 3, ar.Total)
+	assert.Equal(t, []AuditEntry{}, ar.Entries)
+}
+
+func TestGetAuditOfAnonymousSession(t *testing.T) {
+	est := `{"TaskID":"T1","UserID":"tigger","UserName":"Tigger","BestCase":1,"MostLikelyCase":2,"WorstCase":3,"Rationale":"","Assumptions":null,"Orphaned":false}`
+	as := new(datastore.MockAuditStore)
+	as.On("GetAuditEntries", "12345", datastore.AuditQuery{Limit: defaultAuditLimit}).Return([]datastore.AuditEntry{
+		{Type: "estimate.added", Actor: "Tigger", ActorID: "tigger", IP: "192.0.2.1", After: est},
+		{Type: "user.left", Actor: "Pooh", IP: "192.0.2.2", Before: `{"ID":"pooh","Name":"Pooh","AvatarURL":"","Email":"","Role":"estimator"}`},
+		{Type: "session.removed", ActorID: "pooh", Before: `{"tasks":[{"ID":"T1"}],"users":[{"ID":"tigger","Name":"Tigger","AvatarURL":"","Email":"","Role":"estimator"}]}`},
+	}, 3, nil)
+	m := new(datastore.MockDatastore)
+	m.On("GetUsers", "12345").Return(testUsers("Tigger", "Pooh"), nil)
+	ss := new(datastore.MockSettingsStore)
+	ss.On("GetSettings", "12345").Return(datastore.Settings{Anonymity: datastore.AnonymityPseudonyms, Moderator: "pooh", Salt: "s4lt"}, nil)
+
+	app := NewServer(&Config{}, m, nil, WithAudit(as), WithSettings(ss)).Start()
+
+	res, err := app.Test(settingsRequest("GET", "/api/sessions/12345/audit", "Rabbit", ""), -1)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, res.StatusCode)
+
+	var ar AuditResponse
+	assert.NoError(t, json.NewDecoder(res.Body).Decode(&ar))
+	tigger := datastore.Pseudonym("s4lt", "tigger")
+	assert.Equal(t, AuditEntry{
+		Type:   "estimate.added",
+		Actor:  tigger,
+		Before: json.RawMessage("null"),
+		After:  json.RawMessage(`{"TaskID":"T1","UserID":"","UserName":"` + tigger + `","BestCase":1,"MostLikelyCase":2,"WorstCase":3,"Rationale":"","Assumptions":null,"Orphaned":false}`),
+	}, ar.Entries[0])
+	// Actors, who aren't known, are left out as well
+	assert.Equal(t, "", ar.Entries[1].Actor)
+	assert.Equal(t, "", ar.Entries[1].IP)
+	assert.JSONEq(t, `{"ID":"","Name":"`+datastore.Pseudonym("s4lt", "pooh")+`","AvatarURL":"","Email":"","Role":"estimator"}`, string(ar.Entries[1].Before))
+	assert.JSONEq(t, `{"tasks":[{"ID":"T1"}],"users":[{"ID":"","Name":"`+tigger+`","AvatarURL":"","Email":"","Role":"estimator"}]}`, string(ar.Entries[2].Before))
+
+	// The moderator sees everything
+	res, err = app.Test(settingsRequest("GET", "/api/sessions/12345/audit", "Pooh", ""), -1)
+	assert.NoError(t, err)
+	assert.NoError(t, json.NewDecoder(res.Body).Decode(&ar))
+	assert.Equal(t, "Tigger", ar.Entries[0].Actor)
+	assert.Equal(t, "192.0.2.1", ar.Entries[0].IP)
+	assert.Equal(t, json.RawMessage(est), ar.Entries[0].After)
+	assert.Equal(t, "Pooh", ar.Entries[1].Actor)
+}
+
+func TestGetAuditFails(t *testing.T) {
+	tests := []struct {
+		name   string
+		query  string
+		reason string
+	}{
+		{"unknown type", "?type=task.renamed", "Unknown audit type: task.renamed"},
+		{"negative offset", "?offset=-1", "Offset must be a number >= 0, provided: -1"},
+		{"invalid limit", "?limit=all", "Limit must be a number >= 1, provided: all"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			as := new(datastore.MockAuditStore)
+			app := NewServer(&Config{}, new(datastore.MockDatastore), nil, WithAudit(as)).Start()
+
+			res, err := app.Test(httptestRequest("GET", "/api/sessions/12345/audit"+tt.query, ""), -1)
+			assert.NoError(t, err)
+			assertErrorResponse(t, res, 400, tt.reason)
+			as.AssertNotCalled(t, "GetAuditEntries", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestAuditRoutesDisabled(t *testing.T) {
+	app := NewServer(&Config{}, new(datastore.MockDatastore), nil).Start()
+
+	res, err := app.Test(httptestRequest("GET", "/api/sessions/12345/audit", ""), -1)
+	assert.NoError(t, err)
+	assert.Equal(t, 404, res.StatusCode)
+}
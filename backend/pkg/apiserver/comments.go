@@ -0,0 +1,354 @@
+package apiserver
+
+import (
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"github.com/haro87/dokerb/pkg/datastore"
+)
+
+// Comment represents a new or edited comment on a task, Estimate
+// optionally references the user, by ID or unique name, whose
+// estimate of the task the comment refers to and Round the
+// estimation round of the task it was written in
+type Comment struct {
+	Text     string `json:"text" example:"Login needs a password reset as well" format:"string"`
+	Estimate string `json:"estimate" example:"3f2a9c1e7b4d8e60" format:"string"`
+	Round    int    `json:"round" example:"2" format:"int"`
+}
+
+// CommentInfo represents a comment on a task, in anonymous sessions
+// the author is only revealed like the names of estimates
+type CommentInfo struct {
+	ID       string `json:"id" example:"9d1c0e2f3a4b5c6d" format:"string"`
+	TaskID   string `json:"task" example:"TEST01" format:"string"`
+	AuthorID string `json:"authorid" example:"3f2a9c1e7b4d8e60" format:"string"`
+	Author   string `json:"author" example:"Tigger" format:"string"`
+	Text     string `json:"text" example:"Login needs a password reset as well" format:"string"`
+	Estimate string `json:"estimate,omitempty" example:"3f2a9c1e7b4d8e60" format:"string"`
+	Round    int    `json:"round,omitempty" example:"2" format:"int"`
+	Time     string `json:"time" example:"2021-01-14T15:04:05Z" format:"string"`
+	Edited   string `json:"edited,omitempty" example:"2021-01-14T15:10:00Z" format:"string"`
+}
+
+// CommentsResponse represents the get comments response
+type CommentsResponse struct {
+	Message  string        `json:"message" example:"ok" format:"string"`
+	Comments []CommentInfo `json:"comments" format:"[]CommentInfo"`
+}
+
+// WithComments enables comments on tasks, which are kept in
+// the provided store
+func WithComments(store datastore.CommentStore) Option {
+	return func(s *APIServer) {
+		s.comments = store
+	}
+}
+
+// commentRoutes registers the routes for discussing tasks
+func commentRoutes(app *fiber.App, store datastore.DataStore, comments datastore.CommentStore, settings sessionSettings) {
+	APIGroup := app.Group("/api")
+
+	addAddCommentRoute(APIGroup, store, comments)
+
+	addGetCommentsRoute(APIGroup, store, comments, settings)
+
+	addUpdateCommentRoute(APIGroup, store, comments)
+
+	addRemoveCommentRoute(APIGroup, store, comments, settings)
+}
+
+// Adding the add comment route
+// @Summary Add a comment to a task
+// @Description Adds a comment of the requesting user to an existing task, e.g. to explain an outlier estimate. The comment may refer to the estimate of a user for the task and to the estimation round it was written in.
+// @Tags comment
+// @Accept  json
+// @Produce  json
+// @Param token path string true "Session Token"
+// @Param id path string true "Task ID"
+// @Param X-Doker-User header string true "ID or name of the author"
+// @Param comment body Comment true "Comment"
+// @Success 200 {object} GeneralResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /sessions/{token}/tasks/{id}/comments [post]
+func addAddCommentRoute(api fiber.Router, store datastore.DataStore, comments datastore.CommentStore) {
+	api.Post("/sessions/:token/tasks/:id/comments", func(c *fiber.Ctx) error {
+		cm := new(Comment)
+
+		if err := c.BodyParser(cm); err != nil {
+			return sendError(c, 400, err)
+		}
+
+		users, err := store.GetUsers(c.Params("token"))
+
+		if err != nil {
+			return sendError(c, 500, err)
+		}
+
+		if c.Get(HeaderUser) == "" {
+			return sendError(c, 400, fmt.Errorf("Header %s must reference the author", HeaderUser))
+		}
+
+		author, err := lookupUser(users, c.Get(HeaderUser))
+
+		if err != nil {
+			return sendError(c, 400, err)
+		}
+
+		if err := checkTask(store, c.Params("token"), c.Params("id")); err != nil {
+			return sendError(c, 400, err)
+		}
+
+		comment := datastore.Comment{
+			TaskID:     c.Params("id"),
+			AuthorID:   author.ID,
+			AuthorName: author.Name,
+			Text:       cm.Text,
+			Round:      cm.Round,
+		}
+
+		if cm.Estimate != "" {
+			if comment.EstimateUserID, err = estimateOf(store, c.Params("token"), users, cm.Estimate, comment.TaskID); err != nil {
+				return sendError(c, 400, err)
+			}
+		}
+
+		id, err := comments.AddComment(c.Params("token"), comment)
+
+		if err != nil {
+			return sendError(c, storeErrorStatusOr(err, 400), err)
+		}
+
+		data := GeneralResponse{
+			Message: "ok",
+			Route:   "/sessions/" + c.Params("token") + "/tasks/" + comment.TaskID + "/comments/" + id,
+		}
+		return c.Status(200).JSON(data)
+	})
+}
+
+// Adding the get comments route
+// @Summary Get the comments of a task
+// @Description Gets the comments of a task in the order they were added, in anonymous sessions authors are only revealed to the moderator and to themselves
+// @Tags comment
+// @Produce  json
+// @Param token path string true "Session Token"
+// @Param id path string true "Task ID"
+// @Param X-Doker-User header string false "ID or name of the requesting user"
+// @Success 200 {object} CommentsResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /sessions/{token}/tasks/{id}/comments [get]
+func addGetCommentsRoute(api fiber.Router, store datastore.DataStore, comments datastore.CommentStore, settings sessionSettings) {
+	api.Get("/sessions/:token/tasks/:id/comments", func(c *fiber.Ctx) error {
+		users, err := store.GetUsers(c.Params("token"))
+
+		if err != nil {
+			return sendError(c, 500, err)
+		}
+
+		cms, err := comments.GetComments(c.Params("token"), c.Params("id"))
+
+		if err != nil {
+			return sendError(c, 500, err)
+		}
+
+		v, err := newViewer(c, settings, users)
+
+		if err != nil {
+			return sendError(c, 500, err)
+		}
+
+		data := CommentsResponse{
+			Message:  "ok",
+			Comments: v.comments(cms, users),
+		}
+		return c.Status(200).JSON(data)
+	})
+}
+
+// Adding the update comment route
+// @Summary Edit a comment
+// @Description Replaces the text of a comment, only the author may edit the comment
+// @Tags comment
+// @Accept  json
+// @Produce  json
+// @Param token path string true "Session Token"
+// @Param id path string true "Task ID"
+// @Param comment path string true "Comment ID"
+// @Param X-Doker-User header string true "ID or name of the author"
+// @Param text body Comment true "Comment, only the text is used"
+// @Success 200 {object} GeneralResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /sessions/{token}/tasks/{id}/comments/{comment} [put]
+func addUpdateCommentRoute(api fiber.Router, store datastore.DataStore, comments datastore.CommentStore) {
+	api.Put("/sessions/:token/tasks/:id/comments/:comment", func(c *fiber.Ctx) error {
+		cm := new(Comment)
+
+		if err := c.BodyParser(cm); err != nil {
+			return sendError(c, 400, err)
+		}
+
+		users, err := store.GetUsers(c.Params("token"))
+
+		if err != nil {
+			return sendError(c, 500, err)
+		}
+
+		existing, err := findComment(comments, c.Params("token"), c.Params("id"), c.Params("comment"))
+
+		if err != nil {
+			return sendError(c, 404, err)
+		}
+
+		if existing.AuthorID != requester(c, users) {
+			return sendError(c, 403, fmt.Errorf("Only the author may edit the comment"))
+		}
+
+		if err := comments.UpdateComment(c.Params("token"), existing.ID, cm.Text); err != nil {
+			return sendError(c, storeErrorStatusOr(err, 400), err)
+		}
+
+		data := GeneralResponse{
+			Message: "ok",
+			Route:   "/sessions/" + c.Params("token") + "/tasks/" + existing.TaskID + "/comments/" + existing.ID,
+		}
+		return c.Status(200).JSON(data)
+	})
+}
+
+// Adding the remove comment route
+// @Summary Remove a comment
+// @Description Removes a comment, only the author and the moderator may remove the comment
+// @Tags comment
+// @Produce  json
+// @Param token path string true "Session Token"
+// @Param id path string true "Task ID"
+// @Param comment path string true "Comment ID"
+// @Param X-Doker-User header string true "ID or name of the author or moderator"
+// @Success 200 {object} GeneralResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /sessions/{token}/tasks/{id}/comments/{comment} [delete]
+func addRemoveCommentRoute(api fiber.Router, store datastore.DataStore, comments datastore.CommentStore, settings sessionSettings) {
+	api.Delete("/sessions/:token/tasks/:id/comments/:comment", func(c *fiber.Ctx) error {
+		users, err := store.GetUsers(c.Params("token"))
+
+		if err != nil {
+			return sendError(c, 500, err)
+		}
+
+		existing, err := findComment(comments, c.Params("token"), c.Params("id"), c.Params("comment"))
+
+		if err != nil {
+			return sendError(c, 404, err)
+		}
+
+		v, err := newViewer(c, settings, users)
+
+		if err != nil {
+			return sendError(c, 500, err)
+		}
+
+		if v.user == "" || (existing.AuthorID != v.user && v.settings.Moderator != v.user) {
+			return sendError(c, 403, fmt.Errorf("Only the author or the moderator may remove the comment"))
+		}
+
+		if err := comments.RemoveComment(c.Params("token"), existing.ID); err != nil {
+			return sendError(c, storeErrorStatus(err), err)
+		}
+
+		data := GeneralResponse{
+			Message: "ok",
+		}
+		return c.Status(200).JSON(data)
+	})
+}
+
+// comments returns the comments as seen by the viewer, authors are
+// shown with their current name unless they left the session
+func (v viewer) comments(cms []datastore.Comment, users []datastore.User) []CommentInfo {
+	res := make([]CommentInfo, 0, len(cms))
+	for _, cm := range cms {
+		name := cm.AuthorName
+		for _, u := range users {
+			if u.ID == cm.AuthorID {
+				name = u.Name
+			}
+		}
+
+		info := CommentInfo{
+			ID:       cm.ID,
+			TaskID:   cm.TaskID,
+			AuthorID: cm.AuthorID,
+			Author:   v.name(cm.AuthorID, name),
+			Text:     cm.Text,
+			Estimate: cm.EstimateUserID,
+			Round:    cm.Round,
+			Time:     cm.Time,
+			Edited:   cm.Edited,
+		}
+		if !v.reveals(cm.AuthorID) {
+			info.AuthorID = ""
+		}
+		if !v.reveals(cm.EstimateUserID) {
+			info.Estimate = ""
+		}
+		res = append(res, info)
+	}
+	return res
+}
+
+// checkTask verifies that the task is part of the session
+func checkTask(store datastore.DataStore, token, id string) error {
+	tasks, err := store.GetTasks(token)
+	if err != nil {
+		return err
+	}
+
+	if !containsTask(tasks, id) {
+		return fmt.Errorf("Task with ID: %s does not exist", id)
+	}
+	return nil
+}
+
+// estimateOf returns the ID of the referenced user, if the user
+// provided an estimate for the task
+func estimateOf(store datastore.DataStore, token string, users []datastore.User, ref, task string) (string, error) {
+	u, err := lookupUser(users, ref)
+	if err != nil {
+		return "", err
+	}
+
+	ests, err := store.GetEstimates(token)
+	if err != nil {
+		return "", err
+	}
+
+	for _, e := range ests {
+		if e.TaskID == task && e.UserID == u.ID && !e.Orphaned {
+			return u.ID, nil
+		}
+	}
+	return "", fmt.Errorf("User: %s did not estimate task: %s", ref, task)
+}
+
+// findComment returns the comment with the ID, if it belongs
+// to the task
+func findComment(comments datastore.CommentStore, token, task, id string) (datastore.Comment, error) {
+	cm, err := comments.GetComment(token, id)
+	if err != nil {
+		return cm, err
+	}
+
+	if cm.TaskID != task {
+		return datastore.Comment{}, fmt.Errorf("Comment with ID: %s does not exist", id)
+	}
+	return cm, nil
+}
@@ -0,0 +1,227 @@
+package apiserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/haro87/dokerb/pkg/datastore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+)
+
+var testComments = []datastore.Comment{
+	{ID: "c1", TaskID: "TEST01", AuthorID: "rabbit", AuthorName: "Rabbit", Text: "Needs a password reset", EstimateUserID: "rabbit", Time: "2021-01-14T15:04:05Z"},
+	{ID: "c2", TaskID: "TEST01", AuthorID: "piglet", AuthorName: "Piglet", Text: "Left early", Time: "2021-01-14T15:05:05Z"},
+}
+
+func newCommentServer(anonymity string) (*APIServer, *datastore.MockDatastore, *datastore.MockCommentStore) {
+	m := new(datastore.MockDatastore)
+	m.On("GetUsers", "12345").Return(testUsers("Tigger", "Rabbit", "Pooh"), nil)
+	m.On("GetTasks", "12345").Return([]datastore.Task{{ID: "TEST01"}}, nil)
+	m.On("GetEstimates", "12345").Return(anonymousEstimates, nil)
+	ss := new(datastore.MockSettingsStore)
+	ss.On("GetSettings", "12345").Return(datastore.Settings{Anonymity: anonymity, Moderator: "pooh", Salt: "s4lt"}, nil)
+	cs := new(datastore.MockCommentStore)
+	cs.On("GetComments", "12345", "TEST01").Return(testComments, nil)
+	cs.On("GetComments", "12345", "").Return(testComments, nil)
+	cs.On("GetComment", "12345", "c1").Return(testComments[0], nil)
+	cs.On("GetComment", "12345", "c4").Return(datastore.Comment{ID: "c4", TaskID: "TEST02"}, nil)
+	cs.On("GetComment", "12345", mock.Anything).Return(datastore.Comment{}, fmt.Errorf("Comment with ID: c3 does not exist"))
+	return NewServer(&Config{}, m, nil, WithSettings(ss), WithComments(cs)), m, cs
+}
+
+func TestAddComment(t *testing.T) {
+	s, _, cs := newCommentServer(datastore.AnonymityOff)
+	cs.On("AddComment", "12345", datastore.Comment{
+		TaskID:         "TEST01",
+		AuthorID:       "rabbit",
+		AuthorName:     "Rabbit",
+		Text:           "Needs a password reset",
+		EstimateUserID: "rabbit",
+		Round:          2,
+	}).Return("c1", nil)
+
+	res, err := s.Start().Test(settingsRequest("POST", "/api/sessions/12345/tasks/TEST01/comments", "Rabbit",
+		`{"text":"Needs a password reset","estimate":"Rabbit","round":2}`), -1)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, res.StatusCode)
+
+	var gr GeneralResponse
+	assert.NoError(t, json.NewDecoder(res.Body).Decode(&gr))
+	assert.Equal(t, "/sessions/12345/tasks/TEST01/comments/c1", gr.Route)
+}
+
+func TestAddCommentFails(t *testing.T) {
+	tests := []struct {
+		name   string
+		route  string
+		user   string
+		body   string
+		status int
+		reason string
+	}{
+		{"no author", "/api/sessions/12345/tasks/TEST01/comments", "", `{"text":"Hi"}`, 400, "Header X-Doker-User must reference the author"},
+		{"unknown task", "/api/sessions/12345/tasks/TEST02/comments", "Rabbit", `{"text":"Hi"}`, 400, "Task with ID: TEST02 does not exist"},
+		{"unknown estimate", "/api/sessions/12345/tasks/TEST01/comments", "Rabbit", `{"text":"Hi","estimate":"Piglet"}`, 400, "User: Piglet is not part of session"},
+		{"empty text", "/api/sessions/12345/tasks/TEST01/comments", "Rabbit", `{"text":""}`, 400, "Comment text should not be empty"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _, cs := newCommentServer(datastore.AnonymityOff)
+			cs.On("AddComment", "12345", mock.Anything).Return("", fmt.Errorf("Comment text should not be empty"))
+
+			res, err := s.Start().Test(settingsRequest("POST", tt.route, tt.user, tt.body), -1)
+			assert.NoError(t, err)
+			assertErrorResponse(t, res, tt.status, tt.reason)
+		})
+	}
+}
+
+func TestAddCommentFailsDueToMissingEstimate(t *testing.T) {
+	m := new(datastore.MockDatastore)
+	m.On("GetUsers", "12345").Return(testUsers("Tigger", "Rabbit"), nil)
+	m.On("GetTasks", "12345").Return([]datastore.Task{{ID: "TEST01"}}, nil)
+	m.On("GetEstimates", "12345").Return([]datastore.Estimate{
+		{TaskID: "TEST01", UserID: "rabbit", Orphaned: true},
+	}, nil)
+	cs := new(datastore.MockCommentStore)
+
+	app := NewServer(&Config{}, m, nil, WithComments(cs)).Start()
+
+	res, err := app.Test(settingsRequest("POST", "/api/sessions/12345/tasks/TEST01/comments", "Tigger",
+		`{"text":"Why so high?","estimate":"rabbit"}`), -1)
+	assert.NoError(t, err)
+	assertErrorResponse(t, res, 400, "User: rabbit did not estimate task: TEST01")
+	cs.AssertNotCalled(t, "AddComment", mock.Anything, mock.Anything)
+}
+
+func TestGetCommentsAnonymity(t *testing.T) {
+	p := func(user string) string { return datastore.Pseudonym("s4lt", user) }
+	tests := []struct {
+		name      string
+		anonymity string
+		user      string
+		want      []CommentInfo
+	}{
+		{
+			"off",
+			datastore.AnonymityOff,
+			"",
+			[]CommentInfo{
+				{ID: "c1", TaskID: "TEST01", AuthorID: "rabbit", Author: "Rabbit", Text: "Needs a password reset", Estimate: "rabbit", Time: "2021-01-14T15:04:05Z"},
+				{ID: "c2", TaskID: "TEST01", AuthorID: "piglet", Author: "Piglet", Text: "Left early", Time: "2021-01-14T15:05:05Z"},
+			},
+		},
+		{
+			"pseudonyms",
+			datastore.AnonymityPseudonyms,
+			"Tigger",
+			[]CommentInfo{
+				{ID: "c1", TaskID: "TEST01", Author: p("rabbit"), Text: "Needs a password reset", Time: "2021-01-14T15:04:05Z"},
+				{ID: "c2", TaskID: "TEST01", Author: p("piglet"), Text: "Left early", Time: "2021-01-14T15:05:05Z"},
+			},
+		},
+		{
+			"hidden reveal own name",
+			datastore.AnonymityHidden,
+			"Rabbit",
+			[]CommentInfo{
+				{ID: "c1", TaskID: "TEST01", AuthorID: "rabbit", Author: "Rabbit", Text: "Needs a password reset", Estimate: "rabbit", Time: "2021-01-14T15:04:05Z"},
+				{ID: "c2", TaskID: "TEST01", Text: "Left early", Time: "2021-01-14T15:05:05Z"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _, _ := newCommentServer(tt.anonymity)
+
+			res, err := s.Start().Test(settingsRequest("GET", "/api/sessions/12345/tasks/TEST01/comments", tt.user, ""), -1)
+			assert.NoError(t, err)
+			assert.Equal(t, 200, res.StatusCode)
+
+			var cr CommentsResponse
+			assert.NoError(t, json.NewDecoder(res.Body).Decode(&cr))
+			assert.Equal(t, tt.want, cr.Comments)
+		})
+	}
+}
+
+func TestUpdateComment(t *testing.T) {
+	tests := []struct {
+		name    string
+		comment string
+		user    string
+		status  int
+		reason  string
+	}{
+		{"author", "c1", "Rabbit", 200, ""},
+		{"moderator", "c1", "Pooh", 403, "Only the author may edit the comment"},
+		{"unknown comment", "c3", "Rabbit", 404, "Comment with ID: c3 does not exist"},
+		{"comment of other task", "c4", "Rabbit", 404, "Comment with ID: c4 does not exist"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _, cs := newCommentServer(datastore.AnonymityOff)
+			cs.On("UpdateComment", "12345", "c1", "Also needs a logout").Return(nil)
+
+			res, err := s.Start().Test(settingsRequest("PUT", "/api/sessions/12345/tasks/TEST01/comments/"+tt.comment, tt.user,
+				`{"text":"Also needs a logout"}`), -1)
+			assert.NoError(t, err)
+			if tt.status != 200 {
+				assertErrorResponse(t, res, tt.status, tt.reason)
+				cs.AssertNotCalled(t, "UpdateComment", mock.Anything, mock.Anything, mock.Anything)
+				return
+			}
+			assert.Equal(t, 200, res.StatusCode)
+			cs.AssertCalled(t, "UpdateComment", "12345", "c1", "Also needs a logout")
+		})
+	}
+}
+
+func TestRemoveComment(t *testing.T) {
+	tests := []struct {
+		name   string
+		user   string
+		status int
+	}{
+		{"author", "Rabbit", 200},
+		{"moderator", "Pooh", 200},
+		{"other user", "Tigger", 403},
+		{"no user", "", 403},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _, cs := newCommentServer(datastore.AnonymityOff)
+			cs.On("RemoveComment", "12345", "c1").Return(nil)
+
+			res, err := s.Start().Test(settingsRequest("DELETE", "/api/sessions/12345/tasks/TEST01/comments/c1", tt.user, ""), -1)
+			assert.NoError(t, err)
+			if tt.status != 200 {
+				assertErrorResponse(t, res, tt.status, "Only the author or the moderator may remove the comment")
+				cs.AssertNotCalled(t, "RemoveComment", mock.Anything, mock.Anything)
+				return
+			}
+			assert.Equal(t, 200, res.StatusCode)
+			cs.AssertCalled(t, "RemoveComment", "12345", "c1")
+		})
+	}
+}
+
+func TestCommentsAreRemovedWithSession(t *testing.T) {
+	s, m, cs := newCommentServer(datastore.AnonymityOff)
+	m.On("RemoveSession", "12345").Return(nil)
+	cs.On("RemoveComments", "12345").Return(nil)
+
+	res, err := s.Start().Test(httptestRequest("DELETE", "/api/sessions/12345", ""), -1)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, res.StatusCode)
+	cs.AssertCalled(t, "RemoveComments", "12345")
+}
+
+func TestCommentRoutesDisabled(t *testing.T) {
+	app := NewServer(&Config{}, new(datastore.MockDatastore), nil).Start()
+
+	res, err := app.Test(httptestRequest("GET", "/api/sessions/12345/tasks/TEST01/comments", ""), -1)
+	assert.NoError(t, err)
+	assert.Equal(t, 404, res.StatusCode)
+}
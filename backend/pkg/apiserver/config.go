@@ -2,26 +2,46 @@ package apiserver
 
 import (
 	"fmt"
+	"gopkg.in/yaml.v2"
 	"os"
 	"path/filepath"
-
-	"gopkg.in/yaml.v2"
+	"time"
 )
 
 // Config struct for project config
 type Config struct {
-	Server   server   `yaml:"server"`
-	Database database `yaml:"database"`
-	Static   static   `yaml:"static"`
+	Server   server       `yaml:"server"`
+	Database database     `yaml:"database"`
+	Static   static       `yaml:"static"`
+	Metrics  metrics      `yaml:"metrics"`
+	Logger   logging      `yaml:"logger"`
+	Limits   limits       `yaml:"limits"`
+	CORS     cors         `yaml:"cors"`
+	Webhooks webhooks     `yaml:"webhooks"`
+	Tracker  issueTracker `yaml:"tracker"`
+	Units    units        `yaml:"units"`
+	Presence userPresence `yaml:"presence"`
 }
 
 type server struct {
-	Host string `yaml:"host"`
-	Port string `yaml:"port"`
+	Host          string        `yaml:"host"`
+	Port          string        `yaml:"port"`
+	TLS           serverTLS     `yaml:"tls"`
+	ShutdownDelay time.Duration `yaml:"shutdown_delay"`
+}
+
+type serverTLS struct {
+	CertFile     string `yaml:"cert_file"`
+	KeyFile      string `yaml:"key_file"`
+	MinVersion   string `yaml:"min_version"`
+	Redirect     bool   `yaml:"redirect"`
+	RedirectPort string `yaml:"redirect_port"`
 }
 
 type database struct {
-	Location string `yaml:"location"`
+	Location      string `yaml:"location"`
+	EventSourced  bool   `yaml:"event_sourced"`
+	RemovalPolicy string `yaml:"removal_policy"`
 }
 
 type static struct {
@@ -29,6 +49,77 @@ type static struct {
 	Path   string `yaml:"path"`
 }
 
+type metrics struct {
+	Enabled bool   `yaml:"enabled"`
+	Host    string `yaml:"host"`
+	Port    string `yaml:"port"`
+}
+
+type logging struct {
+	Level        string `yaml:"level"`
+	Encoding     string `yaml:"encoding"`
+	RedactTokens bool   `yaml:"redact_tokens"`
+}
+
+type limits struct {
+	BodySize     int       `yaml:"body_size"`
+	Sessions     rateLimit `yaml:"sessions"`
+	Joins        rateLimit `yaml:"joins"`
+	Estimates    rateLimit `yaml:"estimates"`
+	MaxUsers     int       `yaml:"max_users"`
+	MaxTasks     int       `yaml:"max_tasks"`
+	MaxEstimates int       `yaml:"max_estimates"`
+}
+
+type rateLimit struct {
+	PerIP  int           `yaml:"per_ip"`
+	Global int           `yaml:"global"`
+	Window time.Duration `yaml:"window"`
+}
+
+type cors struct {
+	AllowOrigins     []string      `yaml:"allow_origins"`
+	AllowMethods     []string      `yaml:"allow_methods"`
+	AllowHeaders     []string      `yaml:"allow_headers"`
+	AllowCredentials bool          `yaml:"allow_credentials"`
+	MaxAge           time.Duration `yaml:"max_age"`
+}
+
+type webhooks struct {
+	Workers     int           `yaml:"workers"`
+	MaxAttempts int           `yaml:"max_attempts"`
+	Backoff     time.Duration `yaml:"backoff"`
+	Timeout     time.Duration `yaml:"timeout"`
+	QueueSize   int           `yaml:"queue_size"`
+	// AllowPrivateTargets allows webhooks targeting loopback,
+	// private and link-local addresses
+	AllowPrivateTargets bool `yaml:"allow_private_targets"`
+}
+
+type issueTracker struct {
+	Enabled    bool          `yaml:"enabled"`
+	DryRun     bool          `yaml:"dry_run"`
+	Method     string        `yaml:"method"`
+	URL        string        `yaml:"url"`
+	Body       string        `yaml:"body"`
+	AuthHeader string        `yaml:"auth_header"`
+	AuthValue  string        `yaml:"auth_value"`
+	Timeout    time.Duration `yaml:"timeout"`
+	QueueSize  int           `yaml:"queue_size"`
+}
+
+type units struct {
+	Default     string  `yaml:"default"`
+	HoursPerDay float64 `yaml:"hours_per_day"`
+	DaysPerWeek float64 `yaml:"days_per_week"`
+}
+
+type userPresence struct {
+	Enabled        bool          `yaml:"enabled"`
+	IdleTimeout    time.Duration `yaml:"idle_timeout"`
+	OfflineTimeout time.Duration `yaml:"offline_timeout"`
+}
+
 // NewConfig returns a new decoded Config struct
 func NewConfig(configPath string) (*Config, error) {
 	// Validate config path
@@ -36,8 +127,8 @@ func NewConfig(configPath string) (*Config, error) {
 		return nil, err
 	}
 
-	// Create config structure
-	config := &Config{}
+	// Create config structure, keeping defaults for missing values
+	config := DefaultConfig()
 
 	// Open config file
 	file, err := os.Open(filepath.Clean(configPath))
@@ -57,7 +148,7 @@ func NewConfig(configPath string) (*Config, error) {
 	d := yaml.NewDecoder(file)
 
 	// Start YAML decoding from file
-	if err := d.Decode(&config); err != nil {
+	if err := d.Decode(config); err != nil {
 		return nil, err
 	}
 
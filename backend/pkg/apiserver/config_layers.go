@@ -0,0 +1,327 @@
+package apiserver
+
+import (
+	"flag"
+	"fmt"
+	"github.com/haro87/dokerb/pkg/datastore"
+	dbestimate "github.com/haro87/dokerb/pkg/estimate"
+	"gopkg.in/yaml.v2"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// envPrefix is the prefix of all environment variables
+// overriding config values
+const envPrefix = "DOKERB_"
+
+const defaultConfigPath = "configs/apiserver.yml"
+
+// configField represents a single settable config value
+// identified by its path of YAML keys
+type configField struct {
+	path  []string
+	value reflect.Value
+}
+
+// configFlag collects the raw value of a config flag, so that it
+// can be applied after the file and environment layers
+type configFlag struct {
+	raw    string
+	isBool bool
+}
+
+func (f *configFlag) String() string   { return f.raw }
+func (f *configFlag) IsBoolFlag() bool { return f.isBool }
+
+func (f *configFlag) Set(v string) error {
+	f.raw = v
+	return nil
+}
+
+// DefaultConfig returns the config used, if no other value
+// is provided
+func DefaultConfig() *Config {
+	return &Config{
+		Server: server{
+			Host:          "0.0.0.0",
+			Port:          "5000",
+			TLS:           serverTLS{MinVersion: "1.2", RedirectPort: "80"},
+			ShutdownDelay: 5 * time.Second,
+		},
+		Database: database{Location: "my.db", EventSourced: false, RemovalPolicy: datastore.RemovalCascade},
+		Static:   static{Prefix: "/", Path: "./static"},
+		Metrics:  metrics{Enabled: false, Host: "0.0.0.0", Port: "9100"},
+		Logger:   logging{Level: "info", Encoding: "console", RedactTokens: true},
+		Limits: limits{
+			BodySize:     64 * 1024,
+			Sessions:     rateLimit{PerIP: 10, Global: 100, Window: time.Minute},
+			Joins:        rateLimit{PerIP: 30, Global: 300, Window: time.Minute},
+			Estimates:    rateLimit{PerIP: 120, Global: 1200, Window: time.Minute},
+			MaxUsers:     50,
+			MaxTasks:     200,
+			MaxEstimates: 10000,
+		},
+		CORS: cors{
+			AllowOrigins: []string{},
+			AllowMethods: []string{"GET", "POST", "PUT", "DELETE", "HEAD"},
+			AllowHeaders: []string{"Origin", "Content-Type", "Accept", "X-Request-ID", "X-Doker-User", "X-Doker-Secret"},
+		},
+		Webhooks: webhooks{
+			Workers:     4,
+			MaxAttempts: 5,
+			Backoff:     time.Second,
+			Timeout:     10 * time.Second,
+			QueueSize:   1000,
+		},
+		Tracker: issueTracker{
+			Method:    "PUT",
+			Timeout:   10 * time.Second,
+			QueueSize: 100,
+		},
+		Units: units{Default: dbestimate.Hours, HoursPerDay: 8, DaysPerWeek: 5},
+		Presence: userPresence{
+			Enabled:        false,
+			IdleTimeout:    30 * time.Second,
+			OfflineTimeout: 2 * time.Minute,
+		},
+	}
+}
+
+// LoadConfig returns the effective config by layering the defaults,
+// the YAML config file, DOKERB_* environment variables and the
+// provided command-line arguments, where later layers win. It also
+// reports whether the effective config should only be printed.
+func LoadConfig(args []string) (*Config, bool, error) {
+	fs := flag.NewFlagSet("apiserver", flag.ContinueOnError)
+	configPath := fs.String("config", "", "path of the YAML config file (env CONFIG_PATH)")
+	printConfig := fs.Bool("print-config", false, "print the effective config and exit")
+
+	flags := map[string]*configFlag{}
+	for _, f := range configFields(reflect.ValueOf(DefaultConfig()).Elem(), nil) {
+		cf := &configFlag{isBool: f.value.Kind() == reflect.Bool}
+		flags[flagName(f.path)] = cf
+		fs.Var(cf, flagName(f.path), fmt.Sprintf("overrides %s (env %s)", strings.Join(f.path, "."), envName(f.path)))
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return nil, false, err
+	}
+
+	path := *configPath
+	if path == "" {
+		path = GetEnv("CONFIG_PATH", "")
+	}
+
+	config := DefaultConfig()
+	if path != "" {
+		c, err := NewConfig(path)
+		if err != nil {
+			return nil, false, err
+		}
+		config = c
+	} else if _, err := os.Stat(defaultConfigPath); err == nil {
+		c, err := NewConfig(defaultConfigPath)
+		if err != nil {
+			return nil, false, err
+		}
+		config = c
+	}
+
+	if err := config.ApplyEnv(); err != nil {
+		return nil, false, err
+	}
+
+	var ferr error
+	fields := configFields(reflect.ValueOf(config).Elem(), nil)
+	fs.Visit(func(fl *flag.Flag) {
+		cf, ok := flags[fl.Name]
+		if !ok || ferr != nil {
+			return
+		}
+		for _, f := range fields {
+			if flagName(f.path) == fl.Name {
+				if err := setField(f.value, cf.raw); err != nil {
+					ferr = fmt.Errorf("Invalid value for flag --%s: %s", fl.Name, cf.raw)
+				}
+			}
+		}
+	})
+	if ferr != nil {
+		return nil, false, ferr
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, false, err
+	}
+
+	return config, *printConfig, nil
+}
+
+// ApplyEnv overrides all config values for which a DOKERB_*
+// environment variable is set, e.g. DOKERB_SERVER_PORT
+func (c *Config) ApplyEnv() error {
+	for _, f := range configFields(reflect.ValueOf(c).Elem(), nil) {
+		raw, ok := os.LookupEnv(envName(f.path))
+		if !ok {
+			continue
+		}
+		if err := setField(f.value, raw); err != nil {
+			return fmt.Errorf("Invalid value for %s: %s", envName(f.path), raw)
+		}
+	}
+	return nil
+}
+
+// Validate checks the config for values the server cannot
+// be started with
+func (c *Config) Validate() error {
+	if err := validatePort("server.port", c.Server.Port); err != nil {
+		return err
+	}
+	if err := c.Server.TLS.validate(c.Server.Port); err != nil {
+		return err
+	}
+	if c.Server.ShutdownDelay < 0 {
+		return fmt.Errorf("server.shutdown_delay must not be negative")
+	}
+	if c.Database.Location == "" {
+		return fmt.Errorf("database.location should not be empty")
+	}
+	if c.Database.RemovalPolicy != datastore.RemovalCascade && c.Database.RemovalPolicy != datastore.RemovalKeep {
+		return fmt.Errorf("database.removal_policy must be one of cascade or keep, provided: '%s'", c.Database.RemovalPolicy)
+	}
+	if c.Static.Prefix != "" && c.Static.Path != "" {
+		s, err := os.Stat(c.Static.Path)
+		if err != nil || !s.IsDir() {
+			return fmt.Errorf("static.path '%s' is not an existing directory", c.Static.Path)
+		}
+	}
+	if c.Metrics.Enabled {
+		if err := validatePort("metrics.port", c.Metrics.Port); err != nil {
+			return err
+		}
+		if c.Metrics.Port == c.Server.Port && c.Metrics.Host == c.Server.Host {
+			return fmt.Errorf("metrics.port must differ from server.port")
+		}
+	}
+	if err := c.CORS.validate(); err != nil {
+		return err
+	}
+	if err := c.Webhooks.validate(); err != nil {
+		return err
+	}
+	if err := c.Tracker.validate(); err != nil {
+		return err
+	}
+	if err := c.Units.validate(); err != nil {
+		return err
+	}
+	if err := c.Presence.validate(); err != nil {
+		return err
+	}
+	return c.Limits.validate()
+}
+
+// Print writes the config as YAML to the provided writer,
+// secrets are masked
+func (c *Config) Print(w io.Writer) error {
+	masked := *c
+	if masked.Tracker.AuthValue != "" {
+		masked.Tracker.AuthValue = "********"
+	}
+
+	out, err := yaml.Marshal(&masked)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(out)
+	return err
+}
+
+func validatePort(name, port string) error {
+	p, err := strconv.Atoi(port)
+	if err != nil || p < 1 || p > 65535 {
+		return fmt.Errorf("%s must be a number between 1 and 65535, provided: '%s'", name, port)
+	}
+	return nil
+}
+
+// configFields returns all settable leaf values of the provided
+// config struct together with their YAML key paths
+func configFields(v reflect.Value, prefix []string) []configField {
+	var fields []configField
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		key := strings.Split(t.Field(i).Tag.Get("yaml"), ",")[0]
+		if key == "" || key == "-" {
+			continue
+		}
+		path := append(append([]string{}, prefix...), key)
+		fv := v.Field(i)
+		if fv.Kind() == reflect.Struct {
+			fields = append(fields, configFields(fv, path)...)
+			continue
+		}
+		fields = append(fields, configField{path: path, value: fv})
+	}
+	return fields
+}
+
+func setField(v reflect.Value, raw string) error {
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int64:
+		if v.Type() == reflect.TypeOf(time.Duration(0)) {
+			d, err := time.ParseDuration(raw)
+			if err != nil {
+				return err
+			}
+			v.SetInt(int64(d))
+			return nil
+		}
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.Slice:
+		if v.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("Unsupported config type: %s", v.Type())
+		}
+		values := []string{}
+		for _, s := range strings.Split(raw, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				values = append(values, s)
+			}
+		}
+		v.Set(reflect.ValueOf(values))
+	default:
+		return fmt.Errorf("Unsupported config type: %s", v.Type())
+	}
+	return nil
+}
+
+func envName(path []string) string {
+	return envPrefix + strings.ToUpper(strings.Join(path, "_"))
+}
+
+func flagName(path []string) string {
+	return strings.Replace(strings.Join(path, "."), "_", "-", -1)
+}
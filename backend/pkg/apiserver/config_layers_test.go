@@ -0,0 +1,321 @@
+package apiserver
+
+import (
+	"bytes"
+	"flag"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"testing"
+	"time"
+)
+
+func setupTestCaseForEnv(t *testing.T, env map[string]string) func(t *testing.T) {
+	for k, v := range env {
+		os.Setenv(k, v)
+	}
+	return func(t *testing.T) {
+		for k := range env {
+			os.Unsetenv(k)
+		}
+	}
+}
+
+func TestLoadConfigUsesDefaults(t *testing.T) {
+	config, printConfig, err := LoadConfig([]string{"--static.path", "../../static"})
+	assert.NoError(t, err)
+	assert.False(t, printConfig)
+
+	want := DefaultConfig()
+	want.Static.Path = "../../static"
+	assert.Equal(t, want, config)
+}
+
+func TestLoadConfigFromFile(t *testing.T) {
+	config, _, err := LoadConfig([]string{
+		"--config", "../../configs/apiserver.yml",
+		"--static.path", "../../static",
+	})
+	assert.NoError(t, err)
+
+	// The shipped config file documents the defaults
+	want := DefaultConfig()
+	want.Static.Path = "../../static"
+	assert.Equal(t, want, config)
+}
+
+func TestLoadConfigFromConfigPathEnv(t *testing.T) {
+	teardown := setupTestCaseForEnv(t, map[string]string{
+		"CONFIG_PATH": "../../configs/apiserver.yml",
+	})
+	defer teardown(t)
+
+	config, _, err := LoadConfig([]string{"--static.path", "../../static"})
+	assert.NoError(t, err)
+	assert.Equal(t, "5000", config.Server.Port)
+	assert.False(t, config.Metrics.Enabled)
+}
+
+func TestLoadConfigEnvOverridesFile(t *testing.T) {
+	teardown := setupTestCaseForEnv(t, map[string]string{
+		"DOKERB_SERVER_PORT":          "8080",
+		"DOKERB_DATABASE_LOCATION":    "/data/doker.db",
+		"DOKERB_STATIC_PATH":          "../../static",
+		"DOKERB_LOGGER_REDACT_TOKENS": "false",
+	})
+	defer teardown(t)
+
+	config, _, err := LoadConfig([]string{"--config", "../../configs/apiserver.yml"})
+	assert.NoError(t, err)
+	assert.Equal(t, "8080", config.Server.Port)
+	assert.Equal(t, "/data/doker.db", config.Database.Location)
+	assert.Equal(t, "../../static", config.Static.Path)
+	assert.False(t, config.Logger.RedactTokens)
+}
+
+func TestLoadConfigFlagsOverrideEnv(t *testing.T) {
+	teardown := setupTestCaseForEnv(t, map[string]string{
+		"DOKERB_SERVER_PORT": "8080",
+		"DOKERB_STATIC_PATH": "../../static",
+	})
+	defer teardown(t)
+
+	config, printConfig, err := LoadConfig([]string{
+		"--server.port", "9090",
+		"--metrics.enabled",
+		"--logger.redact-tokens=false",
+		"--print-config",
+	})
+	assert.NoError(t, err)
+	assert.True(t, printConfig)
+	assert.Equal(t, "9090", config.Server.Port)
+	assert.True(t, config.Metrics.Enabled)
+	assert.False(t, config.Logger.RedactTokens)
+}
+
+func TestLoadConfigFails(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		args    []string
+		wantErr string
+	}{
+		{
+			"missing config file",
+			nil,
+			[]string{"--config", "does-not-exist.yml"},
+			"stat does-not-exist.yml: no such file or directory",
+		},
+		{
+			"unknown flag",
+			nil,
+			[]string{"--unknown"},
+			"flag provided but not defined: -unknown",
+		},
+		{
+			"invalid env value",
+			map[string]string{"DOKERB_METRICS_ENABLED": "maybe"},
+			nil,
+			"Invalid value for DOKERB_METRICS_ENABLED: maybe",
+		},
+		{
+			"invalid flag value",
+			nil,
+			[]string{"--metrics.enabled=maybe"},
+			"Invalid value for flag --metrics.enabled: maybe",
+		},
+		{
+			"invalid port",
+			nil,
+			[]string{"--server.port", "70000", "--static.path", "../../static"},
+			"server.port must be a number between 1 and 65535, provided: '70000'",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			teardown := setupTestCaseForEnv(t, tt.env)
+			defer teardown(t)
+
+			config, _, err := LoadConfig(tt.args)
+			assert.Nil(t, config)
+			assert.Equal(t, tt.wantErr, err.Error())
+		})
+	}
+}
+
+func TestLoadConfigHelp(t *testing.T) {
+	_, _, err := LoadConfig([]string{"-h"})
+	assert.Equal(t, flag.ErrHelp, err)
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(c *Config)
+		wantErr string
+	}{
+		{"valid", func(c *Config) {}, ""},
+		{"no static files", func(c *Config) { c.Static = static{} }, ""},
+		{
+			"non numeric port",
+			func(c *Config) { c.Server.Port = "http" },
+			"server.port must be a number between 1 and 65535, provided: 'http'",
+		},
+		{
+			"port zero",
+			func(c *Config) { c.Server.Port = "0" },
+			"server.port must be a number between 1 and 65535, provided: '0'",
+		},
+		{
+			"negative shutdown delay",
+			func(c *Config) { c.Server.ShutdownDelay = -time.Second },
+			"server.shutdown_delay must not be negative",
+		},
+		{
+			"empty database location",
+			func(c *Config) { c.Database.Location = "" },
+			"database.location should not be empty",
+		},
+		{
+			"invalid removal policy",
+			func(c *Config) { c.Database.RemovalPolicy = "orphan" },
+			"database.removal_policy must be one of cascade or keep, provided: 'orphan'",
+		},
+		{
+			"missing static path",
+			func(c *Config) { c.Static.Path = "./does-not-exist" },
+			"static.path './does-not-exist' is not an existing directory",
+		},
+		{
+			"invalid metrics port",
+			func(c *Config) { c.Metrics = metrics{true, "0.0.0.0", "-1"} },
+			"metrics.port must be a number between 1 and 65535, provided: '-1'",
+		},
+		{
+			"metrics port clash",
+			func(c *Config) { c.Metrics = metrics{true, "0.0.0.0", "5000"} },
+			"metrics.port must differ from server.port",
+		},
+		{
+			"tls key file missing",
+			func(c *Config) { c.Server.TLS.CertFile = "cert.pem" },
+			"server.tls.cert_file and server.tls.key_file must both be set",
+		},
+		{
+			"invalid tls version",
+			func(c *Config) { c.Server.TLS = serverTLS{"cert.pem", "key.pem", "1.4", false, "80"} },
+			"server.tls.min_version must be one of 1.0, 1.1, 1.2 or 1.3, provided: '1.4'",
+		},
+		{
+			"redirect port clash",
+			func(c *Config) { c.Server.TLS = serverTLS{"cert.pem", "key.pem", "1.2", true, "5000"} },
+			"server.tls.redirect_port must differ from server.port",
+		},
+		{
+			"missing tls cert file",
+			func(c *Config) { c.Server.TLS = serverTLS{"cert.pem", "key.pem", "1.3", true, "80"} },
+			"server.tls.cert_file 'cert.pem' is not an existing file",
+		},
+		{
+			"no webhook workers",
+			func(c *Config) { c.Webhooks.Workers = 0 },
+			"webhooks.workers, webhooks.max_attempts and webhooks.queue_size must be at least 1",
+		},
+		{
+			"no webhook timeout",
+			func(c *Config) { c.Webhooks.Timeout = 0 },
+			"webhooks.backoff must not be negative and webhooks.timeout must be positive",
+		},
+		{
+			"relative tracker url",
+			func(c *Config) { c.Tracker = issueTracker{Enabled: true, Method: "PUT", URL: "/issues/{{.ID}}"} },
+			"tracker.url must be an absolute http or https URL template",
+		},
+		{
+			"invalid tracker method",
+			func(c *Config) {
+				c.Tracker = issueTracker{Enabled: true, Method: "GET", URL: "https://tracker.local/{{.ID}}"}
+			},
+			"tracker.method must be one of POST, PUT or PATCH, provided: 'GET'",
+		},
+		{
+			"no tracker queue",
+			func(c *Config) {
+				c.Tracker = issueTracker{Enabled: true, Method: "PUT", URL: "https://tracker.local/{{.ID}}", Timeout: time.Second}
+			},
+			"tracker.timeout must be positive and tracker.queue_size must be at least 1",
+		},
+		{
+			"invalid tracker body template",
+			func(c *Config) {
+				c.Tracker = issueTracker{Enabled: true, Method: "PUT", URL: "https://tracker.local/{{.ID}}", Body: "{{.Effort", Timeout: time.Second, QueueSize: 1}
+			},
+			"Unable to parse body template: template: body:1: unclosed action",
+		},
+		{
+			"invalid default unit",
+			func(c *Config) { c.Units.Default = "months" },
+			"units.default must be one of hours, days, weeks or points, provided: 'months'",
+		},
+		{
+			"no hours per day",
+			func(c *Config) { c.Units.HoursPerDay = 0 },
+			"units.hours_per_day must be > 0 and <= 24, provided: 0",
+		},
+		{
+			"too many days per week",
+			func(c *Config) { c.Units.DaysPerWeek = 8 },
+			"units.days_per_week must be > 0 and <= 7, provided: 8",
+		},
+		{
+			"offline before idle",
+			func(c *Config) {
+				c.Presence.Enabled = true
+				c.Presence.OfflineTimeout = 10 * time.Second
+			},
+			"presence.idle_timeout must be positive and presence.offline_timeout must exceed it",
+		},
+		{
+			"invalid tracker ignored if disabled",
+			func(c *Config) { c.Tracker.URL = "/issues" },
+			"",
+		},
+		{
+			"invalid metrics port ignored if disabled",
+			func(c *Config) { c.Metrics = metrics{false, "0.0.0.0", "-1"} },
+			"",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			config.Static.Path = "../../static"
+			tt.modify(config)
+
+			err := config.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Equal(t, tt.wantErr, err.Error())
+		})
+	}
+}
+
+func TestPrint(t *testing.T) {
+	var out bytes.Buffer
+	err := DefaultConfig().Print(&out)
+	assert.NoError(t, err)
+	assert.Contains(t, out.String(), "server:\n  host: 0.0.0.0\n  port: \"5000\"\n")
+	assert.Contains(t, out.String(), "redact_tokens: true")
+}
+
+func TestPrintMasksSecrets(t *testing.T) {
+	var out bytes.Buffer
+	config := DefaultConfig()
+	config.Tracker.AuthValue = "Bearer s3cr3t"
+
+	assert.NoError(t, config.Print(&out))
+	assert.NotContains(t, out.String(), "s3cr3t")
+	assert.Contains(t, out.String(), "auth_value: '********'")
+	assert.Equal(t, "Bearer s3cr3t", config.Tracker.AuthValue)
+}
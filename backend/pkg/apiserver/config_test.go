@@ -3,6 +3,7 @@ package apiserver
 import (
 	"reflect"
 	"testing"
+	"time"
 )
 
 func TestNewConfig(t *testing.T) {
@@ -16,9 +17,29 @@ func TestNewConfig(t *testing.T) {
 			"successfully",
 			"../../configs/apiserver.yml",
 			&Config{
-				Server:   server{"0.0.0.0", "5000"},
-				Database: database{"my.db"},
+				Server:   server{"0.0.0.0", "5000", serverTLS{"", "", "1.2", false, "80"}, 5 * time.Second},
+				Database: database{"my.db", false, "cascade"},
 				Static:   static{"/", "./static"},
+				Metrics:  metrics{false, "0.0.0.0", "9100"},
+				Logger:   logging{"info", "console", true},
+				Limits: limits{
+					65536,
+					rateLimit{10, 100, time.Minute},
+					rateLimit{30, 300, time.Minute},
+					rateLimit{120, 1200, time.Minute},
+					50, 200, 10000,
+				},
+				CORS: cors{
+					[]string{},
+					[]string{"GET", "POST", "PUT", "DELETE", "HEAD"},
+					[]string{"Origin", "Content-Type", "Accept", "X-Request-ID", "X-Doker-User", "X-Doker-Secret"},
+					false,
+					0,
+				},
+				Webhooks: webhooks{4, 5, time.Second, 10 * time.Second, 1000, false},
+				Tracker:  issueTracker{false, false, "PUT", "", "", "", "", 10 * time.Second, 100},
+				Units:    units{"hours", 8, 5},
+				Presence: userPresence{false, 30 * time.Second, 2 * time.Minute},
 			},
 			false,
 		},
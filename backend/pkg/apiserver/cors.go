@@ -0,0 +1,87 @@
+package apiserver
+
+import (
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"strconv"
+	"strings"
+)
+
+// corsMiddleware returns a middleware adding CORS headers for
+// the configured origins only, requests from all other origins
+// are passed on without any CORS headers
+func (s *APIServer) corsMiddleware() fiber.Handler {
+	policy := s.config.CORS
+	methods := strings.Join(policy.AllowMethods, ",")
+	headers := strings.Join(policy.AllowHeaders, ",")
+	maxAge := strconv.Itoa(int(policy.MaxAge.Seconds()))
+
+	return func(c *fiber.Ctx) error {
+		origin := c.Get(fiber.HeaderOrigin)
+		if origin == "" || !policy.allows(origin) {
+			return c.Next()
+		}
+
+		c.Vary(fiber.HeaderOrigin)
+		c.Set(fiber.HeaderAccessControlAllowOrigin, origin)
+		if policy.AllowCredentials {
+			c.Set(fiber.HeaderAccessControlAllowCredentials, "true")
+		}
+
+		// Answer preflight requests directly
+		if c.Method() != fiber.MethodOptions || c.Get(fiber.HeaderAccessControlRequestMethod) == "" {
+			return c.Next()
+		}
+
+		c.Vary(fiber.HeaderAccessControlRequestMethod)
+		c.Vary(fiber.HeaderAccessControlRequestHeaders)
+		c.Set(fiber.HeaderAccessControlAllowMethods, methods)
+		if headers != "" {
+			c.Set(fiber.HeaderAccessControlAllowHeaders, headers)
+		}
+		if policy.MaxAge > 0 {
+			c.Set(fiber.HeaderAccessControlMaxAge, maxAge)
+		}
+		return c.SendStatus(fiber.StatusNoContent)
+	}
+}
+
+// allows reports whether the provided origin matches one of the
+// allowed origins, where an allowed origin like https://*.example.com
+// matches all subdomains of example.com
+func (p cors) allows(origin string) bool {
+	for _, o := range p.AllowOrigins {
+		if o == "*" || strings.EqualFold(o, origin) {
+			return true
+		}
+		if i := strings.Index(o, "://*."); i >= 0 {
+			scheme, domain := o[:i+3], o[i+4:]
+			if strings.HasPrefix(origin, scheme) && strings.HasSuffix(origin, domain) &&
+				len(origin) > len(scheme)+len(domain) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (p cors) validate() error {
+	for _, o := range p.AllowOrigins {
+		if o == "*" {
+			if p.AllowCredentials {
+				return fmt.Errorf("cors.allow_origins must not contain '*' if cors.allow_credentials is enabled")
+			}
+			continue
+		}
+		if !strings.HasPrefix(o, "http://") && !strings.HasPrefix(o, "https://") {
+			return fmt.Errorf("cors.allow_origins must be '*' or start with http:// or https://, provided: '%s'", o)
+		}
+		if strings.Contains(strings.Replace(o, "://*.", "://", 1), "*") {
+			return fmt.Errorf("cors.allow_origins only supports a wildcard for subdomains, provided: '%s'", o)
+		}
+	}
+	if p.MaxAge < 0 {
+		return fmt.Errorf("cors.max_age must not be negative")
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+package apiserver
+
+import (
+	"github.com/haro87/dokerb/pkg/datastore"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func newCORSTestServer(policy cors) *APIServer {
+	m := new(datastore.MockDatastore)
+	m.On("GetUsers", "12345").Return(testUsers("Tigger"), nil)
+	return NewServer(&Config{CORS: policy}, m, nil)
+}
+
+func TestCORSAllowedOrigins(t *testing.T) {
+	app := newCORSTestServer(cors{
+		AllowOrigins:     []string{"https://doker.example.com", "https://*.example.org"},
+		AllowCredentials: true,
+	}).Start()
+
+	tests := []struct {
+		origin  string
+		allowed bool
+	}{
+		{"https://doker.example.com", true},
+		{"https://team.example.org", true},
+		{"https://a.team.example.org", true},
+		{"https://example.org", false},
+		{"http://team.example.org", false},
+		{"https://evilexample.org", false},
+		{"https://example.com", false},
+		{"https://evil.com", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.origin, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", "/api/sessions/12345/users", nil)
+			req.Header.Set("Origin", tt.origin)
+			res, err := app.Test(req, -1)
+			assert.NoError(t, err)
+			assert.Equal(t, 200, res.StatusCode)
+
+			if tt.allowed {
+				assert.Equal(t, tt.origin, res.Header.Get("Access-Control-Allow-Origin"))
+				assert.Equal(t, "true", res.Header.Get("Access-Control-Allow-Credentials"))
+				return
+			}
+			assert.NotContains(t, res.Header, "Access-Control-Allow-Origin")
+			assert.NotContains(t, res.Header, "Access-Control-Allow-Credentials")
+		})
+	}
+}
+
+func TestCORSDefaultsToSameOrigin(t *testing.T) {
+	app := newCORSTestServer(DefaultConfig().CORS).Start()
+
+	req, _ := http.NewRequest("GET", "/api/sessions/12345/users", nil)
+	req.Header.Set("Origin", "https://evil.com")
+	res, err := app.Test(req, -1)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, res.StatusCode)
+	assert.NotContains(t, res.Header, "Access-Control-Allow-Origin")
+
+	req, _ = http.NewRequest("OPTIONS", "/api/sessions/12345/users", nil)
+	req.Header.Set("Origin", "https://evil.com")
+	req.Header.Set("Access-Control-Request-Method", "POST")
+	res, err = app.Test(req, -1)
+	assert.NoError(t, err)
+	assert.NotContains(t, res.Header, "Access-Control-Allow-Origin")
+	assert.NotContains(t, res.Header, "Access-Control-Allow-Methods")
+}
+
+func TestCORSPreflight(t *testing.T) {
+	policy := DefaultConfig().CORS
+	policy.AllowOrigins = []string{"https://doker.example.com"}
+	policy.MaxAge = 10 * time.Minute
+	app := newCORSTestServer(policy).Start()
+
+	req, _ := http.NewRequest("OPTIONS", "/api/sessions/12345/users", nil)
+	req.Header.Set("Origin", "https://doker.example.com")
+	req.Header.Set("Access-Control-Request-Method", "POST")
+	res, err := app.Test(req, -1)
+	assert.NoError(t, err)
+	assert.Equal(t, 204, res.StatusCode)
+	assert.Equal(t, "https://doker.example.com", res.Header.Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "GET,POST,PUT,DELETE,HEAD", res.Header.Get("Access-Control-Allow-Methods"))
+	assert.Equal(t, "Origin,Content-Type,Accept,X-Request-ID,X-Doker-User,X-Doker-Secret", res.Header.Get("Access-Control-Allow-Headers"))
+	assert.Equal(t, "600", res.Header.Get("Access-Control-Max-Age"))
+	assert.NotContains(t, res.Header, "Access-Control-Allow-Credentials")
+}
+
+func TestCORSAllowAll(t *testing.T) {
+	app := newCORSTestServer(cors{AllowOrigins: []string{"*"}}).Start()
+
+	req, _ := http.NewRequest("GET", "/api/sessions/12345/users", nil)
+	req.Header.Set("Origin", "https://anywhere.com")
+	res, err := app.Test(req, -1)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://anywhere.com", res.Header.Get("Access-Control-Allow-Origin"))
+}
+
+func TestValidateCORS(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  cors
+		wantErr string
+	}{
+		{"same origin", cors{}, ""},
+		{"wildcard subdomain", cors{AllowOrigins: []string{"https://*.example.com"}}, ""},
+		{
+			"all origins with credentials",
+			cors{AllowOrigins: []string{"*"}, AllowCredentials: true},
+			"cors.allow_origins must not contain '*' if cors.allow_credentials is enabled",
+		},
+		{
+			"missing scheme",
+			cors{AllowOrigins: []string{"example.com"}},
+			"cors.allow_origins must be '*' or start with http:// or https://, provided: 'example.com'",
+		},
+		{
+			"wildcard in domain",
+			cors{AllowOrigins: []string{"https://doker.*.com"}},
+			"cors.allow_origins only supports a wildcard for subdomains, provided: 'https://doker.*.com'",
+		},
+		{"negative max age", cors{MaxAge: -time.Second}, "cors.max_age must not be negative"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Equal(t, tt.wantErr, err.Error())
+		})
+	}
+}
@@ -1,13 +1,13 @@
 package apiserver
 
-import "fmt"
+import "go.uber.org/zap"
 
-// ErrChecker method for check error and show message
+// ErrChecker method for check error, log it with the global
+// logger and terminate the process
 func ErrChecker(err error) {
 	// If got error
 	if err != nil {
-		// Show error in logs
-		fmt.Println(err)
-		return
+		// Log error and exit
+		zap.L().Fatal("Unrecoverable error", zap.Error(err))
 	}
 }
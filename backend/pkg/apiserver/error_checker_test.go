@@ -2,6 +2,10 @@ package apiserver
 
 import (
 	"errors"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
 	"testing"
 )
 
@@ -10,25 +14,40 @@ func TestErrChecker(t *testing.T) {
 		err error
 	}
 	tests := []struct {
-		name string
-		args args
+		name      string
+		args      args
+		wantFatal bool
 	}{
 		{
 			"no error",
 			args{
 				err: nil,
 			},
+			false,
 		},
 		{
 			"error",
 			args{
 				err: errors.New("This is error"),
 			},
+			true,
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			ErrChecker(tt.args.err)
+			core, logs := observer.New(zapcore.InfoLevel)
+			undo := zap.ReplaceGlobals(zap.New(core, zap.OnFatal(zapcore.WriteThenPanic)))
+			defer undo()
+
+			if tt.wantFatal {
+				assert.Panics(t, func() { ErrChecker(tt.args.err) })
+				assert.Equal(t, 1, logs.Len())
+				assert.Equal(t, zapcore.FatalLevel, logs.All()[0].Level)
+				assert.Equal(t, "This is error", logs.All()[0].ContextMap()["error"])
+			} else {
+				ErrChecker(tt.args.err)
+				assert.Equal(t, 0, logs.Len())
+			}
 		})
 	}
 }
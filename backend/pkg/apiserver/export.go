@@ -0,0 +1,89 @@
+package apiserver
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/haro87/dokerb/pkg/datastore"
+)
+
+// ExportResponse represents a whole session, e.g. for keeping the
+// outcome of a planning, Comments are empty unless enabled. In
+// anonymous sessions users are shown as for estimates.
+type ExportResponse struct {
+	Message   string               `json:"message" example:"ok" format:"string"`
+	Unit      string               `json:"unit" example:"hours" format:"string"`
+	Users     []User               `json:"users" format:"[]User"`
+	Tasks     []datastore.Task     `json:"tasks" format:"[]datastore.Task"`
+	Estimates []datastore.Estimate `json:"estimates" format:"[]datastore.Estimate"`
+	Comments  []CommentInfo        `json:"comments" format:"[]CommentInfo"`
+}
+
+// exportRoutes registers the routes for exporting sessions, comments
+// may be nil if they are disabled
+func exportRoutes(app *fiber.App, store datastore.DataStore, comments datastore.CommentStore, settings sessionSettings) {
+	APIGroup := app.Group("/api")
+
+	addExportSessionRoute(APIGroup, store, comments, settings)
+}
+
+// Adding the export session route
+// @Summary Export a session
+// @Description Gets the users, tasks, estimates and, if enabled, the comments of an existing session at once in the unit of the session or the requested unit. In anonymous sessions users are shown as for estimates.
+// @Tags session
+// @Produce  json
+// @Param token path string true "Session Token"
+// @Param X-Doker-User header string false "ID or name of the requesting user"
+// @Param unit query string false "Unit to convert into, one of hours, days or weeks"
+// @Success 200 {object} ExportResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /sessions/{token}/export [get]
+func addExportSessionRoute(api fiber.Router, store datastore.DataStore, comments datastore.CommentStore, settings sessionSettings) {
+	api.Get("/sessions/:token/export", func(c *fiber.Ctx) error {
+		users, err := store.GetUsers(c.Params("token"))
+
+		if err != nil {
+			return sendError(c, 500, err)
+		}
+
+		tasks, err := store.GetTasks(c.Params("token"))
+
+		if err != nil {
+			return sendError(c, 500, err)
+		}
+
+		ests, err := store.GetEstimates(c.Params("token"))
+
+		if err != nil {
+			return sendError(c, 500, err)
+		}
+
+		cms := []datastore.Comment{}
+		if comments != nil {
+			if cms, err = comments.GetComments(c.Params("token"), ""); err != nil {
+				return sendError(c, 500, err)
+			}
+		}
+
+		v, err := newViewer(c, settings, users)
+
+		if err != nil {
+			return sendError(c, 500, err)
+		}
+
+		uc, err := settings.units.converter(c, v.settings.Unit)
+
+		if err != nil {
+			return sendError(c, 400, err)
+		}
+
+		data := ExportResponse{
+			Message:   "ok",
+			Unit:      uc.to,
+			Users:     v.users(users),
+			Tasks:     uc.tasks(tasks),
+			Estimates: v.estimates(uc.estimates(ests)),
+			Comments:  v.comments(cms, users),
+		}
+		return c.Status(200).JSON(data)
+	})
+}
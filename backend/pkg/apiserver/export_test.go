@@ -0,0 +1,85 @@
+package apiserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/haro87/dokerb/pkg/datastore"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestExportSession(t *testing.T) {
+	m := new(datastore.MockDatastore)
+	m.On("GetUsers", "12345").Return(testUsers("Tigger", "Rabbit", "Pooh"), nil)
+	m.On("GetTasks", "12345").Return([]datastore.Task{{ID: "TEST01", Effort: 8, Finalized: true}}, nil)
+	m.On("GetEstimates", "12345").Return(anonymousEstimates, nil)
+	ss := new(datastore.MockSettingsStore)
+	ss.On("GetSettings", "12345").Return(datastore.Settings{Anonymity: datastore.AnonymityOff, Unit: "hours"}, nil)
+	cs := new(datastore.MockCommentStore)
+	cs.On("GetComments", "12345", "").Return(testComments, nil)
+
+	config := &Config{Units: units{Default: "hours", HoursPerDay: 8, DaysPerWeek: 5}}
+	app := NewServer(config, m, nil, WithSettings(ss), WithComments(cs)).Start()
+
+	res, err := app.Test(httptestRequest("GET", "/api/sessions/12345/export?unit=days", ""), -1)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, res.StatusCode)
+
+	var er ExportResponse
+	assert.NoError(t, json.NewDecoder(res.Body).Decode(&er))
+	assert.Equal(t, "days", er.Unit)
+	assert.Equal(t, []User{
+		{ID: "tigger", Name: "Tigger", Role: datastore.RoleEstimator},
+		{ID: "rabbit", Name: "Rabbit", Role: datastore.RoleEstimator},
+		{ID: "pooh", Name: "Pooh", Role: datastore.RoleEstimator},
+	}, er.Users)
+	assert.Equal(t, []datastore.Task{{ID: "TEST01", Effort: 1, Finalized: true}}, er.Tasks)
+	assert.Len(t, er.Estimates, 3)
+	assert.Equal(t, 0.5, er.Estimates[1].BestCase)
+	assert.Equal(t, "Rabbit", er.Estimates[1].UserName)
+	assert.Len(t, er.Comments, 2)
+	assert.Equal(t, "Rabbit", er.Comments[0].Author)
+}
+
+func TestExportAnonymousSession(t *testing.T) {
+	s, _, _ := newCommentServer(datastore.AnonymityHidden)
+
+	res, err := s.Start().Test(settingsRequest("GET", "/api/sessions/12345/export", "Tigger", ""), -1)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, res.StatusCode)
+
+	var er ExportResponse
+	assert.NoError(t, json.NewDecoder(res.Body).Decode(&er))
+	assert.Equal(t, "Tigger", er.Users[0].Name)
+	assert.Equal(t, User{Role: datastore.RoleEstimator}, er.Users[1])
+	assert.Equal(t, "", er.Estimates[1].UserID)
+	assert.Equal(t, "", er.Comments[0].Author)
+}
+
+func TestExportSessionWithoutComments(t *testing.T) {
+	m := new(datastore.MockDatastore)
+	m.On("GetUsers", "12345").Return(testUsers("Tigger"), nil)
+	m.On("GetTasks", "12345").Return([]datastore.Task{}, nil)
+	m.On("GetEstimates", "12345").Return([]datastore.Estimate{}, nil)
+
+	app := NewServer(&Config{}, m, nil).Start()
+
+	res, err := app.Test(httptestRequest("GET", "/api/sessions/12345/export", ""), -1)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, res.StatusCode)
+
+	var er ExportResponse
+	assert.NoError(t, json.NewDecoder(res.Body).Decode(&er))
+	assert.Equal(t, []CommentInfo{}, er.Comments)
+}
+
+func TestExportSessionFails(t *testing.T) {
+	m := new(datastore.MockDatastore)
+	m.On("GetUsers", "12345").Return([]datastore.User{}, fmt.Errorf("Specified session does not exist"))
+
+	app := NewServer(&Config{}, m, nil).Start()
+
+	res, err := app.Test(httptestRequest("GET", "/api/sessions/12345/export", ""), -1)
+	assert.NoError(t, err)
+	assertErrorResponse(t, res, 500, "Specified session does not exist")
+}
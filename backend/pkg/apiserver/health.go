@@ -0,0 +1,62 @@
+package apiserver
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"sync/atomic"
+)
+
+// HealthResponse represents the response of the health check routes
+type HealthResponse struct {
+	Status string            `json:"status" example:"ok" format:"string"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+// SetReady marks the server as ready or not ready to serve requests,
+// e.g. during the graceful shutdown sequence
+func (s *APIServer) SetReady(ready bool) {
+	var r int32
+	if ready {
+		r = 1
+	}
+	atomic.StoreInt32(&s.ready, r)
+}
+
+// IsReady returns whether the server is marked as ready
+func (s *APIServer) IsReady() bool {
+	return atomic.LoadInt32(&s.ready) == 1
+}
+
+// addHealthRoutes adds the liveness and readiness probes
+func (s *APIServer) addHealthRoutes(app *fiber.App) {
+	// The process is up, as long as it can answer at all
+	app.Get("/healthz", func(c *fiber.Ctx) error {
+		return c.Status(200).JSON(HealthResponse{Status: "ok"})
+	})
+
+	// The server is ready, if it is not shutting down and the
+	// datastore can be queried
+	app.Get("/readyz", func(c *fiber.Ctx) error {
+		status := 200
+		data := HealthResponse{
+			Status: "ok",
+			Checks: map[string]string{
+				"server":    "ok",
+				"datastore": "ok",
+			},
+		}
+
+		if !s.IsReady() {
+			status = 503
+			data.Status = "unavailable"
+			data.Checks["server"] = "shutting down"
+		}
+
+		if err := s.ds.Ping(); err != nil {
+			status = 503
+			data.Status = "unavailable"
+			data.Checks["datastore"] = err.Error()
+		}
+
+		return c.Status(status).JSON(data)
+	})
+}
@@ -0,0 +1,96 @@
+package apiserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/haro87/dokerb/pkg/datastore"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+func TestHealthzSuccess(t *testing.T) {
+	m := new(datastore.MockDatastore)
+
+	app := NewServer(&Config{
+		Static: static{Prefix: "/", Path: "../../static"},
+	}, m, nil).Start()
+
+	req, _ := http.NewRequest("GET", "/healthz", nil)
+	res, err := app.Test(req, -1)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, res.StatusCode)
+
+	var hr HealthResponse
+	err = json.NewDecoder(res.Body).Decode(&hr)
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", hr.Status)
+}
+
+func TestReadyzSuccess(t *testing.T) {
+	m := new(datastore.MockDatastore)
+	m.On("Ping").Return(nil)
+
+	app := NewServer(&Config{}, m, nil).Start()
+
+	req, _ := http.NewRequest("GET", "/readyz", nil)
+	res, err := app.Test(req, -1)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, res.StatusCode)
+
+	var hr HealthResponse
+	err = json.NewDecoder(res.Body).Decode(&hr)
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", hr.Status)
+	assert.Equal(t, "ok", hr.Checks["datastore"])
+	assert.Equal(t, "ok", hr.Checks["server"])
+}
+
+func TestReadyzFailsDueToDatastore(t *testing.T) {
+	m := new(datastore.MockDatastore)
+	m.On("Ping").Return(fmt.Errorf("Unable to query sessions table"))
+
+	app := NewServer(&Config{}, m, nil).Start()
+
+	req, _ := http.NewRequest("GET", "/readyz", nil)
+	res, err := app.Test(req, -1)
+	assert.NoError(t, err)
+	assert.Equal(t, 503, res.StatusCode)
+
+	var hr HealthResponse
+	err = json.NewDecoder(res.Body).Decode(&hr)
+	assert.NoError(t, err)
+	assert.Equal(t, "unavailable", hr.Status)
+	assert.Equal(t, "Unable to query sessions table", hr.Checks["datastore"])
+}
+
+func TestReadyzFailsDuringShutdown(t *testing.T) {
+	m := new(datastore.MockDatastore)
+	m.On("Ping").Return(nil)
+
+	s := NewServer(&Config{}, m, nil)
+	app := s.Start()
+	assert.True(t, s.IsReady())
+
+	s.SetReady(false)
+	assert.False(t, s.IsReady())
+
+	req, _ := http.NewRequest("GET", "/readyz", nil)
+	res, err := app.Test(req, -1)
+	assert.NoError(t, err)
+	assert.Equal(t, 503, res.StatusCode)
+
+	var hr HealthResponse
+	err = json.NewDecoder(res.Body).Decode(&hr)
+	assert.NoError(t, err)
+	assert.Equal(t, "unavailable", hr.Status)
+	assert.Equal(t, "shutting down", hr.Checks["server"])
+	assert.Equal(t, "ok", hr.Checks["datastore"])
+
+	s.SetReady(true)
+
+	req, _ = http.NewRequest("GET", "/readyz", nil)
+	res, err = app.Test(req, -1)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, res.StatusCode)
+}
@@ -0,0 +1,156 @@
+package apiserver
+
+import (
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"github.com/haro87/dokerb/pkg/datastore"
+)
+
+// StateChange represents a requested change of the lifecycle state
+// of a session, reopening a closed session requires a reason
+type StateChange struct {
+	State  string `json:"state" example:"closed" format:"string"`
+	Reason string `json:"reason" example:"Planning is complete" format:"string"`
+}
+
+// StateChangeInfo represents a past change of the lifecycle state
+// of a session, Actor is the ID of the user who requested it
+type StateChangeInfo struct {
+	From   string `json:"from" example:"estimating" format:"string"`
+	To     string `json:"to" example:"closed" format:"string"`
+	Actor  string `json:"actor" example:"3f2a9c1e7b4d8e60" format:"string"`
+	Reason string `json:"reason" example:"Planning is complete" format:"string"`
+	Time   string `json:"time" example:"2021-01-14T15:04:05Z" format:"string"`
+}
+
+// LifecycleResponse represents the get lifecycle response, Changes
+// are ordered oldest first
+type LifecycleResponse struct {
+	Message string            `json:"message" example:"ok" format:"string"`
+	State   string            `json:"state" example:"closed" format:"string"`
+	Changes []StateChangeInfo `json:"changes" format:"[]StateChangeInfo"`
+}
+
+// WithLifecycle enables opening, closing and archiving sessions,
+// the lifecycle is kept in the provided store
+func WithLifecycle(store datastore.LifecycleStore) Option {
+	return func(s *APIServer) {
+		s.lifecycles = store
+	}
+}
+
+// lifecycleRoutes registers the routes for changing the lifecycle
+// state of sessions
+func lifecycleRoutes(app *fiber.App, store datastore.DataStore, lifecycles datastore.LifecycleStore, settings sessionSettings) {
+	APIGroup := app.Group("/api")
+
+	addGetLifecycleRoute(APIGroup, store, lifecycles)
+
+	addChangeStateRoute(APIGroup, store, lifecycles, settings)
+}
+
+// Adding the get lifecycle route
+// @Summary Get the lifecycle state of a session
+// @Description Gets the lifecycle state of an existing session, which is one of open, estimating, closed or archived, and how it changed. Sessions are open unless changed.
+// @Tags session
+// @Produce  json
+// @Param token path string true "Session Token"
+// @Success 200 {object} LifecycleResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /sessions/{token}/lifecycle [get]
+func addGetLifecycleRoute(api fiber.Router, store datastore.DataStore, lifecycles datastore.LifecycleStore) {
+	api.Get("/sessions/:token/lifecycle", func(c *fiber.Ctx) error {
+		// Unknown sessions would otherwise appear to be open
+		if _, err := store.GetUsers(c.Params("token")); err != nil {
+			return sendError(c, 404, err)
+		}
+
+		lc, err := lifecycles.GetLifecycle(c.Params("token"))
+
+		if err != nil {
+			return sendError(c, 500, err)
+		}
+
+		return c.Status(200).JSON(toLifecycleResponse(lc))
+	})
+}
+
+// Adding the change state route
+// @Summary Change the lifecycle state of a session
+// @Description Changes the lifecycle state of a session from open to estimating or closed, from estimating to closed and from closed to archived. Closed and archived sessions reject all changes of users, tasks and estimates, while they can still be read. Every change must be requested on behalf of a user of the session. As closed sessions are meant to stay as they are, closing, archiving and reopening a session by changing it to open may only be done by the moderator, so a moderator has to be set before. Reopening requires a reason. Without moderator any user may start estimating, once a moderator is set only the moderator may change the state.
+// @Tags session
+// @Accept  json
+// @Produce  json
+// @Param token path string true "Session Token"
+// @Param X-Doker-User header string true "ID or name of the requesting user"
+// @Param X-Doker-Secret header string false "Secret of the requesting user, if secrets are enabled"
+// @Param change body StateChange true "New state"
+// @Success 200 {object} LifecycleResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /sessions/{token}/lifecycle [put]
+func addChangeStateRoute(api fiber.Router, store datastore.DataStore, lifecycles datastore.LifecycleStore, settings sessionSettings) {
+	api.Put("/sessions/:token/lifecycle", func(c *fiber.Ctx) error {
+		sc := new(StateChange)
+
+		if err := c.BodyParser(sc); err != nil {
+			return sendError(c, 400, err)
+		}
+
+		users, err := store.GetUsers(c.Params("token"))
+		if err != nil {
+			return sendError(c, 404, err)
+		}
+
+		s, err := settings.get(c.Params("token"))
+		if err != nil {
+			return sendError(c, 500, err)
+		}
+
+		user := requester(c, users)
+
+		if user == "" {
+			return sendError(c, 401, fmt.Errorf("Header %s must reference a user of the session", HeaderUser))
+		}
+		if s.Moderator == "" && sc.State != datastore.StateEstimating {
+			return sendError(c, 403, fmt.Errorf("Only the moderator may close, archive or reopen the session, but no moderator is set"))
+		}
+		if s.Moderator != "" && s.Moderator != user {
+			return sendError(c, 403, fmt.Errorf("Only the moderator may change the state of the session"))
+		}
+
+		lc, err := lifecycles.ChangeState(c.Params("token"), datastore.StateChange{
+			To:     sc.State,
+			Actor:  user,
+			Reason: sc.Reason,
+		})
+
+		if err != nil {
+			return sendError(c, storeErrorStatusOr(err, 400), err)
+		}
+
+		return c.Status(200).JSON(toLifecycleResponse(lc))
+	})
+}
+
+func toLifecycleResponse(lc datastore.Lifecycle) LifecycleResponse {
+	changes := make([]StateChangeInfo, 0, len(lc.Changes))
+	for _, ch := range lc.Changes {
+		changes = append(changes, StateChangeInfo{
+			From:   ch.From,
+			To:     ch.To,
+			Actor:  ch.Actor,
+			Reason: ch.Reason,
+			Time:   ch.Time,
+		})
+	}
+	return LifecycleResponse{
+		Message: "ok",
+		State:   lc.State,
+		Changes: changes,
+	}
+}
@@ -0,0 +1,223 @@
+package apiserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/haro87/dokerb/pkg/datastore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+)
+
+var closedLifecycle = datastore.Lifecycle{
+	State: datastore.StateClosed,
+	Changes: []datastore.StateChange{
+		{From: datastore.StateOpen, To: datastore.StateClosed, Actor: "pooh", Reason: "Planning is complete", Time: "2021-01-14T15:04:05Z"},
+	},
+}
+
+func newLifecycleServer(moderator string, lc datastore.Lifecycle) (*APIServer, *datastore.MockDatastore, *datastore.MockLifecycleStore) {
+	m := new(datastore.MockDatastore)
+	m.On("GetUsers", "12345").Return(testUsers("Tigger", "Pooh"), nil)
+	ss := new(datastore.MockSettingsStore)
+	ss.On("GetSettings", "12345").Return(datastore.Settings{Anonymity: datastore.AnonymityOff, Moderator: moderator}, nil)
+	ls := new(datastore.MockLifecycleStore)
+	ls.On("GetLifecycle", "12345").Return(lc, nil)
+	return NewServer(&Config{}, m, nil, WithSettings(ss), WithLifecycle(ls)), m, ls
+}
+
+func TestGetLifecycle(t *testing.T) {
+	s, _, _ := newLifecycleServer("pooh", closedLifecycle)
+
+	res, err := s.Start().Test(httptestRequest("GET", "/api/sessions/12345/lifecycle", ""), -1)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, res.StatusCode)
+
+	var lr LifecycleResponse
+	assert.NoError(t, json.NewDecoder(res.Body).Decode(&lr))
+	assert.Equal(t, LifecycleResponse{
+		Message: "ok",
+		State:   datastore.StateClosed,
+		Changes: []StateChangeInfo{
+			{From: "open", To: "closed", Actor: "pooh", Reason: "Planning is complete", Time: "2021-01-14T15:04:05Z"},
+		},
+	}, lr)
+}
+
+func TestChangeState(t *testing.T) {
+	tests := []struct {
+		name      string
+		moderator string
+		user      string
+		actor     string
+		body      string
+		status    int
+		reason    string
+	}{
+		{"estimate without moderator", "", "Tigger", "tigger", `{"state":"estimating"}`, 200, ""},
+		{"estimate without user", "", "", "", `{"state":"estimating"}`, 401, "Header X-Doker-User must reference a user of the session"},
+		{"close without moderator", "", "Pooh", "pooh", `{"state":"closed"}`, 403, "Only the moderator may close, archive or reopen the session, but no moderator is set"},
+		{"close without user", "pooh", "", "", `{"state":"closed"}`, 401, "Header X-Doker-User must reference a user of the session"},
+		{"close by moderator", "pooh", "Pooh", "pooh", `{"state":"closed"}`, 200, ""},
+		{"close by other user", "pooh", "Tigger", "tigger", `{"state":"closed"}`, 403, "Only the moderator may change the state of the session"},
+		{"archive without moderator", "", "Pooh", "pooh", `{"state":"archived"}`, 403, "Only the moderator may close, archive or reopen the session, but no moderator is set"},
+		{"reopen by moderator", "pooh", "pooh", "pooh", `{"state":"open","reason":"Forgot a task"}`, 200, ""},
+		{"reopen by other user", "pooh", "Tigger", "tigger", `{"state":"open","reason":"Forgot a task"}`, 403, "Only the moderator may change the state of the session"},
+		{"reopen without moderator", "", "Pooh", "pooh", `{"state":"open","reason":"Forgot a task"}`, 403, "Only the moderator may close, archive or reopen the session, but no moderator is set"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _, ls := newLifecycleServer(tt.moderator, closedLifecycle)
+			ls.On("ChangeState", "12345", mock.Anything).Return(closedLifecycle, nil)
+
+			res, err := s.Start().Test(settingsRequest("PUT", "/api/sessions/12345/lifecycle", tt.user, tt.body), -1)
+			assert.NoError(t, err)
+			if tt.status != 200 {
+				assertErrorResponse(t, res, tt.status, tt.reason)
+				ls.AssertNotCalled(t, "ChangeState", mock.Anything, mock.Anything)
+				return
+			}
+			assert.Equal(t, 200, res.StatusCode)
+
+			var sc StateChange
+			assert.NoError(t, json.Unmarshal([]byte(tt.body), &sc))
+			ls.AssertCalled(t, "ChangeState", "12345", datastore.StateChange{
+				To:     sc.State,
+				Actor:  tt.actor,
+				Reason: sc.Reason,
+			})
+		})
+	}
+}
+
+func TestChangeStateRequiresSecret(t *testing.T) {
+	s, _, ls := newLifecycleServer("pooh", closedLifecycle)
+	ss := new(datastore.MockSecretStore)
+	ss.On("VerifySecret", "12345", "pooh", "s3cr3t").Return(true, nil)
+	ss.On("VerifySecret", "12345", "pooh", mock.Anything).Return(false, nil)
+	WithSecrets(ss)(s)
+	ls.On("ChangeState", "12345", mock.Anything).Return(closedLifecycle, nil)
+	app := s.Start()
+
+	req := settingsRequest("PUT", "/api/sessions/12345/lifecycle", "Pooh", `{"state":"closed"}`)
+	req.Header.Set(HeaderSecret, "guess")
+	res, err := app.Test(req, -1)
+	assert.NoError(t, err)
+	assertErrorResponse(t, res, 401, "Header X-Doker-Secret must contain the secret of user: Pooh")
+	ls.AssertNotCalled(t, "ChangeState", mock.Anything, mock.Anything)
+
+	req = settingsRequest("PUT", "/api/sessions/12345/lifecycle", "Pooh", `{"state":"closed"}`)
+	req.Header.Set(HeaderSecret, "s3cr3t")
+	res, err = app.Test(req, -1)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, res.StatusCode)
+}
+
+func TestLifecycleOfUnknownSession(t *testing.T) {
+	m := new(datastore.MockDatastore)
+	m.On("GetUsers", "67890").Return([]datastore.User{}, fmt.Errorf("Specified session does not exist"))
+	ls := new(datastore.MockLifecycleStore)
+
+	app := NewServer(&Config{}, m, nil, WithLifecycle(ls)).Start()
+
+	res, err := app.Test(httptestRequest("GET", "/api/sessions/67890/lifecycle", ""), -1)
+	assert.NoError(t, err)
+	assertErrorResponse(t, res, 404, "Specified session does not exist")
+
+	res, err = app.Test(httptestRequest("PUT", "/api/sessions/67890/lifecycle", `{"state":"closed"}`), -1)
+	assert.NoError(t, err)
+	assertErrorResponse(t, res, 404, "Specified session does not exist")
+	ls.AssertNotCalled(t, "ChangeState", mock.Anything, mock.Anything)
+}
+
+func TestChangeStateFailsDueToInvalidTransition(t *testing.T) {
+	s, _, ls := newLifecycleServer("", closedLifecycle)
+	ls.On("ChangeState", "12345", mock.Anything).Return(datastore.Lifecycle{}, fmt.Errorf("Session can't change from closed to estimating"))
+
+	res, err := s.Start().Test(settingsRequest("PUT", "/api/sessions/12345/lifecycle", "Tigger", `{"state":"estimating"}`), -1)
+	assert.NoError(t, err)
+	assertErrorResponse(t, res, 400, "Session can't change from closed to estimating")
+}
+
+func TestClosedSessionRejectsChanges(t *testing.T) {
+	tests := []struct {
+		method string
+		route  string
+		body   string
+	}{
+		{"DELETE", "/api/sessions/12345", ""},
+		{"POST", "/api/sessions/12345/users", `{"name":"Rabbit"}`},
+		{"PUT", "/api/sessions/12345/users/tigger", `{"name":"Tigger"}`},
+		{"DELETE", "/api/sessions/12345/users/tigger", ""},
+		{"POST", "/api/sessions/12345/tasks", `{"id":"TEST02","summary":"Logout"}`},
+		{"DELETE", "/api/sessions/12345/tasks/TEST01", ""},
+		{"PUT", "/api/sessions/12345/tasks/TEST01", `{"effort":3,"standarddeviation":1}`},
+		{"DELETE", "/api/sessions/12345/tasks/TEST01/estimate", ""},
+		{"POST", "/api/sessions/12345/estimates", `{"id":"TEST01","user":"Tigger","b":1,"m":2,"w":3}`},
+		{"DELETE", "/api/sessions/12345/estimates/tigger/TEST01", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.route, func(t *testing.T) {
+			s, _, _ := newLifecycleServer("pooh", closedLifecycle)
+
+			res, err := s.Start().Test(httptestRequest(tt.method, tt.route, tt.body), -1)
+			assert.NoError(t, err)
+			assertErrorResponse(t, res, 409, "Session does not accept changes: it is closed and must be reopened first")
+		})
+	}
+}
+
+func TestClosedSessionRejectsChangesOfExtensions(t *testing.T) {
+	tests := []struct {
+		method string
+		route  string
+		body   string
+	}{
+		{"PUT", "/api/sessions/12345/settings", `{"anonymity":"off"}`},
+		{"POST", "/api/sessions/12345/tasks/TEST01/comments", `{"text":"Hi"}`},
+		{"PUT", "/api/sessions/12345/tasks/TEST01/comments/c1", `{"text":"Hi"}`},
+		{"DELETE", "/api/sessions/12345/tasks/TEST01/comments/c1", ""},
+		{"POST", "/api/sessions/12345/webhooks", `{"url":"https://bot.example.com/doker"}`},
+		{"DELETE", "/api/sessions/12345/webhooks/abcd", ""},
+		{"POST", "/api/sessions/12345/clone", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.route, func(t *testing.T) {
+			m := new(datastore.MockDatastore)
+			m.On("GetUsers", "12345").Return(testUsers("Tigger", "Pooh"), nil)
+			m.On("GetTasks", "12345").Return([]datastore.Task{{ID: "TEST01"}}, nil)
+			m.On("GetEstimates", "12345").Return([]datastore.Estimate{}, nil)
+			ss := new(datastore.MockSettingsStore)
+			ss.On("GetSettings", "12345").Return(datastore.Settings{Anonymity: datastore.AnonymityOff, Moderator: "pooh"}, nil)
+			cs := new(datastore.MockCommentStore)
+			cs.On("GetComment", "12345", "c1").Return(datastore.Comment{ID: "c1", TaskID: "TEST01", AuthorID: "pooh"}, nil)
+			ls := new(datastore.MockLifecycleStore)
+			ls.On("GetLifecycle", "12345").Return(closedLifecycle, nil)
+
+			app := NewServer(&Config{}, m, nil, WithSettings(ss), WithLifecycle(ls), WithComments(cs),
+				WithWebhooks(new(datastore.MockWebhookStore), new(recordingPublisher)),
+				WithTemplates(new(datastore.MockTemplateStore))).Start()
+
+			res, err := app.Test(settingsRequest(tt.method, tt.route, "Pooh", tt.body), -1)
+			assert.NoError(t, err)
+			assertErrorResponse(t, res, 409, "Session does not accept changes: it is closed and must be reopened first")
+		})
+	}
+}
+
+func TestClosedSessionCanBeRead(t *testing.T) {
+	s, m, _ := newLifecycleServer("pooh", closedLifecycle)
+	m.On("GetTasks", "12345").Return([]datastore.Task{{ID: "TEST01", Effort: 3}}, nil)
+
+	res, err := s.Start().Test(httptestRequest("GET", "/api/sessions/12345/tasks", ""), -1)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, res.StatusCode)
+}
+
+func TestLifecycleRoutesDisabled(t *testing.T) {
+	app := NewServer(&Config{}, new(datastore.MockDatastore), nil).Start()
+
+	res, err := app.Test(httptestRequest("GET", "/api/sessions/12345/lifecycle", ""), -1)
+	assert.NoError(t, err)
+	assert.Equal(t, 404, res.StatusCode)
+}
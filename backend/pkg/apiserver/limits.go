@@ -0,0 +1,123 @@
+package apiserver
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/limiter"
+	"github.com/haro87/dokerb/pkg/datastore"
+	"time"
+)
+
+// addRateLimits registers the configured rate limits in front
+// of the routes creating sessions, users and estimates
+func (s *APIServer) addRateLimits(app *fiber.App) {
+	l := s.config.Limits
+	for path, rl := range map[string]rateLimit{
+		"/api/sessions":                  l.Sessions,
+		"/api/sessions/:token/users":     l.Joins,
+		"/api/sessions/:token/estimates": l.Estimates,
+	} {
+		if handlers := rl.handlers(); len(handlers) > 0 {
+			app.Post(path, handlers...)
+			// Cloning creates sessions as well and shares their limit
+			if path == "/api/sessions" {
+				app.Post("/api/sessions/:token/clone", handlers...)
+			}
+		}
+	}
+}
+
+// handlers returns the limiter middlewares for the rate limit,
+// the global limit is only checked if the per client IP limit
+// is not exceeded
+func (rl rateLimit) handlers() []fiber.Handler {
+	var handlers []fiber.Handler
+	if rl.PerIP > 0 {
+		handlers = append(handlers, newLimiter(rl.PerIP, rl.Window, func(c *fiber.Ctx) string {
+			return c.IP()
+		}))
+	}
+	if rl.Global > 0 {
+		handlers = append(handlers, newLimiter(rl.Global, rl.Window, func(c *fiber.Ctx) string {
+			return "global"
+		}))
+	}
+	return handlers
+}
+
+func newLimiter(max int, window time.Duration, key func(c *fiber.Ctx) string) fiber.Handler {
+	return limiter.New(limiter.Config{
+		Max:          max,
+		Expiration:   window,
+		KeyGenerator: key,
+		LimitReached: func(c *fiber.Ctx) error {
+			return sendError(c, fiber.StatusTooManyRequests, fmt.Errorf("Too many requests, please retry later"))
+		},
+	})
+}
+
+// errorHandler responds with the standard error response for
+// request bodies exceeding the configured size
+func errorHandler(c *fiber.Ctx, err error) error {
+	if e, ok := err.(*fiber.Error); ok && e.Code == fiber.StatusRequestEntityTooLarge {
+		return sendError(c, e.Code, fmt.Errorf("Request body too large"))
+	}
+	return fiber.DefaultErrorHandler(c, err)
+}
+
+// storeErrorStatus returns the HTTP status for an error
+// returned by the datastore
+func storeErrorStatus(err error) int {
+	return storeErrorStatusOr(err, fiber.StatusInternalServerError)
+}
+
+// storeErrorStatusOr returns the HTTP status for an error returned
+// by a store, errors without specific status result in the fallback
+func storeErrorStatusOr(err error, fallback int) int {
+	if errors.Is(err, datastore.ErrLimitExceeded) {
+		return fiber.StatusTooManyRequests
+	}
+	if errors.Is(err, datastore.ErrObserver) {
+		return fiber.StatusForbidden
+	}
+	if errors.Is(err, datastore.ErrSessionClosed) {
+		return fiber.StatusConflict
+	}
+	if errors.Is(err, datastore.ErrInvalid) {
+		return fiber.StatusBadRequest
+	}
+	return fallback
+}
+
+func (l limits) validate() error {
+	for _, v := range []struct {
+		name  string
+		value int
+	}{
+		{"limits.body_size", l.BodySize},
+		{"limits.max_users", l.MaxUsers},
+		{"limits.max_tasks", l.MaxTasks},
+		{"limits.max_estimates", l.MaxEstimates},
+	} {
+		if v.value < 0 {
+			return fmt.Errorf("%s must not be negative", v.name)
+		}
+	}
+	for _, rl := range []struct {
+		name  string
+		limit rateLimit
+	}{
+		{"limits.sessions", l.Sessions},
+		{"limits.joins", l.Joins},
+		{"limits.estimates", l.Estimates},
+	} {
+		if rl.limit.PerIP < 0 || rl.limit.Global < 0 {
+			return fmt.Errorf("%s limits must not be negative", rl.name)
+		}
+		if (rl.limit.PerIP > 0 || rl.limit.Global > 0) && rl.limit.Window < time.Second {
+			return fmt.Errorf("%s.window must be at least 1s", rl.name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+package apiserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/haro87/dokerb/pkg/datastore"
+	"github.com/stretchr/testify/assert"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func assertErrorResponse(t *testing.T, res *http.Response, status int, reason string) {
+	assert.Equal(t, status, res.StatusCode)
+
+	var er ErrorResponse
+	err := json.NewDecoder(res.Body).Decode(&er)
+	assert.NoError(t, err)
+	assert.Equal(t, "error", er.Message)
+	assert.Equal(t, reason, er.Reason)
+}
+
+func TestSessionCreationPerIPRateLimit(t *testing.T) {
+	m := new(datastore.MockDatastore)
+	m.On("CreateSession").Return("12345", nil)
+
+	app := NewServer(&Config{
+		Limits: limits{Sessions: rateLimit{PerIP: 2, Window: time.Minute}},
+	}, m, nil).Start()
+
+	for i := 0; i < 2; i++ {
+		res, err := app.Test(httptestRequest("POST", "/api/sessions", ""), -1)
+		assert.NoError(t, err)
+		assert.Equal(t, 200, res.StatusCode)
+	}
+
+	res, err := app.Test(httptestRequest("POST", "/api/sessions", ""), -1)
+	assert.NoError(t, err)
+	assertErrorResponse(t, res, 429, "Too many requests, please retry later")
+	assert.NotEmpty(t, res.Header.Get("Retry-After"))
+	m.AssertNumberOfCalls(t, "CreateSession", 2)
+}
+
+func TestUserJoinGlobalRateLimit(t *testing.T) {
+	m := new(datastore.MockDatastore)
+	m.On("JoinSession", "12345", datastore.User{Name: "Tigger"}).Return("tigger", nil)
+	m.On("JoinSession", "54321", datastore.User{Name: "Tigger"}).Return("tigger", nil)
+
+	app := NewServer(&Config{
+		Limits: limits{Joins: rateLimit{PerIP: 10, Global: 1, Window: time.Minute}},
+	}, m, nil).Start()
+
+	res, err := app.Test(httptestRequest("POST", "/api/sessions/12345/users", `{"name":"Tigger"}`), -1)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, res.StatusCode)
+
+	res, err = app.Test(httptestRequest("POST", "/api/sessions/54321/users", `{"name":"Tigger"}`), -1)
+	assert.NoError(t, err)
+	assertErrorResponse(t, res, 429, "Too many requests, please retry later")
+
+	// Other routes are not limited
+	m.On("GetUsers", "12345").Return(testUsers("Tigger"), nil)
+	res, err = app.Test(httptestRequest("GET", "/api/sessions/12345/users", ""), -1)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, res.StatusCode)
+}
+
+func TestPerSessionUserLimit(t *testing.T) {
+	m := new(datastore.MockDatastore)
+	m.On("GetUsers", "12345").Return(testUsers("Tigger"), nil)
+
+	app := NewServer(&Config{
+		Limits: limits{MaxUsers: 1},
+	}, m, nil).Start()
+
+	res, err := app.Test(httptestRequest("POST", "/api/sessions/12345/users", `{"name":"Rabbit"}`), -1)
+	assert.NoError(t, err)
+	assertErrorResponse(t, res, 429, "Limit exceeded: at most 1 users per session")
+	m.AssertNotCalled(t, "JoinSession", "12345", datastore.User{Name: "Rabbit"})
+}
+
+func TestInvalidValuesAreBadRequests(t *testing.T) {
+	m := new(datastore.MockDatastore)
+	m.On("GetUsers", "12345").Return(testUsers("Tigger"), nil)
+	m.On("AddTask", "12345", "TEST01", "Test").Return(fmt.Errorf("%w: Task with ID: TEST01 already part of session", datastore.ErrInvalid))
+
+	app := NewServer(&Config{}, m, nil).Start()
+
+	res, err := app.Test(httptestRequest("POST", "/api/sessions/12345/tasks", `{"id":"TEST01","summary":"Test"}`), -1)
+	assert.NoError(t, err)
+	assertErrorResponse(t, res, 400, "Invalid value: Task with ID: TEST01 already part of session")
+}
+
+func TestRequestBodySizeLimit(t *testing.T) {
+	m := new(datastore.MockDatastore)
+
+	app := NewServer(&Config{
+		Limits: limits{BodySize: 32},
+	}, m, nil).Start()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	go app.Listener(ln)
+	defer func() {
+		http.DefaultClient.CloseIdleConnections()
+		app.Shutdown()
+	}()
+
+	body := `{"id":"TEST01","summary":"` + strings.Repeat("a", 64) + `"}`
+	res, err := http.Post("http://"+ln.Addr().String()+"/api/sessions/12345/tasks", "application/json", strings.NewReader(body))
+	assert.NoError(t, err)
+	defer res.Body.Close()
+	assertErrorResponse(t, res, 413, "Request body too large")
+	m.AssertNotCalled(t, "AddTask", "12345", "TEST01", strings.Repeat("a", 64))
+}
+
+func TestValidateLimits(t *testing.T) {
+	tests := []struct {
+		name    string
+		limits  limits
+		wantErr string
+	}{
+		{"unlimited", limits{}, ""},
+		{"negative cap", limits{MaxTasks: -1}, "limits.max_tasks must not be negative"},
+		{"negative rate", limits{Joins: rateLimit{Global: -1}}, "limits.joins limits must not be negative"},
+		{"missing window", limits{Estimates: rateLimit{PerIP: 1}}, "limits.estimates.window must be at least 1s"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.limits.validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Equal(t, tt.wantErr, err.Error())
+		})
+	}
+}
+
+func httptestRequest(method, target, body string) *http.Request {
+	req, _ := http.NewRequest(method, target, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return req
+}
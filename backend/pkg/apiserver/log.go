@@ -0,0 +1,112 @@
+package apiserver
+
+import (
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"github.com/haro87/dokerb/pkg/datastore"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"strings"
+	"time"
+)
+
+// localsError is the key under which the error of a failed
+// request is kept for the request logger
+const localsError = "error"
+
+// NewLogger returns a new zap logger configured by the
+// level and encoding of the provided config
+func NewLogger(config *Config) (*zap.Logger, error) {
+	level := zap.NewAtomicLevel()
+	if config.Logger.Level != "" {
+		if err := level.UnmarshalText([]byte(config.Logger.Level)); err != nil {
+			return nil, fmt.Errorf("Invalid log level: %s", config.Logger.Level)
+		}
+	}
+
+	encoding := config.Logger.Encoding
+	if encoding == "" {
+		encoding = "console"
+	}
+	if encoding != "console" && encoding != "json" {
+		return nil, fmt.Errorf("Invalid log encoding: %s", encoding)
+	}
+
+	encoderConfig := zap.NewProductionEncoderConfig()
+	encoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+
+	zc := zap.Config{
+		Level:            level,
+		Encoding:         encoding,
+		EncoderConfig:    encoderConfig,
+		OutputPaths:      []string{"stdout"},
+		ErrorOutputPaths: []string{"stderr"},
+	}
+
+	return zc.Build()
+}
+
+// requestLogger returns a middleware logging every request
+// together with its request ID and session token
+func (s *APIServer) requestLogger() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+
+		err := c.Next()
+
+		status := c.Response().StatusCode()
+		if err != nil {
+			status = fiber.StatusInternalServerError
+			if e, ok := err.(*fiber.Error); ok {
+				status = e.Code
+			}
+		}
+
+		path := c.Path()
+		token := c.Params("token")
+		if token != "" && s.config.Logger.RedactTokens {
+			path = strings.Replace(path, token, datastore.RedactToken(token), 1)
+			token = datastore.RedactToken(token)
+		}
+
+		fields := []zap.Field{
+			zap.String("request_id", fmt.Sprintf("%v", c.Locals("requestid"))),
+			zap.String("method", c.Method()),
+			zap.String("path", path),
+			zap.Int("status", status),
+			zap.Duration("latency", time.Since(start)),
+			zap.String("ip", c.IP()),
+		}
+		if token != "" {
+			fields = append(fields, zap.String("session", token))
+		}
+
+		if err != nil {
+			fields = append(fields, zap.Error(err))
+		} else if e, ok := c.Locals(localsError).(error); ok {
+			fields = append(fields, zap.Error(e))
+		}
+
+		switch {
+		case status >= 500:
+			s.logger.Error("Request failed", fields...)
+		case status >= 400:
+			s.logger.Warn("Request rejected", fields...)
+		default:
+			s.logger.Info("Request handled", fields...)
+		}
+
+		return err
+	}
+}
+
+// sendError responds with the standard error response and keeps
+// the error for the request logger
+func sendError(c *fiber.Ctx, status int, err error) error {
+	c.Locals(localsError, err)
+	data := ErrorResponse{
+		Message: "error",
+		Reason:  err.Error(),
+	}
+	return c.Status(status).JSON(data)
+}
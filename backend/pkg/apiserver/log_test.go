@@ -0,0 +1,102 @@
+package apiserver
+
+import (
+	"fmt"
+	"github.com/haro87/dokerb/pkg/datastore"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"net/http"
+	"testing"
+)
+
+func TestNewLogger(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  logging
+		wantErr string
+	}{
+		{"defaults", logging{}, ""},
+		{"json debug", logging{Level: "debug", Encoding: "json"}, ""},
+		{"console warn", logging{Level: "warn", Encoding: "console"}, ""},
+		{"invalid level", logging{Level: "loud"}, "Invalid log level: loud"},
+		{"invalid encoding", logging{Encoding: "xml"}, "Invalid log encoding: xml"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger, err := NewLogger(&Config{Logger: tt.config})
+			if tt.wantErr != "" {
+				assert.Nil(t, logger)
+				assert.Equal(t, tt.wantErr, err.Error())
+				return
+			}
+			assert.NoError(t, err)
+			assert.NotNil(t, logger)
+		})
+	}
+}
+
+func TestRequestLoggerLogsRequestWithRedactedToken(t *testing.T) {
+	m := new(datastore.MockDatastore)
+	m.On("RemoveSession", "12345678901234567890123456789012").Return(nil)
+
+	core, logs := observer.New(zapcore.InfoLevel)
+	app := NewServer(&Config{
+		Logger: logging{RedactTokens: true},
+	}, m, zap.New(core)).Start()
+
+	req, _ := http.NewRequest("DELETE", "/api/sessions/12345678901234567890123456789012", nil)
+	req.Header.Set("X-Request-ID", "my-request")
+	res, err := app.Test(req, -1)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, res.StatusCode)
+
+	assert.Equal(t, 1, logs.Len())
+	entry := logs.All()[0]
+	fields := entry.ContextMap()
+	assert.Equal(t, zapcore.InfoLevel, entry.Level)
+	assert.Equal(t, "Request handled", entry.Message)
+	assert.Equal(t, "my-request", fields["request_id"])
+	assert.Equal(t, "1234****", fields["session"])
+	assert.Equal(t, "/api/sessions/1234****", fields["path"])
+	assert.Equal(t, int64(200), fields["status"])
+}
+
+func TestRequestLoggerLogsDatastoreErrors(t *testing.T) {
+	m := new(datastore.MockDatastore)
+	m.On("RemoveSession", "12345").Return(fmt.Errorf("Unable to remove session"))
+
+	core, logs := observer.New(zapcore.InfoLevel)
+	app := NewServer(&Config{}, m, zap.New(core)).Start()
+
+	req, _ := http.NewRequest("DELETE", "/api/sessions/12345", nil)
+	res, err := app.Test(req, -1)
+	assert.NoError(t, err)
+	assert.Equal(t, 500, res.StatusCode)
+
+	assert.Equal(t, 1, logs.Len())
+	entry := logs.All()[0]
+	fields := entry.ContextMap()
+	assert.Equal(t, zapcore.ErrorLevel, entry.Level)
+	assert.Equal(t, "Request failed", entry.Message)
+	assert.Equal(t, "12345", fields["session"])
+	assert.Equal(t, "Unable to remove session", fields["error"])
+	assert.NotEmpty(t, fields["request_id"])
+}
+
+func TestRequestLoggerLogsRejectedRequests(t *testing.T) {
+	m := new(datastore.MockDatastore)
+
+	core, logs := observer.New(zapcore.InfoLevel)
+	app := NewServer(&Config{}, m, zap.New(core)).Start()
+
+	req, _ := http.NewRequest("GET", "/api/unknown", nil)
+	res, err := app.Test(req, -1)
+	assert.NoError(t, err)
+	assert.Equal(t, 404, res.StatusCode)
+
+	assert.Equal(t, 1, logs.Len())
+	assert.Equal(t, zapcore.WarnLevel, logs.All()[0].Level)
+	assert.NotContains(t, logs.All()[0].ContextMap(), "session")
+}
@@ -2,51 +2,269 @@ package apiserver
 
 import (
 	"github.com/gofiber/fiber/v2"
-	cors "github.com/gofiber/fiber/v2/middleware/cors"
-	logger "github.com/gofiber/fiber/v2/middleware/logger"
+	requestid "github.com/gofiber/fiber/v2/middleware/requestid"
 	"github.com/haro87/dokerb/pkg/datastore"
-	"os"
+	apimetrics "github.com/haro87/dokerb/pkg/metrics"
+	"github.com/haro87/dokerb/pkg/presence"
+	"github.com/haro87/dokerb/pkg/tracker"
+	"github.com/haro87/dokerb/pkg/webhook"
+	"go.uber.org/zap"
+	"net/http"
 )
 
 // APIServer struct
 type APIServer struct {
-	config *Config
-	ds     datastore.DataStore
+	config  *Config
+	ds      datastore.DataStore
+	metrics *apimetrics.Metrics
+	logger  *zap.Logger
+	ready   int32
+
+	webhooks  datastore.WebhookStore
+	publisher webhook.Publisher
+
+	syncs  datastore.SyncStore
+	syncer tracker.TaskSyncer
+
+	settings   datastore.SettingsStore
+	templates  datastore.TemplateStore
+	portfolios datastore.PortfolioStore
+	audit      datastore.AuditStore
+	comments   datastore.CommentStore
+	lifecycles datastore.LifecycleStore
+	replayer   datastore.SessionReplayer
+	secrets    datastore.SecretStore
+
+	participants *presence.Tracker
 }
 
-// NewServer method for init new server instance
-func NewServer(config *Config, ds datastore.DataStore) *APIServer {
-	return &APIServer{
+// NewServer method for init new server instance, a nil logger
+// disables logging
+func NewServer(config *Config, ds datastore.DataStore, logger *zap.Logger, opts ...Option) *APIServer {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+
+	s := &APIServer{
 		config: config,
 		ds:     ds,
+		logger: logger,
+		ready:  1,
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	// Instrument the datastore, if metrics are enabled in config
+	if config.Metrics.Enabled {
+		s.metrics = apimetrics.NewMetrics(ds)
+		s.ds = apimetrics.NewInstrumentedDataStore(ds, s.metrics)
+	}
+
+	// Track the presence of users, if enabled in config
+	if config.Presence.Enabled {
+		s.participants = presence.NewTracker(
+			presence.WithTimeouts(config.Presence.IdleTimeout, config.Presence.OfflineTimeout),
+		)
+	}
+
+	// Publish session events, if webhooks are enabled
+	if s.publisher != nil {
+		s.ds = webhook.NewNotifyingDataStore(s.ds, s.publisher, s.settings)
+	}
+
+	// Sync finalized tasks, if the issue tracker is enabled
+	if s.syncer != nil {
+		s.ds = tracker.NewSyncingDataStore(s.ds, s.syncer)
+	}
+
+	// Only accept estimates matching the deck of the session
+	if s.settings != nil {
+		s.ds = datastore.NewDeckDataStore(s.ds, s.settings)
 	}
+
+	// Enforce the per session limits
+	l := config.Limits
+	if l.MaxUsers > 0 || l.MaxTasks > 0 || l.MaxEstimates > 0 {
+		s.ds = datastore.NewLimitedDataStore(s.ds, datastore.Limits{
+			MaxUsers:     l.MaxUsers,
+			MaxTasks:     l.MaxTasks,
+			MaxEstimates: l.MaxEstimates,
+		})
+		if s.templates != nil {
+			s.templates = datastore.NewLimitedTemplateStore(s.templates, datastore.Limits{
+				MaxUsers: l.MaxUsers,
+				MaxTasks: l.MaxTasks,
+			})
+		}
+	}
+
+	// Revoke the secrets of users leaving and of removed sessions
+	if s.secrets != nil {
+		s.ds = datastore.NewRevokingDataStore(s.ds, s.secrets, logger)
+	}
+
+	// Remove the webhooks of removed sessions
+	if s.webhooks != nil {
+		s.ds = datastore.NewWebhookRemovingDataStore(s.ds, s.webhooks, logger)
+	}
+
+	// Remove the comments of removed sessions
+	if s.comments != nil {
+		s.ds = datastore.NewCommentRemovingDataStore(s.ds, s.comments, logger)
+	}
+
+	// Reject changes of closed sessions, if the lifecycle is enabled
+	if s.lifecycles != nil {
+		locking := datastore.NewLockingDataStore(s.ds, s.lifecycles, logger)
+		s.ds, s.lifecycles = locking, locking
+		if s.settings != nil {
+			s.settings = datastore.NewLockingSettingsStore(s.settings, locking)
+		}
+		if s.comments != nil {
+			s.comments = datastore.NewLockingCommentStore(s.comments, locking)
+		}
+		if s.webhooks != nil {
+			s.webhooks = datastore.NewLockingWebhookStore(s.webhooks, locking)
+		}
+		if s.templates != nil {
+			s.templates = datastore.NewLockingTemplateStore(s.templates, locking)
+		}
+	}
+
+	// Record all mutations on behalf of the requesting user,
+	// if the audit log is enabled
+	if s.audit != nil {
+		s.ds = datastore.NewAuditingDataStore(s.ds, s.audit, logger)
+	}
+
+	return s
 }
 
 // Start method for start new server
 func (s *APIServer) Start() *fiber.App {
 	// Initialize a new app
-	app := fiber.New()
+	app := fiber.New(fiber.Config{
+		BodyLimit:    s.config.Limits.BodySize,
+		ErrorHandler: errorHandler,
+	})
+
+	// Count and time all requests, if metrics are enabled
+	if s.metrics != nil {
+		app.Use(s.metrics.Middleware())
+	}
 
 	// Register middlewares
 	app.Use(
-		cors.New(), // Add CORS to each route
-		// Simple logger
-		logger.New(
-			logger.Config{
-				Format:     "${time} [${status}] ${method} ${path} (${latency})\n",
-				TimeFormat: "Mon, 2 Jan 2006 15:04:05 MST",
-				Output:     os.Stdout,
-			},
-		),
+		s.corsMiddleware(), // Add CORS for the allowed origins
+		requestid.New(),    // Add a request ID to each request
+		s.requestLogger(),
 	)
 
+	// Add liveness and readiness probes
+	s.addHealthRoutes(app)
+
 	// Add static files, if prefix and path was defined in config
 	if s.config.Static.Prefix != "" && s.config.Static.Path != "" {
 		app.Static(s.config.Static.Prefix, s.config.Static.Path)
 	}
 
+	// Limit the rate of creating sessions, users and estimates
+	s.addRateLimits(app)
+
+	// Verify who requests are sent on behalf of, if secrets are enabled
+	settings := sessionSettings{store: s.settings, units: s.config.Units}
+	if s.secrets != nil {
+		addAuthentication(app, s.ds, s.secrets, settings)
+	}
+
 	// Register API routes
-	Routes(app, s.ds)
+	Routes(app, s.ds, settings, s.templates, s.participants, s.secrets)
+
+	// Register the session export, comments are only included if enabled
+	exportRoutes(app, s.ds, s.comments, settings)
+
+	// Register template and cloning routes, if enabled
+	if s.templates != nil {
+		templateRoutes(app, s.ds, s.templates, s.secrets)
+	}
+
+	// Register portfolio routes, if enabled
+	if s.portfolios != nil {
+		portfolioRoutes(app, s.ds, s.portfolios, settings)
+	}
+
+	// Register session settings routes, if enabled
+	if s.settings != nil {
+		settingsRoutes(app, s.ds, settings)
+	}
+
+	// Register comment routes, if enabled
+	if s.comments != nil {
+		commentRoutes(app, s.ds, s.comments, settings)
+	}
+
+	// Register lifecycle routes, if enabled
+	if s.lifecycles != nil {
+		lifecycleRoutes(app, s.ds, s.lifecycles, settings)
+	}
+
+	// Register audit log routes, if enabled
+	if s.audit != nil {
+		auditRoutes(app, s.ds, s.audit, settings)
+	}
+
+	// Register presence routes, if enabled
+	if s.participants != nil {
+		presenceRoutes(app, s.ds, s.participants, settings)
+	}
+
+	// Register session replay routes, if enabled
+	if s.replayer != nil {
+		replayRoutes(app, s.replayer, settings)
+	}
+
+	// Register webhook routes, if enabled
+	if s.webhooks != nil {
+		webhookRoutes(app, s.ds, s.webhooks, s.config.Webhooks.AllowPrivateTargets)
+	}
+
+	// Register issue tracker routes, if enabled
+	if s.syncs != nil {
+		trackerRoutes(app, s.syncs)
+	}
 
 	return app
 }
+
+// StartMetrics method for serving the metrics endpoint on its
+// own port, returns nil if metrics are disabled
+func (s *APIServer) StartMetrics() *http.Server {
+	srv := s.newMetricsServer()
+	if srv == nil {
+		return nil
+	}
+
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			s.logger.Error("Metrics server failed", zap.Error(err))
+		}
+	}()
+
+	return srv
+}
+
+func (s *APIServer) newMetricsServer() *http.Server {
+	if s.metrics == nil {
+		return nil
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", s.metrics.Handler())
+
+	return &http.Server{
+		Addr:    s.config.Metrics.Host + ":" + s.config.Metrics.Port,
+		Handler: mux,
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"io/ioutil"
 	"net/http"
+	"net/http/httptest"
 	"os"
 	"testing"
 )
@@ -77,12 +78,12 @@ func TestAPIRoutes(t *testing.T) {
 	app1 := NewServer(&Config{
 		Database: database{Location: td + "/my.db"},
 		Static:   static{Prefix: "/public", Path: "../../static"},
-	}, m).Start()
+	}, m, nil).Start()
 
 	app2 := NewServer(&Config{
 		Database: database{Location: td + "/my-second.db"},
 		Static:   static{Prefix: "/", Path: "../../static"},
-	}, m).Start()
+	}, m, nil).Start()
 
 	// Needed routes
 	app1.Get("/hello-test", func(c *fiber.Ctx) error {
@@ -145,3 +146,37 @@ func TestAPIRoutes(t *testing.T) {
 
 	os.RemoveAll(td)
 }
+
+func TestMetricsDisabled(t *testing.T) {
+	m := new(datastore.MockDatastore)
+	s := NewServer(&Config{}, m, nil)
+
+	assert.Nil(t, s.StartMetrics())
+}
+
+func TestMetricsEnabled(t *testing.T) {
+	m := new(datastore.MockDatastore)
+	m.On("GetSessions").Return([]string{}, nil)
+	m.On("RemoveSession", "12345").Return(nil)
+
+	s := NewServer(&Config{
+		Metrics: metrics{Enabled: true, Host: "127.0.0.1", Port: "9100"},
+	}, m, nil)
+	app := s.Start()
+
+	req, _ := http.NewRequest("DELETE", "/api/sessions/12345", nil)
+	res, err := app.Test(req, -1)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, res.StatusCode)
+
+	srv := s.newMetricsServer()
+	assert.Equal(t, "127.0.0.1:9100", srv.Addr)
+
+	rec := httptest.NewRecorder()
+	srv.Handler.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body, _ := ioutil.ReadAll(rec.Body)
+
+	assert.Equal(t, 200, rec.Code)
+	assert.Contains(t, string(body), `dokerb_http_requests_total{method="DELETE",route="/api/sessions/:token",status="200"} 1`)
+	assert.Contains(t, string(body), `dokerb_datastore_operation_duration_seconds_count{operation="RemoveSession"} 1`)
+}
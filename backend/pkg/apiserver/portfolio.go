@@ -0,0 +1,308 @@
+package apiserver
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/haro87/dokerb/pkg/compute"
+	"github.com/haro87/dokerb/pkg/datastore"
+)
+
+// Portfolio represents a named group of sessions
+type Portfolio struct {
+	Name     string   `json:"name" example:"roadmap" format:"string"`
+	Sessions []string `json:"sessions" example:"eaf27c59ecdf0db4e165c4f940e176ec" format:"[]string"`
+}
+
+// PortfoliosResponse represents the get portfolios response, which
+// only contains the names as the IDs grant access to the portfolios
+type PortfoliosResponse struct {
+	Message    string   `json:"message" example:"ok" format:"string"`
+	Portfolios []string `json:"portfolios" example:"roadmap" format:"[]string"`
+}
+
+// SessionSummary represents the finalized tasks of a session and
+// their total effort, removed sessions are flagged
+type SessionSummary struct {
+	Token             string           `json:"token" example:"eaf27c59ecdf0db4e165c4f940e176ec" format:"string"`
+	Tasks             []datastore.Task `json:"tasks" format:"[]datastore.Task"`
+	Effort            float64          `json:"effort" example:"21.5" format:"float64"`
+	StandardDeviation float64          `json:"standarddeviation" example:"2.4" format:"float64"`
+	Pending           int              `json:"pending" example:"2" format:"int"`
+	Unestimated       int              `json:"unestimated" example:"3" format:"int"`
+	Removed           bool             `json:"removed" example:"false" format:"bool"`
+}
+
+// PortfolioSummary represents the combined summary of all sessions
+// of a portfolio
+type PortfolioSummary struct {
+	Name              string           `json:"name" example:"roadmap" format:"string"`
+	Unit              string           `json:"unit" example:"days" format:"string"`
+	Sessions          []SessionSummary `json:"sessions" format:"[]SessionSummary"`
+	Effort            float64          `json:"effort" example:"43" format:"float64"`
+	StandardDeviation float64          `json:"standarddeviation" example:"3.1" format:"float64"`
+	Finalized         int              `json:"finalized" example:"12" format:"int"`
+	Pending           int              `json:"pending" example:"4" format:"int"`
+	Unestimated       int              `json:"unestimated" example:"6" format:"int"`
+	Removed           int              `json:"removed" example:"0" format:"int"`
+}
+
+// PortfolioResponse represents the get portfolio response
+type PortfolioResponse struct {
+	Message   string           `json:"message" example:"ok" format:"string"`
+	Portfolio PortfolioSummary `json:"portfolio" format:"PortfolioSummary"`
+}
+
+// WithPortfolios enables portfolios grouping several sessions,
+// portfolios are kept in the provided store
+func WithPortfolios(store datastore.PortfolioStore) Option {
+	return func(s *APIServer) {
+		s.portfolios = store
+	}
+}
+
+// portfolioRoutes registers the routes for managing portfolios
+func portfolioRoutes(app *fiber.App, store datastore.DataStore, portfolios datastore.PortfolioStore, settings sessionSettings) {
+	APIGroup := app.Group("/api")
+
+	addCreatePortfolioRoute(APIGroup, portfolios)
+
+	addSavePortfolioRoute(APIGroup, portfolios)
+
+	addGetPortfoliosRoute(APIGroup, portfolios)
+
+	addGetPortfolioRoute(APIGroup, store, portfolios, settings)
+
+	addRemovePortfolioRoute(APIGroup, portfolios)
+}
+
+// Adding the create portfolio route
+// @Summary Create a portfolio
+// @Description Creates a named portfolio grouping several existing sessions. The route of the response contains the secret ID of the portfolio, which is required to get, change or remove it.
+// @Tags portfolio
+// @Accept  json
+// @Produce  json
+// @Param portfolio body Portfolio true "Portfolio"
+// @Success 200 {object} GeneralResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /portfolios [post]
+func addCreatePortfolioRoute(api fiber.Router, portfolios datastore.PortfolioStore) {
+	api.Post("/portfolios", func(c *fiber.Ctx) error {
+		p := new(Portfolio)
+
+		if err := c.BodyParser(p); err != nil {
+			return sendError(c, 400, err)
+		}
+
+		id, err := portfolios.CreatePortfolio(datastore.Portfolio{
+			Name:     p.Name,
+			Sessions: p.Sessions,
+		})
+
+		if err != nil {
+			return sendError(c, 500, err)
+		}
+
+		data := GeneralResponse{
+			Message: "ok",
+			Route:   "/portfolios/" + id,
+		}
+		return c.Status(200).JSON(data)
+	})
+}
+
+// Adding the save portfolio route
+// @Summary Save a portfolio
+// @Description Replaces the name and sessions of an existing portfolio
+// @Tags portfolio
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Portfolio ID"
+// @Param portfolio body Portfolio true "Portfolio"
+// @Success 200 {object} GeneralResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /portfolios/{id} [put]
+func addSavePortfolioRoute(api fiber.Router, portfolios datastore.PortfolioStore) {
+	api.Put("/portfolios/:id", func(c *fiber.Ctx) error {
+		p := new(Portfolio)
+
+		if err := c.BodyParser(p); err != nil {
+			return sendError(c, 400, err)
+		}
+
+		if _, err := portfolios.GetPortfolio(c.Params("id")); err != nil {
+			return sendError(c, 404, err)
+		}
+
+		err := portfolios.SavePortfolio(datastore.Portfolio{
+			ID:       c.Params("id"),
+			Name:     p.Name,
+			Sessions: p.Sessions,
+		})
+
+		if err != nil {
+			return sendError(c, 500, err)
+		}
+
+		data := GeneralResponse{
+			Message: "ok",
+			Route:   "/portfolios/" + c.Params("id"),
+		}
+		return c.Status(200).JSON(data)
+	})
+}
+
+// Adding the get portfolios route
+// @Summary Get all portfolios
+// @Description Gets the names of all portfolios, neither their IDs nor their sessions are revealed
+// @Tags portfolio
+// @Produce  json
+// @Success 200 {object} PortfoliosResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /portfolios [get]
+func addGetPortfoliosRoute(api fiber.Router, portfolios datastore.PortfolioStore) {
+	api.Get("/portfolios", func(c *fiber.Ctx) error {
+		ps, err := portfolios.GetPortfolios()
+
+		if err != nil {
+			return sendError(c, 500, err)
+		}
+
+		res := []string{}
+		for _, p := range ps {
+			res = append(res, p.Name)
+		}
+
+		data := PortfoliosResponse{
+			Message:    "ok",
+			Portfolios: res,
+		}
+		return c.Status(200).JSON(data)
+	})
+}
+
+// Adding the get portfolio route
+// @Summary Get the summary of a portfolio
+// @Description Gets the finalized tasks of all sessions of a portfolio together with the total effort per session and overall, efforts are converted into the requested unit or the configured default unit. The standard deviation of a total assumes the tasks to be independent. Pending counts tasks estimated by users but not finalized, unestimated counts tasks without any estimate. Sessions removed since are flagged and counted as removed.
+// @Tags portfolio
+// @Produce  json
+// @Param id path string true "Portfolio ID"
+// @Param unit query string false "Unit to convert into, one of hours, days or weeks"
+// @Success 200 {object} PortfolioResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /portfolios/{id} [get]
+func addGetPortfolioRoute(api fiber.Router, store datastore.DataStore, portfolios datastore.PortfolioStore, settings sessionSettings) {
+	api.Get("/portfolios/:id", func(c *fiber.Ctx) error {
+		p, err := portfolios.GetPortfolio(c.Params("id"))
+
+		if err != nil {
+			return sendError(c, 404, err)
+		}
+
+		tokens, err := store.GetSessions()
+
+		if err != nil {
+			return sendError(c, 500, err)
+		}
+
+		existing := map[string]bool{}
+		for _, token := range tokens {
+			existing[token] = true
+		}
+
+		unit := c.Query("unit", settings.units.Default)
+		sessions := []compute.SessionSummary{}
+
+		for _, token := range p.Sessions {
+			if !existing[token] {
+				sessions = append(sessions, compute.RemovedSession(token))
+				continue
+			}
+
+			tasks, err := store.GetTasks(token)
+			if err != nil {
+				return sendError(c, 500, err)
+			}
+
+			ests, err := store.GetEstimates(token)
+			if err != nil {
+				return sendError(c, 500, err)
+			}
+
+			s, err := settings.get(token)
+			if err != nil {
+				return sendError(c, 500, err)
+			}
+
+			uc, err := settings.units.convert(s.Unit, unit)
+			if err != nil {
+				return sendError(c, 400, err)
+			}
+
+			sessions = append(sessions, compute.SummarizeSession(token, uc.tasks(tasks), ests))
+		}
+
+		data := PortfolioResponse{
+			Message:   "ok",
+			Portfolio: toPortfolioSummary(p.Name, unit, compute.SummarizePortfolio(sessions)),
+		}
+		return c.Status(200).JSON(data)
+	})
+}
+
+// Adding the remove portfolio route
+// @Summary Remove a portfolio
+// @Description Removes the portfolio with the specified ID, its sessions are kept
+// @Tags portfolio
+// @Produce  json
+// @Param id path string true "Portfolio ID"
+// @Success 200 {object} GeneralResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /portfolios/{id} [delete]
+func addRemovePortfolioRoute(api fiber.Router, portfolios datastore.PortfolioStore) {
+	api.Delete("/portfolios/:id", func(c *fiber.Ctx) error {
+		if _, err := portfolios.GetPortfolio(c.Params("id")); err != nil {
+			return sendError(c, 404, err)
+		}
+
+		if err := portfolios.RemovePortfolio(c.Params("id")); err != nil {
+			return sendError(c, 500, err)
+		}
+
+		data := GeneralResponse{
+			Message: "ok",
+		}
+		return c.Status(200).JSON(data)
+	})
+}
+
+func toPortfolioSummary(name, unit string, p compute.PortfolioSummary) PortfolioSummary {
+	sessions := []SessionSummary{}
+	for _, s := range p.Sessions {
+		sessions = append(sessions, SessionSummary{
+			Token:             s.Token,
+			Tasks:             s.Tasks,
+			Effort:            s.Effort,
+			StandardDeviation: s.StandardDeviation,
+			Pending:           s.Pending,
+			Unestimated:       s.Unestimated,
+			Removed:           s.Removed,
+		})
+	}
+
+	return PortfolioSummary{
+		Name:              name,
+		Unit:              unit,
+		Sessions:          sessions,
+		Effort:            p.Effort,
+		StandardDeviation: p.StandardDeviation,
+		Finalized:         p.Finalized,
+		Pending:           p.Pending,
+		Unestimated:       p.Unestimated,
+		Removed:           p.Removed,
+	}
+}
@@ -0,0 +1,189 @@
+package apiserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/haro87/dokerb/pkg/datastore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"math"
+	"testing"
+)
+
+func TestCreatePortfolio(t *testing.T) {
+	ps := new(datastore.MockPortfolioStore)
+	ps.On("CreatePortfolio", datastore.Portfolio{Name: "roadmap", Sessions: []string{"12345", "67890"}}).Return("p1", nil)
+	ps.On("CreatePortfolio", datastore.Portfolio{Name: "other", Sessions: []string{"12345"}}).Return("", fmt.Errorf("Session: 12345 does not exist"))
+
+	app := NewServer(&Config{}, new(datastore.MockDatastore), nil, WithPortfolios(ps)).Start()
+
+	res, err := app.Test(httptestRequest("POST", "/api/portfolios", `{"name":"roadmap","sessions":["12345","67890"]}`), -1)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, res.StatusCode)
+	var gr GeneralResponse
+	assert.NoError(t, json.NewDecoder(res.Body).Decode(&gr))
+	assert.Equal(t, "/portfolios/p1", gr.Route)
+
+	res, err = app.Test(httptestRequest("POST", "/api/portfolios", `{"name":"other","sessions":["12345"]}`), -1)
+	assert.NoError(t, err)
+	assertErrorResponse(t, res, 500, "Session: 12345 does not exist")
+}
+
+func TestSavePortfolio(t *testing.T) {
+	ps := new(datastore.MockPortfolioStore)
+	ps.On("GetPortfolio", "p1").Return(datastore.Portfolio{ID: "p1", Name: "roadmap", Sessions: []string{"12345"}}, nil)
+	ps.On("GetPortfolio", "guess").Return(datastore.Portfolio{}, fmt.Errorf("Portfolio with ID: guess does not exist"))
+	ps.On("SavePortfolio", datastore.Portfolio{ID: "p1", Name: "roadmap", Sessions: []string{"12345", "67890"}}).Return(nil)
+
+	app := NewServer(&Config{}, new(datastore.MockDatastore), nil, WithPortfolios(ps)).Start()
+
+	res, err := app.Test(httptestRequest("PUT", "/api/portfolios/p1", `{"name":"roadmap","sessions":["12345","67890"]}`), -1)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, res.StatusCode)
+	var gr GeneralResponse
+	assert.NoError(t, json.NewDecoder(res.Body).Decode(&gr))
+	assert.Equal(t, "/portfolios/p1", gr.Route)
+
+	// Portfolios can't be overwritten without knowing their ID
+	res, err = app.Test(httptestRequest("PUT", "/api/portfolios/guess", `{"name":"roadmap","sessions":["12345"]}`), -1)
+	assert.NoError(t, err)
+	assertErrorResponse(t, res, 404, "Portfolio with ID: guess does not exist")
+	ps.AssertNumberOfCalls(t, "SavePortfolio", 1)
+}
+
+func TestGetPortfolios(t *testing.T) {
+	ps := new(datastore.MockPortfolioStore)
+	ps.On("GetPortfolios").Return([]datastore.Portfolio{{ID: "p1", Name: "roadmap", Sessions: []string{"12345"}}}, nil)
+
+	app := NewServer(&Config{}, new(datastore.MockDatastore), nil, WithPortfolios(ps)).Start()
+
+	res, err := app.Test(httptestRequest("GET", "/api/portfolios", ""), -1)
+	assert.NoError(t, err)
+	var pr PortfoliosResponse
+	assert.NoError(t, json.NewDecoder(res.Body).Decode(&pr))
+	assert.Equal(t, []string{"roadmap"}, pr.Portfolios)
+}
+
+func TestGetPortfolioSummary(t *testing.T) {
+	m := new(datastore.MockDatastore)
+	m.On("GetSessions").Return([]string{"12345", "67890"}, nil)
+	m.On("GetTasks", "12345").Return([]datastore.Task{
+		{ID: "TEST01", Effort: 24, StandardDeviation: 6, Finalized: true},
+		{ID: "TEST02"},
+	}, nil)
+	m.On("GetEstimates", "12345").Return([]datastore.Estimate{
+		{TaskID: "TEST02", UserID: "tigger", UserName: "Tigger", BestCase: 1, MostLikelyCase: 2, WorstCase: 3},
+	}, nil)
+	m.On("GetTasks", "67890").Return([]datastore.Task{
+		{ID: "TEST01", Effort: 2, StandardDeviation: 1, Finalized: true},
+		{ID: "TEST03"},
+	}, nil)
+	m.On("GetEstimates", "67890").Return([]datastore.Estimate{}, nil)
+	ss := new(datastore.MockSettingsStore)
+	ss.On("GetSettings", "12345").Return(datastore.Settings{Anonymity: "off", Unit: "hours"}, nil)
+	ss.On("GetSettings", "67890").Return(datastore.Settings{Anonymity: "off", Unit: "days"}, nil)
+	ps := new(datastore.MockPortfolioStore)
+	ps.On("GetPortfolio", "p1").Return(datastore.Portfolio{ID: "p1", Name: "roadmap", Sessions: []string{"12345", "67890", "abcde"}}, nil)
+
+	config := &Config{Units: units{Default: "days", HoursPerDay: 8, DaysPerWeek: 5}}
+	app := NewServer(config, m, nil, WithSettings(ss), WithPortfolios(ps)).Start()
+
+	res, err := app.Test(httptestRequest("GET", "/api/portfolios/p1", ""), -1)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, res.StatusCode)
+
+	var pr PortfolioResponse
+	assert.NoError(t, json.NewDecoder(res.Body).Decode(&pr))
+	p := pr.Portfolio
+	assert.Equal(t, "roadmap", p.Name)
+	assert.Equal(t, "days", p.Unit)
+	assert.Equal(t, 3, len(p.Sessions))
+	assert.Equal(t, []datastore.Task{{ID: "TEST01", Effort: 3, StandardDeviation: 0.75, Finalized: true}}, p.Sessions[0].Tasks)
+	assert.Equal(t, 1, p.Sessions[0].Pending)
+	assert.Equal(t, 1, p.Sessions[1].Unestimated)
+	assert.Equal(t, 5.0, p.Effort)
+	assert.True(t, math.Abs(1.25-p.StandardDeviation) <= float64CompareThreshold)
+	assert.Equal(t, 2, p.Finalized)
+	assert.Equal(t, 1, p.Pending)
+	assert.Equal(t, 1, p.Unestimated)
+
+	// Removed sessions are flagged instead of failing the summary
+	assert.Equal(t, SessionSummary{Token: "abcde", Tasks: []datastore.Task{}, Removed: true}, p.Sessions[2])
+	assert.Equal(t, 1, p.Removed)
+
+	res, err = app.Test(httptestRequest("GET", "/api/portfolios/p1?unit=hours", ""), -1)
+	assert.NoError(t, err)
+	assert.NoError(t, json.NewDecoder(res.Body).Decode(&pr))
+	assert.Equal(t, "hours", pr.Portfolio.Unit)
+	assert.Equal(t, 40.0, pr.Portfolio.Effort)
+}
+
+func TestGetPortfolioSummaryFails(t *testing.T) {
+	m := new(datastore.MockDatastore)
+	m.On("GetSessions").Return([]string{"12345", "67890"}, nil)
+	m.On("GetTasks", "12345").Return([]datastore.Task{}, nil)
+	m.On("GetEstimates", "12345").Return([]datastore.Estimate{}, nil)
+	m.On("GetTasks", "67890").Return([]datastore.Task{}, fmt.Errorf("Specified session does not exist"))
+	ss := new(datastore.MockSettingsStore)
+	ss.On("GetSettings", "12345").Return(datastore.Settings{Anonymity: "off", Unit: "points"}, nil)
+	ps := new(datastore.MockPortfolioStore)
+	ps.On("GetPortfolio", "roadmap").Return(datastore.Portfolio{ID: "roadmap", Name: "roadmap", Sessions: []string{"12345"}}, nil)
+	ps.On("GetPortfolio", "gone").Return(datastore.Portfolio{ID: "gone", Name: "gone", Sessions: []string{"67890"}}, nil)
+	ps.On("GetPortfolio", "other").Return(datastore.Portfolio{}, fmt.Errorf("Portfolio with ID: other does not exist"))
+
+	config := &Config{Units: units{Default: "hours", HoursPerDay: 8, DaysPerWeek: 5}}
+	app := NewServer(config, m, nil, WithSettings(ss), WithPortfolios(ps)).Start()
+
+	res, err := app.Test(httptestRequest("GET", "/api/portfolios/roadmap", ""), -1)
+	assert.NoError(t, err)
+	assertErrorResponse(t, res, 400, "Unable to convert points to hours")
+
+	res, err = app.Test(httptestRequest("GET", "/api/portfolios/gone", ""), -1)
+	assert.NoError(t, err)
+	assertErrorResponse(t, res, 500, "Specified session does not exist")
+
+}
+
+func TestGetUnknownPortfolio(t *testing.T) {
+	ps := new(datastore.MockPortfolioStore)
+	ps.On("GetPortfolio", "other").Return(datastore.Portfolio{}, fmt.Errorf("Portfolio with ID: other does not exist"))
+
+	app := NewServer(&Config{}, new(datastore.MockDatastore), nil, WithPortfolios(ps)).Start()
+
+	res, err := app.Test(httptestRequest("GET", "/api/portfolios/other", ""), -1)
+	assert.NoError(t, err)
+	assertErrorResponse(t, res, 404, "Portfolio with ID: other does not exist")
+}
+
+func TestRemovePortfolio(t *testing.T) {
+	ps := new(datastore.MockPortfolioStore)
+	ps.On("GetPortfolio", "roadmap").Return(datastore.Portfolio{ID: "roadmap", Name: "roadmap", Sessions: []string{}}, nil)
+	ps.On("RemovePortfolio", "roadmap").Return(nil)
+
+	app := NewServer(&Config{}, new(datastore.MockDatastore), nil, WithPortfolios(ps)).Start()
+
+	res, err := app.Test(httptestRequest("DELETE", "/api/portfolios/roadmap", ""), -1)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, res.StatusCode)
+	ps.AssertExpectations(t)
+}
+
+func TestRemoveUnknownPortfolio(t *testing.T) {
+	ps := new(datastore.MockPortfolioStore)
+	ps.On("GetPortfolio", "other").Return(datastore.Portfolio{}, fmt.Errorf("Portfolio with ID: other does not exist"))
+
+	app := NewServer(&Config{}, new(datastore.MockDatastore), nil, WithPortfolios(ps)).Start()
+
+	res, err := app.Test(httptestRequest("DELETE", "/api/portfolios/other", ""), -1)
+	assert.NoError(t, err)
+	assertErrorResponse(t, res, 404, "Portfolio with ID: other does not exist")
+	ps.AssertNotCalled(t, "RemovePortfolio", mock.Anything)
+}
+
+func TestPortfolioRoutesDisabled(t *testing.T) {
+	app := NewServer(&Config{}, new(datastore.MockDatastore), nil).Start()
+
+	res, err := app.Test(httptestRequest("GET", "/api/portfolios", ""), -1)
+	assert.NoError(t, err)
+	assert.Equal(t, 404, res.StatusCode)
+}
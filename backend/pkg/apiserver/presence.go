@@ -0,0 +1,164 @@
+package apiserver
+
+import (
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+	"github.com/haro87/dokerb/pkg/datastore"
+	"github.com/haro87/dokerb/pkg/presence"
+	"strconv"
+	"time"
+)
+
+// Participant represents the presence of a user inside a session,
+// LastSeen is empty if the user never sent a heartbeat. In anonymous
+// sessions ID and Name are only revealed as for estimates.
+type Participant struct {
+	ID       string `json:"id" example:"3f2a9c1e7b4d8e60" format:"string"`
+	Name     string `json:"name" example:"Tigger" format:"string"`
+	State    string `json:"state" example:"online" format:"string"`
+	LastSeen string `json:"lastseen" example:"2021-01-14T15:04:05Z" format:"string"`
+}
+
+// PresenceResponse represents the get presence response
+type PresenceResponse struct {
+	Message      string        `json:"message" example:"ok" format:"string"`
+	Participants []Participant `json:"participants" format:"[]Participant"`
+}
+
+func (p userPresence) validate() error {
+	if !p.Enabled {
+		return nil
+	}
+	if p.IdleTimeout <= 0 || p.OfflineTimeout <= p.IdleTimeout {
+		return fmt.Errorf("presence.idle_timeout must be positive and presence.offline_timeout must exceed it")
+	}
+	return nil
+}
+
+// presenceRoutes registers the routes for sending heartbeats and
+// getting the presence of users
+func presenceRoutes(app *fiber.App, store datastore.DataStore, participants *presence.Tracker, settings sessionSettings) {
+	APIGroup := app.Group("/api")
+
+	addHeartbeatRoute(APIGroup, store, participants)
+
+	addGetPresenceRoute(APIGroup, store, participants, settings)
+}
+
+// Adding the heartbeat route
+// @Summary Send a heartbeat of a user
+// @Description Marks an existing user of an existing session as online, clients are expected to send heartbeats periodically while the session is open. Users without heartbeat become idle and later offline after the configured timeouts.
+// @Tags user
+// @Produce  json
+// @Param token path string true "Session Token"
+// @Param user path string true "ID or unique name of the user"
+// @Param X-Doker-User header string false "ID or name of the requesting user"
+// @Param X-Doker-Secret header string false "Secret of the requesting user, if secrets are enabled"
+// @Success 200 {object} GeneralResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /sessions/{token}/users/{user}/heartbeat [post]
+func addHeartbeatRoute(api fiber.Router, store datastore.DataStore, participants *presence.Tracker) {
+	api.Post("/sessions/:token/users/:user/heartbeat", func(c *fiber.Ctx) error {
+		users, err := store.GetUsers(c.Params("token"))
+
+		if err != nil {
+			return sendError(c, 404, err)
+		}
+
+		u, err := lookupUser(users, c.Params("user"))
+
+		if err != nil {
+			return sendError(c, 404, err)
+		}
+
+		// Params are only valid within the handler, the tracker keeps copies
+		participants.Heartbeat(utils.CopyString(c.Params("token")), u.ID)
+
+		data := GeneralResponse{
+			Message: "ok",
+		}
+		return c.Status(200).JSON(data)
+	})
+}
+
+// Adding the get presence route
+// @Summary Get the presence of the users of a session
+// @Description Gets whether the users of an existing session are online, idle or offline together with the time of their last heartbeat. In anonymous sessions users are shown as for estimates.
+// @Tags user
+// @Produce  json
+// @Param token path string true "Session Token"
+// @Param X-Doker-User header string false "ID or name of the requesting user"
+// @Success 200 {object} PresenceResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /sessions/{token}/presence [get]
+func addGetPresenceRoute(api fiber.Router, store datastore.DataStore, participants *presence.Tracker, settings sessionSettings) {
+	api.Get("/sessions/:token/presence", func(c *fiber.Ctx) error {
+		users, err := store.GetUsers(c.Params("token"))
+
+		if err != nil {
+			return sendError(c, 404, err)
+		}
+
+		v, err := newViewer(c, settings, users)
+
+		if err != nil {
+			return sendError(c, 500, err)
+		}
+
+		res := []Participant{}
+		for i, p := range participants.Participants(c.Params("token"), userIDs(users)) {
+			lastSeen := ""
+			if !p.LastSeen.IsZero() {
+				lastSeen = p.LastSeen.UTC().Format(time.RFC3339)
+			}
+			id, name := p.ID, users[i].Name
+			if !v.reveals(id) {
+				id, name = "", v.name(id, name)
+			}
+			res = append(res, Participant{ID: id, Name: name, State: p.State, LastSeen: lastSeen})
+		}
+
+		data := PresenceResponse{
+			Message:      "ok",
+			Participants: res,
+		}
+		return c.Status(200).JSON(data)
+	})
+}
+
+// expectedUsers returns the users expected to provide estimates,
+// which are only the present users unless absent users are requested
+func expectedUsers(c *fiber.Ctx, participants *presence.Tracker, users []datastore.User) ([]datastore.User, error) {
+	if participants == nil {
+		return users, nil
+	}
+
+	absent := false
+	if v := c.Query("absent"); v != "" {
+		var err error
+		if absent, err = strconv.ParseBool(v); err != nil {
+			return users, fmt.Errorf("Absent must be true or false, provided: %s", v)
+		}
+	}
+	if absent {
+		return users, nil
+	}
+
+	present := map[string]bool{}
+	for _, id := range participants.Present(c.Params("token"), userIDs(users)) {
+		present[id] = true
+	}
+
+	res := []datastore.User{}
+	for _, u := range users {
+		if present[u.ID] {
+			res = append(res, u)
+		}
+	}
+	return res, nil
+}
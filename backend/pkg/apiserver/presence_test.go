@@ -0,0 +1,153 @@
+package apiserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/haro87/dokerb/pkg/datastore"
+	"github.com/haro87/dokerb/pkg/presence"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func setupTestCaseForPresence(t *testing.T, m *datastore.MockDatastore) (*APIServer, *time.Time) {
+	now := time.Date(2021, 1, 14, 15, 4, 5, 0, time.UTC)
+	config := &Config{Presence: userPresence{Enabled: true, IdleTimeout: 30 * time.Second, OfflineTimeout: 2 * time.Minute}}
+	s := NewServer(config, m, nil)
+	s.participants = presence.NewTracker(
+		presence.WithTimeouts(config.Presence.IdleTimeout, config.Presence.OfflineTimeout),
+		presence.WithClock(func() time.Time { return now }),
+	)
+	return s, &now
+}
+
+func TestHeartbeatAndGetPresence(t *testing.T) {
+	m := new(datastore.MockDatastore)
+	m.On("GetUsers", "12345").Return(testUsers("Tigger", "Pooh", "Rabbit"), nil)
+	s, now := setupTestCaseForPresence(t, m)
+	app := s.Start()
+
+	res, err := app.Test(httptestRequest("POST", "/api/sessions/12345/users/Tigger/heartbeat", ""), -1)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, res.StatusCode)
+
+	*now = now.Add(time.Minute)
+	res, err = app.Test(httptestRequest("POST", "/api/sessions/12345/users/pooh/heartbeat", ""), -1)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, res.StatusCode)
+
+	res, err = app.Test(httptestRequest("GET", "/api/sessions/12345/presence", ""), -1)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, res.StatusCode)
+
+	var pr PresenceResponse
+	assert.NoError(t, json.NewDecoder(res.Body).Decode(&pr))
+	assert.Equal(t, []Participant{
+		{ID: "tigger", Name: "Tigger", State: "idle", LastSeen: "2021-01-14T15:04:05Z"},
+		{ID: "pooh", Name: "Pooh", State: "online", LastSeen: "2021-01-14T15:05:05Z"},
+		{ID: "rabbit", Name: "Rabbit", State: "offline", LastSeen: ""},
+	}, pr.Participants)
+}
+
+func TestGetPresenceOfAnonymousSession(t *testing.T) {
+	m := new(datastore.MockDatastore)
+	m.On("GetUsers", "12345").Return(testUsers("Tigger", "Pooh"), nil)
+	s, _ := setupTestCaseForPresence(t, m)
+	ss := new(datastore.MockSettingsStore)
+	ss.On("GetSettings", "12345").Return(datastore.Settings{Anonymity: datastore.AnonymityPseudonyms, Salt: "s4lt"}, nil)
+	WithSettings(ss)(s)
+	app := s.Start()
+
+	res, err := app.Test(settingsRequest("GET", "/api/sessions/12345/presence", "Tigger", ""), -1)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, res.StatusCode)
+
+	var pr PresenceResponse
+	assert.NoError(t, json.NewDecoder(res.Body).Decode(&pr))
+	assert.Equal(t, []Participant{
+		{ID: "tigger", Name: "Tigger", State: "offline"},
+		{ID: "", Name: datastore.Pseudonym("s4lt", "pooh"), State: "offline"},
+	}, pr.Participants)
+}
+
+func TestHeartbeatFails(t *testing.T) {
+	m := new(datastore.MockDatastore)
+	m.On("GetUsers", "12345").Return(testUsers("Tigger"), nil)
+	m.On("GetUsers", "67890").Return([]datastore.User{}, fmt.Errorf("Specified session does not exist"))
+	s, _ := setupTestCaseForPresence(t, m)
+	app := s.Start()
+
+	res, err := app.Test(httptestRequest("POST", "/api/sessions/12345/users/Pooh/heartbeat", ""), -1)
+	assert.NoError(t, err)
+	assertErrorResponse(t, res, 404, "User: Pooh is not part of session")
+
+	res, err = app.Test(httptestRequest("POST", "/api/sessions/67890/users/Pooh/heartbeat", ""), -1)
+	assert.NoError(t, err)
+	assertErrorResponse(t, res, 404, "Specified session does not exist")
+}
+
+func TestGetPresenceOfUnknownSession(t *testing.T) {
+	m := new(datastore.MockDatastore)
+	m.On("GetUsers", "67890").Return([]datastore.User{}, fmt.Errorf("Specified session does not exist"))
+	s, _ := setupTestCaseForPresence(t, m)
+	app := s.Start()
+
+	res, err := app.Test(httptestRequest("GET", "/api/sessions/67890/presence", ""), -1)
+	assert.NoError(t, err)
+	assertErrorResponse(t, res, 404, "Specified session does not exist")
+}
+
+func TestGetAverageEstimateOnlyExpectsPresentUsers(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		message string
+		users   []string
+	}{
+		{"present users", "", "ok", []string{}},
+		{"absent users", "?absent=true", "warning", []string{"Rabbit"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := new(datastore.MockDatastore)
+			m.On("GetEstimates", "12345").Return([]datastore.Estimate{
+				{TaskID: "TEST01", UserID: "tigger", UserName: "Tigger", BestCase: 1, MostLikelyCase: 2, WorstCase: 3},
+			}, nil)
+			m.On("GetUsers", "12345").Return(testUsers("Tigger", "Rabbit"), nil)
+			s, _ := setupTestCaseForPresence(t, m)
+			s.participants.Heartbeat("12345", "tigger")
+			app := s.Start()
+
+			res, err := app.Test(httptestRequest("GET", "/api/sessions/12345/estimates/TEST01"+tt.query, ""), -1)
+			assert.NoError(t, err)
+			assert.Equal(t, 200, res.StatusCode)
+
+			var ce CalcEstimate
+			assert.NoError(t, json.NewDecoder(res.Body).Decode(&ce))
+			assert.Equal(t, tt.message, ce.Message)
+			assert.Equal(t, tt.users, ce.Users)
+		})
+	}
+}
+
+func TestGetAverageEstimateInvalidAbsent(t *testing.T) {
+	m := new(datastore.MockDatastore)
+	m.On("GetEstimates", "12345").Return([]datastore.Estimate{
+		{TaskID: "TEST01", UserID: "tigger", UserName: "Tigger", BestCase: 1, MostLikelyCase: 2, WorstCase: 3},
+	}, nil)
+	m.On("GetUsers", "12345").Return(testUsers("Tigger"), nil)
+	s, _ := setupTestCaseForPresence(t, m)
+	app := s.Start()
+
+	res, err := app.Test(httptestRequest("GET", "/api/sessions/12345/estimates/TEST01?absent=maybe", ""), -1)
+	assert.NoError(t, err)
+	assertErrorResponse(t, res, 400, "Absent must be true or false, provided: maybe")
+}
+
+func TestPresenceRoutesDisabled(t *testing.T) {
+	app := NewServer(&Config{}, new(datastore.MockDatastore), nil).Start()
+
+	res, err := app.Test(httptestRequest("GET", "/api/sessions/12345/presence", ""), -1)
+	assert.NoError(t, err)
+	assert.Equal(t, 404, res.StatusCode)
+}
@@ -0,0 +1,102 @@
+package apiserver
+
+import (
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"github.com/haro87/dokerb/pkg/datastore"
+	"strconv"
+	"time"
+)
+
+// SessionStateResponse represents the state of a session as of an
+// earlier event, Seq is the sequence number of the last applied event
+type SessionStateResponse struct {
+	Message   string               `json:"message" example:"ok" format:"string"`
+	Token     string               `json:"token" example:"eaf27c59ecdf0db4e165c4f940e176ec" format:"string"`
+	Seq       int                  `json:"seq" example:"12" format:"int"`
+	Time      string               `json:"time" example:"2021-01-14T15:04:05.123Z" format:"string"`
+	Removed   bool                 `json:"removed" example:"false" format:"bool"`
+	Unit      string               `json:"unit" example:"hours" format:"string"`
+	Users     []User               `json:"users" format:"[]User"`
+	Tasks     []datastore.Task     `json:"tasks" format:"[]datastore.Task"`
+	Estimates []datastore.Estimate `json:"estimates" format:"[]datastore.Estimate"`
+}
+
+// WithReplay enables restoring the state of sessions as of an
+// earlier event, which requires an event-sourced datastore
+func WithReplay(replayer datastore.SessionReplayer) Option {
+	return func(s *APIServer) {
+		s.replayer = replayer
+	}
+}
+
+// replayRoutes registers the routes for replaying sessions
+func replayRoutes(app *fiber.App, replayer datastore.SessionReplayer, settings sessionSettings) {
+	APIGroup := app.Group("/api")
+
+	addGetSessionStateRoute(APIGroup, replayer, settings)
+}
+
+// Adding the get session state route
+// @Summary Get the state of a session as of an earlier event
+// @Description Gets the users, tasks and estimates of a session by replaying its events up to the requested sequence number or time, without either all events are replayed. Removed sessions can be replayed as well. In anonymous sessions names are only revealed to the moderator and the owner of the estimate.
+// @Tags session
+// @Produce  json
+// @Param token path string true "Session Token"
+// @Param seq query int false "Sequence number of the last event to replay"
+// @Param at query string false "Time of the last event to replay in RFC 3339 format"
+// @Success 200 {object} SessionStateResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /sessions/{token}/state [get]
+func addGetSessionStateRoute(api fiber.Router, replayer datastore.SessionReplayer, settings sessionSettings) {
+	api.Get("/sessions/:token/state", func(c *fiber.Ctx) error {
+		query, err := replayQuery(c)
+
+		if err != nil {
+			return sendError(c, 400, err)
+		}
+
+		state, err := replayer.ReplaySession(c.Params("token"), query)
+
+		if err != nil {
+			return sendError(c, 500, err)
+		}
+
+		v, err := newViewer(c, settings, state.Users)
+
+		if err != nil {
+			return sendError(c, 500, err)
+		}
+
+		data := SessionStateResponse{
+			Message:   "ok",
+			Token:     state.Token,
+			Seq:       state.Seq,
+			Time:      state.Time,
+			Removed:   state.Removed,
+			Unit:      v.settings.Unit,
+			Users:     v.users(state.Users),
+			Tasks:     state.Tasks,
+			Estimates: v.estimates(state.Estimates),
+		}
+		return c.Status(200).JSON(data)
+	})
+}
+
+func replayQuery(c *fiber.Ctx) (datastore.ReplayQuery, error) {
+	var query datastore.ReplayQuery
+
+	var err error
+	if v := c.Query("seq"); v != "" {
+		if query.Seq, err = strconv.Atoi(v); err != nil || query.Seq < 1 {
+			return query, fmt.Errorf("Sequence number must be a number >= 1, provided: %s", v)
+		}
+	}
+	if v := c.Query("at"); v != "" {
+		if query.Time, err = time.Parse(time.RFC3339, v); err != nil {
+			return query, fmt.Errorf("Time must be in RFC 3339 format, provided: %s", v)
+		}
+	}
+	return query, nil
+}
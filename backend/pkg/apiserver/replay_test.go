@@ -0,0 +1,81 @@
+package apiserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/haro87/dokerb/pkg/datastore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+	"time"
+)
+
+func TestGetSessionState(t *testing.T) {
+	at, _ := time.Parse(time.RFC3339, "2021-01-14T15:04:05Z")
+	rp := new(datastore.MockSessionReplayer)
+	rp.On("ReplaySession", "12345", datastore.ReplayQuery{Seq: 3, Time: at}).Return(datastore.SessionState{
+		Token:     "12345",
+		Seq:       3,
+		Time:      "2021-01-14T15:04:05.123Z",
+		Users:     []datastore.User{{ID: "tigger", Name: "Tigger", Role: "estimator"}, {ID: "pooh", Name: "Pooh", Role: "estimator"}},
+		Tasks:     []datastore.Task{{ID: "T1", Summary: "Login"}},
+		Estimates: []datastore.Estimate{{TaskID: "T1", UserID: "tigger", UserName: "Tigger", BestCase: 1, MostLikelyCase: 2, WorstCase: 3}},
+	}, nil)
+	ss := new(datastore.MockSettingsStore)
+	ss.On("GetSettings", "12345").Return(datastore.Settings{Anonymity: "hidden", Salt: "s4lt"}, nil)
+
+	app := NewServer(&Config{Units: units{Default: "hours"}}, new(datastore.MockDatastore), nil, WithReplay(rp), WithSettings(ss)).Start()
+
+	res, err := app.Test(settingsRequest("GET", "/api/sessions/12345/state?seq=3&at=2021-01-14T15:04:05Z", "Pooh", ""), -1)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, res.StatusCode)
+
+	var sr SessionStateResponse
+	assert.NoError(t, json.NewDecoder(res.Body).Decode(&sr))
+	assert.Equal(t, SessionStateResponse{
+		Message:   "ok",
+		Token:     "12345",
+		Seq:       3,
+		Time:      "2021-01-14T15:04:05.123Z",
+		Unit:      "hours",
+		Users:     []User{{Role: "estimator"}, {ID: "pooh", Name: "Pooh", Role: "estimator"}},
+		Tasks:     []datastore.Task{{ID: "T1", Summary: "Login"}},
+		Estimates: []datastore.Estimate{{TaskID: "T1", BestCase: 1, MostLikelyCase: 2, WorstCase: 3}},
+	}, sr)
+}
+
+func TestGetSessionStateFails(t *testing.T) {
+	tests := []struct {
+		name   string
+		query  string
+		status int
+		reason string
+	}{
+		{"invalid seq", "?seq=first", 400, "Sequence number must be a number >= 1, provided: first"},
+		{"zero seq", "?seq=0", 400, "Sequence number must be a number >= 1, provided: 0"},
+		{"invalid time", "?at=yesterday", 400, "Time must be in RFC 3339 format, provided: yesterday"},
+		{"unknown session", "", 500, "Specified session does not exist"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rp := new(datastore.MockSessionReplayer)
+			rp.On("ReplaySession", "12345", datastore.ReplayQuery{}).Return(datastore.SessionState{}, fmt.Errorf("Specified session does not exist"))
+			app := NewServer(&Config{}, new(datastore.MockDatastore), nil, WithReplay(rp)).Start()
+
+			res, err := app.Test(httptestRequest("GET", "/api/sessions/12345/state"+tt.query, ""), -1)
+			assert.NoError(t, err)
+			assertErrorResponse(t, res, tt.status, tt.reason)
+			if tt.status == 400 {
+				rp.AssertNotCalled(t, "ReplaySession", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestReplayRoutesDisabled(t *testing.T) {
+	app := NewServer(&Config{}, new(datastore.MockDatastore), nil).Start()
+
+	res, err := app.Test(httptestRequest("GET", "/api/sessions/12345/state", ""), -1)
+	assert.NoError(t, err)
+	assert.Equal(t, 404, res.StatusCode)
+}
@@ -1,11 +1,14 @@
 package apiserver
 
 import (
+	"errors"
+	"fmt"
 	"github.com/arsmn/fiber-swagger/v2"
 	"github.com/gofiber/fiber/v2"
 	_ "github.com/haro87/dokerb/docs"
 	"github.com/haro87/dokerb/pkg/compute"
 	"github.com/haro87/dokerb/pkg/datastore"
+	"github.com/haro87/dokerb/pkg/presence"
 )
 
 // DocEntry represents a single documentation entry
@@ -32,18 +35,70 @@ type GeneralResponse struct {
 	Route   string `json:"route" example:"/sessions/token" format:"string"`
 }
 
-// UsersResponse represents the get users response
-type UsersResponse struct {
-	Message string   `json:"message" example:"ok" format:"string"`
-	Users   []string `json:"users" example:"Tigger,Rabbit" format:"[]string"`
+// JoinResponse represents the join session response, Secret is only
+// returned once and must be sent in the X-Doker-Secret header along
+// with the X-Doker-User header, if secrets are enabled
+type JoinResponse struct {
+	Message string `json:"message" example:"ok" format:"string"`
+	Route   string `json:"route" example:"/sessions/token/users/3f2a9c1e7b4d8e60" format:"string"`
+	Secret  string `json:"secret,omitempty" example:"9b1c4e7a2d5f8a3c6e9b2d4f7a1c3e5b" format:"string"`
+}
+
+// UserSecret represents the secret issued for a user of a new session
+type UserSecret struct {
+	ID     string `json:"id" example:"3f2a9c1e7b4d8e60" format:"string"`
+	Name   string `json:"name" example:"Tigger" format:"string"`
+	Secret string `json:"secret" example:"9b1c4e7a2d5f8a3c6e9b2d4f7a1c3e5b" format:"string"`
+}
+
+// SessionResponse represents the create and clone session response,
+// Secrets contains the secrets of the users the session starts with,
+// which are only returned once, if secrets are enabled
+type SessionResponse struct {
+	Message string       `json:"message" example:"ok" format:"string"`
+	Route   string       `json:"route" example:"/sessions/token" format:"string"`
+	Secrets []UserSecret `json:"secrets,omitempty" format:"[]UserSecret"`
+}
+
+// DistanceResponse represents the get max distance users response,
+// the estimates of these users are included with their reasoning
+type DistanceResponse struct {
+	Message   string               `json:"message" example:"ok" format:"string"`
+	Users     []string             `json:"users" example:"Tigger,Rabbit" format:"[]string"`
+	Estimates []datastore.Estimate `json:"estimates" format:"[]datastore.Estimate"`
+}
+
+// SessionUsersResponse represents the get users response
+type SessionUsersResponse struct {
+	Message string `json:"message" example:"ok" format:"string"`
+	Users   []User `json:"users" format:"[]User"`
 }
 
 // TaskResponse represents the get tasks response
 type TaskResponse struct {
 	Message string           `json:"message" example:"ok" format:"string"`
+	Unit    string           `json:"unit" example:"hours" format:"string"`
 	Tasks   []datastore.Task `json:"tasks" format:"[]datastore.Task"`
 }
 
+// UserProgress represents whether a user submitted an estimate
+type UserProgress struct {
+	ID        string `json:"id" example:"3f2a9c1e7b4d8e60" format:"string"`
+	Name      string `json:"name" example:"Tigger" format:"string"`
+	Estimated bool   `json:"estimated" example:"true" format:"bool"`
+}
+
+// ProgressResponse represents the voting progress of a task, Ready
+// is set once all users submitted an estimate
+type ProgressResponse struct {
+	Message   string         `json:"message" example:"ok" format:"string"`
+	Users     []UserProgress `json:"users" format:"[]UserProgress"`
+	Total     int            `json:"total" example:"4" format:"int"`
+	Estimated int            `json:"estimated" example:"3" format:"int"`
+	Missing   int            `json:"missing" example:"1" format:"int"`
+	Ready     bool           `json:"ready" example:"false" format:"bool"`
+}
+
 // Task represents a task
 type Task struct {
 	ID      string `json:"id" example:"TEST01" format:"string"`
@@ -56,32 +111,46 @@ type Estimate struct {
 	StandardDeviation float64 `json:"standarddeviation" example:"0.2" format:"float64"`
 }
 
-// CalcEstimate represents the response for calculated average estimate
+// CalcEstimate represents the response for calculated average estimate,
+// Conversions contains the estimate in all units it can be converted to
 type CalcEstimate struct {
-	Message  string   `json:"message" example:"warning" format:"string"`
-	Hint     string   `json:"hint" example:"not all users provided estimates" format:"string"`
-	Users    []string `json:"users" example:"Tigger" format:"[]string"`
-	Estimate Estimate `json:"estimate" format:"Estimate"`
+	Message     string         `json:"message" example:"warning" format:"string"`
+	Hint        string         `json:"hint" example:"not all users provided estimates" format:"string"`
+	Users       []string       `json:"users" example:"Tigger" format:"[]string"`
+	Unit        string         `json:"unit" example:"hours" format:"string"`
+	Estimate    Estimate       `json:"estimate" format:"Estimate"`
+	Conversions []UnitEstimate `json:"conversions" format:"[]UnitEstimate"`
 }
 
-// PerUserEstimate represents a user and task individual estimate
+// PerUserEstimate represents a user and task individual estimate, the
+// user is referenced by ID or, as long as it is unique, by name. The
+// rationale and the assumptions optionally explain the estimate.
 type PerUserEstimate struct {
-	TaskID         string  `json:"id" example:"TEST01" format:"string"`
-	UserName       string  `json:"user" example:"Tigger" format:"string"`
-	BestCase       float64 `json:"b" example:"1.5" format:"float64"`
-	MostLikelyCase float64 `json:"m" example:"2.0" format:"float64"`
-	WorstCase      float64 `json:"w" example:"3.6" format:"float64"`
+	TaskID         string   `json:"id" example:"TEST01" format:"string"`
+	UserID         string   `json:"userid" example:"3f2a9c1e7b4d8e60" format:"string"`
+	UserName       string   `json:"user" example:"Tigger" format:"string"`
+	BestCase       float64  `json:"b" example:"1.5" format:"float64"`
+	MostLikelyCase float64  `json:"m" example:"2.0" format:"float64"`
+	WorstCase      float64  `json:"w" example:"3.6" format:"float64"`
+	Rationale      string   `json:"rationale" example:"Login needs a password reset as well" format:"string"`
+	Assumptions    []string `json:"assumptions" example:"SSO is out of scope" format:"[]string"`
 }
 
 // PerUserEstimateResponse represents the get estimates response
 type PerUserEstimateResponse struct {
 	Message   string               `json:"message" example:"ok" format:"string"`
+	Unit      string               `json:"unit" example:"hours" format:"string"`
 	Estimates []datastore.Estimate `json:"estimates" format:"[]datastore.Estimate"`
 }
 
-// User represents a user
+// User represents a user, the ID is assigned when joining the
+// session and stays the same while the profile may change
 type User struct {
-	Name string `json:"name" example:"Tigger" format:"string"`
+	ID     string `json:"id" example:"3f2a9c1e7b4d8e60" format:"string"`
+	Name   string `json:"name" example:"Tigger" format:"string"`
+	Avatar string `json:"avatar,omitempty" example:"https://example.com/tigger.png" format:"string"`
+	Email  string `json:"email,omitempty" example:"tigger@example.com" format:"string"`
+	Role   string `json:"role,omitempty" example:"observer" format:"string"`
 }
 
 // Routes list of the available routes for project
@@ -97,7 +166,7 @@ type User struct {
 
 // @host localhost:5000
 // @BasePath /api
-func Routes(app *fiber.App, store datastore.DataStore) {
+func Routes(app *fiber.App, store datastore.DataStore, settings sessionSettings, templates datastore.TemplateStore, participants *presence.Tracker, secrets datastore.SecretStore) {
 	// Create group for API routes
 	APIGroup := app.Group("/api")
 
@@ -105,17 +174,19 @@ func Routes(app *fiber.App, store datastore.DataStore) {
 
 	addDocRoute(APIGroup)
 
-	addCreateSessionRoute(APIGroup, store)
+	addCreateSessionRoute(APIGroup, store, templates, secrets)
 
 	addRemoveSessionRoute(APIGroup, store)
 
-	addAddUserToSessionRoute(APIGroup, store)
+	addAddUserToSessionRoute(APIGroup, store, secrets)
 
 	addGetUsersFromSessionRoute(APIGroup, store)
 
+	addUpdateUserOfSessionRoute(APIGroup, store)
+
 	addRemoveUserFromSessionRoute(APIGroup, store)
 
-	addGetTasksFromSessionRoute(APIGroup, store)
+	addGetTasksFromSessionRoute(APIGroup, store, settings)
 
 	addAddTaskToSessionRoute(APIGroup, store)
 
@@ -129,11 +200,13 @@ func Routes(app *fiber.App, store datastore.DataStore) {
 
 	addRemoveUserEstimateFromSessionRoute(APIGroup, store)
 
-	addGetUserEstimatesFromSessionRoute(APIGroup, store)
+	addGetUserEstimatesFromSessionRoute(APIGroup, store, settings)
 
-	addGetAverageEstimateForTaskFromSessionRoute(APIGroup, store)
+	addGetAverageEstimateForTaskFromSessionRoute(APIGroup, store, settings, participants)
 
-	addGetUserWithMaxEstimateDistanceForTaskFromSessionRoute(APIGroup, store)
+	addGetUserWithMaxEstimateDistanceForTaskFromSessionRoute(APIGroup, store, settings)
+
+	addGetVotingProgressForTaskFromSessionRoute(APIGroup, store, settings)
 }
 
 // Adding the documentation route
@@ -172,27 +245,53 @@ func addDocRoute(api fiber.Router) {
 
 // Adding the create session route
 // @Summary Create a new Doker session
-// @Description Creates a new Doker session and responds with the corresponding token
+// @Description Creates a new Doker session, optionally from a saved template, and responds with the corresponding token. If secrets are enabled, the response contains the secrets of the users of the template.
 // @Tags session
+// @Accept  json
 // @Produce  json
-// @Success 200 {object} GeneralResponse
+// @Param session body NewSession false "Template to create the session from"
+// @Success 200 {object} SessionResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 413 {object} ErrorResponse
+// @Failure 429 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
 // @Router /sessions [post]
-func addCreateSessionRoute(api fiber.Router, store datastore.DataStore) {
+func addCreateSessionRoute(api fiber.Router, store datastore.DataStore, templates datastore.TemplateStore, secrets datastore.SecretStore) {
 	api.Post("/sessions", func(c *fiber.Ctx) error {
-		t, err := store.CreateSession()
+		ns := new(NewSession)
 
-		if err != nil {
-			data := ErrorResponse{
-				Message: "error",
-				Reason:  err.Error(),
+		if len(c.Body()) > 0 {
+			if err := c.BodyParser(ns); err != nil {
+				return sendError(c, 400, err)
 			}
-			return c.Status(500).JSON(data)
 		}
 
-		data := GeneralResponse{
+		if ns.Template != "" && templates == nil {
+			return sendError(c, 400, fmt.Errorf("Session templates are not enabled"))
+		}
+
+		var t string
+		var err error
+		if ns.Template != "" {
+			t, err = templates.CreateSessionFromTemplate(ns.Template)
+		} else {
+			t, err = store.CreateSession()
+		}
+
+		if err != nil {
+			return sendError(c, 500, err)
+		}
+
+		s, err := issueSecrets(store, secrets, t)
+
+		if err != nil {
+			return sendError(c, 500, err)
+		}
+
+		data := SessionResponse{
 			Message: "ok",
 			Route:   "/sessions/" + t,
+			Secrets: s,
 		}
 		return c.Status(200).JSON(data)
 	})
@@ -205,16 +304,14 @@ func addCreateSessionRoute(api fiber.Router, store datastore.DataStore) {
 // @Produce  json
 // @Param token path string true "Session Token"
 // @Success 200 {object} GeneralResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
 // @Router /sessions/{token} [delete]
 func addRemoveSessionRoute(api fiber.Router, store datastore.DataStore) {
 	api.Delete("/sessions/:token", func(c *fiber.Ctx) error {
-		if err := store.RemoveSession(c.Params("token")); err != nil {
-			data := ErrorResponse{
-				Message: "error",
-				Reason:  err.Error(),
-			}
-			return c.Status(500).JSON(data)
+		if err := forActor(c, store).RemoveSession(c.Params("token")); err != nil {
+			return sendError(c, storeErrorStatus(err), err)
 		}
 
 		data := GeneralResponse{
@@ -226,37 +323,42 @@ func addRemoveSessionRoute(api fiber.Router, store datastore.DataStore) {
 
 // Adding the Add user to session route
 // @Summary Add a new user to a existing session
-// @Description Adds a new (non-existing) user to an existing session
+// @Description Adds a new user to an existing session either as estimator or as observer, which watches the session without providing estimates. The user gets an ID, so several users may share a name. If secrets are enabled, the user gets a secret, which is only returned once and must be sent in the X-Doker-Secret header whenever the X-Doker-User header references the user.
 // @Tags user
 // @Produce  json
 // @Param token path string true "Session Token"
 // @Param  user body User true "New User"
-// @Success 200 {object} GeneralResponse
+// @Success 200 {object} JoinResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 413 {object} ErrorResponse
+// @Failure 429 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
 // @Router /sessions/{token}/users [post]
-func addAddUserToSessionRoute(api fiber.Router, store datastore.DataStore) {
+func addAddUserToSessionRoute(api fiber.Router, store datastore.DataStore, secrets datastore.SecretStore) {
 	api.Post("/sessions/:token/users", func(c *fiber.Ctx) error {
 		u := new(User)
 
 		if err := c.BodyParser(u); err != nil {
-			data := ErrorResponse{
-				Message: "error",
-				Reason:  err.Error(),
-			}
-			return c.Status(400).JSON(data)
+			return sendError(c, 400, err)
 		}
 
-		if err := store.JoinSession(c.Params("token"), u.Name); err != nil {
-			data := ErrorResponse{
-				Message: "error",
-				Reason:  err.Error(),
-			}
-			return c.Status(500).JSON(data)
+		id, err := forActor(c, store).JoinSession(c.Params("token"), fromUser(*u))
+
+		if err != nil {
+			return sendError(c, storeErrorStatus(err), err)
 		}
 
-		data := GeneralResponse{
+		data := JoinResponse{
 			Message: "ok",
-			Route:   "/sessions/" + c.Params("token") + "/users/" + u.Name,
+			Route:   "/sessions/" + c.Params("token") + "/users/" + id,
+		}
+
+		if secrets != nil {
+			if data.Secret, err = secrets.IssueSecret(c.Params("token"), id); err != nil {
+				return sendError(c, 500, err)
+			}
 		}
 		return c.Status(200).JSON(data)
 	})
@@ -264,11 +366,11 @@ func addAddUserToSessionRoute(api fiber.Router, store datastore.DataStore) {
 
 // Adding the Get users from session route
 // @Summary Get the users of an existing session
-// @Description Gets all users of an existing session
+// @Description Gets all users of an existing session with their ID, profile and role, which is either estimator or observer
 // @Tags user
 // @Produce  json
 // @Param token path string true "Session Token"
-// @Success 200 {object} UsersResponse
+// @Success 200 {object} SessionUsersResponse
 // @Failure 500 {object} ErrorResponse
 // @Router /sessions/{token}/users [get]
 func addGetUsersFromSessionRoute(api fiber.Router, store datastore.DataStore) {
@@ -276,16 +378,56 @@ func addGetUsersFromSessionRoute(api fiber.Router, store datastore.DataStore) {
 		u, e := store.GetUsers(c.Params("token"))
 
 		if e != nil {
-			data := ErrorResponse{
-				Message: "error",
-				Reason:  e.Error(),
+			return sendError(c, 500, e)
+		}
+
+		data := SessionUsersResponse{
+			Message: "ok",
+			Users:   toUsers(u),
+		}
+		return c.Status(200).JSON(data)
+	})
+}
+
+// Adding the Update user of session route
+// @Summary Update a user of a session
+// @Description Updates the name, avatar, email or role of an existing user of an existing session, the ID and the estimates of the user are kept
+// @Tags user
+// @Accept  json
+// @Produce  json
+// @Param token path string true "Session Token"
+// @Param id path string true "ID of the user"
+// @Param  user body User true "Updated User"
+// @Param X-Doker-User header string false "ID or name of the requesting user"
+// @Param X-Doker-Secret header string false "Secret of the requesting user, if secrets are enabled"
+// @Success 200 {object} GeneralResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /sessions/{token}/users/{id} [put]
+func addUpdateUserOfSessionRoute(api fiber.Router, store datastore.DataStore) {
+	api.Put("/sessions/:token/users/:id", func(c *fiber.Ctx) error {
+		u := new(User)
+
+		if err := c.BodyParser(u); err != nil {
+			return sendError(c, 400, err)
+		}
+
+		user := fromUser(*u)
+		user.ID = c.Params("id")
+
+		if err := forActor(c, store).UpdateUser(c.Params("token"), user); err != nil {
+			if errors.Is(err, datastore.ErrSessionClosed) {
+				return sendError(c, 409, err)
 			}
-			return c.Status(500).JSON(data)
+			return sendError(c, 400, err)
 		}
 
-		data := UsersResponse{
+		data := GeneralResponse{
 			Message: "ok",
-			Users:   u,
+			Route:   "/sessions/" + c.Params("token") + "/users/" + c.Params("id"),
 		}
 		return c.Status(200).JSON(data)
 	})
@@ -297,18 +439,32 @@ func addGetUsersFromSessionRoute(api fiber.Router, store datastore.DataStore) {
 // @Tags user
 // @Produce  json
 // @Param token path string true "Session Token"
-// @Param name path string true "Name of the user"
+// @Param user path string true "ID or unique name of the user"
+// @Param X-Doker-User header string false "ID or name of the requesting user"
+// @Param X-Doker-Secret header string false "Secret of the requesting user, if secrets are enabled"
 // @Success 200 {object} GeneralResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
-// @Router /sessions/{token}/users/{name} [delete]
+// @Router /sessions/{token}/users/{user} [delete]
 func addRemoveUserFromSessionRoute(api fiber.Router, store datastore.DataStore) {
-	api.Delete("/sessions/:token/users/:name", func(c *fiber.Ctx) error {
-		if err := store.LeaveSession(c.Params("token"), c.Params("name")); err != nil {
-			data := ErrorResponse{
-				Message: "error",
-				Reason:  err.Error(),
-			}
-			return c.Status(500).JSON(data)
+	api.Delete("/sessions/:token/users/:user", func(c *fiber.Ctx) error {
+		users, err := store.GetUsers(c.Params("token"))
+
+		if err != nil {
+			return sendError(c, 500, err)
+		}
+
+		u, err := lookupUser(users, c.Params("user"))
+
+		if err != nil {
+			return sendError(c, 500, err)
+		}
+
+		if err := forActor(c, store).LeaveSession(c.Params("token"), u.ID); err != nil {
+			return sendError(c, storeErrorStatus(err), err)
 		}
 
 		data := GeneralResponse{
@@ -320,28 +476,39 @@ func addRemoveUserFromSessionRoute(api fiber.Router, store datastore.DataStore)
 
 // Adding the Get tasks from session route
 // @Summary Get the tasks of a session
-// @Description Gets all tasks of an existing session
+// @Description Gets all tasks of an existing session, final estimates are converted into the requested unit
 // @Tags task
 // @Produce  json
 // @Param token path string true "Session Token"
+// @Param unit query string false "Unit to convert into, one of hours, days or weeks"
 // @Success 200 {object} TaskResponse
+// @Failure 400 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
 // @Router /sessions/{token}/tasks [get]
-func addGetTasksFromSessionRoute(api fiber.Router, store datastore.DataStore) {
+func addGetTasksFromSessionRoute(api fiber.Router, store datastore.DataStore, settings sessionSettings) {
 	api.Get("/sessions/:token/tasks", func(c *fiber.Ctx) error {
 		tasks, e := store.GetTasks(c.Params("token"))
 
 		if e != nil {
-			data := ErrorResponse{
-				Message: "error",
-				Reason:  e.Error(),
-			}
-			return c.Status(500).JSON(data)
+			return sendError(c, 500, e)
+		}
+
+		s, e := settings.get(c.Params("token"))
+
+		if e != nil {
+			return sendError(c, 500, e)
+		}
+
+		uc, e := settings.units.converter(c, s.Unit)
+
+		if e != nil {
+			return sendError(c, 400, e)
 		}
 
 		data := TaskResponse{
 			Message: "ok",
-			Tasks:   tasks,
+			Unit:    uc.to,
+			Tasks:   uc.tasks(tasks),
 		}
 		return c.Status(200).JSON(data)
 	})
@@ -356,6 +523,10 @@ func addGetTasksFromSessionRoute(api fiber.Router, store datastore.DataStore) {
 // @Param  task body Task true "New Task"
 // @Success 200 {object} GeneralResponse
 // @Failure 400 {object} ErrorResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 413 {object} ErrorResponse
+// @Failure 429 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
 // @Router /sessions/{token}/tasks [post]
 func addAddTaskToSessionRoute(api fiber.Router, store datastore.DataStore) {
@@ -363,19 +534,11 @@ func addAddTaskToSessionRoute(api fiber.Router, store datastore.DataStore) {
 		task := new(Task)
 
 		if err := c.BodyParser(task); err != nil {
-			data := ErrorResponse{
-				Message: "error",
-				Reason:  err.Error(),
-			}
-			return c.Status(400).JSON(data)
+			return sendError(c, 400, err)
 		}
 
-		if err := store.AddTask(c.Params("token"), task.ID, task.Summary); err != nil {
-			data := ErrorResponse{
-				Message: "error",
-				Reason:  err.Error(),
-			}
-			return c.Status(500).JSON(data)
+		if err := forActor(c, store).AddTask(c.Params("token"), task.ID, task.Summary); err != nil {
+			return sendError(c, storeErrorStatus(err), err)
 		}
 
 		data := GeneralResponse{
@@ -394,16 +557,14 @@ func addAddTaskToSessionRoute(api fiber.Router, store datastore.DataStore) {
 // @Param token path string true "Session Token"
 // @Param id path string true "ID of the task"
 // @Success 200 {object} GeneralResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
 // @Router /sessions/{token}/tasks/{id} [delete]
 func addRemoveTaskFromSessionRoute(api fiber.Router, store datastore.DataStore) {
 	api.Delete("/sessions/:token/tasks/:id", func(c *fiber.Ctx) error {
-		if err := store.RemoveTask(c.Params("token"), c.Params("id")); err != nil {
-			data := ErrorResponse{
-				Message: "error",
-				Reason:  err.Error(),
-			}
-			return c.Status(500).JSON(data)
+		if err := forActor(c, store).RemoveTask(c.Params("token"), c.Params("id")); err != nil {
+			return sendError(c, storeErrorStatus(err), err)
 		}
 
 		data := GeneralResponse{
@@ -423,6 +584,7 @@ func addRemoveTaskFromSessionRoute(api fiber.Router, store datastore.DataStore)
 // @Param  estimate body Estimate true "New Estimate"
 // @Success 200 {object} GeneralResponse
 // @Failure 400 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
 // @Router /sessions/{token}/tasks/{id} [put]
 func addUpdateTaskEstimateOfTaskRoute(api fiber.Router, store datastore.DataStore) {
@@ -430,19 +592,11 @@ func addUpdateTaskEstimateOfTaskRoute(api fiber.Router, store datastore.DataStor
 		es := new(Estimate)
 
 		if err := c.BodyParser(es); err != nil {
-			data := ErrorResponse{
-				Message: "error",
-				Reason:  err.Error(),
-			}
-			return c.Status(400).JSON(data)
+			return sendError(c, 400, err)
 		}
 
-		if err := store.AddEstimateToTask(c.Params("token"), c.Params("id"), es.Effort, es.StandardDeviation); err != nil {
-			data := ErrorResponse{
-				Message: "error",
-				Reason:  err.Error(),
-			}
-			return c.Status(500).JSON(data)
+		if err := forActor(c, store).AddEstimateToTask(c.Params("token"), c.Params("id"), es.Effort, es.StandardDeviation); err != nil {
+			return sendError(c, storeErrorStatus(err), err)
 		}
 
 		data := GeneralResponse{
@@ -460,16 +614,14 @@ func addUpdateTaskEstimateOfTaskRoute(api fiber.Router, store datastore.DataStor
 // @Param token path string true "Session Token"
 // @Param id path string true "ID of the task"
 // @Success 200 {object} GeneralResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
 // @Router /sessions/{token}/tasks/{id}/estimate [delete]
 func addResetEstimateOfTaskRoute(api fiber.Router, store datastore.DataStore) {
 	api.Delete("/sessions/:token/tasks/:id/estimate", func(c *fiber.Ctx) error {
-		if err := store.RemoveEstimateFromTask(c.Params("token"), c.Params("id")); err != nil {
-			data := ErrorResponse{
-				Message: "error",
-				Reason:  err.Error(),
-			}
-			return c.Status(500).JSON(data)
+		if err := forActor(c, store).RemoveEstimateFromTask(c.Params("token"), c.Params("id")); err != nil {
+			return sendError(c, storeErrorStatus(err), err)
 		}
 
 		data := GeneralResponse{
@@ -481,13 +633,21 @@ func addResetEstimateOfTaskRoute(api fiber.Router, store datastore.DataStore) {
 
 // Adding the Add user estimate to session route
 // @Summary Add the estimate of a user for a task
-// @Description Adds a estimate of a existing user of a existing task inside a existing session
+// @Description Adds a estimate of a existing user of a existing task inside a existing session, the user is referenced by ID or unique name. Observers can't provide estimates. The optional rationale may have up to 1000 and each of the up to 10 assumptions up to 200 characters, control characters are removed.
 // @Tags estimate
 // @Produce  json
 // @Param token path string true "Session Token"
 // @Param  estimate body PerUserEstimate true "New Estimate"
+// @Param X-Doker-User header string false "ID or name of the requesting user"
+// @Param X-Doker-Secret header string false "Secret of the requesting user, if secrets are enabled"
 // @Success 200 {object} GeneralResponse
 // @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 413 {object} ErrorResponse
+// @Failure 429 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
 // @Router /sessions/{token}/estimates [post]
 func addAddUserEstimateToSessionRoute(api fiber.Router, store datastore.DataStore) {
@@ -495,32 +655,44 @@ func addAddUserEstimateToSessionRoute(api fiber.Router, store datastore.DataStor
 		es := new(PerUserEstimate)
 
 		if err := c.BodyParser(es); err != nil {
-			data := ErrorResponse{
-				Message: "error",
-				Reason:  err.Error(),
-			}
-			return c.Status(400).JSON(data)
+			return sendError(c, 400, err)
+		}
+
+		users, err := store.GetUsers(c.Params("token"))
+
+		if err != nil {
+			return sendError(c, 500, err)
+		}
+
+		ref := es.UserID
+		if ref == "" {
+			ref = es.UserName
+		}
+
+		u, err := lookupUser(users, ref)
+
+		if err != nil {
+			return sendError(c, 400, err)
 		}
 
 		est := datastore.Estimate{
 			TaskID:         es.TaskID,
-			UserName:       es.UserName,
+			UserID:         u.ID,
+			UserName:       u.Name,
 			BestCase:       es.BestCase,
 			MostLikelyCase: es.MostLikelyCase,
 			WorstCase:      es.WorstCase,
+			Rationale:      es.Rationale,
+			Assumptions:    es.Assumptions,
 		}
 
-		if err := store.AddEstimate(c.Params("token"), est); err != nil {
-			data := ErrorResponse{
-				Message: "error",
-				Reason:  err.Error(),
-			}
-			return c.Status(500).JSON(data)
+		if err := forActor(c, store).AddEstimate(c.Params("token"), est); err != nil {
+			return sendError(c, storeErrorStatus(err), err)
 		}
 
 		data := GeneralResponse{
 			Message: "ok",
-			Route:   "/sessions/" + c.Params("token") + "/estimates/" + es.UserName + "/" + es.TaskID,
+			Route:   "/sessions/" + c.Params("token") + "/estimates/" + u.ID + "/" + es.TaskID,
 		}
 		return c.Status(200).JSON(data)
 	})
@@ -532,26 +704,39 @@ func addAddUserEstimateToSessionRoute(api fiber.Router, store datastore.DataStor
 // @Tags estimate
 // @Produce  json
 // @Param token path string true "Session Token"
-// @Param  user path string true "User Name"
+// @Param  user path string true "ID or unique name of the user"
 // @Param  id path string true "Task ID"
+// @Param X-Doker-User header string false "ID or name of the requesting user"
+// @Param X-Doker-Secret header string false "Secret of the requesting user, if secrets are enabled"
 // @Success 200 {object} GeneralResponse
 // @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
 // @Router /sessions/{token}/estimates/{user}/{id} [delete]
 func addRemoveUserEstimateFromSessionRoute(api fiber.Router, store datastore.DataStore) {
 	api.Delete("/sessions/:token/estimates/:user/:id", func(c *fiber.Ctx) error {
 
+		users, err := store.GetUsers(c.Params("token"))
+
+		if err != nil {
+			return sendError(c, 500, err)
+		}
+
+		// Estimates of users, who left, can only be removed by ID
+		userID := c.Params("user")
+		if u, err := lookupUser(users, userID); err == nil {
+			userID = u.ID
+		}
+
 		est := datastore.Estimate{
-			TaskID:   c.Params("id"),
-			UserName: c.Params("user"),
+			TaskID: c.Params("id"),
+			UserID: userID,
 		}
 
-		if err := store.RemoveEstimate(c.Params("token"), est); err != nil {
-			data := ErrorResponse{
-				Message: "error",
-				Reason:  err.Error(),
-			}
-			return c.Status(500).JSON(data)
+		if err := forActor(c, store).RemoveEstimate(c.Params("token"), est); err != nil {
+			return sendError(c, storeErrorStatus(err), err)
 		}
 
 		data := GeneralResponse{
@@ -563,30 +748,47 @@ func addRemoveUserEstimateFromSessionRoute(api fiber.Router, store datastore.Dat
 
 // Adding the Get user estimates from session route
 // @Summary Get the estimates of all users for all tasks
-// @Description Gets all estimates of all existing users of all existing tasks inside a existing session
+// @Description Gets all estimates of all existing users of all existing tasks inside a existing session, in anonymous sessions names are only revealed to the moderator and the owner of the estimate
 // @Tags estimate
 // @Produce  json
 // @Param token path string true "Session Token"
+// @Param X-Doker-User header string false "ID or name of the requesting user"
+// @Param unit query string false "Unit to convert into, one of hours, days or weeks"
 // @Success 200 {object} PerUserEstimateResponse
 // @Failure 400 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
 // @Router /sessions/{token}/estimates [get]
-func addGetUserEstimatesFromSessionRoute(api fiber.Router, store datastore.DataStore) {
+func addGetUserEstimatesFromSessionRoute(api fiber.Router, store datastore.DataStore, settings sessionSettings) {
 	api.Get("/sessions/:token/estimates", func(c *fiber.Ctx) error {
 
 		ests, e := store.GetEstimates(c.Params("token"))
 
 		if e != nil {
-			data := ErrorResponse{
-				Message: "error",
-				Reason:  e.Error(),
-			}
-			return c.Status(500).JSON(data)
+			return sendError(c, 500, e)
+		}
+
+		users, e := store.GetUsers(c.Params("token"))
+
+		if e != nil {
+			return sendError(c, 500, e)
+		}
+
+		v, e := newViewer(c, settings, users)
+
+		if e != nil {
+			return sendError(c, 500, e)
+		}
+
+		uc, e := settings.units.converter(c, v.settings.Unit)
+
+		if e != nil {
+			return sendError(c, 400, e)
 		}
 
 		data := PerUserEstimateResponse{
 			Message:   "ok",
-			Estimates: ests,
+			Unit:      uc.to,
+			Estimates: v.estimates(uc.estimates(ests)),
 		}
 		return c.Status(200).JSON(data)
 	})
@@ -594,63 +796,69 @@ func addGetUserEstimatesFromSessionRoute(api fiber.Router, store datastore.DataS
 
 // Adding the Get average user estimate from session route
 // @Summary Get the average estimate of all users for a specific task
-// @Description Gets the average estimate of all existing users of a existing task inside a existing session
+// @Description Gets the average estimate of all existing users of a existing task inside a existing session in the unit of the session or the requested unit, hours, days and weeks are converted into each other. Observers aren't expected to provide estimates and, if presence is enabled, only users who are online or idle, unless absent users are requested.
 // @Tags estimate
 // @Produce  json
 // @Param token path string true "Session Token"
 // @Param id path string true "Task ID"
+// @Param unit query string false "Unit to convert into, one of hours, days or weeks"
+// @Param absent query bool false "Also expect estimates of offline users"
 // @Success 200 {object} CalcEstimate
 // @Failure 400 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
 // @Router /sessions/{token}/estimates/{id} [get]
-func addGetAverageEstimateForTaskFromSessionRoute(api fiber.Router, store datastore.DataStore) {
+func addGetAverageEstimateForTaskFromSessionRoute(api fiber.Router, store datastore.DataStore, settings sessionSettings, participants *presence.Tracker) {
 	api.Get("/sessions/:token/estimates/:id", func(c *fiber.Ctx) error {
 
 		ests, e := store.GetEstimates(c.Params("token"))
 
 		if e != nil {
-			data := ErrorResponse{
-				Message: "error",
-				Reason:  e.Error(),
-			}
-			return c.Status(500).JSON(data)
+			return sendError(c, 500, e)
 		}
 
 		ests, e = compute.ExtractEstimatesForTask(ests, c.Params("id"))
 
 		if e != nil {
-			data := ErrorResponse{
-				Message: "error",
-				Reason:  e.Error(),
-			}
-			return c.Status(500).JSON(data)
+			return sendError(c, 500, e)
 		}
 
 		users, ue := store.GetUsers(c.Params("token"))
 
 		if ue != nil {
-			data := ErrorResponse{
-				Message: "error",
-				Reason:  ue.Error(),
-			}
-			return c.Status(500).JSON(data)
+			return sendError(c, 500, ue)
+		}
+
+		users, ue = expectedUsers(c, participants, estimators(users))
+
+		if ue != nil {
+			return sendError(c, 400, ue)
 		}
 
 		avge, ae := compute.CalculateAverageEstimate(ests, c.Params("id"))
 
 		if ae != nil {
-			data := ErrorResponse{
-				Message: "error",
-				Reason:  ae.Error(),
-			}
-			return c.Status(500).JSON(data)
+			return sendError(c, 500, ae)
 		}
 
+		s, se := settings.get(c.Params("token"))
+
+		if se != nil {
+			return sendError(c, 500, se)
+		}
+
+		uc, ce := settings.units.converter(c, s.Unit)
+
+		if ce != nil {
+			return sendError(c, 400, ce)
+		}
+
+		effort, sd := uc.effort(avge.GetEffort()), uc.effort(avge.GetStandardDeviation())
+
 		message := "ok"
 		hint := ""
 
 		for _, es := range ests {
-			users = checkForAllUsers(users, es.UserName)
+			users = checkForAllUsers(users, es.UserID)
 		}
 
 		if len(users) > 0 {
@@ -658,14 +866,21 @@ func addGetAverageEstimateForTaskFromSessionRoute(api fiber.Router, store datast
 			hint = "not all users did provide estimates"
 		}
 
+		missing := []string{}
+		for _, u := range users {
+			missing = append(missing, u.Name)
+		}
+
 		data := CalcEstimate{
 			Message: message,
 			Hint:    hint,
-			Users:   users,
+			Users:   missing,
+			Unit:    uc.to,
 			Estimate: Estimate{
-				Effort:            avge.GetEffort(),
-				StandardDeviation: avge.GetStandardDeviation(),
+				Effort:            effort,
+				StandardDeviation: sd,
 			},
+			Conversions: settings.units.conversions(uc.to, effort, sd),
 		}
 		return c.Status(200).JSON(data)
 	})
@@ -673,59 +888,149 @@ func addGetAverageEstimateForTaskFromSessionRoute(api fiber.Router, store datast
 
 // Adding the Get max distance users for estimate from session route
 // @Summary Get the users with max distance between their estimates for a specific task
-// @Description Gets the users with max distance in their estimates of a existing task inside a existing session
+// @Description Gets the users with max distance in their estimates of a existing task inside a existing session together with their estimates, including rationale and assumptions, in anonymous sessions names are only revealed to the moderator and the owner of the estimate
 // @Tags estimate
 // @Produce  json
 // @Param token path string true "Session Token"
+// @Param X-Doker-User header string false "ID or name of the requesting user"
 // @Param id path string true "Task ID"
-// @Success 200 {object} UsersResponse
+// @Success 200 {object} DistanceResponse
 // @Failure 400 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
 // @Router /sessions/{token}/estimates/{id}/users/distance [get]
-func addGetUserWithMaxEstimateDistanceForTaskFromSessionRoute(api fiber.Router, store datastore.DataStore) {
+func addGetUserWithMaxEstimateDistanceForTaskFromSessionRoute(api fiber.Router, store datastore.DataStore, settings sessionSettings) {
 	api.Get("/sessions/:token/estimates/:id/users/distance", func(c *fiber.Ctx) error {
 
 		ests, e := store.GetEstimates(c.Params("token"))
 
 		if e != nil {
-			data := ErrorResponse{
-				Message: "error",
-				Reason:  e.Error(),
-			}
-			return c.Status(500).JSON(data)
+			return sendError(c, 500, e)
 		}
 
 		ests, e = compute.ExtractEstimatesForTask(ests, c.Params("id"))
 
 		if e != nil {
-			data := ErrorResponse{
-				Message: "error",
-				Reason:  e.Error(),
-			}
-			return c.Status(500).JSON(data)
+			return sendError(c, 500, e)
 		}
 
-		users, ae := compute.GetUsersWithMaxDistanceBetweenEffort(ests, c.Params("id"))
+		ids, ae := compute.GetUsersWithMaxDistanceBetweenEffort(ests, c.Params("id"))
 
 		if ae != nil {
-			data := ErrorResponse{
-				Message: "error",
-				Reason:  ae.Error(),
+			return sendError(c, 500, ae)
+		}
+
+		users, e := store.GetUsers(c.Params("token"))
+
+		if e != nil {
+			return sendError(c, 500, e)
+		}
+
+		v, e := newViewer(c, settings, users)
+
+		if e != nil {
+			return sendError(c, 500, e)
+		}
+
+		// Estimates keep the names of users, who left
+		byUser := map[string]datastore.Estimate{}
+		for _, es := range ests {
+			byUser[es.UserID] = es
+		}
+
+		res := []string{}
+		reasoning := []datastore.Estimate{}
+		for _, id := range ids {
+			res = append(res, v.name(id, byUser[id].UserName))
+			reasoning = append(reasoning, byUser[id])
+		}
+
+		data := DistanceResponse{
+			Message:   "ok",
+			Users:     res,
+			Estimates: v.estimates(reasoning),
+		}
+		return c.Status(200).JSON(data)
+	})
+}
+
+// Adding the Get voting progress of a task from session route
+// @Summary Get the voting progress of a specific task
+// @Description Gets which users of an existing session submitted an estimate for a task without revealing the estimates, ready is set once all users did. Observers aren't expected to provide estimates. In anonymous sessions users are shown as for estimates.
+// @Tags estimate
+// @Produce  json
+// @Param token path string true "Session Token"
+// @Param id path string true "Task ID"
+// @Param X-Doker-User header string false "ID or name of the requesting user"
+// @Success 200 {object} ProgressResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /sessions/{token}/tasks/{id}/progress [get]
+func addGetVotingProgressForTaskFromSessionRoute(api fiber.Router, store datastore.DataStore, settings sessionSettings) {
+	api.Get("/sessions/:token/tasks/:id/progress", func(c *fiber.Ctx) error {
+		tasks, e := store.GetTasks(c.Params("token"))
+
+		if e != nil {
+			return sendError(c, 500, e)
+		}
+
+		if !containsTask(tasks, c.Params("id")) {
+			return sendError(c, 404, fmt.Errorf("Task with ID: %s is not part of session", c.Params("id")))
+		}
+
+		users, e := store.GetUsers(c.Params("token"))
+
+		if e != nil {
+			return sendError(c, 500, e)
+		}
+
+		ests, e := store.GetEstimates(c.Params("token"))
+
+		if e != nil {
+			return sendError(c, 500, e)
+		}
+
+		v, e := newViewer(c, settings, users)
+
+		if e != nil {
+			return sendError(c, 500, e)
+		}
+
+		p := compute.GetVotingProgress(estimators(users), ests, c.Params("id"))
+
+		res := []UserProgress{}
+		for _, u := range p.Users {
+			id, name := u.ID, u.Name
+			if !v.reveals(id) {
+				id, name = "", v.name(id, name)
 			}
-			return c.Status(500).JSON(data)
+			res = append(res, UserProgress{ID: id, Name: name, Estimated: u.Estimated})
 		}
 
-		data := UsersResponse{
-			Message: "ok",
-			Users:   users,
+		data := ProgressResponse{
+			Message:   "ok",
+			Users:     res,
+			Total:     len(res),
+			Estimated: p.Estimated,
+			Missing:   p.Missing,
+			Ready:     p.Ready,
 		}
 		return c.Status(200).JSON(data)
 	})
 }
 
-func checkForAllUsers(users []string, user string) []string {
+// containsTask reports whether the task with the ID is part of the tasks
+func containsTask(tasks []datastore.Task, id string) bool {
+	for _, t := range tasks {
+		if t.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+func checkForAllUsers(users []datastore.User, id string) []datastore.User {
 	for i, u := range users {
-		if u == user {
+		if u.ID == id {
 			users = append(users[:i], users[i+1:]...)
 			break
 		}
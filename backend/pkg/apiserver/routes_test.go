@@ -82,7 +82,7 @@ func TestCreateSessionFails(t *testing.T) {
 
 	app := NewServer(&Config{
 		Static: static{Prefix: "/public", Path: "../../static"},
-	}, m).Start()
+	}, m, nil).Start()
 
 	req, _ := http.NewRequest(
 		"POST",
@@ -112,7 +112,7 @@ func TestCreateSessionSuccess(t *testing.T) {
 
 	app := NewServer(&Config{
 		Static: static{Prefix: "/public", Path: "../../static"},
-	}, m).Start()
+	}, m, nil).Start()
 
 	req, _ := http.NewRequest(
 		"POST",
@@ -144,7 +144,7 @@ func TestDeleteSessionFails(t *testing.T) {
 
 	app := NewServer(&Config{
 		Static: static{Prefix: "/public", Path: "../../static"},
-	}, m).Start()
+	}, m, nil).Start()
 
 	req, _ := http.NewRequest(
 		"DELETE",
@@ -174,7 +174,7 @@ func TestDeleteSessionSuccess(t *testing.T) {
 
 	app := NewServer(&Config{
 		Static: static{Prefix: "/public", Path: "../../static"},
-	}, m).Start()
+	}, m, nil).Start()
 
 	req, _ := http.NewRequest(
 		"DELETE",
@@ -199,11 +199,11 @@ func TestAddUserToSessionFails(t *testing.T) {
 	setupAndTearDown := setupTestCaseForMock(t)
 	defer setupAndTearDown(t)
 
-	m.On("JoinSession", "12345", "Tigger").Return(fmt.Errorf("Unable to add user"))
+	m.On("JoinSession", "12345", datastore.User{Name: "Tigger"}).Return("", fmt.Errorf("Unable to add user"))
 
 	app := NewServer(&Config{
 		Static: static{Prefix: "/public", Path: "../../static"},
-	}, m).Start()
+	}, m, nil).Start()
 
 	payloadf := map[string]interface{}{
 		"name": "Tigger",
@@ -239,7 +239,7 @@ func TestAddUserToSessionFailsDueToMissingHeader(t *testing.T) {
 
 	app := NewServer(&Config{
 		Static: static{Prefix: "/public", Path: "../../static"},
-	}, m).Start()
+	}, m, nil).Start()
 
 	payloadf := map[string]interface{}{
 		"name": "Tigger",
@@ -271,11 +271,11 @@ func TestAddUserToSessionSuccess(t *testing.T) {
 	setupAndTearDown := setupTestCaseForMock(t)
 	defer setupAndTearDown(t)
 
-	m.On("JoinSession", "12345", "Tigger").Return(nil)
+	m.On("JoinSession", "12345", datastore.User{Name: "Tigger"}).Return("tigger", nil)
 
 	app := NewServer(&Config{
 		Static: static{Prefix: "/public", Path: "../../static"},
-	}, m).Start()
+	}, m, nil).Start()
 
 	payloadf := map[string]interface{}{
 		"name": "Tigger",
@@ -301,7 +301,7 @@ func TestAddUserToSessionSuccess(t *testing.T) {
 	err = decoder.Decode(&ar)
 	assert.NoError(t, err)
 	assert.Equal(t, "ok", ar.Message)
-	assert.Equal(t, "/sessions/12345/users/Tigger", ar.Route)
+	assert.Equal(t, "/sessions/12345/users/tigger", ar.Route)
 	assert.Equal(t, 200, res.StatusCode)
 }
 
@@ -309,11 +309,11 @@ func TestGetUsersFromSessionFails(t *testing.T) {
 	setupAndTearDown := setupTestCaseForMock(t)
 	defer setupAndTearDown(t)
 
-	m.On("GetUsers", "12345").Return([]string{}, fmt.Errorf("Unable to retrieve users"))
+	m.On("GetUsers", "12345").Return([]datastore.User{}, fmt.Errorf("Unable to retrieve users"))
 
 	app := NewServer(&Config{
 		Static: static{Prefix: "/public", Path: "../../static"},
-	}, m).Start()
+	}, m, nil).Start()
 
 	req, _ := http.NewRequest(
 		"GET",
@@ -338,11 +338,11 @@ func TestGetUsersFromSessionSuccess(t *testing.T) {
 	setupAndTearDown := setupTestCaseForMock(t)
 	defer setupAndTearDown(t)
 
-	m.On("GetUsers", "12345").Return([]string{"Tigger", "Rabbit"}, nil)
+	m.On("GetUsers", "12345").Return(testUsers("Tigger", "Rabbit"), nil)
 
 	app := NewServer(&Config{
 		Static: static{Prefix: "/public", Path: "../../static"},
-	}, m).Start()
+	}, m, nil).Start()
 
 	req, _ := http.NewRequest(
 		"GET",
@@ -354,12 +354,15 @@ func TestGetUsersFromSessionSuccess(t *testing.T) {
 
 	assert.NoError(t, err)
 
-	var ar apiResponse
+	var ur SessionUsersResponse
 	decoder := json.NewDecoder(res.Body)
-	err = decoder.Decode(&ar)
+	err = decoder.Decode(&ur)
 	assert.NoError(t, err)
-	assert.Equal(t, "ok", ar.Message)
-	assert.Equal(t, []string{"Tigger", "Rabbit"}, ar.Users)
+	assert.Equal(t, "ok", ur.Message)
+	assert.Equal(t, []User{
+		{ID: "tigger", Name: "Tigger", Role: "estimator"},
+		{ID: "rabbit", Name: "Rabbit", Role: "estimator"},
+	}, ur.Users)
 	assert.Equal(t, 200, res.StatusCode)
 }
 
@@ -367,11 +370,12 @@ func TestRemoveUserFromSessionFails(t *testing.T) {
 	setupAndTearDown := setupTestCaseForMock(t)
 	defer setupAndTearDown(t)
 
-	m.On("LeaveSession", "12345", "Tigger").Return(fmt.Errorf("Unable to remove user from session"))
+	m.On("GetUsers", "12345").Return(testUsers("Tigger"), nil)
+	m.On("LeaveSession", "12345", "tigger").Return(fmt.Errorf("Unable to remove user from session"))
 
 	app := NewServer(&Config{
 		Static: static{Prefix: "/public", Path: "../../static"},
-	}, m).Start()
+	}, m, nil).Start()
 
 	req, _ := http.NewRequest(
 		"DELETE",
@@ -396,11 +400,12 @@ func TestRemoveUserFromSessionSuccess(t *testing.T) {
 	setupAndTearDown := setupTestCaseForMock(t)
 	defer setupAndTearDown(t)
 
-	m.On("LeaveSession", "12345", "Tigger").Return(nil)
+	m.On("GetUsers", "12345").Return(testUsers("Tigger"), nil)
+	m.On("LeaveSession", "12345", "tigger").Return(nil)
 
 	app := NewServer(&Config{
 		Static: static{Prefix: "/public", Path: "../../static"},
-	}, m).Start()
+	}, m, nil).Start()
 
 	req, _ := http.NewRequest(
 		"DELETE",
@@ -428,7 +433,7 @@ func TestGetTasksFromSessionFails(t *testing.T) {
 
 	app := NewServer(&Config{
 		Static: static{Prefix: "/public", Path: "../../static"},
-	}, m).Start()
+	}, m, nil).Start()
 
 	req, _ := http.NewRequest(
 		"GET",
@@ -457,7 +462,7 @@ func TestGetTasksFromSessionSuccess(t *testing.T) {
 
 	app := NewServer(&Config{
 		Static: static{Prefix: "/public", Path: "../../static"},
-	}, m).Start()
+	}, m, nil).Start()
 
 	req, _ := http.NewRequest(
 		"GET",
@@ -486,7 +491,7 @@ func TestAddTaskToSessionFails(t *testing.T) {
 
 	app := NewServer(&Config{
 		Static: static{Prefix: "/public", Path: "../../static"},
-	}, m).Start()
+	}, m, nil).Start()
 
 	payloadf := map[string]interface{}{
 		"id":      "TEST01",
@@ -525,7 +530,7 @@ func TestAddTaskToSessionFailsDueToMissingHeader(t *testing.T) {
 
 	app := NewServer(&Config{
 		Static: static{Prefix: "/public", Path: "../../static"},
-	}, m).Start()
+	}, m, nil).Start()
 
 	payloadf := map[string]interface{}{
 		"id":      "TEST01",
@@ -562,7 +567,7 @@ func TestAddTaskToSessionSuccess(t *testing.T) {
 
 	app := NewServer(&Config{
 		Static: static{Prefix: "/public", Path: "../../static"},
-	}, m).Start()
+	}, m, nil).Start()
 
 	payloadf := map[string]interface{}{
 		"id":      "TEST01",
@@ -601,7 +606,7 @@ func TestRemoveTaskFromSessionFails(t *testing.T) {
 
 	app := NewServer(&Config{
 		Static: static{Prefix: "/public", Path: "../../static"},
-	}, m).Start()
+	}, m, nil).Start()
 
 	req, _ := http.NewRequest(
 		"DELETE",
@@ -630,7 +635,7 @@ func TestRemoveTaskFromSessionSuccess(t *testing.T) {
 
 	app := NewServer(&Config{
 		Static: static{Prefix: "/public", Path: "../../static"},
-	}, m).Start()
+	}, m, nil).Start()
 
 	req, _ := http.NewRequest(
 		"DELETE",
@@ -658,7 +663,7 @@ func TestUpdateTaskEstimateOfSessionFails(t *testing.T) {
 
 	app := NewServer(&Config{
 		Static: static{Prefix: "/public", Path: "../../static"},
-	}, m).Start()
+	}, m, nil).Start()
 
 	payloadf := map[string]interface{}{
 		"effort":            1.2,
@@ -695,7 +700,7 @@ func TestUpdateTaskEstimateOfSessionFailsDueToMissingHeader(t *testing.T) {
 
 	app := NewServer(&Config{
 		Static: static{Prefix: "/public", Path: "../../static"},
-	}, m).Start()
+	}, m, nil).Start()
 
 	payloadf := map[string]interface{}{
 		"effort":            1.2,
@@ -732,7 +737,7 @@ func TestUpdateTaskEstimateOfSessionSuccess(t *testing.T) {
 
 	app := NewServer(&Config{
 		Static: static{Prefix: "/public", Path: "../../static"},
-	}, m).Start()
+	}, m, nil).Start()
 
 	payloadf := map[string]interface{}{
 		"effort":            1.2,
@@ -770,7 +775,7 @@ func TestResetEstimateOfTaskFails(t *testing.T) {
 
 	app := NewServer(&Config{
 		Static: static{Prefix: "/public", Path: "../../static"},
-	}, m).Start()
+	}, m, nil).Start()
 
 	req, _ := http.NewRequest(
 		"DELETE",
@@ -799,7 +804,7 @@ func TestResetEstimateOfTaskSuccess(t *testing.T) {
 
 	app := NewServer(&Config{
 		Static: static{Prefix: "/public", Path: "../../static"},
-	}, m).Start()
+	}, m, nil).Start()
 
 	req, _ := http.NewRequest(
 		"DELETE",
@@ -823,8 +828,10 @@ func TestAddUserEstimateToSessionFails(t *testing.T) {
 	setupAndTearDown := setupTestCaseForMock(t)
 	defer setupAndTearDown(t)
 
+	m.On("GetUsers", "12345").Return(testUsers("Tigger"), nil)
 	m.On("AddEstimate", "12345", datastore.Estimate{
 		TaskID:         "TEST01",
+		UserID:         "tigger",
 		UserName:       "Tigger",
 		BestCase:       0.5,
 		MostLikelyCase: 1.5,
@@ -832,7 +839,7 @@ func TestAddUserEstimateToSessionFails(t *testing.T) {
 
 	app := NewServer(&Config{
 		Static: static{Prefix: "/public", Path: "../../static"},
-	}, m).Start()
+	}, m, nil).Start()
 
 	payloadf := map[string]interface{}{
 		"id":   "TEST01",
@@ -872,7 +879,7 @@ func TestAddUserEstimateToSessionFailsDueToMissingHeader(t *testing.T) {
 
 	app := NewServer(&Config{
 		Static: static{Prefix: "/public", Path: "../../static"},
-	}, m).Start()
+	}, m, nil).Start()
 
 	payloadf := map[string]interface{}{
 		"id":   "TEST01",
@@ -908,8 +915,10 @@ func TestAddUserEstimateToSessionSuccess(t *testing.T) {
 	setupAndTearDown := setupTestCaseForMock(t)
 	defer setupAndTearDown(t)
 
+	m.On("GetUsers", "12345").Return(testUsers("Tigger"), nil)
 	m.On("AddEstimate", "12345", datastore.Estimate{
 		TaskID:         "TEST01",
+		UserID:         "tigger",
 		UserName:       "Tigger",
 		BestCase:       0.5,
 		MostLikelyCase: 1.5,
@@ -917,7 +926,7 @@ func TestAddUserEstimateToSessionSuccess(t *testing.T) {
 
 	app := NewServer(&Config{
 		Static: static{Prefix: "/public", Path: "../../static"},
-	}, m).Start()
+	}, m, nil).Start()
 
 	payloadf := map[string]interface{}{
 		"id":   "TEST01",
@@ -947,7 +956,32 @@ func TestAddUserEstimateToSessionSuccess(t *testing.T) {
 	err = decoder.Decode(&ar)
 	assert.NoError(t, err)
 	assert.Equal(t, "ok", ar.Message)
-	assert.Equal(t, "/sessions/12345/estimates/Tigger/TEST01", ar.Route)
+	assert.Equal(t, "/sessions/12345/estimates/tigger/TEST01", ar.Route)
+	assert.Equal(t, 200, res.StatusCode)
+}
+
+func TestAddUserEstimateWithReasoningToSessionSuccess(t *testing.T) {
+	setupAndTearDown := setupTestCaseForMock(t)
+	defer setupAndTearDown(t)
+
+	m.On("GetUsers", "12345").Return(testUsers("Tigger"), nil)
+	m.On("AddEstimate", "12345", datastore.Estimate{
+		TaskID:         "TEST01",
+		UserID:         "tigger",
+		UserName:       "Tigger",
+		BestCase:       0.5,
+		MostLikelyCase: 1.5,
+		WorstCase:      3.0,
+		Rationale:      "Bouncing takes time",
+		Assumptions:    []string{"No SSO", "Designs are ready"},
+	}).Return(nil)
+
+	app := NewServer(&Config{}, m, nil).Start()
+
+	res, err := app.Test(httptestRequest("POST", "/api/sessions/12345/estimates",
+		`{"id":"TEST01","user":"Tigger","b":0.5,"m":1.5,"w":3,"rationale":"Bouncing takes time","assumptions":["No SSO","Designs are ready"]}`), -1)
+
+	assert.NoError(t, err)
 	assert.Equal(t, 200, res.StatusCode)
 }
 
@@ -955,13 +989,14 @@ func TestRemoveUserEstimateFromSessionFails(t *testing.T) {
 	setupAndTearDown := setupTestCaseForMock(t)
 	defer setupAndTearDown(t)
 
+	m.On("GetUsers", "12345").Return(testUsers("Tigger"), nil)
 	m.On("RemoveEstimate", "12345", datastore.Estimate{
-		TaskID:   "TEST01",
-		UserName: "Tigger"}).Return(fmt.Errorf("Unable to remove estimate"))
+		TaskID: "TEST01",
+		UserID: "tigger"}).Return(fmt.Errorf("Unable to remove estimate"))
 
 	app := NewServer(&Config{
 		Static: static{Prefix: "/public", Path: "../../static"},
-	}, m).Start()
+	}, m, nil).Start()
 
 	req, _ := http.NewRequest(
 		"DELETE",
@@ -986,13 +1021,14 @@ func TestRemoveUserEstimateFromSessionSuccess(t *testing.T) {
 	setupAndTearDown := setupTestCaseForMock(t)
 	defer setupAndTearDown(t)
 
+	m.On("GetUsers", "12345").Return(testUsers("Tigger"), nil)
 	m.On("RemoveEstimate", "12345", datastore.Estimate{
-		TaskID:   "TEST01",
-		UserName: "Tigger"}).Return(nil)
+		TaskID: "TEST01",
+		UserID: "tigger"}).Return(nil)
 
 	app := NewServer(&Config{
 		Static: static{Prefix: "/public", Path: "../../static"},
-	}, m).Start()
+	}, m, nil).Start()
 
 	req, _ := http.NewRequest(
 		"DELETE",
@@ -1020,7 +1056,7 @@ func TestGetUserEstimatesFromSessionFails(t *testing.T) {
 
 	app := NewServer(&Config{
 		Static: static{Prefix: "/public", Path: "../../static"},
-	}, m).Start()
+	}, m, nil).Start()
 
 	req, _ := http.NewRequest(
 		"GET",
@@ -1045,11 +1081,12 @@ func TestGetUserEstimatesFromSessionSuccess(t *testing.T) {
 	setupAndTearDown := setupTestCaseForMock(t)
 	defer setupAndTearDown(t)
 
+	m.On("GetUsers", "12345").Return(testUsers("Tigger"), nil)
 	m.On("GetEstimates", "12345").Return([]datastore.Estimate{datastore.Estimate{TaskID: "TEST01"}}, nil)
 
 	app := NewServer(&Config{
 		Static: static{Prefix: "/public", Path: "../../static"},
-	}, m).Start()
+	}, m, nil).Start()
 
 	req, _ := http.NewRequest(
 		"GET",
@@ -1078,7 +1115,7 @@ func TestGetAverageEstimateForTaskFromSessionFailsDueToErrorOnGetEstimates(t *te
 
 	app := NewServer(&Config{
 		Static: static{Prefix: "/public", Path: "../../static"},
-	}, m).Start()
+	}, m, nil).Start()
 
 	req, _ := http.NewRequest(
 		"GET",
@@ -1107,7 +1144,7 @@ func TestGetAverageEstimateForTaskFromSessionFailsDueToNoEstimates(t *testing.T)
 
 	app := NewServer(&Config{
 		Static: static{Prefix: "/public", Path: "../../static"},
-	}, m).Start()
+	}, m, nil).Start()
 
 	req, _ := http.NewRequest(
 		"GET",
@@ -1133,14 +1170,15 @@ func TestGetAverageEstimateForTaskFromSessionFailsDueToErrorOnGetUsers(t *testin
 
 	m.On("GetEstimates", "12345").Return([]datastore.Estimate{datastore.Estimate{
 		TaskID:   "TEST01",
+		UserID:   "tigger",
 		UserName: "Tigger",
 	}}, nil)
 
-	m.On("GetUsers", "12345").Return([]string{}, fmt.Errorf("Unable to retrieve users"))
+	m.On("GetUsers", "12345").Return([]datastore.User{}, fmt.Errorf("Unable to retrieve users"))
 
 	app := NewServer(&Config{
 		Static: static{Prefix: "/public", Path: "../../static"},
-	}, m).Start()
+	}, m, nil).Start()
 
 	req, _ := http.NewRequest(
 		"GET",
@@ -1167,17 +1205,18 @@ func TestGetAverageEstimateForTaskFromSessionFailsDueToInvalidEstimate(t *testin
 
 	m.On("GetEstimates", "12345").Return([]datastore.Estimate{datastore.Estimate{
 		TaskID:         "TEST01",
+		UserID:         "tigger",
 		UserName:       "Tigger",
 		BestCase:       0.5,
 		MostLikelyCase: 0.2,
 		WorstCase:      1.5,
 	}}, nil)
 
-	m.On("GetUsers", "12345").Return([]string{"Tigger"}, nil)
+	m.On("GetUsers", "12345").Return(testUsers("Tigger"), nil)
 
 	app := NewServer(&Config{
 		Static: static{Prefix: "/public", Path: "../../static"},
-	}, m).Start()
+	}, m, nil).Start()
 
 	req, _ := http.NewRequest(
 		"GET",
@@ -1204,6 +1243,7 @@ func TestGetAverageEstimateForTaskFromSessionSuccessWithAllUsersProvidedEstimate
 
 	m.On("GetEstimates", "12345").Return([]datastore.Estimate{datastore.Estimate{
 		TaskID:         "TEST01",
+		UserID:         "tigger",
 		UserName:       "Tigger",
 		BestCase:       1.0,
 		MostLikelyCase: 2.0,
@@ -1211,6 +1251,7 @@ func TestGetAverageEstimateForTaskFromSessionSuccessWithAllUsersProvidedEstimate
 	},
 		{
 			TaskID:         "TEST01",
+			UserID:         "rabbit",
 			UserName:       "Rabbit",
 			BestCase:       2.0,
 			MostLikelyCase: 3.0,
@@ -1218,11 +1259,11 @@ func TestGetAverageEstimateForTaskFromSessionSuccessWithAllUsersProvidedEstimate
 		},
 	}, nil)
 
-	m.On("GetUsers", "12345").Return([]string{"Tigger", "Rabbit"}, nil)
+	m.On("GetUsers", "12345").Return(testUsers("Tigger", "Rabbit"), nil)
 
 	app := NewServer(&Config{
 		Static: static{Prefix: "/public", Path: "../../static"},
-	}, m).Start()
+	}, m, nil).Start()
 
 	req, _ := http.NewRequest(
 		"GET",
@@ -1251,6 +1292,7 @@ func TestGetAverageEstimateForTaskFromSessionSuccessWithNotAllUsersProvidedEstim
 
 	m.On("GetEstimates", "12345").Return([]datastore.Estimate{datastore.Estimate{
 		TaskID:         "TEST01",
+		UserID:         "tigger",
 		UserName:       "Tigger",
 		BestCase:       1.0,
 		MostLikelyCase: 2.0,
@@ -1258,6 +1300,7 @@ func TestGetAverageEstimateForTaskFromSessionSuccessWithNotAllUsersProvidedEstim
 	},
 		{
 			TaskID:         "TEST01",
+			UserID:         "rabbit",
 			UserName:       "Rabbit",
 			BestCase:       2.0,
 			MostLikelyCase: 3.0,
@@ -1265,11 +1308,11 @@ func TestGetAverageEstimateForTaskFromSessionSuccessWithNotAllUsersProvidedEstim
 		},
 	}, nil)
 
-	m.On("GetUsers", "12345").Return([]string{"Tigger", "Rabbit", "Piglet"}, nil)
+	m.On("GetUsers", "12345").Return(testUsers("Tigger", "Rabbit", "Piglet"), nil)
 
 	app := NewServer(&Config{
 		Static: static{Prefix: "/public", Path: "../../static"},
-	}, m).Start()
+	}, m, nil).Start()
 
 	req, _ := http.NewRequest(
 		"GET",
@@ -1293,6 +1336,63 @@ func TestGetAverageEstimateForTaskFromSessionSuccessWithNotAllUsersProvidedEstim
 	assert.Equal(t, 200, res.StatusCode)
 }
 
+func TestGetAverageEstimateForTaskFromSessionIgnoresOrphanedEstimates(t *testing.T) {
+	setupAndTearDown := setupTestCaseForMock(t)
+	defer setupAndTearDown(t)
+
+	m.On("GetEstimates", "12345").Return([]datastore.Estimate{datastore.Estimate{
+		TaskID:         "TEST01",
+		UserID:         "tigger",
+		UserName:       "Tigger",
+		BestCase:       1.0,
+		MostLikelyCase: 2.0,
+		WorstCase:      4.0,
+	},
+		{
+			TaskID:         "TEST01",
+			UserID:         "rabbit",
+			UserName:       "Rabbit",
+			BestCase:       2.0,
+			MostLikelyCase: 3.0,
+			WorstCase:      5.0,
+		},
+		{
+			TaskID:         "TEST01",
+			UserID:         "pooh",
+			UserName:       "Pooh",
+			BestCase:       20.0,
+			MostLikelyCase: 30.0,
+			WorstCase:      50.0,
+			Orphaned:       true,
+		},
+	}, nil)
+
+	m.On("GetUsers", "12345").Return(testUsers("Tigger", "Rabbit"), nil)
+
+	app := NewServer(&Config{
+		Static: static{Prefix: "/public", Path: "../../static"},
+	}, m, nil).Start()
+
+	req, _ := http.NewRequest(
+		"GET",
+		"/api/sessions/12345/estimates/TEST01",
+		nil,
+	)
+
+	res, err := app.Test(req, -1)
+
+	assert.NoError(t, err)
+
+	var ar apiResponse
+	decoder := json.NewDecoder(res.Body)
+	err = decoder.Decode(&ar)
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", ar.Message)
+	assert.True(t, math.Abs(2.666-ar.Estimate.Effort) <= float64CompareThreshold)
+	assert.True(t, math.Abs(0.5-ar.Estimate.StandardDeviation) <= float64CompareThreshold)
+	assert.Equal(t, 200, res.StatusCode)
+}
+
 func TestGetUserWithMaxEstimateDistanceForTaskFromSessionFailsDueToErrorOnGetEstimates(t *testing.T) {
 	setupAndTearDown := setupTestCaseForMock(t)
 	defer setupAndTearDown(t)
@@ -1301,7 +1401,7 @@ func TestGetUserWithMaxEstimateDistanceForTaskFromSessionFailsDueToErrorOnGetEst
 
 	app := NewServer(&Config{
 		Static: static{Prefix: "/public", Path: "../../static"},
-	}, m).Start()
+	}, m, nil).Start()
 
 	req, _ := http.NewRequest(
 		"GET",
@@ -1330,7 +1430,7 @@ func TestGetUserWithMaxEstimateDistanceForTaskFromSessionFailsDueToNoEstimates(t
 
 	app := NewServer(&Config{
 		Static: static{Prefix: "/public", Path: "../../static"},
-	}, m).Start()
+	}, m, nil).Start()
 
 	req, _ := http.NewRequest(
 		"GET",
@@ -1357,17 +1457,18 @@ func TestGetUserWithMaxEstimateDistanceForTaskFromSessionFailsDueToInvalidEstima
 
 	m.On("GetEstimates", "12345").Return([]datastore.Estimate{datastore.Estimate{
 		TaskID:         "TEST01",
+		UserID:         "tigger",
 		UserName:       "Tigger",
 		BestCase:       0.5,
 		MostLikelyCase: 0.2,
 		WorstCase:      1.5,
 	}}, nil)
 
-	m.On("GetUsers", "12345").Return([]string{"Tigger"}, nil)
+	m.On("GetUsers", "12345").Return(testUsers("Tigger"), nil)
 
 	app := NewServer(&Config{
 		Static: static{Prefix: "/public", Path: "../../static"},
-	}, m).Start()
+	}, m, nil).Start()
 
 	req, _ := http.NewRequest(
 		"GET",
@@ -1394,6 +1495,7 @@ func TestGetUserWithMaxEstimateDistanceForTaskFromSessionSuccess(t *testing.T) {
 
 	m.On("GetEstimates", "12345").Return([]datastore.Estimate{datastore.Estimate{
 		TaskID:         "TEST01",
+		UserID:         "tigger",
 		UserName:       "Tigger",
 		BestCase:       1.0,
 		MostLikelyCase: 2.0,
@@ -1401,6 +1503,7 @@ func TestGetUserWithMaxEstimateDistanceForTaskFromSessionSuccess(t *testing.T) {
 	},
 		{
 			TaskID:         "TEST01",
+			UserID:         "rabbit",
 			UserName:       "Rabbit",
 			BestCase:       2.0,
 			MostLikelyCase: 3.0,
@@ -1408,6 +1511,7 @@ func TestGetUserWithMaxEstimateDistanceForTaskFromSessionSuccess(t *testing.T) {
 		},
 		{
 			TaskID:         "TEST01",
+			UserID:         "piglet",
 			UserName:       "Piglet",
 			BestCase:       5.0,
 			MostLikelyCase: 6.0,
@@ -1415,11 +1519,11 @@ func TestGetUserWithMaxEstimateDistanceForTaskFromSessionSuccess(t *testing.T) {
 		},
 	}, nil)
 
-	m.On("GetUsers", "12345").Return([]string{"Tigger", "Rabbit", "Piglet"}, nil)
+	m.On("GetUsers", "12345").Return(testUsers("Tigger", "Rabbit", "Piglet"), nil)
 
 	app := NewServer(&Config{
 		Static: static{Prefix: "/public", Path: "../../static"},
-	}, m).Start()
+	}, m, nil).Start()
 
 	req, _ := http.NewRequest(
 		"GET",
@@ -1440,13 +1544,57 @@ func TestGetUserWithMaxEstimateDistanceForTaskFromSessionSuccess(t *testing.T) {
 	assert.Equal(t, 200, res.StatusCode)
 }
 
+func TestGetUserWithMaxEstimateDistanceForTaskFromSessionIncludesReasoning(t *testing.T) {
+	setupAndTearDown := setupTestCaseForMock(t)
+	defer setupAndTearDown(t)
+
+	m.On("GetEstimates", "12345").Return([]datastore.Estimate{datastore.Estimate{
+		TaskID:         "TEST01",
+		UserID:         "tigger",
+		UserName:       "Tigger",
+		BestCase:       1.0,
+		MostLikelyCase: 2.0,
+		WorstCase:      4.0,
+		Rationale:      "Login only",
+	},
+		{
+			TaskID:         "TEST01",
+			UserID:         "piglet",
+			UserName:       "Piglet",
+			BestCase:       5.0,
+			MostLikelyCase: 6.0,
+			WorstCase:      7.0,
+			Rationale:      "Login needs a password reset as well",
+			Assumptions:    []string{"No SSO"},
+		},
+	}, nil)
+
+	m.On("GetUsers", "12345").Return(testUsers("Tigger", "Piglet"), nil)
+
+	app := NewServer(&Config{}, m, nil).Start()
+
+	res, err := app.Test(httptestRequest("GET", "/api/sessions/12345/estimates/TEST01/users/distance", ""), -1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 200, res.StatusCode)
+
+	var dr DistanceResponse
+	assert.NoError(t, json.NewDecoder(res.Body).Decode(&dr))
+	assert.Equal(t, []string{"Piglet", "Tigger"}, dr.Users)
+	assert.Len(t, dr.Estimates, 2)
+	assert.Equal(t, "piglet", dr.Estimates[0].UserID)
+	assert.Equal(t, "Login needs a password reset as well", dr.Estimates[0].Rationale)
+	assert.Equal(t, []string{"No SSO"}, dr.Estimates[0].Assumptions)
+	assert.Equal(t, "Login only", dr.Estimates[1].Rationale)
+}
+
 func TestSmokeWithRealDB(t *testing.T) {
 	setupAndTearDown := setupTestCaseForRealDatastore(t)
 	defer setupAndTearDown(t)
 
 	app := NewServer(&Config{
 		Static: static{Prefix: "/public", Path: "../../static"},
-	}, ds).Start()
+	}, ds, nil).Start()
 
 	req, _ := http.NewRequest(
 		"POST",
@@ -1702,3 +1850,100 @@ func TestSmokeWithRealDB(t *testing.T) {
 	assert.Equal(t, "Tigger", ar.Estimates[1].UserName)
 	assert.Len(t, ar.Estimates, 2)
 }
+
+func TestGetVotingProgressForTaskFromSessionFailsDueToUnknownTask(t *testing.T) {
+	setupAndTearDown := setupTestCaseForMock(t)
+	defer setupAndTearDown(t)
+
+	m.On("GetTasks", "12345").Return([]datastore.Task{{ID: "TEST02"}}, nil)
+
+	app := NewServer(&Config{}, m, nil).Start()
+
+	res, err := app.Test(httptestRequest("GET", "/api/sessions/12345/tasks/TEST01/progress", ""), -1)
+
+	assert.NoError(t, err)
+	assertErrorResponse(t, res, 404, "Task with ID: TEST01 is not part of session")
+	m.AssertNotCalled(t, "GetEstimates", "12345")
+}
+
+func TestGetVotingProgressForTaskFromSessionFailsDueToErrorOnGetTasks(t *testing.T) {
+	setupAndTearDown := setupTestCaseForMock(t)
+	defer setupAndTearDown(t)
+
+	m.On("GetTasks", "12345").Return([]datastore.Task{}, fmt.Errorf("Specified session does not exist"))
+
+	app := NewServer(&Config{}, m, nil).Start()
+
+	res, err := app.Test(httptestRequest("GET", "/api/sessions/12345/tasks/TEST01/progress", ""), -1)
+
+	assert.NoError(t, err)
+	assertErrorResponse(t, res, 500, "Specified session does not exist")
+}
+
+func TestGetVotingProgressForTaskFromSessionFailsDueToErrorOnGetUsers(t *testing.T) {
+	setupAndTearDown := setupTestCaseForMock(t)
+	defer setupAndTearDown(t)
+
+	m.On("GetTasks", "12345").Return([]datastore.Task{{ID: "TEST01"}}, nil)
+	m.On("GetUsers", "12345").Return([]datastore.User{}, fmt.Errorf("Specified session does not exist"))
+
+	app := NewServer(&Config{}, m, nil).Start()
+
+	res, err := app.Test(httptestRequest("GET", "/api/sessions/12345/tasks/TEST01/progress", ""), -1)
+
+	assert.NoError(t, err)
+	assertErrorResponse(t, res, 500, "Specified session does not exist")
+}
+
+func TestGetVotingProgressForTaskFromSessionFailsDueToErrorOnGetEstimates(t *testing.T) {
+	setupAndTearDown := setupTestCaseForMock(t)
+	defer setupAndTearDown(t)
+
+	m.On("GetTasks", "12345").Return([]datastore.Task{{ID: "TEST01"}}, nil)
+	m.On("GetUsers", "12345").Return(testUsers("Tigger"), nil)
+	m.On("GetEstimates", "12345").Return([]datastore.Estimate{}, fmt.Errorf("Unable to retrieve estimates"))
+
+	app := NewServer(&Config{}, m, nil).Start()
+
+	res, err := app.Test(httptestRequest("GET", "/api/sessions/12345/tasks/TEST01/progress", ""), -1)
+
+	assert.NoError(t, err)
+	assertErrorResponse(t, res, 500, "Unable to retrieve estimates")
+}
+
+func TestGetVotingProgressForTaskFromSessionSuccess(t *testing.T) {
+	setupAndTearDown := setupTestCaseForMock(t)
+	defer setupAndTearDown(t)
+
+	m.On("GetTasks", "12345").Return([]datastore.Task{{ID: "TEST01"}}, nil)
+	m.On("GetUsers", "12345").Return(testUsers("Tigger", "Rabbit", "Piglet"), nil)
+	m.On("GetEstimates", "12345").Return([]datastore.Estimate{
+		{TaskID: "TEST01", UserID: "tigger", UserName: "Tigger", BestCase: 1.0, MostLikelyCase: 2.0, WorstCase: 4.0},
+		{TaskID: "TEST01", UserID: "piglet", UserName: "Piglet", BestCase: 5.0, MostLikelyCase: 6.0, WorstCase: 7.0},
+		{TaskID: "TEST02", UserID: "rabbit", UserName: "Rabbit", BestCase: 2.0, MostLikelyCase: 3.0, WorstCase: 5.0},
+	}, nil)
+
+	app := NewServer(&Config{}, m, nil).Start()
+
+	res, err := app.Test(httptestRequest("GET", "/api/sessions/12345/tasks/TEST01/progress", ""), -1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 200, res.StatusCode)
+
+	var pr ProgressResponse
+	decoder := json.NewDecoder(res.Body)
+	err = decoder.Decode(&pr)
+	assert.NoError(t, err)
+	assert.Equal(t, ProgressResponse{
+		Message: "ok",
+		Users: []UserProgress{
+			{ID: "tigger", Name: "Tigger", Estimated: true},
+			{ID: "rabbit", Name: "Rabbit", Estimated: false},
+			{ID: "piglet", Name: "Piglet", Estimated: true},
+		},
+		Total:     3,
+		Estimated: 2,
+		Missing:   1,
+		Ready:     false,
+	}, pr)
+}
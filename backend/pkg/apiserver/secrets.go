@@ -0,0 +1,131 @@
+package apiserver
+
+import (
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"github.com/haro87/dokerb/pkg/datastore"
+)
+
+// HeaderSecret carries the secret the user referenced by HeaderUser
+// got when joining the session, if secrets are enabled
+const HeaderSecret = "X-Doker-Secret"
+
+// WithSecrets enables secrets, which users get when joining a session
+// and have to send along with the X-Doker-User header, so that nobody
+// can act on behalf of somebody else. Secrets are kept in the provided
+// store.
+func WithSecrets(store datastore.SecretStore) Option {
+	return func(s *APIServer) {
+		s.secrets = store
+	}
+}
+
+// addAuthentication rejects requests of sessions, which reference
+// a user of the session in the X-Doker-User header without sending
+// the secret of the user. Changes of users, their estimates and their
+// presence are only accepted from the user or the moderator.
+func addAuthentication(app *fiber.App, store datastore.DataStore, secrets datastore.SecretStore, settings sessionSettings) {
+	handler := authenticate(store, secrets)
+	app.All("/api/sessions/:token", handler)
+	app.All("/api/sessions/:token/*", handler)
+
+	fromPath := func(c *fiber.Ctx) string { return c.Params("user") }
+	fromEstimate := func(c *fiber.Ctx) string {
+		es := new(PerUserEstimate)
+		if err := c.BodyParser(es); err != nil || es.UserID == "" {
+			return es.UserName
+		}
+		return es.UserID
+	}
+	app.Put("/api/sessions/:token/users/:user", authorize(store, settings, fromPath))
+	app.Delete("/api/sessions/:token/users/:user", authorize(store, settings, fromPath))
+	app.Post("/api/sessions/:token/users/:user/heartbeat", authorize(store, settings, fromPath))
+	app.Post("/api/sessions/:token/estimates", authorize(store, settings, fromEstimate))
+	app.Delete("/api/sessions/:token/estimates/:user/:id", authorize(store, settings, fromPath))
+}
+
+func authenticate(store datastore.DataStore, secrets datastore.SecretStore) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ref := c.Get(HeaderUser)
+		if ref == "" {
+			return c.Next()
+		}
+
+		// Requests of unknown sessions or users are left to the
+		// routes, as they don't act on behalf of a user
+		users, err := store.GetUsers(c.Params("token"))
+		if err != nil {
+			return c.Next()
+		}
+		u, err := lookupUser(users, ref)
+		if err != nil {
+			return c.Next()
+		}
+
+		ok, err := secrets.VerifySecret(c.Params("token"), u.ID, c.Get(HeaderSecret))
+		if err != nil {
+			return sendError(c, 500, err)
+		}
+		if !ok {
+			return sendError(c, 401, fmt.Errorf("Header %s must contain the secret of user: %s", HeaderSecret, ref))
+		}
+		return c.Next()
+	}
+}
+
+// authorize rejects requests changing the user referenced by target,
+// unless they are sent on behalf of the user or the moderator, which
+// authenticate verified before
+func authorize(store datastore.DataStore, settings sessionSettings, target func(c *fiber.Ctx) string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		// Requests of unknown sessions are left to the routes
+		users, err := store.GetUsers(c.Params("token"))
+		if err != nil {
+			return c.Next()
+		}
+
+		// Users, who left, can only be referenced by ID
+		ref := target(c)
+		id := ref
+		if u, err := lookupUser(users, ref); err == nil {
+			id = u.ID
+		}
+
+		user := requester(c, users)
+		if user == "" {
+			return sendError(c, 401, fmt.Errorf("Header %s must reference the user or the moderator", HeaderUser))
+		}
+
+		s, err := settings.get(c.Params("token"))
+		if err != nil {
+			return sendError(c, 500, err)
+		}
+		if user != id && user != s.Moderator {
+			return sendError(c, 403, fmt.Errorf("Only the user or the moderator may change user: %s", ref))
+		}
+		return c.Next()
+	}
+}
+
+// issueSecrets issues secrets for the users a new session starts with,
+// which are only returned once, if secrets are enabled
+func issueSecrets(store datastore.DataStore, secrets datastore.SecretStore, token string) ([]UserSecret, error) {
+	if secrets == nil {
+		return nil, nil
+	}
+
+	users, err := store.GetUsers(token)
+	if err != nil {
+		return nil, err
+	}
+
+	res := []UserSecret{}
+	for _, u := range users {
+		s, err := secrets.IssueSecret(token, u.ID)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, UserSecret{ID: u.ID, Name: u.Name, Secret: s})
+	}
+	return res, nil
+}
@@ -0,0 +1,246 @@
+package apiserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/haro87/dokerb/pkg/datastore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+	"time"
+)
+
+func TestJoinSessionIssuesSecret(t *testing.T) {
+	m := new(datastore.MockDatastore)
+	m.On("JoinSession", "12345", datastore.User{Name: "Pooh"}).Return("pooh", nil)
+	ss := new(datastore.MockSecretStore)
+	ss.On("IssueSecret", "12345", "pooh").Return("s3cr3t", nil)
+	app := NewServer(&Config{}, m, nil, WithSecrets(ss)).Start()
+
+	res, err := app.Test(httptestRequest("POST", "/api/sessions/12345/users", `{"name":"Pooh"}`), -1)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, res.StatusCode)
+
+	var jr JoinResponse
+	assert.NoError(t, json.NewDecoder(res.Body).Decode(&jr))
+	assert.Equal(t, JoinResponse{Message: "ok", Route: "/sessions/12345/users/pooh", Secret: "s3cr3t"}, jr)
+}
+
+func TestJoinSessionFailsDueToSecret(t *testing.T) {
+	m := new(datastore.MockDatastore)
+	m.On("JoinSession", "12345", datastore.User{Name: "Pooh"}).Return("pooh", nil)
+	ss := new(datastore.MockSecretStore)
+	ss.On("IssueSecret", "12345", "pooh").Return("", fmt.Errorf("Unable to store secret"))
+	app := NewServer(&Config{}, m, nil, WithSecrets(ss)).Start()
+
+	res, err := app.Test(httptestRequest("POST", "/api/sessions/12345/users", `{"name":"Pooh"}`), -1)
+	assert.NoError(t, err)
+	assertErrorResponse(t, res, 500, "Unable to store secret")
+}
+
+func TestRequestsOnBehalfOfUsersRequireSecret(t *testing.T) {
+	tests := []struct {
+		name   string
+		user   string
+		secret string
+		status int
+		reason string
+	}{
+		{"without user", "", "", 200, ""},
+		{"with secret", "Pooh", "s3cr3t", 200, ""},
+		{"with secret by ID", "pooh", "s3cr3t", 200, ""},
+		{"without secret", "Pooh", "", 401, "Header X-Doker-Secret must contain the secret of user: Pooh"},
+		{"with wrong secret", "Pooh", "guess", 401, "Header X-Doker-Secret must contain the secret of user: Pooh"},
+		{"unknown user", "Rabbit", "", 200, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := new(datastore.MockDatastore)
+			m.On("GetUsers", "12345").Return(testUsers("Tigger", "Pooh"), nil)
+			m.On("GetEstimates", "12345").Return([]datastore.Estimate{}, nil)
+			ss := new(datastore.MockSecretStore)
+			ss.On("VerifySecret", "12345", "pooh", "s3cr3t").Return(true, nil)
+			ss.On("VerifySecret", "12345", "pooh", mock.Anything).Return(false, nil)
+			app := NewServer(&Config{}, m, nil, WithSecrets(ss)).Start()
+
+			req := settingsRequest("GET", "/api/sessions/12345/estimates", tt.user, "")
+			if tt.secret != "" {
+				req.Header.Set(HeaderSecret, tt.secret)
+			}
+			res, err := app.Test(req, -1)
+			assert.NoError(t, err)
+			if tt.status != 200 {
+				assertErrorResponse(t, res, tt.status, tt.reason)
+				return
+			}
+			assert.Equal(t, 200, res.StatusCode)
+		})
+	}
+}
+
+func TestModeratorCantBeImpersonated(t *testing.T) {
+	s := newAnonymousServer(datastore.AnonymityPseudonyms)
+	ss := new(datastore.MockSecretStore)
+	ss.On("VerifySecret", "12345", "pooh", "").Return(false, nil)
+	WithSecrets(ss)(s)
+
+	res, err := s.Start().Test(settingsRequest("GET", "/api/sessions/12345/estimates", "pooh", ""), -1)
+	assert.NoError(t, err)
+	assertErrorResponse(t, res, 401, "Header X-Doker-Secret must contain the secret of user: pooh")
+}
+
+func TestSecretsAreRevoked(t *testing.T) {
+	m := new(datastore.MockDatastore)
+	m.On("GetUsers", "12345").Return(testUsers("Pooh"), nil)
+	m.On("LeaveSession", "12345", "pooh").Return(nil)
+	m.On("RemoveSession", "12345").Return(nil)
+	ss := new(datastore.MockSecretStore)
+	ss.On("VerifySecret", "12345", "pooh", "s3cr3t").Return(true, nil)
+	ss.On("RevokeSecrets", "12345", mock.Anything).Return(nil)
+	app := NewServer(&Config{}, m, nil, WithSecrets(ss)).Start()
+
+	req := settingsRequest("DELETE", "/api/sessions/12345/users/pooh", "Pooh", "")
+	req.Header.Set(HeaderSecret, "s3cr3t")
+	res, err := app.Test(req, -1)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, res.StatusCode)
+	ss.AssertCalled(t, "RevokeSecrets", "12345", []string{"pooh"})
+
+	res, err = app.Test(httptestRequest("DELETE", "/api/sessions/12345", ""), -1)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, res.StatusCode)
+	ss.AssertCalled(t, "RevokeSecrets", "12345", []string(nil))
+}
+
+func TestChangesOfUsersRequireUserOrModerator(t *testing.T) {
+	routes := []struct {
+		method string
+		route  string
+		body   string
+	}{
+		{"PUT", "/api/sessions/12345/users/tigger", `{"name":"Tiggr"}`},
+		{"DELETE", "/api/sessions/12345/users/Tigger", ""},
+		{"POST", "/api/sessions/12345/users/tigger/heartbeat", ""},
+		{"POST", "/api/sessions/12345/estimates", `{"id":"TEST01","user":"Tigger","b":1,"m":2,"w":3}`},
+		{"POST", "/api/sessions/12345/estimates", `{"id":"TEST01","userid":"tigger","b":1,"m":2,"w":3}`},
+		{"DELETE", "/api/sessions/12345/estimates/tigger/TEST01", ""},
+	}
+	tests := []struct {
+		name   string
+		user   string
+		status int
+		reason string
+	}{
+		{"user", "Tigger", 200, ""},
+		{"moderator", "Pooh", 200, ""},
+		{"other user", "Rabbit", 403, "Only the user or the moderator may change user: "},
+		{"without user", "", 401, "Header X-Doker-User must reference the user or the moderator"},
+	}
+	for _, r := range routes {
+		for _, tt := range tests {
+			t.Run(r.method+" "+r.route+" "+tt.name, func(t *testing.T) {
+				m := new(datastore.MockDatastore)
+				m.On("GetUsers", "12345").Return(testUsers("Tigger", "Pooh", "Rabbit"), nil)
+				m.On("GetEstimates", "12345").Return([]datastore.Estimate{}, nil)
+				m.On("UpdateUser", "12345", mock.Anything).Return(nil)
+				m.On("LeaveSession", "12345", "tigger").Return(nil)
+				m.On("AddEstimate", "12345", mock.Anything).Return(nil)
+				m.On("RemoveEstimate", "12345", mock.Anything).Return(nil)
+				ss := new(datastore.MockSecretStore)
+				ss.On("VerifySecret", "12345", mock.Anything, "s3cr3t").Return(true, nil)
+				ss.On("RevokeSecrets", "12345", mock.Anything).Return(nil)
+				st := new(datastore.MockSettingsStore)
+				st.On("GetSettings", "12345").Return(datastore.Settings{Anonymity: datastore.AnonymityOff, Moderator: "pooh"}, nil)
+				config := &Config{Presence: userPresence{Enabled: true, IdleTimeout: time.Minute, OfflineTimeout: time.Hour}}
+				app := NewServer(config, m, nil, WithSecrets(ss), WithSettings(st)).Start()
+
+				req := settingsRequest(r.method, r.route, tt.user, r.body)
+				req.Header.Set(HeaderSecret, "s3cr3t")
+				res, err := app.Test(req, -1)
+				assert.NoError(t, err)
+				if tt.status != 200 {
+					var er ErrorResponse
+					assert.NoError(t, json.NewDecoder(res.Body).Decode(&er))
+					assert.Equal(t, tt.status, res.StatusCode)
+					assert.Contains(t, er.Reason, tt.reason)
+					m.AssertNotCalled(t, "AddEstimate", mock.Anything, mock.Anything)
+					m.AssertNotCalled(t, "RemoveEstimate", mock.Anything, mock.Anything)
+					m.AssertNotCalled(t, "LeaveSession", mock.Anything, mock.Anything)
+					m.AssertNotCalled(t, "UpdateUser", mock.Anything, mock.Anything)
+					return
+				}
+				assert.Equal(t, 200, res.StatusCode)
+			})
+		}
+	}
+}
+
+func TestEstimatesOfLeftUsersCanOnlyBeRemovedByModerator(t *testing.T) {
+	m := new(datastore.MockDatastore)
+	m.On("GetUsers", "12345").Return(testUsers("Tigger", "Pooh"), nil)
+	m.On("RemoveEstimate", "12345", datastore.Estimate{TaskID: "TEST01", UserID: "rabbit"}).Return(nil)
+	ss := new(datastore.MockSecretStore)
+	ss.On("VerifySecret", "12345", mock.Anything, "s3cr3t").Return(true, nil)
+	st := new(datastore.MockSettingsStore)
+	st.On("GetSettings", "12345").Return(datastore.Settings{Anonymity: datastore.AnonymityOff, Moderator: "pooh"}, nil)
+	app := NewServer(&Config{}, m, nil, WithSecrets(ss), WithSettings(st)).Start()
+
+	req := settingsRequest("DELETE", "/api/sessions/12345/estimates/rabbit/TEST01", "Tigger", "")
+	req.Header.Set(HeaderSecret, "s3cr3t")
+	res, err := app.Test(req, -1)
+	assert.NoError(t, err)
+	assertErrorResponse(t, res, 403, "Only the user or the moderator may change user: rabbit")
+
+	req = settingsRequest("DELETE", "/api/sessions/12345/estimates/rabbit/TEST01", "Pooh", "")
+	req.Header.Set(HeaderSecret, "s3cr3t")
+	res, err = app.Test(req, -1)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, res.StatusCode)
+}
+
+func TestSessionsFromTemplatesIssueSecrets(t *testing.T) {
+	m := new(datastore.MockDatastore)
+	m.On("GetUsers", "abcd").Return(testUsers("Tigger", "Pooh"), nil)
+	ts := new(datastore.MockTemplateStore)
+	ts.On("CreateSessionFromTemplate", "sprint").Return("abcd", nil)
+	ts.On("CloneSession", "12345", false).Return("abcd", nil)
+	ss := new(datastore.MockSecretStore)
+	ss.On("IssueSecret", "abcd", "tigger").Return("s3cr3t", nil)
+	ss.On("IssueSecret", "abcd", "pooh").Return("h0n3y", nil)
+	app := NewServer(&Config{}, m, nil, WithTemplates(ts), WithSecrets(ss)).Start()
+
+	expected := SessionResponse{
+		Message: "ok",
+		Route:   "/sessions/abcd",
+		Secrets: []UserSecret{
+			{ID: "tigger", Name: "Tigger", Secret: "s3cr3t"},
+			{ID: "pooh", Name: "Pooh", Secret: "h0n3y"},
+		},
+	}
+	for _, req := range []struct{ route, body string }{
+		{"/api/sessions", `{"template":"sprint"}`},
+		{"/api/sessions/12345/clone", ""},
+	} {
+		res, err := app.Test(httptestRequest("POST", req.route, req.body), -1)
+		assert.NoError(t, err)
+		assert.Equal(t, 200, res.StatusCode)
+
+		var sr SessionResponse
+		assert.NoError(t, json.NewDecoder(res.Body).Decode(&sr))
+		assert.Equal(t, expected, sr)
+	}
+}
+
+func TestCloneSessionFailsDueToSecret(t *testing.T) {
+	m := new(datastore.MockDatastore)
+	m.On("GetUsers", "abcd").Return(testUsers("Tigger"), nil)
+	ts := new(datastore.MockTemplateStore)
+	ts.On("CloneSession", "12345", false).Return("abcd", nil)
+	ss := new(datastore.MockSecretStore)
+	ss.On("IssueSecret", "abcd", "tigger").Return("", fmt.Errorf("Unable to store secret"))
+	app := NewServer(&Config{}, m, nil, WithTemplates(ts), WithSecrets(ss)).Start()
+
+	res, err := app.Test(httptestRequest("POST", "/api/sessions/12345/clone", ""), -1)
+	assert.NoError(t, err)
+	assertErrorResponse(t, res, 500, "Unable to store secret")
+}
@@ -0,0 +1,280 @@
+package apiserver
+
+import (
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"github.com/haro87/dokerb/pkg/datastore"
+	dbestimate "github.com/haro87/dokerb/pkg/estimate"
+)
+
+// HeaderUser identifies the user a request is sent on behalf of by
+// ID or unique name, it decides whose names are revealed in anonymous
+// sessions
+const HeaderUser = "X-Doker-User"
+
+// Settings represents the settings of a session, Deck is either
+// the name of a predefined deck or custom for the provided Cards
+type Settings struct {
+	Anonymity string `json:"anonymity" example:"pseudonyms" format:"string"`
+	Moderator string `json:"moderator" example:"Tigger" format:"string"`
+	Unit      string `json:"unit" example:"hours" format:"string"`
+	Deck      string `json:"deck" example:"fibonacci" format:"string"`
+	Cards     []Card `json:"cards" format:"[]Card"`
+}
+
+// Card represents a card of a deck
+type Card struct {
+	Label string  `json:"label" example:"M" format:"string"`
+	Value float64 `json:"value" example:"3" format:"float64"`
+}
+
+// SettingsResponse represents the get settings response
+type SettingsResponse struct {
+	Message  string   `json:"message" example:"ok" format:"string"`
+	Settings Settings `json:"settings" format:"Settings"`
+}
+
+// WithSettings enables session settings like anonymous
+// estimation, which are kept in the provided store
+func WithSettings(store datastore.SettingsStore) Option {
+	return func(s *APIServer) {
+		s.settings = store
+	}
+}
+
+// sessionSettings returns the settings of sessions with the
+// configured defaults applied, a nil store only provides defaults
+type sessionSettings struct {
+	store datastore.SettingsStore
+	units units
+}
+
+func (s sessionSettings) get(token string) (datastore.Settings, error) {
+	settings := datastore.Settings{Anonymity: datastore.AnonymityOff}
+	if s.store != nil {
+		var err error
+		if settings, err = s.store.GetSettings(token); err != nil {
+			return settings, err
+		}
+	}
+	if settings.Unit == "" {
+		settings.Unit = s.units.Default
+	}
+	return settings, nil
+}
+
+// settingsRoutes registers the routes for managing session settings
+func settingsRoutes(app *fiber.App, store datastore.DataStore, settings sessionSettings) {
+	APIGroup := app.Group("/api")
+
+	addGetSettingsRoute(APIGroup, settings)
+
+	addUpdateSettingsRoute(APIGroup, store, settings)
+}
+
+// Adding the get settings route
+// @Summary Get the settings of a session
+// @Description Gets the settings of an existing session, anonymity is off and the configured default unit is used unless changed
+// @Tags session
+// @Produce  json
+// @Param token path string true "Session Token"
+// @Success 200 {object} SettingsResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /sessions/{token}/settings [get]
+func addGetSettingsRoute(api fiber.Router, settings sessionSettings) {
+	api.Get("/sessions/:token/settings", func(c *fiber.Ctx) error {
+		s, err := settings.get(c.Params("token"))
+
+		if err != nil {
+			return sendError(c, 500, err)
+		}
+
+		data := SettingsResponse{
+			Message:  "ok",
+			Settings: toSettings(s),
+		}
+		return c.Status(200).JSON(data)
+	})
+}
+
+// Adding the update settings route
+// @Summary Update the settings of a session
+// @Description Updates the settings of an existing session. In anonymous sessions estimates are returned with pseudonyms or without names to everyone except the moderator and the owner of the estimate. The moderator is referenced by ID or unique name and stored by ID. Once a moderator is set, only the moderator may change the settings. If a deck is set, estimates must use the values of its cards.
+// @Tags session
+// @Accept  json
+// @Produce  json
+// @Param token path string true "Session Token"
+// @Param X-Doker-User header string false "ID or name of the requesting user"
+// @Param settings body Settings true "Settings"
+// @Success 200 {object} GeneralResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /sessions/{token}/settings [put]
+func addUpdateSettingsRoute(api fiber.Router, store datastore.DataStore, settings sessionSettings) {
+	api.Put("/sessions/:token/settings", func(c *fiber.Ctx) error {
+		ns := new(Settings)
+
+		if err := c.BodyParser(ns); err != nil {
+			return sendError(c, 400, err)
+		}
+
+		updated, err := fromSettings(*ns)
+		if err != nil {
+			return sendError(c, 400, err)
+		}
+
+		users, err := store.GetUsers(c.Params("token"))
+		if err != nil {
+			return sendError(c, 500, err)
+		}
+
+		if ns.Moderator != "" {
+			moderator, err := lookupUser(users, ns.Moderator)
+			if err != nil {
+				return sendError(c, 400, err)
+			}
+			updated.Moderator = moderator.ID
+		}
+
+		s, err := settings.store.GetSettings(c.Params("token"))
+		if err != nil {
+			return sendError(c, 500, err)
+		}
+
+		if s.Moderator != "" && s.Moderator != requester(c, users) {
+			return sendError(c, 403, fmt.Errorf("Only the moderator may change the settings"))
+		}
+
+		// Keep the salt, so that pseudonyms stay the same
+		updated.Salt = s.Salt
+
+		if err := settings.store.SetSettings(c.Params("token"), updated); err != nil {
+			return sendError(c, storeErrorStatusOr(err, 400), err)
+		}
+
+		data := GeneralResponse{
+			Message: "ok",
+			Route:   "/sessions/" + c.Params("token") + "/settings",
+		}
+		return c.Status(200).JSON(data)
+	})
+}
+
+// requester returns the ID of the user the request is sent on
+// behalf of, or an empty string if the user is not part of the session
+func requester(c *fiber.Ctx, users []datastore.User) string {
+	u, err := lookupUser(users, c.Get(HeaderUser))
+	if err != nil {
+		return ""
+	}
+	return u.ID
+}
+
+// viewer decides which user names are revealed to the user
+// a request is sent on behalf of
+type viewer struct {
+	settings datastore.Settings
+	user     string
+}
+
+// newViewer returns the viewer of the request out of the provided
+// users, which sees all names if settings are disabled
+func newViewer(c *fiber.Ctx, settings sessionSettings, users []datastore.User) (viewer, error) {
+	s, err := settings.get(c.Params("token"))
+	return viewer{settings: s, user: requester(c, users)}, err
+}
+
+// reveals reports whether the viewer may see who the user with the
+// ID is, the moderator sees all names and everybody sees their own name
+func (v viewer) reveals(id string) bool {
+	return v.settings.Anonymity == datastore.AnonymityOff || (v.user != "" && id == v.user) ||
+		(v.settings.Moderator != "" && v.settings.Moderator == v.user)
+}
+
+// name returns the name of the user with the ID as seen by the viewer
+func (v viewer) name(id, name string) string {
+	if v.reveals(id) {
+		return name
+	}
+	return v.settings.Anonymize(id, name)
+}
+
+// estimates returns copies of the estimates with the names as seen
+// by the viewer, IDs of unrevealed users are removed
+func (v viewer) estimates(ests []datastore.Estimate) []datastore.Estimate {
+	res := make([]datastore.Estimate, 0, len(ests))
+	for _, e := range ests {
+		if !v.reveals(e.UserID) {
+			e.UserName = v.name(e.UserID, e.UserName)
+			e.UserID = ""
+		}
+		res = append(res, e)
+	}
+	return res
+}
+
+// users returns the users as seen by the viewer, only the names
+// of unrevealed users are kept as pseudonyms
+func (v viewer) users(users []datastore.User) []User {
+	res := make([]User, 0, len(users))
+	for _, u := range users {
+		res = append(res, toUser(v.profile(u)))
+	}
+	return res
+}
+
+// profile returns the user as seen by the viewer, only the name of an
+// unrevealed user is kept as pseudonym
+func (v viewer) profile(u datastore.User) datastore.User {
+	if v.reveals(u.ID) {
+		return u
+	}
+	return datastore.User{Name: v.name(u.ID, u.Name), Role: u.Role}
+}
+
+// fromSettings returns the settings to store, predefined decks
+// are expanded to their cards
+func fromSettings(s Settings) (datastore.Settings, error) {
+	settings := datastore.Settings{
+		Anonymity: s.Anonymity,
+		Moderator: s.Moderator,
+		Unit:      s.Unit,
+		Deck:      s.Deck,
+	}
+	if settings.Anonymity == "" {
+		settings.Anonymity = datastore.AnonymityOff
+	}
+
+	switch {
+	case s.Deck != "" && s.Deck != "custom":
+		cards, err := dbestimate.Deck(s.Deck)
+		if err != nil {
+			return settings, err
+		}
+		settings.Cards = cards
+	case len(s.Cards) > 0:
+		settings.Deck = "custom"
+		for _, c := range s.Cards {
+			settings.Cards = append(settings.Cards, dbestimate.Card{Label: c.Label, Value: c.Value})
+		}
+	default:
+		settings.Deck = ""
+	}
+	return settings, nil
+}
+
+func toSettings(s datastore.Settings) Settings {
+	cards := []Card{}
+	for _, c := range s.Cards {
+		cards = append(cards, Card{Label: c.Label, Value: c.Value})
+	}
+	return Settings{
+		Anonymity: s.Anonymity,
+		Moderator: s.Moderator,
+		Unit:      s.Unit,
+		Deck:      s.Deck,
+		Cards:     cards,
+	}
+}
@@ -0,0 +1,293 @@
+package apiserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/haro87/dokerb/pkg/datastore"
+	dbestimate "github.com/haro87/dokerb/pkg/estimate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"net/http"
+	"testing"
+)
+
+var anonymousEstimates = []datastore.Estimate{
+	{TaskID: "TEST01", UserID: "tigger", UserName: "Tigger", BestCase: 1, MostLikelyCase: 2, WorstCase: 3},
+	{TaskID: "TEST01", UserID: "rabbit", UserName: "Rabbit", BestCase: 4, MostLikelyCase: 5, WorstCase: 9},
+	{TaskID: "TEST01", UserID: "pooh", UserName: "Pooh", BestCase: 1, MostLikelyCase: 3, WorstCase: 4},
+}
+
+func settingsRequest(method, route, user, body string) *http.Request {
+	req := httptestRequest(method, route, body)
+	if user != "" {
+		req.Header.Set(HeaderUser, user)
+	}
+	return req
+}
+
+func newAnonymousServer(anonymity string) *APIServer {
+	m := new(datastore.MockDatastore)
+	m.On("GetEstimates", "12345").Return(anonymousEstimates, nil)
+	m.On("GetUsers", "12345").Return(testUsers("Tigger", "Rabbit", "Pooh"), nil)
+	ss := new(datastore.MockSettingsStore)
+	ss.On("GetSettings", "12345").Return(datastore.Settings{Anonymity: anonymity, Moderator: "pooh", Salt: "s4lt"}, nil)
+	return NewServer(&Config{}, m, nil, WithSettings(ss))
+}
+
+func TestGetEstimatesAnonymity(t *testing.T) {
+	p := func(user string) string { return datastore.Pseudonym("s4lt", user) }
+	tests := []struct {
+		name      string
+		anonymity string
+		user      string
+		want      []string
+	}{
+		{"off", datastore.AnonymityOff, "", []string{"Tigger", "Rabbit", "Pooh"}},
+		{"pseudonyms", datastore.AnonymityPseudonyms, "", []string{p("tigger"), p("rabbit"), p("pooh")}},
+		{"pseudonyms reveal own name", datastore.AnonymityPseudonyms, "Rabbit", []string{p("tigger"), "Rabbit", p("pooh")}},
+		{"pseudonyms reveal own name by ID", datastore.AnonymityPseudonyms, "rabbit", []string{p("tigger"), "Rabbit", p("pooh")}},
+		{"hidden reveal own name", datastore.AnonymityHidden, "Tigger", []string{"Tigger", "", ""}},
+		{"moderator", datastore.AnonymityHidden, "Pooh", []string{"Tigger", "Rabbit", "Pooh"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newAnonymousServer(tt.anonymity).Start()
+
+			res, err := app.Test(settingsRequest("GET", "/api/sessions/12345/estimates", tt.user, ""), -1)
+			assert.NoError(t, err)
+			assert.Equal(t, 200, res.StatusCode)
+
+			var er PerUserEstimateResponse
+			assert.NoError(t, json.NewDecoder(res.Body).Decode(&er))
+			names := []string{}
+			for i, e := range er.Estimates {
+				names = append(names, e.UserName)
+				assert.Equal(t, anonymousEstimates[i].MostLikelyCase, e.MostLikelyCase)
+				if e.UserName != anonymousEstimates[i].UserName {
+					assert.Empty(t, e.UserID)
+				}
+			}
+			assert.Equal(t, tt.want, names)
+		})
+	}
+}
+
+func TestGetDistanceUsersAnonymity(t *testing.T) {
+	tests := []struct {
+		name string
+		user string
+		want []string
+	}{
+		{"other user", "Pooh2", []string{datastore.Pseudonym("s4lt", "tigger"), datastore.Pseudonym("s4lt", "rabbit")}},
+		{"owner", "Tigger", []string{"Tigger", datastore.Pseudonym("s4lt", "rabbit")}},
+		{"moderator", "Pooh", []string{"Tigger", "Rabbit"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newAnonymousServer(datastore.AnonymityPseudonyms).Start()
+
+			res, err := app.Test(settingsRequest("GET", "/api/sessions/12345/estimates/TEST01/users/distance", tt.user, ""), -1)
+			assert.NoError(t, err)
+			assert.Equal(t, 200, res.StatusCode)
+
+			var dr DistanceResponse
+			assert.NoError(t, json.NewDecoder(res.Body).Decode(&dr))
+			assert.ElementsMatch(t, tt.want, dr.Users)
+			for i, e := range dr.Estimates {
+				assert.Equal(t, dr.Users[i], e.UserName)
+				if e.UserName != "Tigger" && e.UserName != "Rabbit" {
+					assert.Empty(t, e.UserID)
+				}
+			}
+		})
+	}
+}
+
+func TestGetVotingProgressAnonymity(t *testing.T) {
+	m := new(datastore.MockDatastore)
+	m.On("GetTasks", "12345").Return([]datastore.Task{{ID: "TEST01"}}, nil)
+	m.On("GetUsers", "12345").Return(testUsers("Tigger", "Rabbit"), nil)
+	m.On("GetEstimates", "12345").Return(anonymousEstimates[:1], nil)
+	ss := new(datastore.MockSettingsStore)
+	ss.On("GetSettings", "12345").Return(datastore.Settings{Anonymity: datastore.AnonymityHidden, Salt: "s4lt"}, nil)
+
+	app := NewServer(&Config{}, m, nil, WithSettings(ss)).Start()
+
+	res, err := app.Test(settingsRequest("GET", "/api/sessions/12345/tasks/TEST01/progress", "Rabbit", ""), -1)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, res.StatusCode)
+
+	var pr ProgressResponse
+	assert.NoError(t, json.NewDecoder(res.Body).Decode(&pr))
+	assert.Equal(t, []UserProgress{
+		{Estimated: true},
+		{ID: "rabbit", Name: "Rabbit", Estimated: false},
+	}, pr.Users)
+	assert.Equal(t, 1, pr.Missing)
+}
+
+func TestGetSettings(t *testing.T) {
+	ss := new(datastore.MockSettingsStore)
+	ss.On("GetSettings", "12345").Return(datastore.Settings{Anonymity: "hidden", Moderator: "pooh", Salt: "s4lt"}, nil)
+
+	app := NewServer(&Config{}, new(datastore.MockDatastore), nil, WithSettings(ss)).Start()
+
+	res, err := app.Test(httptestRequest("GET", "/api/sessions/12345/settings", ""), -1)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, res.StatusCode)
+
+	var sr SettingsResponse
+	assert.NoError(t, json.NewDecoder(res.Body).Decode(&sr))
+	assert.Equal(t, Settings{Anonymity: "hidden", Moderator: "pooh", Cards: []Card{}}, sr.Settings)
+}
+
+func TestUpdateSettings(t *testing.T) {
+	m := new(datastore.MockDatastore)
+	m.On("GetUsers", "12345").Return(testUsers("Tigger", "Pooh"), nil)
+	ss := new(datastore.MockSettingsStore)
+	ss.On("GetSettings", "12345").Return(datastore.Settings{Anonymity: "off", Salt: "s4lt"}, nil)
+	ss.On("SetSettings", "12345", datastore.Settings{Anonymity: "pseudonyms", Moderator: "pooh", Salt: "s4lt"}).Return(nil)
+
+	app := NewServer(&Config{}, m, nil, WithSettings(ss)).Start()
+
+	res, err := app.Test(httptestRequest("PUT", "/api/sessions/12345/settings", `{"anonymity":"pseudonyms","moderator":"Pooh"}`), -1)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, res.StatusCode)
+	ss.AssertExpectations(t)
+}
+
+func TestUpdateSettingsFails(t *testing.T) {
+	tests := []struct {
+		name     string
+		user     string
+		body     string
+		settings datastore.Settings
+		status   int
+		reason   string
+	}{
+		{"unknown moderator", "", `{"anonymity":"hidden","moderator":"Rabbit"}`, datastore.Settings{Anonymity: "off"}, 400, "User: Rabbit is not part of session"},
+		{"ambiguous moderator", "", `{"anonymity":"hidden","moderator":"Tigger"}`, datastore.Settings{Anonymity: "off"}, 400, "User name: Tigger is ambiguous, use the user ID"},
+		{"not the moderator", "Tigger", `{"anonymity":"off"}`, datastore.Settings{Anonymity: "hidden", Moderator: "pooh"}, 403, "Only the moderator may change the settings"},
+		{"no user", "", `{"anonymity":"off"}`, datastore.Settings{Anonymity: "hidden", Moderator: "pooh"}, 403, "Only the moderator may change the settings"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := new(datastore.MockDatastore)
+			users := append(testUsers("Tigger", "Pooh"), datastore.User{ID: "tigger2", Name: "Tigger"})
+			m.On("GetUsers", "12345").Return(users, nil)
+			ss := new(datastore.MockSettingsStore)
+			ss.On("GetSettings", "12345").Return(tt.settings, nil)
+
+			app := NewServer(&Config{}, m, nil, WithSettings(ss)).Start()
+
+			res, err := app.Test(settingsRequest("PUT", "/api/sessions/12345/settings", tt.user, tt.body), -1)
+			assert.NoError(t, err)
+			assertErrorResponse(t, res, tt.status, tt.reason)
+			ss.AssertNotCalled(t, "SetSettings", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestUpdateSettingsInvalidAnonymity(t *testing.T) {
+	m := new(datastore.MockDatastore)
+	m.On("GetUsers", "12345").Return(testUsers("Tigger"), nil)
+	ss := new(datastore.MockSettingsStore)
+	ss.On("GetSettings", "12345").Return(datastore.Settings{Anonymity: "off"}, nil)
+	ss.On("SetSettings", "12345", mock.Anything).Return(fmt.Errorf("Anonymity must be one of off, pseudonyms or hidden"))
+
+	app := NewServer(&Config{}, m, nil, WithSettings(ss)).Start()
+
+	res, err := app.Test(httptestRequest("PUT", "/api/sessions/12345/settings", `{"anonymity":"masked"}`), -1)
+	assert.NoError(t, err)
+	assertErrorResponse(t, res, 400, "Anonymity must be one of off, pseudonyms or hidden")
+}
+
+func TestUpdateSettingsDeck(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want datastore.Settings
+	}{
+		{
+			"preset deck",
+			`{"unit":"points","deck":"t-shirt"}`,
+			datastore.Settings{Anonymity: "off", Unit: "points", Deck: "t-shirt", Cards: []dbestimate.Card{
+				{Label: "XS", Value: 1}, {Label: "S", Value: 2}, {Label: "M", Value: 3},
+				{Label: "L", Value: 5}, {Label: "XL", Value: 8}, {Label: "XXL", Value: 13},
+			}, Salt: "s4lt"},
+		},
+		{
+			"custom cards",
+			`{"unit":"days","cards":[{"label":"half","value":0.5},{"label":"one","value":1}]}`,
+			datastore.Settings{Anonymity: "off", Unit: "days", Deck: "custom", Cards: []dbestimate.Card{
+				{Label: "half", Value: 0.5}, {Label: "one", Value: 1},
+			}, Salt: "s4lt"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := new(datastore.MockDatastore)
+			m.On("GetUsers", "12345").Return(testUsers("Tigger"), nil)
+			ss := new(datastore.MockSettingsStore)
+			ss.On("GetSettings", "12345").Return(datastore.Settings{Anonymity: "off", Salt: "s4lt"}, nil)
+			ss.On("SetSettings", "12345", tt.want).Return(nil)
+
+			app := NewServer(&Config{}, m, nil, WithSettings(ss)).Start()
+
+			res, err := app.Test(httptestRequest("PUT", "/api/sessions/12345/settings", tt.body), -1)
+			assert.NoError(t, err)
+			assert.Equal(t, 200, res.StatusCode)
+			ss.AssertExpectations(t)
+		})
+	}
+}
+
+func TestUpdateSettingsUnknownDeck(t *testing.T) {
+	ss := new(datastore.MockSettingsStore)
+	ss.On("GetSettings", "12345").Return(datastore.Settings{Anonymity: "off"}, nil)
+
+	app := NewServer(&Config{}, new(datastore.MockDatastore), nil, WithSettings(ss)).Start()
+
+	res, err := app.Test(httptestRequest("PUT", "/api/sessions/12345/settings", `{"deck":"tarot"}`), -1)
+	assert.NoError(t, err)
+	assertErrorResponse(t, res, 400, "Unknown deck: tarot")
+	ss.AssertNotCalled(t, "SetSettings", mock.Anything, mock.Anything)
+}
+
+func TestAddEstimateNotInDeck(t *testing.T) {
+	m := new(datastore.MockDatastore)
+	m.On("GetUsers", "12345").Return(testUsers("Tigger"), nil)
+	ss := new(datastore.MockSettingsStore)
+	ss.On("GetSettings", "12345").Return(datastore.Settings{Anonymity: "off", Deck: "fibonacci", Cards: []dbestimate.Card{
+		{Label: "1", Value: 1}, {Label: "2", Value: 2}, {Label: "3", Value: 3},
+	}}, nil)
+
+	app := NewServer(&Config{}, m, nil, WithSettings(ss)).Start()
+
+	res, err := app.Test(httptestRequest("POST", "/api/sessions/12345/estimates", `{"id":"TEST01","user":"Tigger","b":1,"m":2.5,"w":3}`), -1)
+	assert.NoError(t, err)
+	assertErrorResponse(t, res, 400, "Estimate value 2.5 is not part of the session deck")
+	m.AssertNotCalled(t, "AddEstimate", mock.Anything, mock.Anything)
+}
+
+func TestAddEstimateStillValidatesUserInAnonymousSessions(t *testing.T) {
+	m := new(datastore.MockDatastore)
+	m.On("GetUsers", "12345").Return(testUsers("Tigger"), nil)
+	ss := new(datastore.MockSettingsStore)
+	ss.On("GetSettings", "12345").Return(datastore.Settings{Anonymity: "hidden"}, nil)
+
+	app := NewServer(&Config{}, m, nil, WithSettings(ss)).Start()
+
+	res, err := app.Test(httptestRequest("POST", "/api/sessions/12345/estimates", `{"id":"TEST01","user":"Ghost","b":1,"m":2,"w":3}`), -1)
+	assert.NoError(t, err)
+	assertErrorResponse(t, res, 400, "User: Ghost is not part of session")
+	m.AssertNotCalled(t, "AddEstimate", mock.Anything, mock.Anything)
+}
+
+func TestSettingsRoutesDisabled(t *testing.T) {
+	app := NewServer(&Config{}, new(datastore.MockDatastore), nil).Start()
+
+	res, err := app.Test(httptestRequest("GET", "/api/sessions/12345/settings", ""), -1)
+	assert.NoError(t, err)
+	assert.Equal(t, 404, res.StatusCode)
+}
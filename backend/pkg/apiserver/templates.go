@@ -0,0 +1,249 @@
+package apiserver
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/haro87/dokerb/pkg/datastore"
+)
+
+// NewSession represents the optional body of the create session request
+type NewSession struct {
+	Template string `json:"template" example:"sprint" format:"string"`
+}
+
+// CloneSession represents the body of the clone session request
+type CloneSession struct {
+	Tasks bool `json:"tasks" example:"true" format:"bool"`
+}
+
+// SessionTemplate represents a named session template
+type SessionTemplate struct {
+	Name     string   `json:"name" example:"sprint" format:"string"`
+	Users    []string `json:"users" example:"Tigger,Rabbit" format:"[]string"`
+	Tasks    []Task   `json:"tasks" format:"[]Task"`
+	Settings Settings `json:"settings" format:"Settings"`
+}
+
+// TemplatesResponse represents the get templates response
+type TemplatesResponse struct {
+	Message   string            `json:"message" example:"ok" format:"string"`
+	Templates []SessionTemplate `json:"templates" format:"[]SessionTemplate"`
+}
+
+// TemplateResponse represents the get template response
+type TemplateResponse struct {
+	Message  string          `json:"message" example:"ok" format:"string"`
+	Template SessionTemplate `json:"template" format:"SessionTemplate"`
+}
+
+// WithTemplates enables session templates and cloning, templates
+// are kept in the provided store
+func WithTemplates(store datastore.TemplateStore) Option {
+	return func(s *APIServer) {
+		s.templates = store
+	}
+}
+
+// templateRoutes registers the routes for managing templates
+// and cloning sessions
+func templateRoutes(app *fiber.App, store datastore.DataStore, templates datastore.TemplateStore, secrets datastore.SecretStore) {
+	APIGroup := app.Group("/api")
+
+	addCloneSessionRoute(APIGroup, store, templates, secrets)
+
+	addSaveTemplateRoute(APIGroup, templates)
+
+	addGetTemplatesRoute(APIGroup, templates)
+
+	addGetTemplateRoute(APIGroup, templates)
+
+	addRemoveTemplateRoute(APIGroup, templates)
+}
+
+// Adding the clone session route
+// @Summary Clone a session
+// @Description Creates a new session with the users and settings of an existing session in a single step, tasks without a final estimate are copied if requested. If secrets are enabled, the response contains new secrets for the users of the clone.
+// @Tags session
+// @Accept  json
+// @Produce  json
+// @Param token path string true "Session Token"
+// @Param clone body CloneSession false "Clone options"
+// @Success 200 {object} SessionResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 429 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /sessions/{token}/clone [post]
+func addCloneSessionRoute(api fiber.Router, store datastore.DataStore, templates datastore.TemplateStore, secrets datastore.SecretStore) {
+	api.Post("/sessions/:token/clone", func(c *fiber.Ctx) error {
+		cs := new(CloneSession)
+
+		if len(c.Body()) > 0 {
+			if err := c.BodyParser(cs); err != nil {
+				return sendError(c, 400, err)
+			}
+		}
+
+		t, err := templates.CloneSession(c.Params("token"), cs.Tasks)
+
+		if err != nil {
+			return sendError(c, storeErrorStatus(err), err)
+		}
+
+		s, err := issueSecrets(store, secrets, t)
+
+		if err != nil {
+			return sendError(c, 500, err)
+		}
+
+		data := SessionResponse{
+			Message: "ok",
+			Route:   "/sessions/" + t,
+			Secrets: s,
+		}
+		return c.Status(200).JSON(data)
+	})
+}
+
+// Adding the save template route
+// @Summary Save a session template
+// @Description Saves a named template with the users, tasks and settings new sessions start with, replacing an existing template with the same name
+// @Tags template
+// @Accept  json
+// @Produce  json
+// @Param template body SessionTemplate true "Template"
+// @Success 200 {object} GeneralResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 429 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /templates [post]
+func addSaveTemplateRoute(api fiber.Router, templates datastore.TemplateStore) {
+	api.Post("/templates", func(c *fiber.Ctx) error {
+		st := new(SessionTemplate)
+
+		if err := c.BodyParser(st); err != nil {
+			return sendError(c, 400, err)
+		}
+
+		tasks := []datastore.Task{}
+		for _, t := range st.Tasks {
+			tasks = append(tasks, datastore.Task{ID: t.ID, Summary: t.Summary})
+		}
+
+		settings, err := fromSettings(st.Settings)
+		if err != nil {
+			return sendError(c, 400, err)
+		}
+
+		err = templates.SaveTemplate(datastore.Template{
+			Name:     st.Name,
+			Users:    st.Users,
+			Tasks:    tasks,
+			Settings: settings,
+		})
+
+		if err != nil {
+			return sendError(c, storeErrorStatus(err), err)
+		}
+
+		data := GeneralResponse{
+			Message: "ok",
+			Route:   "/templates/" + st.Name,
+		}
+		return c.Status(200).JSON(data)
+	})
+}
+
+// Adding the get templates route
+// @Summary Get all session templates
+// @Description Gets all saved session templates
+// @Tags template
+// @Produce  json
+// @Success 200 {object} TemplatesResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /templates [get]
+func addGetTemplatesRoute(api fiber.Router, templates datastore.TemplateStore) {
+	api.Get("/templates", func(c *fiber.Ctx) error {
+		ts, err := templates.GetTemplates()
+
+		if err != nil {
+			return sendError(c, 500, err)
+		}
+
+		res := []SessionTemplate{}
+		for _, t := range ts {
+			res = append(res, toSessionTemplate(t))
+		}
+
+		data := TemplatesResponse{
+			Message:   "ok",
+			Templates: res,
+		}
+		return c.Status(200).JSON(data)
+	})
+}
+
+// Adding the get template route
+// @Summary Get a session template
+// @Description Gets the session template with the specified name
+// @Tags template
+// @Produce  json
+// @Param name path string true "Template Name"
+// @Success 200 {object} TemplateResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /templates/{name} [get]
+func addGetTemplateRoute(api fiber.Router, templates datastore.TemplateStore) {
+	api.Get("/templates/:name", func(c *fiber.Ctx) error {
+		t, err := templates.GetTemplate(c.Params("name"))
+
+		if err != nil {
+			return sendError(c, 500, err)
+		}
+
+		data := TemplateResponse{
+			Message:  "ok",
+			Template: toSessionTemplate(t),
+		}
+		return c.Status(200).JSON(data)
+	})
+}
+
+// Adding the remove template route
+// @Summary Remove a session template
+// @Description Removes the session template with the specified name, sessions created from it are kept
+// @Tags template
+// @Produce  json
+// @Param name path string true "Template Name"
+// @Success 200 {object} GeneralResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /templates/{name} [delete]
+func addRemoveTemplateRoute(api fiber.Router, templates datastore.TemplateStore) {
+	api.Delete("/templates/:name", func(c *fiber.Ctx) error {
+		if err := templates.RemoveTemplate(c.Params("name")); err != nil {
+			return sendError(c, 500, err)
+		}
+
+		data := GeneralResponse{
+			Message: "ok",
+		}
+		return c.Status(200).JSON(data)
+	})
+}
+
+func toSessionTemplate(t datastore.Template) SessionTemplate {
+	tasks := []Task{}
+	for _, task := range t.Tasks {
+		tasks = append(tasks, Task{ID: task.ID, Summary: task.Summary})
+	}
+
+	users := t.Users
+	if users == nil {
+		users = []string{}
+	}
+
+	return SessionTemplate{
+		Name:     t.Name,
+		Users:    users,
+		Tasks:    tasks,
+		Settings: toSettings(t.Settings),
+	}
+}
@@ -0,0 +1,170 @@
+package apiserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/haro87/dokerb/pkg/datastore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+	"time"
+)
+
+func TestCreateSessionFromTemplate(t *testing.T) {
+	m := new(datastore.MockDatastore)
+	ts := new(datastore.MockTemplateStore)
+	ts.On("CreateSessionFromTemplate", "sprint").Return("abcd", nil)
+
+	app := NewServer(&Config{}, m, nil, WithTemplates(ts)).Start()
+
+	res, err := app.Test(httptestRequest("POST", "/api/sessions", `{"template":"sprint"}`), -1)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, res.StatusCode)
+
+	var gr SessionResponse
+	assert.NoError(t, json.NewDecoder(res.Body).Decode(&gr))
+	assert.Equal(t, "/sessions/abcd", gr.Route)
+	m.AssertNotCalled(t, "CreateSession")
+}
+
+func TestCreateSessionFromTemplateFails(t *testing.T) {
+	ts := new(datastore.MockTemplateStore)
+	ts.On("CreateSessionFromTemplate", "sprint").Return("", fmt.Errorf("Template with name: sprint does not exist"))
+
+	app := NewServer(&Config{}, new(datastore.MockDatastore), nil, WithTemplates(ts)).Start()
+
+	res, err := app.Test(httptestRequest("POST", "/api/sessions", `{"template":"sprint"}`), -1)
+	assert.NoError(t, err)
+	assertErrorResponse(t, res, 500, "Template with name: sprint does not exist")
+}
+
+func TestCreateSessionFromTemplateDisabled(t *testing.T) {
+	app := NewServer(&Config{}, new(datastore.MockDatastore), nil).Start()
+
+	res, err := app.Test(httptestRequest("POST", "/api/sessions", `{"template":"sprint"}`), -1)
+	assert.NoError(t, err)
+	assertErrorResponse(t, res, 400, "Session templates are not enabled")
+}
+
+func TestCloneSession(t *testing.T) {
+	ts := new(datastore.MockTemplateStore)
+	ts.On("CloneSession", "12345", true).Return("abcd", nil)
+	ts.On("CloneSession", "12345", false).Return("efgh", nil)
+
+	app := NewServer(&Config{}, new(datastore.MockDatastore), nil, WithTemplates(ts)).Start()
+
+	res, err := app.Test(httptestRequest("POST", "/api/sessions/12345/clone", `{"tasks":true}`), -1)
+	assert.NoError(t, err)
+	var gr SessionResponse
+	assert.NoError(t, json.NewDecoder(res.Body).Decode(&gr))
+	assert.Equal(t, "/sessions/abcd", gr.Route)
+
+	res, err = app.Test(httptestRequest("POST", "/api/sessions/12345/clone", ""), -1)
+	assert.NoError(t, err)
+	assert.NoError(t, json.NewDecoder(res.Body).Decode(&gr))
+	assert.Equal(t, "/sessions/efgh", gr.Route)
+}
+
+func TestCloneSessionFails(t *testing.T) {
+	ts := new(datastore.MockTemplateStore)
+	ts.On("CloneSession", "12345", false).Return("", fmt.Errorf("Specified session does not exist"))
+
+	app := NewServer(&Config{}, new(datastore.MockDatastore), nil, WithTemplates(ts)).Start()
+
+	res, err := app.Test(httptestRequest("POST", "/api/sessions/12345/clone", ""), -1)
+	assert.NoError(t, err)
+	assertErrorResponse(t, res, 500, "Specified session does not exist")
+}
+
+func TestCloneSessionIsRateLimited(t *testing.T) {
+	ts := new(datastore.MockTemplateStore)
+	ts.On("CloneSession", "12345", false).Return("abcd", nil)
+	m := new(datastore.MockDatastore)
+	m.On("CreateSession").Return("efgh", nil)
+
+	config := &Config{Limits: limits{Sessions: rateLimit{PerIP: 1, Window: time.Minute}}}
+	app := NewServer(config, m, nil, WithTemplates(ts)).Start()
+
+	res, err := app.Test(httptestRequest("POST", "/api/sessions/12345/clone", ""), -1)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, res.StatusCode)
+
+	res, err = app.Test(httptestRequest("POST", "/api/sessions/12345/clone", ""), -1)
+	assert.NoError(t, err)
+	assertErrorResponse(t, res, 429, "Too many requests, please retry later")
+}
+
+func TestSaveTemplate(t *testing.T) {
+	ts := new(datastore.MockTemplateStore)
+	ts.On("SaveTemplate", datastore.Template{
+		Name:     "sprint",
+		Users:    []string{"Tigger", "Pooh"},
+		Tasks:    []datastore.Task{{ID: "TEST01", Summary: "Standup notes"}},
+		Settings: datastore.Settings{Anonymity: "pseudonyms", Moderator: "Pooh"},
+	}).Return(nil)
+
+	app := NewServer(&Config{}, new(datastore.MockDatastore), nil, WithTemplates(ts)).Start()
+
+	res, err := app.Test(httptestRequest("POST", "/api/templates",
+		`{"name":"sprint","users":["Tigger","Pooh"],"tasks":[{"id":"TEST01","summary":"Standup notes"}],"settings":{"anonymity":"pseudonyms","moderator":"Pooh"}}`), -1)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, res.StatusCode)
+
+	var gr SessionResponse
+	assert.NoError(t, json.NewDecoder(res.Body).Decode(&gr))
+	assert.Equal(t, "/templates/sprint", gr.Route)
+}
+
+func TestSaveTemplateExceedingLimits(t *testing.T) {
+	ts := new(datastore.MockTemplateStore)
+
+	config := &Config{Limits: limits{MaxUsers: 1}}
+	app := NewServer(config, new(datastore.MockDatastore), nil, WithTemplates(ts)).Start()
+
+	res, err := app.Test(httptestRequest("POST", "/api/templates", `{"name":"sprint","users":["Tigger","Pooh"]}`), -1)
+	assert.NoError(t, err)
+	assertErrorResponse(t, res, 429, "Limit exceeded: at most 1 users per session")
+	ts.AssertNotCalled(t, "SaveTemplate", mock.Anything)
+}
+
+func TestGetTemplates(t *testing.T) {
+	ts := new(datastore.MockTemplateStore)
+	ts.On("GetTemplates").Return([]datastore.Template{
+		{Name: "sprint", Users: []string{"Tigger"}, Tasks: []datastore.Task{{ID: "TEST01"}}, Settings: datastore.Settings{Anonymity: "off", Salt: "s4lt"}},
+		{Name: "empty", Settings: datastore.Settings{Anonymity: "off"}},
+	}, nil)
+	ts.On("GetTemplate", "sprint").Return(datastore.Template{Name: "sprint", Settings: datastore.Settings{Anonymity: "hidden"}}, nil)
+
+	app := NewServer(&Config{}, new(datastore.MockDatastore), nil, WithTemplates(ts)).Start()
+
+	res, err := app.Test(httptestRequest("GET", "/api/templates", ""), -1)
+	assert.NoError(t, err)
+	var tr TemplatesResponse
+	assert.NoError(t, json.NewDecoder(res.Body).Decode(&tr))
+	assert.Equal(t, []SessionTemplate{
+		{Name: "sprint", Users: []string{"Tigger"}, Tasks: []Task{{ID: "TEST01"}}, Settings: Settings{Anonymity: "off", Cards: []Card{}}},
+		{Name: "empty", Users: []string{}, Tasks: []Task{}, Settings: Settings{Anonymity: "off", Cards: []Card{}}},
+	}, tr.Templates)
+
+	res, err = app.Test(httptestRequest("GET", "/api/templates/sprint", ""), -1)
+	assert.NoError(t, err)
+	var r TemplateResponse
+	assert.NoError(t, json.NewDecoder(res.Body).Decode(&r))
+	assert.Equal(t, "hidden", r.Template.Settings.Anonymity)
+}
+
+func TestRemoveTemplate(t *testing.T) {
+	ts := new(datastore.MockTemplateStore)
+	ts.On("RemoveTemplate", "sprint").Return(nil)
+	ts.On("RemoveTemplate", "other").Return(fmt.Errorf("Template with name: other does not exist"))
+
+	app := NewServer(&Config{}, new(datastore.MockDatastore), nil, WithTemplates(ts)).Start()
+
+	res, err := app.Test(httptestRequest("DELETE", "/api/templates/sprint", ""), -1)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, res.StatusCode)
+
+	res, err = app.Test(httptestRequest("DELETE", "/api/templates/other", ""), -1)
+	assert.NoError(t, err)
+	assertErrorResponse(t, res, 500, "Template with name: other does not exist")
+}
@@ -0,0 +1,162 @@
+package apiserver
+
+import (
+	"crypto/tls"
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+)
+
+// tlsVersions maps the supported config values to TLS versions
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// CertReloader keeps the server certificate, which can be
+// reloaded from disk without restarting the server
+type CertReloader struct {
+	certFile string
+	keyFile  string
+	mu       sync.RWMutex
+	cert     *tls.Certificate
+}
+
+// NewCertReloader returns a new CertReloader with the certificate
+// loaded from the provided files
+func NewCertReloader(certFile, keyFile string) (*CertReloader, error) {
+	r := &CertReloader{certFile: certFile, keyFile: keyFile}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Reload loads the certificate from disk again, the current
+// certificate is kept if loading fails
+func (r *CertReloader) Reload() error {
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("Unable to load certificate: %s", err.Error())
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cert = &cert
+	return nil
+}
+
+// GetCertificate returns the current certificate, it is used
+// for every new TLS handshake, so existing connections are
+// not affected by a reload
+func (r *CertReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert, nil
+}
+
+// NewTLSConfig returns the TLS config for serving the provided
+// certificates with the configured minimum TLS version
+func NewTLSConfig(config *Config, certs *CertReloader) *tls.Config {
+	return &tls.Config{
+		MinVersion:     tlsVersions[config.Server.TLS.MinVersion],
+		GetCertificate: certs.GetCertificate,
+	}
+}
+
+// Listen method for serving the app on the configured address,
+// using HTTPS if certificates are provided
+func (s *APIServer) Listen(app *fiber.App, certs *CertReloader) error {
+	ln, err := s.newListener(certs)
+	if err != nil {
+		return err
+	}
+	return app.Listener(ln)
+}
+
+func (s *APIServer) newListener(certs *CertReloader) (net.Listener, error) {
+	ln, err := net.Listen("tcp", s.config.Server.Host+":"+s.config.Server.Port)
+	if err != nil {
+		return nil, err
+	}
+	if certs == nil {
+		return ln, nil
+	}
+	return tls.NewListener(ln, NewTLSConfig(s.config, certs)), nil
+}
+
+// StartRedirect method for redirecting plain HTTP requests to
+// HTTPS, returns nil if TLS or the redirect is disabled
+func (s *APIServer) StartRedirect() *http.Server {
+	srv := s.newRedirectServer()
+	if srv == nil {
+		return nil
+	}
+
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			s.logger.Error("Redirect server failed", zap.Error(err))
+		}
+	}()
+
+	return srv
+}
+
+func (s *APIServer) newRedirectServer() *http.Server {
+	if !s.config.Server.TLS.Enabled() || !s.config.Server.TLS.Redirect {
+		return nil
+	}
+
+	return &http.Server{
+		Addr:    s.config.Server.Host + ":" + s.config.Server.TLS.RedirectPort,
+		Handler: http.HandlerFunc(s.redirectToHTTPS),
+	}
+}
+
+func (s *APIServer) redirectToHTTPS(w http.ResponseWriter, r *http.Request) {
+	host := r.Host
+	if h, _, err := net.SplitHostPort(r.Host); err == nil {
+		host = h
+	}
+	if s.config.Server.Port != "443" {
+		host = net.JoinHostPort(host, s.config.Server.Port)
+	}
+	http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), http.StatusMovedPermanently)
+}
+
+// Enabled reports whether the server should serve HTTPS
+func (t serverTLS) Enabled() bool {
+	return t.CertFile != "" || t.KeyFile != ""
+}
+
+func (t serverTLS) validate(port string) error {
+	if !t.Enabled() {
+		return nil
+	}
+	if t.CertFile == "" || t.KeyFile == "" {
+		return fmt.Errorf("server.tls.cert_file and server.tls.key_file must both be set")
+	}
+	if _, ok := tlsVersions[t.MinVersion]; !ok {
+		return fmt.Errorf("server.tls.min_version must be one of 1.0, 1.1, 1.2 or 1.3, provided: '%s'", t.MinVersion)
+	}
+	if t.Redirect {
+		if err := validatePort("server.tls.redirect_port", t.RedirectPort); err != nil {
+			return err
+		}
+		if t.RedirectPort == port {
+			return fmt.Errorf("server.tls.redirect_port must differ from server.port")
+		}
+	}
+	for _, f := range [][2]string{{"cert_file", t.CertFile}, {"key_file", t.KeyFile}} {
+		if s, err := os.Stat(f[1]); err != nil || s.IsDir() {
+			return fmt.Errorf("server.tls.%s '%s' is not an existing file", f[0], f[1])
+		}
+	}
+	return nil
+}
@@ -0,0 +1,198 @@
+package apiserver
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"github.com/haro87/dokerb/pkg/datastore"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeSelfSignedCert writes a new self-signed certificate for
+// 127.0.0.1 with the provided serial number to the directory
+func writeSelfSignedCert(t *testing.T, dir string, serial int64) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{Organization: []string{"dokerb test"}},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.NoError(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	assert.NoError(t, err)
+
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	err = ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600)
+	assert.NoError(t, err)
+	err = ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600)
+	assert.NoError(t, err)
+
+	return certFile, keyFile
+}
+
+func setupTestCaseForCerts(t *testing.T) (string, func(t *testing.T)) {
+	dir, err := ioutil.TempDir("", "dokerb-tls")
+	assert.NoError(t, err)
+	return dir, func(t *testing.T) {
+		os.RemoveAll(dir)
+	}
+}
+
+func servedSerial(t *testing.T, certs *CertReloader) int64 {
+	cert, err := certs.GetCertificate(nil)
+	assert.NoError(t, err)
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	assert.NoError(t, err)
+	return leaf.SerialNumber.Int64()
+}
+
+func TestCertReloaderReload(t *testing.T) {
+	dir, teardown := setupTestCaseForCerts(t)
+	defer teardown(t)
+
+	certFile, keyFile := writeSelfSignedCert(t, dir, 1)
+	certs, err := NewCertReloader(certFile, keyFile)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), servedSerial(t, certs))
+
+	writeSelfSignedCert(t, dir, 2)
+	assert.NoError(t, certs.Reload())
+	assert.Equal(t, int64(2), servedSerial(t, certs))
+}
+
+func TestCertReloaderKeepsCertificateOnFailedReload(t *testing.T) {
+	dir, teardown := setupTestCaseForCerts(t)
+	defer teardown(t)
+
+	certFile, keyFile := writeSelfSignedCert(t, dir, 1)
+	certs, err := NewCertReloader(certFile, keyFile)
+	assert.NoError(t, err)
+
+	assert.NoError(t, ioutil.WriteFile(keyFile, []byte("broken"), 0600))
+	err = certs.Reload()
+	assert.Contains(t, err.Error(), "Unable to load certificate")
+	assert.Equal(t, int64(1), servedSerial(t, certs))
+}
+
+func TestNewCertReloaderFails(t *testing.T) {
+	certs, err := NewCertReloader("does-not-exist.pem", "does-not-exist.key")
+	assert.Nil(t, certs)
+	assert.Equal(t, "Unable to load certificate: open does-not-exist.pem: no such file or directory", err.Error())
+}
+
+func TestListenServesHTTPS(t *testing.T) {
+	dir, teardown := setupTestCaseForCerts(t)
+	defer teardown(t)
+
+	certFile, keyFile := writeSelfSignedCert(t, dir, 1)
+	certs, err := NewCertReloader(certFile, keyFile)
+	assert.NoError(t, err)
+
+	m := new(datastore.MockDatastore)
+	m.On("Ping").Return(nil)
+
+	s := NewServer(&Config{
+		Server: server{Host: "127.0.0.1", Port: "0", TLS: serverTLS{MinVersion: "1.2"}},
+	}, m, nil)
+	app := s.Start()
+	ln, err := s.newListener(certs)
+	assert.NoError(t, err)
+	go app.Listener(ln)
+
+	transport := &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}
+	defer func() {
+		transport.CloseIdleConnections()
+		app.Shutdown()
+	}()
+	client := &http.Client{Transport: transport}
+
+	res, err := client.Get("https://" + ln.Addr().String() + "/readyz")
+	assert.NoError(t, err)
+	assert.Equal(t, 200, res.StatusCode)
+	res.Body.Close()
+
+	// Certificate reloads apply to new handshakes only
+	writeSelfSignedCert(t, dir, 2)
+	assert.NoError(t, certs.Reload())
+	conn, err := tls.Dial("tcp", ln.Addr().String(), &tls.Config{InsecureSkipVerify: true})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), conn.ConnectionState().PeerCertificates[0].SerialNumber.Int64())
+	conn.Close()
+
+	// Clients below the minimum TLS version are rejected
+	_, err = tls.Dial("tcp", ln.Addr().String(), &tls.Config{
+		InsecureSkipVerify: true,
+		MaxVersion:         tls.VersionTLS11,
+	})
+	assert.Error(t, err)
+}
+
+func TestNewRedirectServer(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  serverTLS
+		wantNil bool
+	}{
+		{"tls disabled", serverTLS{Redirect: true, RedirectPort: "8080"}, true},
+		{"redirect disabled", serverTLS{CertFile: "cert.pem", KeyFile: "key.pem"}, true},
+		{"enabled", serverTLS{CertFile: "cert.pem", KeyFile: "key.pem", Redirect: true, RedirectPort: "8080"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewServer(&Config{
+				Server: server{Host: "127.0.0.1", Port: "8443", TLS: tt.config},
+			}, new(datastore.MockDatastore), nil)
+			srv := s.newRedirectServer()
+			if tt.wantNil {
+				assert.Nil(t, srv)
+				return
+			}
+			assert.Equal(t, "127.0.0.1:8080", srv.Addr)
+		})
+	}
+}
+
+func TestRedirectToHTTPS(t *testing.T) {
+	tests := []struct {
+		name     string
+		port     string
+		target   string
+		expected string
+	}{
+		{"custom port", "8443", "http://example.com:8080/api/sessions?x=1", "https://example.com:8443/api/sessions?x=1"},
+		{"default port", "443", "http://example.com/index.html", "https://example.com/index.html"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewServer(&Config{
+				Server: server{Port: tt.port},
+			}, new(datastore.MockDatastore), nil)
+
+			rec := httptest.NewRecorder()
+			s.redirectToHTTPS(rec, httptest.NewRequest("GET", tt.target, nil))
+			assert.Equal(t, http.StatusMovedPermanently, rec.Code)
+			assert.Equal(t, tt.expected, rec.Header().Get("Location"))
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package apiserver
+
+import (
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"github.com/haro87/dokerb/pkg/datastore"
+	"github.com/haro87/dokerb/pkg/tracker"
+	"net/url"
+	"strings"
+)
+
+// SyncStatusResponse struct
+type SyncStatusResponse struct {
+	Message string               `json:"message" example:"ok" format:"string"`
+	Sync    datastore.SyncStatus `json:"sync" format:"datastore.SyncStatus"`
+}
+
+// WithTracker enables syncing finalized tasks to an issue tracker,
+// the outcome of every sync is kept in the provided store
+func WithTracker(store datastore.SyncStore, syncer tracker.TaskSyncer) Option {
+	return func(s *APIServer) {
+		s.syncs = store
+		s.syncer = syncer
+	}
+}
+
+// trackerRoutes registers the routes for the issue tracker sync
+func trackerRoutes(app *fiber.App, syncs datastore.SyncStore) {
+	APIGroup := app.Group("/api")
+
+	addGetSyncStatusRoute(APIGroup, syncs)
+}
+
+// Adding the get sync status route
+// @Summary Get the sync status of a task
+// @Description Gets the outcome of the latest sync of the task to the issue tracker, which is unsynced if the task was never synced
+// @Tags tracker
+// @Produce  json
+// @Param token path string true "Session Token"
+// @Param id path string true "Task ID"
+// @Success 200 {object} SyncStatusResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /sessions/{token}/tasks/{id}/sync [get]
+func addGetSyncStatusRoute(api fiber.Router, syncs datastore.SyncStore) {
+	api.Get("/sessions/:token/tasks/:id/sync", func(c *fiber.Ctx) error {
+		status, err := syncs.GetSyncStatus(c.Params("token"), c.Params("id"))
+
+		if err != nil {
+			return sendError(c, 500, err)
+		}
+
+		data := SyncStatusResponse{
+			Message: "ok",
+			Sync:    status,
+		}
+		return c.Status(200).JSON(data)
+	})
+}
+
+// RESTConfig returns the config of the REST sink
+func (t issueTracker) RESTConfig() tracker.RESTConfig {
+	return tracker.RESTConfig{
+		Method:     t.Method,
+		URL:        t.URL,
+		Body:       t.Body,
+		AuthHeader: t.AuthHeader,
+		AuthValue:  t.AuthValue,
+		Timeout:    t.Timeout,
+	}
+}
+
+func (t issueTracker) validate() error {
+	if !t.Enabled {
+		return nil
+	}
+
+	// Check the host of the URL up to the first template action
+	u, err := url.Parse(strings.SplitN(t.URL, "{{", 2)[0])
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("tracker.url must be an absolute http or https URL template")
+	}
+	if !contains([]string{"POST", "PUT", "PATCH"}, t.Method) {
+		return fmt.Errorf("tracker.method must be one of POST, PUT or PATCH, provided: '%s'", t.Method)
+	}
+	if t.Timeout <= 0 || t.QueueSize < 1 {
+		return fmt.Errorf("tracker.timeout must be positive and tracker.queue_size must be at least 1")
+	}
+	if _, err := tracker.NewRESTSink(t.RESTConfig()); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package apiserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/haro87/dokerb/pkg/datastore"
+	"github.com/haro87/dokerb/pkg/tracker"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestGetSyncStatus(t *testing.T) {
+	ss := new(datastore.MockSyncStore)
+	ss.On("GetSyncStatus", "12345", "TEST01").Return(datastore.SyncStatus{TaskID: "TEST01", Status: "synced"}, nil)
+	ss.On("GetSyncStatus", "12345", "TEST02").Return(datastore.SyncStatus{}, fmt.Errorf("Unable to query sync status"))
+
+	app := NewServer(&Config{}, new(datastore.MockDatastore), nil, WithTracker(ss, new(tracker.Syncer))).Start()
+
+	res, err := app.Test(httptestRequest("GET", "/api/sessions/12345/tasks/TEST01/sync", ""), -1)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, res.StatusCode)
+
+	var sr SyncStatusResponse
+	assert.NoError(t, json.NewDecoder(res.Body).Decode(&sr))
+	assert.Equal(t, datastore.SyncStatus{TaskID: "TEST01", Status: "synced"}, sr.Sync)
+
+	res, err = app.Test(httptestRequest("GET", "/api/sessions/12345/tasks/TEST02/sync", ""), -1)
+	assert.NoError(t, err)
+	assertErrorResponse(t, res, 500, "Unable to query sync status")
+}
+
+func TestTrackerRoutesDisabled(t *testing.T) {
+	app := NewServer(&Config{}, new(datastore.MockDatastore), nil).Start()
+
+	res, err := app.Test(httptestRequest("GET", "/api/sessions/12345/tasks/TEST01/sync", ""), -1)
+	assert.NoError(t, err)
+	assert.Equal(t, 404, res.StatusCode)
+}
+
+func TestFinalizingTaskSyncsTracker(t *testing.T) {
+	received := make(chan string, 1)
+	tr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		received <- r.Method + " " + r.URL.Path + " " + string(body)
+	}))
+	defer tr.Close()
+
+	m := new(datastore.MockDatastore)
+	m.On("AddEstimateToTask", "12345", "TEST01", 1.5, 0.2).Return(nil)
+	m.On("GetTasks", "12345").Return([]datastore.Task{{ID: "TEST01", Summary: "Login page"}}, nil)
+	ss := new(datastore.MockSyncStore)
+	ss.On("SetSyncStatus", "12345", mock.Anything).Return(nil)
+
+	config := DefaultConfig().Tracker
+	config.URL = tr.URL + "/issues/{{.ID}}"
+	config.Body = `{"effort":{{.Effort}}}`
+	sink, err := tracker.NewRESTSink(config.RESTConfig())
+	assert.NoError(t, err)
+
+	s := tracker.NewSyncer(ss, sink)
+	s.Start()
+	defer s.Stop()
+
+	app := NewServer(&Config{}, m, nil, WithTracker(ss, s)).Start()
+
+	res, err := app.Test(httptestRequest("PUT", "/api/sessions/12345/tasks/TEST01", `{"effort":1.5,"standarddeviation":0.2}`), -1)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, res.StatusCode)
+
+	select {
+	case r := <-received:
+		assert.Equal(t, `PUT /issues/TEST01 {"effort":1.5}`, r)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Tracker not synced")
+	}
+}
@@ -0,0 +1,117 @@
+package apiserver
+
+import (
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"github.com/haro87/dokerb/pkg/datastore"
+	dbestimate "github.com/haro87/dokerb/pkg/estimate"
+)
+
+// UnitEstimate represents an estimate converted to another unit
+type UnitEstimate struct {
+	Unit              string  `json:"unit" example:"days" format:"string"`
+	Effort            float64 `json:"effort" example:"1.5" format:"float64"`
+	StandardDeviation float64 `json:"standarddeviation" example:"0.2" format:"float64"`
+}
+
+// unitConverter converts efforts from the unit of a session into
+// the unit requested via the unit query parameter
+type unitConverter struct {
+	calendar dbestimate.Calendar
+	from     string
+	to       string
+}
+
+// calendar returns the calendar used for converting between units
+func (u units) calendar() dbestimate.Calendar {
+	return dbestimate.Calendar{HoursPerDay: u.HoursPerDay, DaysPerWeek: u.DaysPerWeek}
+}
+
+// converter returns the converter for the request, which keeps
+// the unit of the session if no unit is requested
+func (u units) converter(c *fiber.Ctx, unit string) (unitConverter, error) {
+	return u.convert(unit, c.Query("unit", unit))
+}
+
+// convert returns the converter between the provided units
+func (u units) convert(from, to string) (unitConverter, error) {
+	uc := unitConverter{calendar: u.calendar(), from: from, to: to}
+	if uc.from == uc.to {
+		return uc, nil
+	}
+	_, err := uc.calendar.ConvertEffort(0, uc.from, uc.to)
+	return uc, err
+}
+
+// conversions returns the estimate in all other units it can be
+// converted to, estimates in points can't be converted
+func (u units) conversions(unit string, effort, standardDeviation float64) []UnitEstimate {
+	res := []UnitEstimate{}
+	cal := u.calendar()
+	for _, to := range dbestimate.Units {
+		if to == unit {
+			continue
+		}
+		e, err := cal.ConvertEffort(effort, unit, to)
+		if err != nil {
+			continue
+		}
+		sd, err := cal.ConvertEffort(standardDeviation, unit, to)
+		if err != nil {
+			continue
+		}
+		res = append(res, UnitEstimate{Unit: to, Effort: e, StandardDeviation: sd})
+	}
+	return res
+}
+
+func (u units) validate() error {
+	if !dbestimate.ValidUnit(u.Default) {
+		return fmt.Errorf("units.default must be one of hours, days, weeks or points, provided: '%s'", u.Default)
+	}
+	if u.HoursPerDay <= 0 || u.HoursPerDay > 24 {
+		return fmt.Errorf("units.hours_per_day must be > 0 and <= 24, provided: %g", u.HoursPerDay)
+	}
+	if u.DaysPerWeek <= 0 || u.DaysPerWeek > 7 {
+		return fmt.Errorf("units.days_per_week must be > 0 and <= 7, provided: %g", u.DaysPerWeek)
+	}
+	return nil
+}
+
+// effort converts a single effort or standard deviation
+func (uc unitConverter) effort(value float64) float64 {
+	if uc.from == uc.to {
+		return value
+	}
+	res, _ := uc.calendar.ConvertEffort(value, uc.from, uc.to)
+	return res
+}
+
+// tasks converts the final estimates of the tasks
+func (uc unitConverter) tasks(tasks []datastore.Task) []datastore.Task {
+	res := make([]datastore.Task, 0, len(tasks))
+	for _, t := range tasks {
+		t.Effort = uc.effort(t.Effort)
+		t.StandardDeviation = uc.effort(t.StandardDeviation)
+		res = append(res, t)
+	}
+	return res
+}
+
+// estimates converts all cases of the estimates
+func (uc unitConverter) estimates(ests []datastore.Estimate) []datastore.Estimate {
+	if uc.from == uc.to {
+		return ests
+	}
+	res := make([]datastore.Estimate, 0, len(ests))
+	for _, e := range ests {
+		d, _ := uc.calendar.ConvertDelphiEstimate(dbestimate.DelphiEstimate{
+			BestCase:   e.BestCase,
+			MostLikely: e.MostLikelyCase,
+			WorstCase:  e.WorstCase,
+		}, uc.from, uc.to)
+		e.BestCase, e.MostLikelyCase, e.WorstCase = d.BestCase, d.MostLikely, d.WorstCase
+		res = append(res, e)
+	}
+	return res
+}
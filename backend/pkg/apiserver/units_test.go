@@ -0,0 +1,117 @@
+package apiserver
+
+import (
+	"encoding/json"
+	"github.com/haro87/dokerb/pkg/datastore"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestConversions(t *testing.T) {
+	u := units{Default: "hours", HoursPerDay: 8, DaysPerWeek: 5}
+
+	assert.Equal(t, []UnitEstimate{
+		{Unit: "days", Effort: 5, StandardDeviation: 0.5},
+		{Unit: "weeks", Effort: 1, StandardDeviation: 0.1},
+	}, u.conversions("hours", 40, 4))
+	assert.Equal(t, []UnitEstimate{
+		{Unit: "hours", Effort: 12, StandardDeviation: 4},
+		{Unit: "weeks", Effort: 0.3, StandardDeviation: 0.1},
+	}, u.conversions("days", 1.5, 0.5))
+	assert.Equal(t, []UnitEstimate{}, u.conversions("points", 5, 1))
+}
+
+func TestGetAverageEstimateInSessionUnit(t *testing.T) {
+	m := new(datastore.MockDatastore)
+	m.On("GetEstimates", "12345").Return([]datastore.Estimate{
+		{TaskID: "TEST01", UserID: "tigger", UserName: "Tigger", BestCase: 1, MostLikelyCase: 2, WorstCase: 3},
+	}, nil)
+	m.On("GetUsers", "12345").Return(testUsers("Tigger"), nil)
+	ss := new(datastore.MockSettingsStore)
+	ss.On("GetSettings", "12345").Return(datastore.Settings{Anonymity: "off", Unit: "days"}, nil)
+
+	config := &Config{Units: units{Default: "hours", HoursPerDay: 8, DaysPerWeek: 5}}
+	app := NewServer(config, m, nil, WithSettings(ss)).Start()
+
+	res, err := app.Test(httptestRequest("GET", "/api/sessions/12345/estimates/TEST01", ""), -1)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, res.StatusCode)
+
+	var ce CalcEstimate
+	assert.NoError(t, json.NewDecoder(res.Body).Decode(&ce))
+	assert.Equal(t, "days", ce.Unit)
+	assert.Equal(t, 2.0, ce.Estimate.Effort)
+	assert.Len(t, ce.Conversions, 2)
+	assert.Equal(t, "hours", ce.Conversions[0].Unit)
+	assert.Equal(t, 16.0, ce.Conversions[0].Effort)
+}
+
+func TestGetTasksUsesDefaultUnit(t *testing.T) {
+	m := new(datastore.MockDatastore)
+	m.On("GetTasks", "12345").Return([]datastore.Task{{ID: "TEST01"}}, nil)
+
+	config := &Config{Units: units{Default: "points", HoursPerDay: 8, DaysPerWeek: 5}}
+	app := NewServer(config, m, nil).Start()
+
+	res, err := app.Test(httptestRequest("GET", "/api/sessions/12345/tasks", ""), -1)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, res.StatusCode)
+
+	var tr TaskResponse
+	assert.NoError(t, json.NewDecoder(res.Body).Decode(&tr))
+	assert.Equal(t, "points", tr.Unit)
+}
+
+func TestGetInRequestedUnit(t *testing.T) {
+	m := new(datastore.MockDatastore)
+	m.On("GetTasks", "12345").Return([]datastore.Task{{ID: "TEST01", Effort: 20, StandardDeviation: 4}}, nil)
+	m.On("GetEstimates", "12345").Return([]datastore.Estimate{
+		{TaskID: "TEST01", UserID: "tigger", UserName: "Tigger", BestCase: 8, MostLikelyCase: 16, WorstCase: 36},
+	}, nil)
+	m.On("GetUsers", "12345").Return(testUsers("Tigger"), nil)
+
+	config := &Config{Units: units{Default: "hours", HoursPerDay: 8, DaysPerWeek: 5}}
+	app := NewServer(config, m, nil).Start()
+
+	res, err := app.Test(httptestRequest("GET", "/api/sessions/12345/tasks?unit=weeks", ""), -1)
+	assert.NoError(t, err)
+	var tr TaskResponse
+	assert.NoError(t, json.NewDecoder(res.Body).Decode(&tr))
+	assert.Equal(t, "weeks", tr.Unit)
+	assert.Equal(t, []datastore.Task{{ID: "TEST01", Effort: 0.5, StandardDeviation: 0.1}}, tr.Tasks)
+
+	res, err = app.Test(httptestRequest("GET", "/api/sessions/12345/estimates?unit=days", ""), -1)
+	assert.NoError(t, err)
+	var er PerUserEstimateResponse
+	assert.NoError(t, json.NewDecoder(res.Body).Decode(&er))
+	assert.Equal(t, "days", er.Unit)
+	assert.Equal(t, []datastore.Estimate{
+		{TaskID: "TEST01", UserID: "tigger", UserName: "Tigger", BestCase: 1, MostLikelyCase: 2, WorstCase: 4.5},
+	}, er.Estimates)
+
+	res, err = app.Test(httptestRequest("GET", "/api/sessions/12345/estimates/TEST01?unit=days", ""), -1)
+	assert.NoError(t, err)
+	var ce CalcEstimate
+	assert.NoError(t, json.NewDecoder(res.Body).Decode(&ce))
+	assert.Equal(t, "days", ce.Unit)
+	assert.Equal(t, 2.25, ce.Estimate.Effort)
+	assert.Equal(t, 0.5833333333333334, ce.Estimate.StandardDeviation)
+}
+
+func TestGetInRequestedUnitFails(t *testing.T) {
+	m := new(datastore.MockDatastore)
+	m.On("GetTasks", "12345").Return([]datastore.Task{}, nil)
+	ss := new(datastore.MockSettingsStore)
+	ss.On("GetSettings", "12345").Return(datastore.Settings{Anonymity: "off", Unit: "points"}, nil)
+
+	config := &Config{Units: units{Default: "hours", HoursPerDay: 8, DaysPerWeek: 5}}
+	app := NewServer(config, m, nil, WithSettings(ss)).Start()
+
+	res, err := app.Test(httptestRequest("GET", "/api/sessions/12345/tasks?unit=hours", ""), -1)
+	assert.NoError(t, err)
+	assertErrorResponse(t, res, 400, "Unable to convert points to hours")
+
+	res, err = app.Test(httptestRequest("GET", "/api/sessions/12345/tasks?unit=months", ""), -1)
+	assert.NoError(t, err)
+	assertErrorResponse(t, res, 400, "Unit must be one of hours, days, weeks or points")
+}
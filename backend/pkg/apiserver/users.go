@@ -0,0 +1,78 @@
+package apiserver
+
+import (
+	"fmt"
+	"github.com/haro87/dokerb/pkg/datastore"
+)
+
+// lookupUser returns the user referenced by either the ID or the
+// name, names are only accepted as long as they are unique within
+// the session
+func lookupUser(users []datastore.User, ref string) (datastore.User, error) {
+	var found []datastore.User
+	for _, u := range users {
+		if u.ID == ref {
+			return u, nil
+		}
+		if u.Name == ref {
+			found = append(found, u)
+		}
+	}
+
+	switch len(found) {
+	case 0:
+		return datastore.User{}, fmt.Errorf("User: %s is not part of session", ref)
+	case 1:
+		return found[0], nil
+	default:
+		return datastore.User{}, fmt.Errorf("User name: %s is ambiguous, use the user ID", ref)
+	}
+}
+
+// estimators returns the users expected to provide estimates,
+// which excludes observers
+func estimators(users []datastore.User) []datastore.User {
+	res := []datastore.User{}
+	for _, u := range users {
+		if u.Role != datastore.RoleObserver {
+			res = append(res, u)
+		}
+	}
+	return res
+}
+
+// userIDs returns the IDs of the users in the same order
+func userIDs(users []datastore.User) []string {
+	res := make([]string, 0, len(users))
+	for _, u := range users {
+		res = append(res, u.ID)
+	}
+	return res
+}
+
+func toUser(u datastore.User) User {
+	return User{
+		ID:     u.ID,
+		Name:   u.Name,
+		Avatar: u.AvatarURL,
+		Email:  u.Email,
+		Role:   u.Role,
+	}
+}
+
+func toUsers(users []datastore.User) []User {
+	res := make([]User, 0, len(users))
+	for _, u := range users {
+		res = append(res, toUser(u))
+	}
+	return res
+}
+
+func fromUser(u User) datastore.User {
+	return datastore.User{
+		Name:      u.Name,
+		AvatarURL: u.Avatar,
+		Email:     u.Email,
+		Role:      u.Role,
+	}
+}
@@ -0,0 +1,194 @@
+package apiserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/haro87/dokerb/pkg/datastore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"strings"
+	"testing"
+)
+
+// testUsers returns estimators with the lowercase name as ID
+func testUsers(names ...string) []datastore.User {
+	users := []datastore.User{}
+	for _, n := range names {
+		users = append(users, datastore.User{ID: strings.ToLower(n), Name: n, Role: datastore.RoleEstimator})
+	}
+	return users
+}
+
+func TestLookupUser(t *testing.T) {
+	users := append(testUsers("Tigger", "Pooh"), datastore.User{ID: "pooh2", Name: "Pooh"})
+
+	tests := []struct {
+		name string
+		ref  string
+		id   string
+		err  string
+	}{
+		{"by ID", "pooh2", "pooh2", ""},
+		{"by unique name", "Tigger", "tigger", ""},
+		{"ambiguous name", "Pooh", "", "User name: Pooh is ambiguous, use the user ID"},
+		{"unknown user", "Rabbit", "", "User: Rabbit is not part of session"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, err := lookupUser(users, tt.ref)
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.id, u.ID)
+		})
+	}
+}
+
+func TestEstimators(t *testing.T) {
+	users := append(testUsers("Tigger", "Rabbit"), datastore.User{ID: "pooh", Name: "Pooh", Role: datastore.RoleObserver})
+	assert.Equal(t, testUsers("Tigger", "Rabbit"), estimators(users))
+	assert.Equal(t, []string{"tigger", "rabbit", "pooh"}, userIDs(users))
+}
+
+func TestJoinSessionAsObserver(t *testing.T) {
+	m := new(datastore.MockDatastore)
+	m.On("JoinSession", "12345", datastore.User{Name: "Pooh", Role: datastore.RoleObserver}).Return("pooh", nil)
+	m.On("GetUsers", "12345").Return([]datastore.User{{ID: "pooh", Name: "Pooh", Role: datastore.RoleObserver}}, nil)
+	app := NewServer(&Config{}, m, nil).Start()
+
+	res, err := app.Test(httptestRequest("POST", "/api/sessions/12345/users", `{"name":"Pooh","role":"observer"}`), -1)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, res.StatusCode)
+	m.AssertCalled(t, "JoinSession", "12345", datastore.User{Name: "Pooh", Role: datastore.RoleObserver})
+}
+
+func TestGetUsersReturnsRoles(t *testing.T) {
+	m := new(datastore.MockDatastore)
+	m.On("GetUsers", "12345").Return(append(testUsers("Tigger"), datastore.User{ID: "pooh", Name: "Pooh", Role: datastore.RoleObserver}), nil)
+	app := NewServer(&Config{}, m, nil).Start()
+
+	res, err := app.Test(httptestRequest("GET", "/api/sessions/12345/users", ""), -1)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, res.StatusCode)
+
+	var ur SessionUsersResponse
+	assert.NoError(t, json.NewDecoder(res.Body).Decode(&ur))
+	assert.Equal(t, SessionUsersResponse{
+		Message: "ok",
+		Users: []User{
+			{ID: "tigger", Name: "Tigger", Role: "estimator"},
+			{ID: "pooh", Name: "Pooh", Role: "observer"},
+		},
+	}, ur)
+}
+
+func TestJoinSessionWithProfile(t *testing.T) {
+	m := new(datastore.MockDatastore)
+	m.On("JoinSession", "12345", datastore.User{
+		Name:      "Pooh",
+		AvatarURL: "https://example.com/pooh.png",
+		Email:     "pooh@example.com",
+		Role:      "observer",
+	}).Return("3f2a9c1e7b4d8e60", nil)
+
+	app := NewServer(&Config{}, m, nil).Start()
+
+	res, err := app.Test(httptestRequest("POST", "/api/sessions/12345/users",
+		`{"name":"Pooh","avatar":"https://example.com/pooh.png","email":"pooh@example.com","role":"observer"}`), -1)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, res.StatusCode)
+
+	var gr GeneralResponse
+	assert.NoError(t, json.NewDecoder(res.Body).Decode(&gr))
+	assert.Equal(t, "/sessions/12345/users/3f2a9c1e7b4d8e60", gr.Route)
+}
+
+func TestJoinSessionFailsDueToInvalidRole(t *testing.T) {
+	m := new(datastore.MockDatastore)
+	m.On("JoinSession", "12345", datastore.User{Name: "Pooh", Role: "moderator"}).Return("", fmt.Errorf("Role must be one of estimator or observer"))
+
+	app := NewServer(&Config{}, m, nil).Start()
+
+	res, err := app.Test(httptestRequest("POST", "/api/sessions/12345/users", `{"name":"Pooh","role":"moderator"}`), -1)
+	assert.NoError(t, err)
+	assertErrorResponse(t, res, 500, "Role must be one of estimator or observer")
+}
+
+func TestUpdateUser(t *testing.T) {
+	m := new(datastore.MockDatastore)
+	m.On("UpdateUser", "12345", datastore.User{ID: "tigger", Name: "Tiggr", Role: "observer"}).Return(nil)
+	m.On("UpdateUser", "12345", datastore.User{ID: "ghost", Name: "Ghost"}).Return(fmt.Errorf("User: ghost is not part of session"))
+
+	app := NewServer(&Config{}, m, nil).Start()
+
+	res, err := app.Test(httptestRequest("PUT", "/api/sessions/12345/users/tigger", `{"name":"Tiggr","role":"observer"}`), -1)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, res.StatusCode)
+
+	var gr GeneralResponse
+	assert.NoError(t, json.NewDecoder(res.Body).Decode(&gr))
+	assert.Equal(t, "/sessions/12345/users/tigger", gr.Route)
+
+	res, err = app.Test(httptestRequest("PUT", "/api/sessions/12345/users/ghost", `{"name":"Ghost"}`), -1)
+	assert.NoError(t, err)
+	assertErrorResponse(t, res, 400, "User: ghost is not part of session")
+}
+
+func TestAddEstimateOfUserWithSharedName(t *testing.T) {
+	m := new(datastore.MockDatastore)
+	m.On("GetUsers", "12345").Return(append(testUsers("Pooh"), datastore.User{ID: "pooh2", Name: "Pooh"}), nil)
+	m.On("AddEstimate", "12345", datastore.Estimate{TaskID: "TEST01", UserID: "pooh2", UserName: "Pooh", BestCase: 1, MostLikelyCase: 2, WorstCase: 3}).Return(nil)
+
+	app := NewServer(&Config{}, m, nil).Start()
+
+	res, err := app.Test(httptestRequest("POST", "/api/sessions/12345/estimates", `{"id":"TEST01","user":"Pooh","b":1,"m":2,"w":3}`), -1)
+	assert.NoError(t, err)
+	assertErrorResponse(t, res, 400, "User name: Pooh is ambiguous, use the user ID")
+	m.AssertNotCalled(t, "AddEstimate", mock.Anything, mock.Anything)
+
+	res, err = app.Test(httptestRequest("POST", "/api/sessions/12345/estimates", `{"id":"TEST01","userid":"pooh2","b":1,"m":2,"w":3}`), -1)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, res.StatusCode)
+}
+
+func TestObserverCantEstimate(t *testing.T) {
+	m := new(datastore.MockDatastore)
+	m.On("GetUsers", "12345").Return([]datastore.User{{ID: "pooh", Name: "Pooh", Role: "observer"}}, nil)
+	m.On("AddEstimate", "12345", mock.Anything).Return(fmt.Errorf("%w: Pooh is an observer", datastore.ErrObserver))
+
+	app := NewServer(&Config{}, m, nil).Start()
+
+	res, err := app.Test(httptestRequest("POST", "/api/sessions/12345/estimates", `{"id":"TEST01","user":"Pooh","b":1,"m":2,"w":3}`), -1)
+	assert.NoError(t, err)
+	assertErrorResponse(t, res, 403, "Observers can't provide estimates: Pooh is an observer")
+}
+
+func TestObserversAreNotExpectedToEstimate(t *testing.T) {
+	m := new(datastore.MockDatastore)
+	m.On("GetUsers", "12345").Return(append(testUsers("Tigger", "Rabbit"), datastore.User{ID: "pooh", Name: "Pooh", Role: "observer"}), nil)
+	m.On("GetEstimates", "12345").Return([]datastore.Estimate{
+		{TaskID: "TEST01", UserID: "tigger", UserName: "Tigger", BestCase: 1, MostLikelyCase: 2, WorstCase: 3},
+	}, nil)
+	m.On("GetTasks", "12345").Return([]datastore.Task{{ID: "TEST01"}}, nil)
+
+	app := NewServer(&Config{}, m, nil).Start()
+
+	res, err := app.Test(httptestRequest("GET", "/api/sessions/12345/estimates/TEST01", ""), -1)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, res.StatusCode)
+
+	var ce CalcEstimate
+	assert.NoError(t, json.NewDecoder(res.Body).Decode(&ce))
+	assert.Equal(t, []string{"Rabbit"}, ce.Users)
+
+	res, err = app.Test(httptestRequest("GET", "/api/sessions/12345/tasks/TEST01/progress", ""), -1)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, res.StatusCode)
+
+	var pr ProgressResponse
+	assert.NoError(t, json.NewDecoder(res.Body).Decode(&pr))
+	assert.Equal(t, 2, pr.Total)
+	assert.Equal(t, 1, pr.Missing)
+}
@@ -0,0 +1,241 @@
+package apiserver
+
+import (
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"github.com/haro87/dokerb/pkg/datastore"
+	"github.com/haro87/dokerb/pkg/webhook"
+	"net/url"
+)
+
+// Webhook struct
+type Webhook struct {
+	URL    string   `json:"url" example:"https://bot.example.com/doker" format:"string"`
+	Secret string   `json:"secret" example:"s3cr3t" format:"string"`
+	Events []string `json:"events" example:"task.finalized,session.removed" format:"[]string"`
+}
+
+// WebhookInfo struct
+type WebhookInfo struct {
+	ID     string   `json:"id" example:"3f2a9c0d1e4b5a6f" format:"string"`
+	URL    string   `json:"url" example:"https://bot.example.com/doker" format:"string"`
+	Events []string `json:"events" example:"task.finalized,session.removed" format:"[]string"`
+}
+
+// WebhooksResponse struct
+type WebhooksResponse struct {
+	Message  string        `json:"message" example:"ok" format:"string"`
+	Webhooks []WebhookInfo `json:"webhooks" format:"[]WebhookInfo"`
+}
+
+// DeliveriesResponse struct, Total is the number of deliveries
+// on all pages
+type DeliveriesResponse struct {
+	Message    string               `json:"message" example:"ok" format:"string"`
+	Total      int                  `json:"total" example:"42" format:"int"`
+	Deliveries []datastore.Delivery `json:"deliveries" format:"[]datastore.Delivery"`
+}
+
+// defaultDeliveriesLimit is the page size of the delivery log,
+// if no limit is requested
+const defaultDeliveriesLimit = 100
+
+// Option configures optional features of the APIServer
+type Option func(s *APIServer)
+
+// WithWebhooks enables webhooks, registered webhooks are kept in the
+// provided store and events are published to the provided publisher
+func WithWebhooks(store datastore.WebhookStore, p webhook.Publisher) Option {
+	return func(s *APIServer) {
+		s.webhooks = store
+		s.publisher = p
+	}
+}
+
+// webhookRoutes registers the routes for managing webhooks, webhooks
+// may only target private addresses if allowed
+func webhookRoutes(app *fiber.App, store datastore.DataStore, hooks datastore.WebhookStore, allowPrivate bool) {
+	APIGroup := app.Group("/api")
+
+	addAddWebhookRoute(APIGroup, store, hooks, allowPrivate)
+
+	addGetWebhooksRoute(APIGroup, hooks)
+
+	addGetWebhookDeliveriesRoute(APIGroup, hooks)
+
+	addRemoveWebhookRoute(APIGroup, hooks)
+}
+
+// Adding the add webhook route
+// @Summary Register a webhook
+// @Description Registers a webhook receiving the specified events of the session as signed JSON, all events are sent if none are specified. Webhooks must not target loopback, private or link-local addresses, unless allowed in the config.
+// @Tags webhook
+// @Accept  json
+// @Produce  json
+// @Param token path string true "Session Token"
+// @Param webhook body Webhook true "Webhook"
+// @Success 200 {object} GeneralResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /sessions/{token}/webhooks [post]
+func addAddWebhookRoute(api fiber.Router, store datastore.DataStore, hooks datastore.WebhookStore, allowPrivate bool) {
+	api.Post("/sessions/:token/webhooks", func(c *fiber.Ctx) error {
+		w := new(Webhook)
+
+		if err := c.BodyParser(w); err != nil {
+			return sendError(c, 400, err)
+		}
+
+		if err := validateWebhook(w, allowPrivate); err != nil {
+			return sendError(c, 400, err)
+		}
+
+		if _, err := store.GetUsers(c.Params("token")); err != nil {
+			return sendError(c, 500, err)
+		}
+
+		id, err := hooks.AddWebhook(c.Params("token"), datastore.Webhook{
+			URL:    w.URL,
+			Secret: w.Secret,
+			Events: w.Events,
+		})
+
+		if err != nil {
+			return sendError(c, storeErrorStatus(err), err)
+		}
+
+		data := GeneralResponse{
+			Message: "ok",
+			Route:   "/sessions/" + c.Params("token") + "/webhooks/" + id,
+		}
+		return c.Status(200).JSON(data)
+	})
+}
+
+// Adding the get webhooks route
+// @Summary Get all webhooks
+// @Description Gets all webhooks registered for the session, without their secrets
+// @Tags webhook
+// @Produce  json
+// @Param token path string true "Session Token"
+// @Success 200 {object} WebhooksResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /sessions/{token}/webhooks [get]
+func addGetWebhooksRoute(api fiber.Router, hooks datastore.WebhookStore) {
+	api.Get("/sessions/:token/webhooks", func(c *fiber.Ctx) error {
+		webhooks, err := hooks.GetWebhooks(c.Params("token"))
+
+		if err != nil {
+			return sendError(c, 500, err)
+		}
+
+		infos := []WebhookInfo{}
+		for _, w := range webhooks {
+			infos = append(infos, WebhookInfo{ID: w.ID, URL: w.URL, Events: w.Events})
+		}
+
+		data := WebhooksResponse{
+			Message:  "ok",
+			Webhooks: infos,
+		}
+		return c.Status(200).JSON(data)
+	})
+}
+
+// Adding the get webhook deliveries route
+// @Summary Get webhook deliveries
+// @Description Gets the delivery log of all webhooks of the session in the order the deliveries happened
+// @Tags webhook
+// @Produce  json
+// @Param token path string true "Session Token"
+// @Param offset query int false "Number of deliveries to skip"
+// @Param limit query int false "Max number of deliveries to return, defaults to 100"
+// @Success 200 {object} DeliveriesResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /sessions/{token}/webhooks/deliveries [get]
+func addGetWebhookDeliveriesRoute(api fiber.Router, hooks datastore.WebhookStore) {
+	api.Get("/sessions/:token/webhooks/deliveries", func(c *fiber.Ctx) error {
+		offset, limit, err := page(c, defaultDeliveriesLimit)
+
+		if err != nil {
+			return sendError(c, 400, err)
+		}
+
+		deliveries, total, err := hooks.GetDeliveries(c.Params("token"), datastore.DeliveryQuery{Offset: offset, Limit: limit})
+
+		if err != nil {
+			return sendError(c, 500, err)
+		}
+
+		data := DeliveriesResponse{
+			Message:    "ok",
+			Total:      total,
+			Deliveries: deliveries,
+		}
+		return c.Status(200).JSON(data)
+	})
+}
+
+// Adding the remove webhook route
+// @Summary Remove a webhook
+// @Description Removes the webhook with the specified ID from the session
+// @Tags webhook
+// @Produce  json
+// @Param token path string true "Session Token"
+// @Param id path string true "Webhook ID"
+// @Success 200 {object} GeneralResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /sessions/{token}/webhooks/{id} [delete]
+func addRemoveWebhookRoute(api fiber.Router, hooks datastore.WebhookStore) {
+	api.Delete("/sessions/:token/webhooks/:id", func(c *fiber.Ctx) error {
+		if err := hooks.RemoveWebhook(c.Params("token"), c.Params("id")); err != nil {
+			return sendError(c, storeErrorStatus(err), err)
+		}
+
+		data := GeneralResponse{
+			Message: "ok",
+		}
+		return c.Status(200).JSON(data)
+	})
+}
+
+func validateWebhook(w *Webhook, allowPrivate bool) error {
+	u, err := url.Parse(w.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("Webhook URL must be an absolute http or https URL")
+	}
+	if !allowPrivate {
+		if err := webhook.CheckHost(u.Hostname()); err != nil {
+			return err
+		}
+	}
+
+	for _, e := range w.Events {
+		if !contains(webhook.Events, e) {
+			return fmt.Errorf("Unknown webhook event: %s", e)
+		}
+	}
+	return nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func (w webhooks) validate() error {
+	if w.Workers < 1 || w.MaxAttempts < 1 || w.QueueSize < 1 {
+		return fmt.Errorf("webhooks.workers, webhooks.max_attempts and webhooks.queue_size must be at least 1")
+	}
+	if w.Backoff < 0 || w.Timeout <= 0 {
+		return fmt.Errorf("webhooks.backoff must not be negative and webhooks.timeout must be positive")
+	}
+	return nil
+}
@@ -0,0 +1,201 @@
+package apiserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/haro87/dokerb/pkg/datastore"
+	"github.com/haro87/dokerb/pkg/webhook"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestAddWebhook(t *testing.T) {
+	m := new(datastore.MockDatastore)
+	h := new(datastore.MockWebhookStore)
+	m.On("GetUsers", "12345").Return([]datastore.User{}, nil)
+	h.On("AddWebhook", "12345", datastore.Webhook{
+		URL:    "https://bot.example.com/doker",
+		Secret: "s3cr3t",
+		Events: []string{"task.finalized"},
+	}).Return("abcd", nil)
+
+	app := NewServer(&Config{}, m, nil, WithWebhooks(h, new(recordingPublisher))).Start()
+
+	res, err := app.Test(httptestRequest("POST", "/api/sessions/12345/webhooks",
+		`{"url":"https://bot.example.com/doker","secret":"s3cr3t","events":["task.finalized"]}`), -1)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, res.StatusCode)
+
+	var gr GeneralResponse
+	assert.NoError(t, json.NewDecoder(res.Body).Decode(&gr))
+	assert.Equal(t, "/sessions/12345/webhooks/abcd", gr.Route)
+}
+
+func TestAddWebhookFails(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		status int
+		reason string
+	}{
+		{"relative url", `{"url":"/hook"}`, 400, "Webhook URL must be an absolute http or https URL"},
+		{"unsupported scheme", `{"url":"ftp://bot.example.com"}`, 400, "Webhook URL must be an absolute http or https URL"},
+		{"loopback address", `{"url":"http://127.0.0.1:8080/hook"}`, 400, "Webhook URL must not target a loopback, private or link-local address"},
+		{"private address", `{"url":"http://10.1.2.3/hook"}`, 400, "Webhook URL must not target a loopback, private or link-local address"},
+		{"metadata address", `{"url":"http://169.254.169.254/latest/meta-data"}`, 400, "Webhook URL must not target a loopback, private or link-local address"},
+		{"localhost", `{"url":"http://localhost:8080/hook"}`, 400, "Webhook URL must not target a loopback, private or link-local address"},
+		{"unknown event", `{"url":"https://bot.example.com","events":["user.joined"]}`, 400, "Unknown webhook event: user.joined"},
+		{"unknown session", `{"url":"https://bot.example.com"}`, 500, "Specified session does not exist"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := new(datastore.MockDatastore)
+			h := new(datastore.MockWebhookStore)
+			m.On("GetUsers", "12345").Return([]datastore.User{}, fmt.Errorf("Specified session does not exist"))
+
+			app := NewServer(&Config{}, m, nil, WithWebhooks(h, new(recordingPublisher))).Start()
+
+			res, err := app.Test(httptestRequest("POST", "/api/sessions/12345/webhooks", tt.body), -1)
+			assert.NoError(t, err)
+			assertErrorResponse(t, res, tt.status, tt.reason)
+			h.AssertNotCalled(t, "AddWebhook", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestGetWebhooksHidesSecrets(t *testing.T) {
+	h := new(datastore.MockWebhookStore)
+	h.On("GetWebhooks", "12345").Return([]datastore.Webhook{
+		{ID: "abcd", URL: "https://bot.example.com/doker", Secret: "s3cr3t", Events: []string{"task.finalized"}},
+	}, nil)
+
+	app := NewServer(&Config{}, new(datastore.MockDatastore), nil, WithWebhooks(h, new(recordingPublisher))).Start()
+
+	res, err := app.Test(httptestRequest("GET", "/api/sessions/12345/webhooks", ""), -1)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, res.StatusCode)
+
+	body, _ := ioutil.ReadAll(res.Body)
+	assert.NotContains(t, string(body), "s3cr3t")
+
+	var wr WebhooksResponse
+	assert.NoError(t, json.Unmarshal(body, &wr))
+	assert.Equal(t, []WebhookInfo{{ID: "abcd", URL: "https://bot.example.com/doker", Events: []string{"task.finalized"}}}, wr.Webhooks)
+}
+
+func TestGetWebhookDeliveries(t *testing.T) {
+	h := new(datastore.MockWebhookStore)
+	h.On("GetDeliveries", "12345", datastore.DeliveryQuery{Limit: 100}).Return([]datastore.Delivery{
+		{ID: "1", WebhookID: "abcd", Event: "session.removed", Status: "delivered", Attempts: 1, StatusCode: 200},
+	}, 1, nil)
+
+	app := NewServer(&Config{}, new(datastore.MockDatastore), nil, WithWebhooks(h, new(recordingPublisher))).Start()
+
+	res, err := app.Test(httptestRequest("GET", "/api/sessions/12345/webhooks/deliveries", ""), -1)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, res.StatusCode)
+
+	var dr DeliveriesResponse
+	assert.NoError(t, json.NewDecoder(res.Body).Decode(&dr))
+	assert.Equal(t, 1, dr.Total)
+	assert.Equal(t, 1, len(dr.Deliveries))
+	assert.Equal(t, "delivered", dr.Deliveries[0].Status)
+}
+
+func TestGetWebhookDeliveriesPage(t *testing.T) {
+	h := new(datastore.MockWebhookStore)
+	h.On("GetDeliveries", "12345", datastore.DeliveryQuery{Offset: 10, Limit: 5}).Return([]datastore.Delivery{}, 12, nil)
+
+	app := NewServer(&Config{}, new(datastore.MockDatastore), nil, WithWebhooks(h, new(recordingPublisher))).Start()
+
+	res, err := app.Test(httptestRequest("GET", "/api/sessions/12345/webhooks/deliveries?offset=10&limit=5", ""), -1)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, res.StatusCode)
+
+	res, err = app.Test(httptestRequest("GET", "/api/sessions/12345/webhooks/deliveries?limit=0", ""), -1)
+	assert.NoError(t, err)
+	assertErrorResponse(t, res, 400, "Limit must be a number >= 1, provided: 0")
+}
+
+func TestRemoveWebhook(t *testing.T) {
+	h := new(datastore.MockWebhookStore)
+	h.On("RemoveWebhook", "12345", "abcd").Return(nil)
+	h.On("RemoveWebhook", "12345", "efgh").Return(fmt.Errorf("Webhook with ID: efgh does not exist"))
+
+	app := NewServer(&Config{}, new(datastore.MockDatastore), nil, WithWebhooks(h, new(recordingPublisher))).Start()
+
+	res, err := app.Test(httptestRequest("DELETE", "/api/sessions/12345/webhooks/abcd", ""), -1)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, res.StatusCode)
+
+	res, err = app.Test(httptestRequest("DELETE", "/api/sessions/12345/webhooks/efgh", ""), -1)
+	assert.NoError(t, err)
+	assertErrorResponse(t, res, 500, "Webhook with ID: efgh does not exist")
+}
+
+func TestWebhookRoutesDisabled(t *testing.T) {
+	app := NewServer(&Config{}, new(datastore.MockDatastore), nil).Start()
+
+	res, err := app.Test(httptestRequest("GET", "/api/sessions/12345/webhooks", ""), -1)
+	assert.NoError(t, err)
+	assert.Equal(t, 404, res.StatusCode)
+}
+
+func TestFinalizingTaskSendsWebhook(t *testing.T) {
+	received := make(chan *http.Request, 1)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received <- r
+	}))
+	defer receiver.Close()
+
+	m := new(datastore.MockDatastore)
+	m.On("AddEstimateToTask", "12345", "TEST01", 1.5, 0.2).Return(nil)
+	h := new(datastore.MockWebhookStore)
+	h.On("GetWebhooks", "12345").Return([]datastore.Webhook{{ID: "abcd", URL: receiver.URL}}, nil)
+	h.On("AddDelivery", "12345", mock.Anything).Return(nil)
+
+	d := webhook.NewDispatcher(h, webhook.WithClient(webhook.NewClient(time.Second, true)))
+	d.Start()
+	defer d.Stop()
+
+	app := NewServer(&Config{}, m, nil, WithWebhooks(h, d)).Start()
+
+	res, err := app.Test(httptestRequest("PUT", "/api/sessions/12345/tasks/TEST01", `{"effort":1.5,"standarddeviation":0.2}`), -1)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, res.StatusCode)
+
+	select {
+	case r := <-received:
+		assert.Equal(t, webhook.EventTaskFinalized, r.Header.Get(webhook.HeaderEvent))
+	case <-time.After(5 * time.Second):
+		t.Fatal("Webhook not received")
+	}
+}
+
+type recordingPublisher struct {
+	events []string
+}
+
+func (r *recordingPublisher) Publish(token, eventType string, data interface{}) {
+	r.events = append(r.events, eventType)
+}
+
+func TestWebhooksAreRemovedWithSession(t *testing.T) {
+	m := new(datastore.MockDatastore)
+	h := new(datastore.MockWebhookStore)
+	p := new(recordingPublisher)
+	m.On("RemoveSession", "12345").Return(nil)
+	h.On("RemoveWebhooks", "12345").Return(nil)
+
+	app := NewServer(&Config{}, m, nil, WithWebhooks(h, p)).Start()
+
+	res, err := app.Test(httptestRequest("DELETE", "/api/sessions/12345", ""), -1)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, res.StatusCode)
+	h.AssertCalled(t, "RemoveWebhooks", "12345")
+}
@@ -0,0 +1,250 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/haro87/dokerb/pkg/apiserver"
+	"github.com/haro87/dokerb/pkg/datastore"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Client talks to a Doker backend via its HTTP API
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	user       string
+	secret     string
+}
+
+// AverageEstimate represents the averaged estimate of a task
+type AverageEstimate struct {
+	Message  string   `json:"message"`
+	Hint     string   `json:"hint"`
+	Users    []string `json:"users"`
+	Estimate struct {
+		Effort            float64 `json:"effort"`
+		StandardDeviation float64 `json:"standarddeviation"`
+	} `json:"estimate"`
+}
+
+// User represents a user of a session
+type User struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Avatar string `json:"avatar,omitempty"`
+	Email  string `json:"email,omitempty"`
+	Role   string `json:"role,omitempty"`
+}
+
+type usersResponse struct {
+	Users []User `json:"users"`
+}
+
+type apiResponse struct {
+	Message   string               `json:"message"`
+	Reason    string               `json:"reason"`
+	Route     string               `json:"route"`
+	Secret    string               `json:"secret"`
+	Users     []string             `json:"users"`
+	Tasks     []datastore.Task     `json:"tasks"`
+	Estimates []datastore.Estimate `json:"estimates"`
+}
+
+var sessionRoute = regexp.MustCompile("^/sessions/([\\d|\\w]+)$")
+
+var userRoute = regexp.MustCompile("^/sessions/[\\d|\\w]+/users/([\\d|\\w]+)$")
+
+const defaultTimeout = 10 * time.Second
+
+// NewClient creates a new client for the Doker backend
+// reachable at the provided server URL
+func NewClient(server string) (*Client, error) {
+	if server == "" {
+		return nil, fmt.Errorf("Server URL should not be empty")
+	}
+	u, err := url.Parse(server)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("Invalid server URL: %s", server)
+	}
+
+	return &Client{
+		baseURL:    strings.TrimRight(server, "/") + "/api",
+		httpClient: &http.Client{Timeout: defaultTimeout},
+	}, nil
+}
+
+// SetUser makes the client send all later requests on behalf of the
+// user referenced by ID or unique name, the secret the user got when
+// joining is required if the server has secrets enabled
+func (c *Client) SetUser(user, secret string) {
+	c.user = user
+	c.secret = secret
+}
+
+// CreateSession creates a new session and returns its token
+func (c *Client) CreateSession() (string, error) {
+	var ar apiResponse
+	if err := c.do("POST", "/sessions", nil, &ar); err != nil {
+		return "", err
+	}
+
+	match := sessionRoute.FindStringSubmatch(ar.Route)
+	if match == nil {
+		return "", fmt.Errorf("Unexpected session route: %s", ar.Route)
+	}
+
+	return match[1], nil
+}
+
+// RemoveSession removes the session identified by the token
+func (c *Client) RemoveSession(token string) error {
+	return c.do("DELETE", "/sessions/"+url.PathEscape(token), nil, nil)
+}
+
+// JoinSession adds a user with the provided name to the session
+// and returns the ID and the secret of the user, which is empty if
+// the server has secrets disabled
+func (c *Client) JoinSession(token, name string) (string, string, error) {
+	payload := map[string]string{"name": name}
+	var ar apiResponse
+	if err := c.do("POST", "/sessions/"+url.PathEscape(token)+"/users", payload, &ar); err != nil {
+		return "", "", err
+	}
+
+	match := userRoute.FindStringSubmatch(ar.Route)
+	if match == nil {
+		return "", "", fmt.Errorf("Unexpected user route: %s", ar.Route)
+	}
+
+	return match[1], ar.Secret, nil
+}
+
+// LeaveSession removes the user with the provided ID or unique
+// name from the session
+func (c *Client) LeaveSession(token, user string) error {
+	return c.do("DELETE", "/sessions/"+url.PathEscape(token)+"/users/"+url.PathEscape(user), nil, nil)
+}
+
+// GetUsers returns all users of the session
+func (c *Client) GetUsers(token string) ([]User, error) {
+	var ur usersResponse
+	if err := c.do("GET", "/sessions/"+url.PathEscape(token)+"/users", nil, &ur); err != nil {
+		return []User{}, err
+	}
+	return ur.Users, nil
+}
+
+// GetTasks returns all tasks of the session
+func (c *Client) GetTasks(token string) ([]datastore.Task, error) {
+	var ar apiResponse
+	if err := c.do("GET", "/sessions/"+url.PathEscape(token)+"/tasks", nil, &ar); err != nil {
+		return []datastore.Task{}, err
+	}
+	return ar.Tasks, nil
+}
+
+// AddTask adds a new task to the session
+func (c *Client) AddTask(token, id, summary string) error {
+	payload := map[string]string{"id": id, "summary": summary}
+	return c.do("POST", "/sessions/"+url.PathEscape(token)+"/tasks", payload, nil)
+}
+
+// SetTaskEstimate stores the final effort and standard deviation of a task
+func (c *Client) SetTaskEstimate(token, id string, effort, standardDeviation float64) error {
+	payload := map[string]float64{"effort": effort, "standarddeviation": standardDeviation}
+	return c.do("PUT", "/sessions/"+url.PathEscape(token)+"/tasks/"+url.PathEscape(id), payload, nil)
+}
+
+// AddEstimate submits the estimate of a user for a task, the user
+// is referenced by ID or, if no ID is provided, by unique name
+func (c *Client) AddEstimate(token string, estimate datastore.Estimate) error {
+	payload := map[string]interface{}{
+		"id":     estimate.TaskID,
+		"userid": estimate.UserID,
+		"user":   estimate.UserName,
+		"b":      estimate.BestCase,
+		"m":      estimate.MostLikelyCase,
+		"w":      estimate.WorstCase,
+	}
+	if estimate.Rationale != "" {
+		payload["rationale"] = estimate.Rationale
+	}
+	if len(estimate.Assumptions) > 0 {
+		payload["assumptions"] = estimate.Assumptions
+	}
+	return c.do("POST", "/sessions/"+url.PathEscape(token)+"/estimates", payload, nil)
+}
+
+// GetEstimates returns all user estimates of the session
+func (c *Client) GetEstimates(token string) ([]datastore.Estimate, error) {
+	var ar apiResponse
+	if err := c.do("GET", "/sessions/"+url.PathEscape(token)+"/estimates", nil, &ar); err != nil {
+		return []datastore.Estimate{}, err
+	}
+	return ar.Estimates, nil
+}
+
+// GetAverageEstimate returns the averaged estimate for a task
+func (c *Client) GetAverageEstimate(token, id string) (AverageEstimate, error) {
+	var avg AverageEstimate
+	err := c.do("GET", "/sessions/"+url.PathEscape(token)+"/estimates/"+url.PathEscape(id), nil, &avg)
+	return avg, err
+}
+
+// GetUsersWithMaxDistance returns the users with the max distance
+// between their estimates for a task
+func (c *Client) GetUsersWithMaxDistance(token, id string) ([]string, error) {
+	var ar apiResponse
+	if err := c.do("GET", "/sessions/"+url.PathEscape(token)+"/estimates/"+url.PathEscape(id)+"/users/distance", nil, &ar); err != nil {
+		return []string{}, err
+	}
+	return ar.Users, nil
+}
+
+func (c *Client) do(method, path string, payload interface{}, result interface{}) error {
+	var body bytes.Buffer
+	if payload != nil {
+		if err := json.NewEncoder(&body).Encode(payload); err != nil {
+			return err
+		}
+	}
+
+	req, err := http.NewRequest(method, c.baseURL+path, &body)
+	if err != nil {
+		return err
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.user != "" {
+		req.Header.Set(apiserver.HeaderUser, c.user)
+	}
+	if c.secret != "" {
+		req.Header.Set(apiserver.HeaderSecret, c.secret)
+	}
+
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		var ar apiResponse
+		if err := json.NewDecoder(res.Body).Decode(&ar); err != nil || ar.Reason == "" {
+			return fmt.Errorf("Request failed with status: %d", res.StatusCode)
+		}
+		return fmt.Errorf("%s", ar.Reason)
+	}
+
+	if result == nil {
+		return nil
+	}
+
+	return json.NewDecoder(res.Body).Decode(result)
+}
@@ -0,0 +1,165 @@
+package client
+
+import (
+	"context"
+	"github.com/genjidb/genji"
+	"github.com/haro87/dokerb/pkg/apiserver"
+	"github.com/haro87/dokerb/pkg/datastore"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net"
+	"os"
+	"testing"
+)
+
+var cl *Client
+
+// withSecrets enables secrets kept in their own database
+func withSecrets(t *testing.T) apiserver.Option {
+	td, _ := ioutil.TempDir("", "client-secrets")
+	db, _ := genji.Open(td + "/secrets.db")
+	t.Cleanup(func() {
+		db.Close()
+		os.RemoveAll(td)
+	})
+	ss, err := datastore.NewGenjiSecretStore(db.WithContext(context.Background()))
+	assert.NoError(t, err)
+	return apiserver.WithSecrets(ss)
+}
+
+func setupTestCaseForRealServer(t *testing.T, opts ...apiserver.Option) func(t *testing.T) {
+	td, _ := ioutil.TempDir("", "client-test")
+	db, _ := genji.Open(td + "/my.db")
+	db = db.WithContext(context.Background())
+	ds, _ := datastore.NewGenjiDatastore(db)
+
+	app := apiserver.NewServer(&apiserver.Config{}, ds, nil, opts...).Start()
+	ln, _ := net.Listen("tcp", "127.0.0.1:0")
+	go app.Listener(ln)
+
+	cl, _ = NewClient("http://" + ln.Addr().String())
+
+	return func(t *testing.T) {
+		cl.httpClient.CloseIdleConnections()
+		app.Shutdown()
+		db.Close()
+		os.RemoveAll(td)
+		cl = nil
+	}
+}
+
+func TestNewClientFailsDueToEmptyURL(t *testing.T) {
+	_, err := NewClient("")
+	assert.Equal(t, "Server URL should not be empty", err.Error())
+}
+
+func TestNewClientFailsDueToInvalidURL(t *testing.T) {
+	_, err := NewClient("localhost")
+	assert.Equal(t, "Invalid server URL: localhost", err.Error())
+}
+
+func TestClientFailsDueToUnreachableServer(t *testing.T) {
+	c, err := NewClient("http://127.0.0.1:1")
+	assert.NoError(t, err)
+	_, err = c.CreateSession()
+	assert.Error(t, err)
+}
+
+func TestClientReturnsServerErrorReason(t *testing.T) {
+	setupAndTearDown := setupTestCaseForRealServer(t)
+	defer setupAndTearDown(t)
+
+	_, _, err := cl.JoinSession("12345", "Tigger")
+	assert.Equal(t, "Session token does not match desired length", err.Error())
+}
+
+func TestClientSendsSecret(t *testing.T) {
+	setupAndTearDown := setupTestCaseForRealServer(t, withSecrets(t))
+	defer setupAndTearDown(t)
+
+	token, err := cl.CreateSession()
+	assert.NoError(t, err)
+	tigger, secret, err := cl.JoinSession(token, "Tigger")
+	assert.NoError(t, err)
+	assert.NotEmpty(t, secret)
+	assert.NoError(t, cl.AddTask(token, "TEST01", "a sample task"))
+
+	est := datastore.Estimate{TaskID: "TEST01", UserID: tigger, BestCase: 1, MostLikelyCase: 2, WorstCase: 3}
+	err = cl.AddEstimate(token, est)
+	assert.Equal(t, "Header X-Doker-User must reference the user or the moderator", err.Error())
+
+	cl.SetUser(tigger, "guess")
+	err = cl.AddEstimate(token, est)
+	assert.Equal(t, "Header X-Doker-Secret must contain the secret of user: "+tigger, err.Error())
+
+	cl.SetUser("Tigger", secret)
+	assert.NoError(t, cl.AddEstimate(token, est))
+	assert.NoError(t, cl.LeaveSession(token, tigger))
+}
+
+func TestClientSessionWorkflowSuccess(t *testing.T) {
+	setupAndTearDown := setupTestCaseForRealServer(t)
+	defer setupAndTearDown(t)
+
+	token, err := cl.CreateSession()
+	assert.NoError(t, err)
+	assert.Len(t, token, 32)
+
+	tigger, secret, err := cl.JoinSession(token, "Tigger")
+	assert.NoError(t, err)
+	assert.Len(t, tigger, 16)
+	assert.Empty(t, secret)
+	_, _, err = cl.JoinSession(token, "Rabbit")
+	assert.NoError(t, err)
+	_, _, err = cl.JoinSession(token, "Piglet")
+	assert.NoError(t, err)
+	assert.NoError(t, cl.LeaveSession(token, "Piglet"))
+
+	users, err := cl.GetUsers(token)
+	assert.NoError(t, err)
+	assert.Len(t, users, 2)
+	assert.Equal(t, User{ID: tigger, Name: "Tigger", Role: "estimator"}, users[0])
+	assert.Equal(t, "Rabbit", users[1].Name)
+
+	assert.NoError(t, cl.AddTask(token, "TEST01", "a sample task"))
+
+	tasks, err := cl.GetTasks(token)
+	assert.NoError(t, err)
+	assert.Len(t, tasks, 1)
+	assert.Equal(t, "TEST01", tasks[0].ID)
+	assert.Equal(t, "a sample task", tasks[0].Summary)
+
+	assert.NoError(t, cl.AddEstimate(token, datastore.Estimate{
+		TaskID: "TEST01", UserID: tigger, BestCase: 1, MostLikelyCase: 2, WorstCase: 3,
+	}))
+	assert.NoError(t, cl.AddEstimate(token, datastore.Estimate{
+		TaskID: "TEST01", UserName: "Rabbit", BestCase: 3, MostLikelyCase: 4, WorstCase: 5,
+		Rationale: "Login needs a password reset", Assumptions: []string{"No SSO"},
+	}))
+
+	ests, err := cl.GetEstimates(token)
+	assert.NoError(t, err)
+	assert.Len(t, ests, 2)
+	assert.Equal(t, "Login needs a password reset", ests[1].Rationale)
+	assert.Equal(t, []string{"No SSO"}, ests[1].Assumptions)
+
+	avg, err := cl.GetAverageEstimate(token, "TEST01")
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", avg.Message)
+	assert.Equal(t, 3.0, avg.Estimate.Effort)
+
+	names, err := cl.GetUsersWithMaxDistance(token, "TEST01")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Rabbit", "Tigger"}, names)
+
+	assert.NoError(t, cl.SetTaskEstimate(token, "TEST01", 3.0, 0.66))
+
+	tasks, err = cl.GetTasks(token)
+	assert.NoError(t, err)
+	assert.Equal(t, 3.0, tasks[0].Effort)
+	assert.Equal(t, 0.66, tasks[0].StandardDeviation)
+
+	assert.NoError(t, cl.RemoveSession(token))
+	_, err = cl.GetUsers(token)
+	assert.Equal(t, "Specified session does not exist", err.Error())
+}
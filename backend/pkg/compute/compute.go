@@ -38,8 +38,8 @@ func CalculateAverageEstimate(estimates []datastore.Estimate, id string) (estima
 
 }
 
-// GetUsersWithMaxDistanceBetweenEffort returns the two users, if they
-// exist, who have the max distance between their effort estimates
+// GetUsersWithMaxDistanceBetweenEffort returns the IDs of the two users,
+// if they exist, who have the max distance between their effort estimates
 func GetUsersWithMaxDistanceBetweenEffort(estimates []datastore.Estimate, id string) ([]string, error) {
 	ests, err := ExtractEstimatesForTask(estimates, id)
 
@@ -54,7 +54,7 @@ func GetUsersWithMaxDistanceBetweenEffort(estimates []datastore.Estimate, id str
 		if e != nil {
 			return []string{}, e
 		}
-		list = append(list, estimate.UserEstimate{Name: est.UserName, Estimate: es})
+		list = append(list, estimate.UserEstimate{Name: est.UserID, Estimate: es})
 	}
 
 	l, le := estimate.NewEstimateList(list)
@@ -69,7 +69,7 @@ func GetUsersWithMaxDistanceBetweenEffort(estimates []datastore.Estimate, id str
 }
 
 // ExtractEstimatesForTask extracts all estimates for a specified
-// task ID
+// task ID, orphaned estimates of removed users and tasks are skipped
 func ExtractEstimatesForTask(estimates []datastore.Estimate, id string) ([]datastore.Estimate, error) {
 	if id == "" {
 		return []datastore.Estimate{}, fmt.Errorf("Task ID cannot be empty")
@@ -80,7 +80,7 @@ func ExtractEstimatesForTask(estimates []datastore.Estimate, id string) ([]datas
 	var ests []datastore.Estimate
 
 	for _, est := range estimates {
-		if est.TaskID == id {
+		if est.TaskID == id && !est.Orphaned {
 			ests = append(ests, est)
 		}
 	}
@@ -60,6 +60,28 @@ func TestExtractEstimatesSuccess(t *testing.T) {
 	assert.Equal(t, "Rabbit", res[1].UserName)
 }
 
+func TestExtractEstimatesSkipsOrphanedEstimates(t *testing.T) {
+	ests := []datastore.Estimate{
+		{
+			TaskID:   "TEST01",
+			UserName: "Tigger",
+			Orphaned: true,
+		},
+		{
+			TaskID:   "TEST01",
+			UserName: "Rabbit",
+		},
+	}
+
+	res, err := ExtractEstimatesForTask(ests, "TEST01")
+	assert.NoError(t, err)
+	assert.Len(t, res, 1)
+	assert.Equal(t, "Rabbit", res[0].UserName)
+
+	_, err = ExtractEstimatesForTask(ests[:1], "TEST01")
+	assert.Equal(t, "Specified task with ID: TEST01 is not part of estimates", err.Error())
+}
+
 func TestCalculateAverageEstimateFailsDueToEmptyID(t *testing.T) {
 	_, err := CalculateAverageEstimate([]datastore.Estimate{}, "")
 	assert.Error(t, err)
@@ -119,25 +141,25 @@ func TestGetUsersWithMaxDistanceBetweenEffortSuccess(t *testing.T) {
 	ests := []datastore.Estimate{
 		{
 			TaskID:         "TEST01",
-			UserName:       "Tigger",
+			UserID:         "tigger",
 			BestCase:       1.0,
 			MostLikelyCase: 2.0,
 			WorstCase:      4.0,
 		},
 		{
-			TaskID:   "TEST02",
-			UserName: "Tigger",
+			TaskID: "TEST02",
+			UserID: "tigger",
 		},
 		{
 			TaskID:         "TEST01",
-			UserName:       "Rabbit",
+			UserID:         "rabbit",
 			BestCase:       2.0,
 			MostLikelyCase: 3.0,
 			WorstCase:      5.0,
 		},
 		{
 			TaskID:         "TEST01",
-			UserName:       "Piglet",
+			UserID:         "piglet",
 			BestCase:       0.4,
 			MostLikelyCase: 1.0,
 			WorstCase:      1.2,
@@ -146,6 +168,6 @@ func TestGetUsersWithMaxDistanceBetweenEffortSuccess(t *testing.T) {
 
 	res, err := GetUsersWithMaxDistanceBetweenEffort(ests, "TEST01")
 	assert.NoError(t, err)
-	assert.Equal(t, "Rabbit", res[0])
-	assert.Equal(t, "Piglet", res[1])
+	assert.Equal(t, "rabbit", res[0])
+	assert.Equal(t, "piglet", res[1])
 }
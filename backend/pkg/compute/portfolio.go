@@ -0,0 +1,90 @@
+package compute
+
+import (
+	"github.com/haro87/dokerb/pkg/datastore"
+	"math"
+)
+
+// SessionSummary contains the finalized tasks of a session together
+// with their total effort, Pending counts the tasks which were
+// estimated by users but not finalized, Unestimated the tasks
+// without any estimate. Removed is set for sessions which no
+// longer exist.
+type SessionSummary struct {
+	Token             string
+	Tasks             []datastore.Task
+	Effort            float64
+	StandardDeviation float64
+	Pending           int
+	Unestimated       int
+	Removed           bool
+}
+
+// PortfolioSummary combines the summaries of several sessions
+type PortfolioSummary struct {
+	Sessions          []SessionSummary
+	Effort            float64
+	StandardDeviation float64
+	Finalized         int
+	Pending           int
+	Unestimated       int
+	Removed           int
+}
+
+// SummarizeSession summarizes the tasks of a session, the standard
+// deviation of the total assumes the tasks to be independent.
+// Orphaned estimates don't count as estimates of a task.
+func SummarizeSession(token string, tasks []datastore.Task, estimates []datastore.Estimate) SessionSummary {
+	summary := SessionSummary{Token: token, Tasks: []datastore.Task{}}
+
+	estimated := map[string]bool{}
+	for _, est := range estimates {
+		if !est.Orphaned {
+			estimated[est.TaskID] = true
+		}
+	}
+
+	var variance float64
+	for _, t := range tasks {
+		switch {
+		case t.Finalized:
+			summary.Tasks = append(summary.Tasks, t)
+			summary.Effort += t.Effort
+			variance += t.StandardDeviation * t.StandardDeviation
+		case estimated[t.ID]:
+			summary.Pending++
+		default:
+			summary.Unestimated++
+		}
+	}
+	summary.StandardDeviation = math.Sqrt(variance)
+
+	return summary
+}
+
+// RemovedSession returns the summary of a session, which no longer
+// exists
+func RemovedSession(token string) SessionSummary {
+	return SessionSummary{Token: token, Tasks: []datastore.Task{}, Removed: true}
+}
+
+// SummarizePortfolio combines the summaries of the sessions, Removed
+// counts the sessions which no longer exist
+func SummarizePortfolio(sessions []SessionSummary) PortfolioSummary {
+	summary := PortfolioSummary{Sessions: sessions}
+
+	var variance float64
+	for _, s := range sessions {
+		if s.Removed {
+			summary.Removed++
+		}
+		summary.Effort += s.Effort
+		variance += s.StandardDeviation * s.StandardDeviation
+		summary.Finalized += len(s.Tasks)
+		summary.Pending += s.Pending
+		summary.Unestimated += s.Unestimated
+	}
+	summary.StandardDeviation = math.Sqrt(variance)
+
+	return summary
+}
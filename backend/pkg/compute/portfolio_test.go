@@ -0,0 +1,57 @@
+package compute
+
+import (
+	"github.com/haro87/dokerb/pkg/datastore"
+	"github.com/stretchr/testify/assert"
+	"math"
+	"testing"
+)
+
+func TestSummarizeSession(t *testing.T) {
+	tasks := []datastore.Task{
+		{ID: "TEST01", Effort: 3, StandardDeviation: 0.3, Finalized: true},
+		{ID: "TEST02", Effort: 5, StandardDeviation: 0.4, Finalized: true},
+		{ID: "TEST05", Finalized: true},
+		{ID: "TEST03"},
+		{ID: "TEST04"},
+		{ID: "TEST06"},
+	}
+	ests := []datastore.Estimate{
+		{TaskID: "TEST01", UserName: "Tigger", BestCase: 1, MostLikelyCase: 3, WorstCase: 5},
+		{TaskID: "TEST03", UserName: "Tigger", BestCase: 1, MostLikelyCase: 2, WorstCase: 3},
+		{TaskID: "TEST06", UserName: "Pooh", BestCase: 1, MostLikelyCase: 2, WorstCase: 3, Orphaned: true},
+	}
+
+	res := SummarizeSession("12345", tasks, ests)
+	assert.Equal(t, "12345", res.Token)
+	assert.Equal(t, tasks[:3], res.Tasks)
+	assert.Equal(t, 8.0, res.Effort)
+	assert.True(t, math.Abs(0.5-res.StandardDeviation) <= float64CompareThreshold)
+	assert.Equal(t, 1, res.Pending)
+	assert.Equal(t, 2, res.Unestimated)
+	assert.False(t, res.Removed)
+}
+
+func TestSummarizeSessionWithoutTasks(t *testing.T) {
+	res := SummarizeSession("12345", nil, nil)
+	assert.Equal(t, []datastore.Task{}, res.Tasks)
+	assert.Equal(t, 0.0, res.Effort)
+	assert.Equal(t, 0.0, res.StandardDeviation)
+}
+
+func TestSummarizePortfolio(t *testing.T) {
+	sessions := []SessionSummary{
+		{Token: "12345", Tasks: []datastore.Task{{ID: "TEST01"}}, Effort: 8, StandardDeviation: 0.6, Pending: 1},
+		{Token: "67890", Tasks: []datastore.Task{{ID: "TEST01"}, {ID: "TEST02"}}, Effort: 4, StandardDeviation: 0.8, Unestimated: 2},
+		RemovedSession("abcde"),
+	}
+
+	res := SummarizePortfolio(sessions)
+	assert.Equal(t, sessions, res.Sessions)
+	assert.Equal(t, 12.0, res.Effort)
+	assert.True(t, math.Abs(1.0-res.StandardDeviation) <= float64CompareThreshold)
+	assert.Equal(t, 3, res.Finalized)
+	assert.Equal(t, 1, res.Pending)
+	assert.Equal(t, 2, res.Unestimated)
+	assert.Equal(t, 1, res.Removed)
+}
@@ -0,0 +1,48 @@
+package compute
+
+import (
+	"github.com/haro87/dokerb/pkg/datastore"
+)
+
+// UserProgress defines whether a user submitted an estimate
+type UserProgress struct {
+	ID        string
+	Name      string
+	Estimated bool
+}
+
+// VotingProgress contains which users of a session submitted an
+// estimate for a task without the values of the estimates, Ready
+// is set once every user did
+type VotingProgress struct {
+	Users     []UserProgress
+	Estimated int
+	Missing   int
+	Ready     bool
+}
+
+// GetVotingProgress returns the voting progress of the users for the
+// task with the specified ID, estimates of users who are no longer
+// part of the session are ignored
+func GetVotingProgress(users []datastore.User, estimates []datastore.Estimate, id string) VotingProgress {
+	progress := VotingProgress{Users: []UserProgress{}}
+
+	estimated := map[string]bool{}
+	for _, est := range estimates {
+		if est.TaskID == id && !est.Orphaned {
+			estimated[est.UserID] = true
+		}
+	}
+
+	for _, u := range users {
+		progress.Users = append(progress.Users, UserProgress{ID: u.ID, Name: u.Name, Estimated: estimated[u.ID]})
+		if estimated[u.ID] {
+			progress.Estimated++
+		} else {
+			progress.Missing++
+		}
+	}
+
+	progress.Ready = len(users) > 0 && progress.Missing == 0
+	return progress
+}
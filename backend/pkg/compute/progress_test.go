@@ -0,0 +1,65 @@
+package compute
+
+import (
+	"github.com/haro87/dokerb/pkg/datastore"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestGetVotingProgress(t *testing.T) {
+	estimates := []datastore.Estimate{
+		{TaskID: "T1", UserID: "tigger", BestCase: 1, MostLikelyCase: 2, WorstCase: 3},
+		{TaskID: "T1", UserID: "rabbit", BestCase: 1, MostLikelyCase: 2, WorstCase: 3},
+		{TaskID: "T2", UserID: "pooh", BestCase: 1, MostLikelyCase: 2, WorstCase: 3},
+		{TaskID: "T2", UserID: "tigger", BestCase: 1, MostLikelyCase: 2, WorstCase: 3, Orphaned: true},
+	}
+
+	tests := []struct {
+		name  string
+		users []datastore.User
+		id    string
+		want  VotingProgress
+	}{
+		{
+			"missing users",
+			[]datastore.User{{ID: "tigger", Name: "Tigger"}, {ID: "pooh", Name: "Pooh"}},
+			"T1",
+			VotingProgress{
+				Users:     []UserProgress{{ID: "tigger", Name: "Tigger", Estimated: true}, {ID: "pooh", Name: "Pooh", Estimated: false}},
+				Estimated: 1,
+				Missing:   1,
+			},
+		},
+		{
+			"ready",
+			[]datastore.User{{ID: "pooh", Name: "Pooh"}},
+			"T2",
+			VotingProgress{
+				Users:     []UserProgress{{ID: "pooh", Name: "Pooh", Estimated: true}},
+				Estimated: 1,
+				Ready:     true,
+			},
+		},
+		{
+			"orphaned estimates",
+			[]datastore.User{{ID: "tigger", Name: "Tigger"}},
+			"T2",
+			VotingProgress{
+				Users:   []UserProgress{{ID: "tigger", Name: "Tigger", Estimated: false}},
+				Missing: 1,
+			},
+		},
+		{
+			"no users",
+			[]datastore.User{},
+			"T1",
+			VotingProgress{Users: []UserProgress{}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, GetVotingProgress(tt.users, estimates, tt.id))
+		})
+	}
+}
@@ -0,0 +1,395 @@
+package datastore
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/genjidb/genji/document"
+	"go.uber.org/zap"
+	"time"
+)
+
+// AuditStore defines the interface for the append-only log of
+// all mutations of sessions
+type AuditStore interface {
+	AddAuditEntry(token string, entry AuditEntry) error
+	GetAuditEntries(token string, query AuditQuery) ([]AuditEntry, int, error)
+}
+
+// AuditEntry defines a single mutation of a session, Actor is the
+// name or ID of the user, if known, and ActorID the ID of the user,
+// if the actor was part of the session. IP is the address the
+// mutation was requested from. Before and After contain the
+// affected values as JSON and are empty if there was no value.
+type AuditEntry struct {
+	Type    string
+	Actor   string
+	ActorID string
+	IP      string
+	Time    string
+	Before  string
+	After   string
+}
+
+// AuditQuery defines which page of audit entries is returned,
+// entries are filtered by Type unless it's empty
+type AuditQuery struct {
+	Type   string
+	Offset int
+	Limit  int
+}
+
+// Types of audit entries
+const (
+	AuditSessionRemoved  = "session.removed"
+	AuditUserJoined      = "user.joined"
+	AuditUserLeft        = "user.left"
+	AuditUserUpdated     = "user.updated"
+	AuditTaskAdded       = "task.added"
+	AuditTaskRemoved     = "task.removed"
+	AuditTaskFinalized   = "task.finalized"
+	AuditTaskReset       = "task.reset"
+	AuditEstimateAdded   = "estimate.added"
+	AuditEstimateRemoved = "estimate.removed"
+)
+
+// AuditTypes contains all types of audit entries
+var AuditTypes = []string{
+	AuditSessionRemoved,
+	AuditUserJoined,
+	AuditUserLeft,
+	AuditUserUpdated,
+	AuditTaskAdded,
+	AuditTaskRemoved,
+	AuditTaskFinalized,
+	AuditTaskReset,
+	AuditEstimateAdded,
+	AuditEstimateRemoved,
+}
+
+// GenjiAuditStore stores audit entries in their own Genji table
+type GenjiAuditStore struct {
+	db GenjiDB
+}
+
+type auditRow struct {
+	Token string
+	AuditEntry
+}
+
+// NewGenjiAuditStore creates a new GenjiAuditStore and the
+// table it requires
+func NewGenjiAuditStore(db GenjiDB) (AuditStore, error) {
+	if db == nil {
+		return nil, fmt.Errorf("Proper DB must be provided and not nil")
+	}
+
+	if err := db.Exec("CREATE TABLE audit"); err != nil && err.Error() != "table already exists" {
+		return nil, fmt.Errorf("Unable to create audit table")
+	}
+
+	return &GenjiAuditStore{db: db}, nil
+}
+
+// AddAuditEntry appends the entry to the audit log of the session
+func (g *GenjiAuditStore) AddAuditEntry(token string, entry AuditEntry) error {
+	if err := g.db.Exec("INSERT INTO audit VALUES ?", &auditRow{Token: token, AuditEntry: entry}); err != nil {
+		return fmt.Errorf("Unable to store audit entry")
+	}
+	return nil
+}
+
+// GetAuditEntries returns the requested page of the audit log of
+// the session in the order the entries were added together with
+// the total number of matching entries, a limit of 0 returns all
+// remaining entries
+func (g *GenjiAuditStore) GetAuditEntries(token string, query AuditQuery) ([]AuditEntry, int, error) {
+	entries := []AuditEntry{}
+
+	if query.Offset < 0 || query.Limit < 0 {
+		return entries, 0, fmt.Errorf("Offset and limit must be >= 0")
+	}
+
+	q, args := "SELECT * FROM audit WHERE token = ?", []interface{}{token}
+	if query.Type != "" {
+		q, args = q+" AND type = ?", append(args, query.Type)
+	}
+
+	res, err := g.db.Query(q, args...)
+	if err != nil {
+		return entries, 0, fmt.Errorf("Unable to query audit entries")
+	}
+
+	defer res.Close()
+
+	total := 0
+	err = res.Iterate(func(d document.Document) error {
+		total++
+		if total <= query.Offset || (query.Limit > 0 && len(entries) == query.Limit) {
+			return nil
+		}
+		var e AuditEntry
+		if err := document.StructScan(d, &e); err != nil {
+			return err
+		}
+		entries = append(entries, e)
+		return nil
+	})
+
+	return entries, total, err
+}
+
+// AuditingDataStore wraps a datastore and records an audit entry
+// for every successful mutation on behalf of its actor
+type AuditingDataStore struct {
+	DataStore
+	audit  AuditStore
+	logger *zap.Logger
+	actor  string
+	ip     string
+}
+
+// NewAuditingDataStore wraps the provided datastore so that all
+// mutations are recorded in the provided audit store, a nil logger
+// disables logging
+func NewAuditingDataStore(ds DataStore, audit AuditStore, logger *zap.Logger) *AuditingDataStore {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &AuditingDataStore{
+		DataStore: ds,
+		audit:     audit,
+		logger:    logger,
+	}
+}
+
+// WithActor returns a datastore recording the provided actor and
+// IP, e.g. of the user a request was sent by
+func (a *AuditingDataStore) WithActor(actor, ip string) DataStore {
+	return &AuditingDataStore{
+		DataStore: a.DataStore,
+		audit:     a.audit,
+		logger:    a.logger,
+		actor:     actor,
+		ip:        ip,
+	}
+}
+
+// JoinSession implements the Datastore interface
+func (a *AuditingDataStore) JoinSession(token string, user User) (string, error) {
+	id, err := a.DataStore.JoinSession(token, user)
+	if err != nil {
+		return id, err
+	}
+	a.record(token, AuditUserJoined, nil, a.user(token, id))
+	return id, nil
+}
+
+// LeaveSession implements the Datastore interface, estimates
+// removed together with the user are recorded as well
+func (a *AuditingDataStore) LeaveSession(token, id string) error {
+	users, _ := a.DataStore.GetUsers(token)
+	ests, _ := a.DataStore.GetEstimates(token)
+	before := a.user(token, id)
+
+	if err := a.DataStore.LeaveSession(token, id); err != nil {
+		return err
+	}
+	actor := a.actorID(users)
+	a.recordAs(token, actor, AuditUserLeft, before, nil)
+	a.recordRemovedEstimates(token, actor, ests)
+	return nil
+}
+
+// UpdateUser implements the Datastore interface
+func (a *AuditingDataStore) UpdateUser(token string, user User) error {
+	before := a.user(token, user.ID)
+
+	if err := a.DataStore.UpdateUser(token, user); err != nil {
+		return err
+	}
+	a.record(token, AuditUserUpdated, before, a.user(token, user.ID))
+	return nil
+}
+
+// RemoveSession implements the Datastore interface
+func (a *AuditingDataStore) RemoveSession(token string) error {
+	users, _ := a.DataStore.GetUsers(token)
+	if users == nil {
+		users = []User{}
+	}
+	tasks, _ := a.DataStore.GetTasks(token)
+	if tasks == nil {
+		tasks = []Task{}
+	}
+
+	if err := a.DataStore.RemoveSession(token); err != nil {
+		return err
+	}
+	a.recordAs(token, a.actorID(users), AuditSessionRemoved, map[string]interface{}{
+		"users": users,
+		"tasks": tasks,
+	}, nil)
+	return nil
+}
+
+// AddTask implements the Datastore interface
+func (a *AuditingDataStore) AddTask(token, id, summary string) error {
+	if err := a.DataStore.AddTask(token, id, summary); err != nil {
+		return err
+	}
+	a.record(token, AuditTaskAdded, nil, Task{ID: id, Summary: summary})
+	return nil
+}
+
+// RemoveTask implements the Datastore interface, estimates removed
+// together with the task are recorded as well
+func (a *AuditingDataStore) RemoveTask(token, id string) error {
+	ests, _ := a.DataStore.GetEstimates(token)
+	before := a.task(token, id)
+
+	if err := a.DataStore.RemoveTask(token, id); err != nil {
+		return err
+	}
+	users, _ := a.DataStore.GetUsers(token)
+	actor := a.actorID(users)
+	a.recordAs(token, actor, AuditTaskRemoved, before, nil)
+	a.recordRemovedEstimates(token, actor, ests)
+	return nil
+}
+
+// AddEstimateToTask implements the Datastore interface
+func (a *AuditingDataStore) AddEstimateToTask(token, id string, effort, standardDeviation float64) error {
+	before := a.task(token, id)
+
+	if err := a.DataStore.AddEstimateToTask(token, id, effort, standardDeviation); err != nil {
+		return err
+	}
+	a.record(token, AuditTaskFinalized, before, a.task(token, id))
+	return nil
+}
+
+// RemoveEstimateFromTask implements the Datastore interface
+func (a *AuditingDataStore) RemoveEstimateFromTask(token, id string) error {
+	before := a.task(token, id)
+
+	if err := a.DataStore.RemoveEstimateFromTask(token, id); err != nil {
+		return err
+	}
+	a.record(token, AuditTaskReset, before, a.task(token, id))
+	return nil
+}
+
+// AddEstimate implements the Datastore interface
+func (a *AuditingDataStore) AddEstimate(token string, estimate Estimate) error {
+	if err := a.DataStore.AddEstimate(token, estimate); err != nil {
+		return err
+	}
+	a.record(token, AuditEstimateAdded, nil, estimate)
+	return nil
+}
+
+// RemoveEstimate implements the Datastore interface
+func (a *AuditingDataStore) RemoveEstimate(token string, estimate Estimate) error {
+	var before interface{}
+	ests, _ := a.DataStore.GetEstimates(token)
+	if i := findEstimate(ests, estimate); i >= 0 {
+		before = ests[i]
+	}
+
+	if err := a.DataStore.RemoveEstimate(token, estimate); err != nil {
+		return err
+	}
+	a.record(token, AuditEstimateRemoved, before, nil)
+	return nil
+}
+
+// user returns the current profile of the user, if it exists
+func (a *AuditingDataStore) user(token, id string) interface{} {
+	users, _ := a.DataStore.GetUsers(token)
+	if u, ok := findUser(users, id); ok {
+		return u
+	}
+	return nil
+}
+
+// task returns the current state of the task, if it exists
+func (a *AuditingDataStore) task(token, id string) interface{} {
+	tasks, _ := a.DataStore.GetTasks(token)
+	for _, t := range tasks {
+		if t.ID == id {
+			return t
+		}
+	}
+	return nil
+}
+
+// actorID returns the ID of the actor out of the users, the actor
+// may reference the user by ID or unique name
+func (a *AuditingDataStore) actorID(users []User) string {
+	id, matches := "", 0
+	for _, u := range users {
+		if u.ID == a.actor {
+			return u.ID
+		}
+		if u.Name == a.actor {
+			id, matches = u.ID, matches+1
+		}
+	}
+	if matches != 1 {
+		return ""
+	}
+	return id
+}
+
+// recordRemovedEstimates records the estimates out of the provided
+// ones, which no longer exist, e.g. as they were removed together
+// with their user or task
+func (a *AuditingDataStore) recordRemovedEstimates(token, actor string, before []Estimate) {
+	after, _ := a.DataStore.GetEstimates(token)
+	for _, e := range before {
+		if findEstimate(after, e) < 0 {
+			a.recordAs(token, actor, AuditEstimateRemoved, e, nil)
+		}
+	}
+}
+
+// record records the entry on behalf of the actor out of the current
+// users of the session
+func (a *AuditingDataStore) record(token, auditType string, before, after interface{}) {
+	users, _ := a.DataStore.GetUsers(token)
+	a.recordAs(token, a.actorID(users), auditType, before, after)
+}
+
+// recordAs logs failures instead of returning them, as the mutation
+// they follow already succeeded
+func (a *AuditingDataStore) recordAs(token, actorID, auditType string, before, after interface{}) {
+	entry := AuditEntry{
+		Type:    auditType,
+		Actor:   a.actor,
+		ActorID: actorID,
+		IP:      a.ip,
+		Time:    time.Now().UTC().Format(time.RFC3339),
+	}
+
+	var err error
+	if entry.Before, err = auditValue(before); err == nil {
+		if entry.After, err = auditValue(after); err == nil {
+			err = a.audit.AddAuditEntry(token, entry)
+		}
+	}
+	if err != nil {
+		a.logger.Error("Unable to record audit entry", zap.String("session", RedactToken(token)),
+			zap.String("type", auditType), zap.Error(err))
+	}
+}
+
+func auditValue(v interface{}) (string, error) {
+	if v == nil {
+		return "", nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
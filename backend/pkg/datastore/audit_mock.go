@@ -0,0 +1,22 @@
+package datastore
+
+import (
+	"github.com/stretchr/testify/mock"
+)
+
+// MockAuditStore represents the mocked object
+type MockAuditStore struct {
+	mock.Mock
+}
+
+// AddAuditEntry implements the AuditStore interface
+func (m *MockAuditStore) AddAuditEntry(t string, e AuditEntry) error {
+	arguments := m.Called(t, e)
+	return arguments.Error(0)
+}
+
+// GetAuditEntries implements the AuditStore interface
+func (m *MockAuditStore) GetAuditEntries(t string, q AuditQuery) ([]AuditEntry, int, error) {
+	arguments := m.Called(t, q)
+	return arguments.Get(0).([]AuditEntry), arguments.Int(1), arguments.Error(2)
+}
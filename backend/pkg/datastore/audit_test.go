@@ -0,0 +1,171 @@
+package datastore
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"testing"
+)
+
+func TestNewGenjiAuditStoreNilDB(t *testing.T) {
+	_, err := NewGenjiAuditStore(nil)
+	assert.Equal(t, "Proper DB must be provided and not nil", err.Error())
+}
+
+func TestNewGenjiAuditStoreFailsDueToCreateTable(t *testing.T) {
+	setupAndTearDown := setupTestCaseForMock(t)
+	defer setupAndTearDown(t)
+	m.On("Exec", "CREATE TABLE audit").Return(fmt.Errorf("Ooops, something went wrong"))
+
+	_, err := NewGenjiAuditStore(m)
+	assert.Equal(t, "Unable to create audit table", err.Error())
+}
+
+func TestGetAuditEntriesWithRealDB(t *testing.T) {
+	setupAndTearDown := setupTestCaseForRealDB(t)
+	defer setupAndTearDown(t)
+	as, err := NewGenjiAuditStore(db)
+	assert.NoError(t, err)
+
+	for i := 0; i < 5; i++ {
+		assert.NoError(t, as.AddAuditEntry("12345", AuditEntry{Type: AuditTaskAdded, After: fmt.Sprintf("%d", i)}))
+	}
+	assert.NoError(t, as.AddAuditEntry("12345", AuditEntry{Type: AuditUserJoined}))
+	assert.NoError(t, as.AddAuditEntry("67890", AuditEntry{Type: AuditTaskAdded}))
+
+	entries, total, err := as.GetAuditEntries("12345", AuditQuery{})
+	assert.NoError(t, err)
+	assert.Equal(t, 6, total)
+	assert.Equal(t, 6, len(entries))
+	assert.Equal(t, "0", entries[0].After)
+
+	entries, total, err = as.GetAuditEntries("12345", AuditQuery{Type: AuditTaskAdded, Offset: 1, Limit: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, 5, total)
+	assert.Equal(t, []AuditEntry{{Type: AuditTaskAdded, After: "1"}, {Type: AuditTaskAdded, After: "2"}}, entries)
+
+	entries, total, err = as.GetAuditEntries("12345", AuditQuery{Offset: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, 6, total)
+	assert.Equal(t, []AuditEntry{}, entries)
+
+	_, _, err = as.GetAuditEntries("12345", AuditQuery{Limit: -1})
+	assert.Equal(t, "Offset and limit must be >= 0", err.Error())
+}
+
+func TestAuditingDataStoreWithRealDB(t *testing.T) {
+	setupAndTearDown := setupTestCaseForRealDB(t)
+	defer setupAndTearDown(t)
+	gds, err := NewGenjiDatastore(db)
+	assert.NoError(t, err)
+	as, err := NewGenjiAuditStore(db)
+	assert.NoError(t, err)
+	ds := NewAuditingDataStore(gds, as, nil).WithActor("Pooh", "192.0.2.1")
+
+	token, err := ds.CreateSession()
+	assert.NoError(t, err)
+	est := Estimate{TaskID: "T1", UserID: "tigger", BestCase: 1, MostLikelyCase: 2, WorstCase: 3}
+
+	_, err = ds.JoinSession(token, User{ID: "tigger", Name: "Tigger"})
+	assert.NoError(t, err)
+	assert.NoError(t, ds.AddTask(token, "T1", "Login"))
+	assert.NoError(t, ds.AddEstimate(token, est))
+	assert.NoError(t, ds.AddEstimateToTask(token, "T1", 2, 0.3))
+	assert.NoError(t, ds.RemoveEstimateFromTask(token, "T1"))
+	assert.NoError(t, ds.RemoveEstimate(token, est))
+	// Estimates removed together with their task or user are recorded
+	assert.NoError(t, ds.AddEstimate(token, est))
+	assert.NoError(t, ds.RemoveTask(token, "T1"))
+	assert.NoError(t, ds.AddTask(token, "T2", "Logout"))
+	assert.NoError(t, ds.AddEstimate(token, Estimate{TaskID: "T2", UserID: "tigger", BestCase: 1, MostLikelyCase: 2, WorstCase: 3}))
+	assert.NoError(t, ds.LeaveSession(token, "tigger"))
+	assert.NoError(t, ds.RemoveSession(token))
+
+	// Failed mutations are not recorded
+	_, err = ds.JoinSession(token, User{Name: "Rabbit"})
+	assert.Error(t, err)
+
+	entries, total, err := as.GetAuditEntries(token, AuditQuery{})
+	assert.NoError(t, err)
+	assert.Equal(t, 14, total)
+
+	types := []string{}
+	for _, e := range entries {
+		assert.Equal(t, "Pooh", e.Actor)
+		assert.Equal(t, "", e.ActorID)
+		assert.Equal(t, "192.0.2.1", e.IP)
+		assert.NotEmpty(t, e.Time)
+		types = append(types, e.Type)
+	}
+	assert.Equal(t, []string{
+		AuditUserJoined,
+		AuditTaskAdded,
+		AuditEstimateAdded,
+		AuditTaskFinalized,
+		AuditTaskReset,
+		AuditEstimateRemoved,
+		AuditEstimateAdded,
+		AuditTaskRemoved,
+		AuditEstimateRemoved,
+		AuditTaskAdded,
+		AuditEstimateAdded,
+		AuditUserLeft,
+		AuditEstimateRemoved,
+		AuditSessionRemoved,
+	}, types)
+
+	assert.Equal(t, "", entries[0].Before)
+	assert.Equal(t, `{"ID":"tigger","Name":"Tigger","AvatarURL":"","Email":"","Role":"estimator"}`, entries[0].After)
+	assert.Equal(t, `{"ID":"T1","Summary":"Login","Effort":0,"StandardDeviation":0,"Finalized":false}`, entries[3].Before)
+	assert.Equal(t, `{"ID":"T1","Summary":"Login","Effort":2,"StandardDeviation":0.3,"Finalized":true}`, entries[3].After)
+	assert.Equal(t, `{"ID":"T1","Summary":"Login","Effort":0,"StandardDeviation":0,"Finalized":false}`, entries[4].After)
+	assert.Equal(t, `{"TaskID":"T1","UserID":"tigger","UserName":"Tigger","BestCase":1,"MostLikelyCase":2,"WorstCase":3,"Rationale":"","Assumptions":null,"Orphaned":false}`, entries[5].Before)
+	assert.Equal(t, "", entries[5].After)
+	assert.Equal(t, entries[5].Before, entries[8].Before)
+	assert.Equal(t, `{"TaskID":"T2","UserID":"tigger","UserName":"Tigger","BestCase":1,"MostLikelyCase":2,"WorstCase":3,"Rationale":"","Assumptions":null,"Orphaned":false}`, entries[12].Before)
+	assert.Equal(t, `{"ID":"tigger","Name":"Tigger","AvatarURL":"","Email":"","Role":"estimator"}`, entries[11].Before)
+	assert.Equal(t, `{"tasks":[{"ID":"T2","Summary":"Logout","Effort":0,"StandardDeviation":0,"Finalized":false}],"users":[]}`, entries[13].Before)
+}
+
+func TestAuditingDataStoreRecordsActorIDWithRealDB(t *testing.T) {
+	setupAndTearDown := setupTestCaseForRealDB(t)
+	defer setupAndTearDown(t)
+	gds, err := NewGenjiDatastore(db)
+	assert.NoError(t, err)
+	as, err := NewGenjiAuditStore(db)
+	assert.NoError(t, err)
+	ds := NewAuditingDataStore(gds, as, nil)
+
+	token, err := ds.CreateSession()
+	assert.NoError(t, err)
+	_, err = ds.WithActor("", "192.0.2.1").JoinSession(token, User{ID: "tigger", Name: "Tigger"})
+	assert.NoError(t, err)
+	_, err = ds.WithActor("Tigger", "192.0.2.1").JoinSession(token, User{ID: "pooh", Name: "Pooh"})
+	assert.NoError(t, err)
+	assert.NoError(t, ds.WithActor("pooh", "192.0.2.1").LeaveSession(token, "pooh"))
+
+	entries, _, err := as.GetAuditEntries(token, AuditQuery{})
+	assert.NoError(t, err)
+	assert.Equal(t, "", entries[0].ActorID)
+	assert.Equal(t, "tigger", entries[1].ActorID)
+	// Users leaving are still known as actors
+	assert.Equal(t, "pooh", entries[2].ActorID)
+}
+
+func TestAuditingDataStoreLogsFailures(t *testing.T) {
+	m := new(MockDatastore)
+	m.On("JoinSession", "12345", User{Name: "Tigger"}).Return("tigger", nil)
+	m.On("GetUsers", "12345").Return([]User{{ID: "tigger", Name: "Tigger"}}, nil)
+	as := new(MockAuditStore)
+	as.On("AddAuditEntry", "12345", mock.Anything).Return(fmt.Errorf("Unable to store audit entry"))
+	core, logs := observer.New(zapcore.ErrorLevel)
+
+	// The mutation already succeeded, so it isn't reported as failed
+	id, err := NewAuditingDataStore(m, as, zap.New(core)).JoinSession("12345", User{Name: "Tigger"})
+	assert.NoError(t, err)
+	assert.Equal(t, "tigger", id)
+	assert.Equal(t, 1, logs.FilterMessage("Unable to record audit entry").Len())
+}
@@ -0,0 +1,218 @@
+package datastore
+
+import (
+	"fmt"
+	"github.com/genjidb/genji/document"
+	"go.uber.org/zap"
+	"time"
+	"unicode/utf8"
+)
+
+// CommentStore defines the interface for storing the comments
+// discussing the tasks of a session
+type CommentStore interface {
+	AddComment(token string, comment Comment) (string, error)
+	UpdateComment(token, id, text string) error
+	RemoveComment(token, id string) error
+	RemoveComments(token string) error
+	GetComment(token, id string) (Comment, error)
+	GetComments(token, task string) ([]Comment, error)
+}
+
+// Comment defines a comment on a task, e.g. the rationale of an
+// outlier estimate. EstimateUserID optionally links the comment to
+// the estimate of this user for the task and Round to the estimation
+// round of the task it was written in, which is 0 if unknown. Edited
+// is empty unless the text was changed.
+type Comment struct {
+	ID             string
+	TaskID         string
+	AuthorID       string
+	AuthorName     string
+	Text           string
+	EstimateUserID string
+	Round          int
+	Time           string
+	Edited         string
+}
+
+// MaxCommentLength is the max number of characters of a comment
+const MaxCommentLength int = 2000
+
+// GenjiCommentStore stores comments in their own Genji table
+type GenjiCommentStore struct {
+	db GenjiDB
+}
+
+type commentRow struct {
+	Token string
+	Comment
+}
+
+const defaultCommentIDLength int = 16
+
+// NewGenjiCommentStore creates a new GenjiCommentStore and the
+// table it requires
+func NewGenjiCommentStore(db GenjiDB) (CommentStore, error) {
+	if db == nil {
+		return nil, fmt.Errorf("Proper DB must be provided and not nil")
+	}
+
+	if err := db.Exec("CREATE TABLE comments"); err != nil && err.Error() != "table already exists" {
+		return nil, fmt.Errorf("Unable to create comments table")
+	}
+
+	return &GenjiCommentStore{db: db}, nil
+}
+
+// AddComment adds the comment to the task of the session and
+// returns its ID
+func (g *GenjiCommentStore) AddComment(token string, comment Comment) (string, error) {
+	if comment.TaskID == "" {
+		return "", fmt.Errorf("Task ID should not be empty")
+	}
+	if comment.AuthorID == "" {
+		return "", fmt.Errorf("Author should not be empty")
+	}
+	if comment.Round < 0 {
+		return "", fmt.Errorf("Round must be >= 0, provided: %d", comment.Round)
+	}
+	if err := validateCommentText(comment.Text); err != nil {
+		return "", err
+	}
+
+	id, err := generateToken(defaultCommentIDLength)
+	if err != nil {
+		return "", fmt.Errorf("Unable to create comment ID")
+	}
+	comment.ID = id
+	comment.Time = time.Now().UTC().Format(time.RFC3339)
+	comment.Edited = ""
+
+	if err := g.db.Exec("INSERT INTO comments VALUES ?", &commentRow{Token: token, Comment: comment}); err != nil {
+		return "", fmt.Errorf("Unable to store comment")
+	}
+	return id, nil
+}
+
+// UpdateComment replaces the text of the comment with the
+// specified ID
+func (g *GenjiCommentStore) UpdateComment(token, id, text string) error {
+	if err := validateCommentText(text); err != nil {
+		return err
+	}
+	if _, err := g.GetComment(token, id); err != nil {
+		return err
+	}
+
+	edited := time.Now().UTC().Format(time.RFC3339)
+	if err := g.db.Exec("UPDATE comments SET `text` = ?, edited = ? WHERE token = ? AND id = ?", text, edited, token, id); err != nil {
+		return fmt.Errorf("Unable to store comment")
+	}
+	return nil
+}
+
+// RemoveComment removes the comment with the specified ID
+// from the session
+func (g *GenjiCommentStore) RemoveComment(token, id string) error {
+	if _, err := g.GetComment(token, id); err != nil {
+		return err
+	}
+	return g.db.Exec("DELETE FROM comments WHERE token = ? AND id = ?", token, id)
+}
+
+// RemoveComments removes all comments of the session
+func (g *GenjiCommentStore) RemoveComments(token string) error {
+	if err := g.db.Exec("DELETE FROM comments WHERE token = ?", token); err != nil {
+		return fmt.Errorf("Unable to remove comments")
+	}
+	return nil
+}
+
+// GetComment returns the comment with the specified ID
+func (g *GenjiCommentStore) GetComment(token, id string) (Comment, error) {
+	comments, err := g.query("SELECT * FROM comments WHERE token = ? AND id = ?", token, id)
+	if err != nil {
+		return Comment{}, err
+	}
+
+	if len(comments) == 0 {
+		return Comment{}, fmt.Errorf("Comment with ID: %s does not exist", id)
+	}
+	return comments[0], nil
+}
+
+// GetComments returns the comments of the task in the order they
+// were added, or the comments of all tasks if no task is specified
+func (g *GenjiCommentStore) GetComments(token, task string) ([]Comment, error) {
+	if task == "" {
+		return g.query("SELECT * FROM comments WHERE token = ?", token)
+	}
+	return g.query("SELECT * FROM comments WHERE token = ? AND taskid = ?", token, task)
+}
+
+func (g *GenjiCommentStore) query(q string, args ...interface{}) ([]Comment, error) {
+	comments := []Comment{}
+
+	res, err := g.db.Query(q, args...)
+	if err != nil {
+		return comments, fmt.Errorf("Unable to query comments")
+	}
+
+	defer res.Close()
+
+	err = res.Iterate(func(d document.Document) error {
+		var c Comment
+		if err := document.StructScan(d, &c); err != nil {
+			return err
+		}
+		comments = append(comments, c)
+		return nil
+	})
+
+	return comments, err
+}
+
+func validateCommentText(text string) error {
+	if text == "" {
+		return fmt.Errorf("Comment text should not be empty")
+	}
+	if n := utf8.RuneCountInString(text); n > MaxCommentLength {
+		return fmt.Errorf("Comment text must not be longer than %d characters, provided: %d", MaxCommentLength, n)
+	}
+	return nil
+}
+
+// CommentRemovingDataStore wraps a datastore and removes the
+// comments of removed sessions
+type CommentRemovingDataStore struct {
+	DataStore
+	comments CommentStore
+	logger   *zap.Logger
+}
+
+// NewCommentRemovingDataStore wraps the provided datastore so that
+// comments of the provided store are removed together with their
+// session, a nil logger disables logging
+func NewCommentRemovingDataStore(ds DataStore, comments CommentStore, logger *zap.Logger) DataStore {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &CommentRemovingDataStore{
+		DataStore: ds,
+		comments:  comments,
+		logger:    logger,
+	}
+}
+
+// RemoveSession implements the Datastore interface, failures to
+// remove the comments are logged as the session is already gone
+func (r *CommentRemovingDataStore) RemoveSession(token string) error {
+	if err := r.DataStore.RemoveSession(token); err != nil {
+		return err
+	}
+	if err := r.comments.RemoveComments(token); err != nil {
+		r.logger.Error("Unable to remove comments", zap.String("session", RedactToken(token)), zap.Error(err))
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package datastore
+
+import (
+	"github.com/stretchr/testify/mock"
+)
+
+// MockCommentStore represents the mocked object
+type MockCommentStore struct {
+	mock.Mock
+}
+
+// AddComment implements the CommentStore interface
+func (m *MockCommentStore) AddComment(t string, c Comment) (string, error) {
+	arguments := m.Called(t, c)
+	return arguments.Get(0).(string), arguments.Error(1)
+}
+
+// UpdateComment implements the CommentStore interface
+func (m *MockCommentStore) UpdateComment(t, id, text string) error {
+	arguments := m.Called(t, id, text)
+	return arguments.Error(0)
+}
+
+// RemoveComment implements the CommentStore interface
+func (m *MockCommentStore) RemoveComment(t, id string) error {
+	arguments := m.Called(t, id)
+	return arguments.Error(0)
+}
+
+// RemoveComments implements the CommentStore interface
+func (m *MockCommentStore) RemoveComments(t string) error {
+	arguments := m.Called(t)
+	return arguments.Error(0)
+}
+
+// GetComment implements the CommentStore interface
+func (m *MockCommentStore) GetComment(t, id string) (Comment, error) {
+	arguments := m.Called(t, id)
+	return arguments.Get(0).(Comment), arguments.Error(1)
+}
+
+// GetComments implements the CommentStore interface
+func (m *MockCommentStore) GetComments(t, task string) ([]Comment, error) {
+	arguments := m.Called(t, task)
+	return arguments.Get(0).([]Comment), arguments.Error(1)
+}
@@ -0,0 +1,135 @@
+package datastore
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"strings"
+	"testing"
+)
+
+func TestNewGenjiCommentStoreNilDB(t *testing.T) {
+	_, err := NewGenjiCommentStore(nil)
+	assert.Equal(t, "Proper DB must be provided and not nil", err.Error())
+}
+
+func TestNewGenjiCommentStoreFailsDueToCreateTable(t *testing.T) {
+	setupAndTearDown := setupTestCaseForMock(t)
+	defer setupAndTearDown(t)
+	m.On("Exec", "CREATE TABLE comments").Return(fmt.Errorf("Ooops, something went wrong"))
+
+	_, err := NewGenjiCommentStore(m)
+	assert.Equal(t, "Unable to create comments table", err.Error())
+}
+
+func TestAddCommentFailsWithRealDB(t *testing.T) {
+	setupAndTearDown := setupTestCaseForRealDB(t)
+	defer setupAndTearDown(t)
+	cs, err := NewGenjiCommentStore(db)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		comment Comment
+		reason  string
+	}{
+		{"empty task", Comment{AuthorID: "tigger", Text: "Bouncy"}, "Task ID should not be empty"},
+		{"empty author", Comment{TaskID: "T1", Text: "Bouncy"}, "Author should not be empty"},
+		{"empty text", Comment{TaskID: "T1", AuthorID: "tigger"}, "Comment text should not be empty"},
+		{"negative round", Comment{TaskID: "T1", AuthorID: "tigger", Text: "Bouncy", Round: -1}, "Round must be >= 0, provided: -1"},
+		{
+			"too long text",
+			Comment{TaskID: "T1", AuthorID: "tigger", Text: strings.Repeat("ä", MaxCommentLength+1)},
+			"Comment text must not be longer than 2000 characters, provided: 2001",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := cs.AddComment("12345", tt.comment)
+			assert.Equal(t, tt.reason, err.Error())
+		})
+	}
+}
+
+func TestCommentsWithRealDB(t *testing.T) {
+	setupAndTearDown := setupTestCaseForRealDB(t)
+	defer setupAndTearDown(t)
+	cs, err := NewGenjiCommentStore(db)
+	assert.NoError(t, err)
+
+	comments, err := cs.GetComments("12345", "T1")
+	assert.NoError(t, err)
+	assert.Empty(t, comments)
+
+	id1, err := cs.AddComment("12345", Comment{TaskID: "T1", AuthorID: "tigger", AuthorName: "Tigger", Text: "Bouncing takes time", EstimateUserID: "tigger", Round: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, defaultCommentIDLength, len(id1))
+	id2, err := cs.AddComment("12345", Comment{TaskID: "T2", AuthorID: "pooh", AuthorName: "Pooh", Text: "Honey first"})
+	assert.NoError(t, err)
+	_, err = cs.AddComment("54321", Comment{TaskID: "T1", AuthorID: "rabbit", AuthorName: "Rabbit", Text: "Other session"})
+	assert.NoError(t, err)
+
+	comments, err = cs.GetComments("12345", "T1")
+	assert.NoError(t, err)
+	assert.Len(t, comments, 1)
+	assert.Equal(t, id1, comments[0].ID)
+	assert.Equal(t, "tigger", comments[0].EstimateUserID)
+	assert.Equal(t, 2, comments[0].Round)
+	assert.NotEmpty(t, comments[0].Time)
+	assert.Empty(t, comments[0].Edited)
+
+	comment, err := cs.GetComment("12345", id2)
+	assert.NoError(t, err)
+	assert.Equal(t, "Honey first", comment.Text)
+	_, err = cs.GetComment("54321", id2)
+	assert.Equal(t, fmt.Sprintf("Comment with ID: %s does not exist", id2), err.Error())
+
+	assert.NoError(t, cs.UpdateComment("12345", id1, "Bouncing takes even more time"))
+	err = cs.UpdateComment("12345", id1, "")
+	assert.Equal(t, "Comment text should not be empty", err.Error())
+	err = cs.UpdateComment("54321", id1, "Wrong session")
+	assert.Equal(t, fmt.Sprintf("Comment with ID: %s does not exist", id1), err.Error())
+
+	comments, err = cs.GetComments("12345", "")
+	assert.NoError(t, err)
+	assert.Len(t, comments, 2)
+	assert.Equal(t, "Bouncing takes even more time", comments[0].Text)
+	assert.NotEmpty(t, comments[0].Edited)
+	assert.Equal(t, id2, comments[1].ID)
+
+	assert.NoError(t, cs.RemoveComment("12345", id1))
+	err = cs.RemoveComment("12345", id1)
+	assert.Equal(t, fmt.Sprintf("Comment with ID: %s does not exist", id1), err.Error())
+
+	comments, err = cs.GetComments("12345", "")
+	assert.NoError(t, err)
+	assert.Len(t, comments, 1)
+	assert.Equal(t, id2, comments[0].ID)
+
+	assert.NoError(t, cs.RemoveComments("12345"))
+	comments, err = cs.GetComments("12345", "")
+	assert.NoError(t, err)
+	assert.Empty(t, comments)
+	comments, err = cs.GetComments("54321", "")
+	assert.NoError(t, err)
+	assert.Len(t, comments, 1)
+}
+
+func TestCommentRemovingDataStore(t *testing.T) {
+	m := new(MockDatastore)
+	cs := new(MockCommentStore)
+	core, logs := observer.New(zapcore.ErrorLevel)
+	ds := NewCommentRemovingDataStore(m, cs, zap.New(core))
+	m.On("RemoveSession", "12345").Return(nil)
+	m.On("RemoveSession", "54321").Return(fmt.Errorf("Specified session does not exist"))
+	cs.On("RemoveComments", "12345").Return(fmt.Errorf("Unable to remove comments"))
+
+	assert.NoError(t, ds.RemoveSession("12345"))
+	cs.AssertCalled(t, "RemoveComments", "12345")
+	assert.Equal(t, 1, logs.FilterMessage("Unable to remove comments").Len())
+
+	assert.Error(t, ds.RemoveSession("54321"))
+	cs.AssertNotCalled(t, "RemoveComments", "54321")
+}
@@ -1,37 +1,84 @@
 package datastore
 
+import (
+	"errors"
+	"fmt"
+)
+
 // DataStore defines the common interface a datastore for
 // the Doker backend must implement.
 type DataStore interface {
 	CreateSession() (string, error)
-	JoinSession(token, name string) error
-	LeaveSession(token, name string) error
+	JoinSession(token string, user User) (string, error)
+	LeaveSession(token, id string) error
+	UpdateUser(token string, user User) error
 	RemoveSession(token string) error
+	GetSessions() ([]string, error)
 	AddTask(token, id, summary string) error
 	RemoveTask(token, id string) error
 	AddEstimateToTask(token, id string, effort, standardDeviation float64) error
 	RemoveEstimateFromTask(token, id string) error
-	GetUsers(token string) ([]string, error)
+	GetUsers(token string) ([]User, error)
 	GetTasks(token string) ([]Task, error)
 	AddEstimate(token string, estimate Estimate) error
 	RemoveEstimate(token string, estimate Estimate) error
 	GetEstimates(token string) ([]Estimate, error)
+	Ping() error
+}
+
+// UserCounter is implemented by datastores, which can count the
+// users of all sessions at once instead of per session
+type UserCounter interface {
+	CountUsers() (map[string]int, error)
+}
+
+// ErrInvalid is wrapped by errors due to invalid values provided
+// by the caller, e.g. an empty user name or a negative effort
+var ErrInvalid = errors.New("Invalid value")
+
+// invalidError keeps the message of the validation error while
+// still matching ErrInvalid
+type invalidError struct {
+	msg string
+}
+
+func (e *invalidError) Error() string {
+	return e.msg
+}
+
+func (e *invalidError) Unwrap() error {
+	return ErrInvalid
+}
+
+// invalidf returns a validation error with the formatted message
+func invalidf(format string, args ...interface{}) error {
+	return &invalidError{msg: fmt.Sprintf(format, args...)}
 }
 
-// Task defines a single task
+// Task defines a single task, Finalized is set once the effort
+// and standard deviation were agreed on, which may well be zero
 type Task struct {
 	ID                string
 	Summary           string
 	Effort            float64
 	StandardDeviation float64
+	Finalized         bool
 }
 
 // Estimate defines a user estimate for a specific
-// task
+// task, the user is identified by the UserID while the
+// UserName is only kept for display. The optional Rationale
+// and Assumptions explain the estimate. Orphaned estimates
+// belong to a user or task which was removed and are only
+// kept as history.
 type Estimate struct {
 	TaskID         string
+	UserID         string
 	UserName       string
 	BestCase       float64
 	MostLikelyCase float64
 	WorstCase      float64
+	Rationale      string
+	Assumptions    []string
+	Orphaned       bool
 }
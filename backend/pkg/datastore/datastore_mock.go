@@ -16,14 +16,20 @@ func (m *MockDatastore) CreateSession() (string, error) {
 }
 
 // JoinSession implements the Datastore interface
-func (m *MockDatastore) JoinSession(t, n string) error {
-	arguments := m.Called(t, n)
-	return arguments.Error(0)
+func (m *MockDatastore) JoinSession(t string, u User) (string, error) {
+	arguments := m.Called(t, u)
+	return arguments.Get(0).(string), arguments.Error(1)
 }
 
 // LeaveSession implements the Datastore interface
-func (m *MockDatastore) LeaveSession(t, n string) error {
-	arguments := m.Called(t, n)
+func (m *MockDatastore) LeaveSession(t, id string) error {
+	arguments := m.Called(t, id)
+	return arguments.Error(0)
+}
+
+// UpdateUser implements the Datastore interface
+func (m *MockDatastore) UpdateUser(t string, u User) error {
+	arguments := m.Called(t, u)
 	return arguments.Error(0)
 }
 
@@ -33,6 +39,12 @@ func (m *MockDatastore) RemoveSession(t string) error {
 	return arguments.Error(0)
 }
 
+// GetSessions implements the Datastore interface
+func (m *MockDatastore) GetSessions() ([]string, error) {
+	arguments := m.Called()
+	return arguments.Get(0).([]string), arguments.Error(1)
+}
+
 // AddTask implements the Datastore interface
 func (m *MockDatastore) AddTask(t, id, s string) error {
 	arguments := m.Called(t, id, s)
@@ -58,9 +70,9 @@ func (m *MockDatastore) RemoveEstimateFromTask(t, id string) error {
 }
 
 // GetUsers implements the Datastore interface
-func (m *MockDatastore) GetUsers(t string) ([]string, error) {
+func (m *MockDatastore) GetUsers(t string) ([]User, error) {
 	arguments := m.Called(t)
-	return arguments.Get(0).([]string), arguments.Error(1)
+	return arguments.Get(0).([]User), arguments.Error(1)
 }
 
 // GetTasks implements the Datastore interface
@@ -86,3 +98,9 @@ func (m *MockDatastore) GetEstimates(t string) ([]Estimate, error) {
 	arguments := m.Called(t)
 	return arguments.Get(0).([]Estimate), arguments.Error(1)
 }
+
+// Ping implements the Datastore interface
+func (m *MockDatastore) Ping() error {
+	arguments := m.Called()
+	return arguments.Error(0)
+}
@@ -39,12 +39,13 @@ func TestJoinSessionNoError(t *testing.T) {
 	m := new(MockDatastore)
 	ds = m
 
-	m.On("JoinSession", "12345", "Tigger").Return(nil)
+	m.On("JoinSession", "12345", User{Name: "Tigger"}).Return("tigger", nil)
 
-	err := ds.JoinSession("12345", "Tigger")
+	id, err := ds.JoinSession("12345", User{Name: "Tigger"})
 
 	assert.NoError(t, err)
-	m.MethodCalled("JoinSession", "12345", "Tigger")
+	assert.Equal(t, "tigger", id)
+	m.MethodCalled("JoinSession", "12345", User{Name: "Tigger"})
 }
 
 func TestJoinSessionError(t *testing.T) {
@@ -52,13 +53,13 @@ func TestJoinSessionError(t *testing.T) {
 	m := new(MockDatastore)
 	ds = m
 
-	m.On("JoinSession", "12345", "Tigger").Return(fmt.Errorf("Some error"))
+	m.On("JoinSession", "12345", User{Name: "Tigger"}).Return("", fmt.Errorf("Some error"))
 
-	err := ds.JoinSession("12345", "Tigger")
+	_, err := ds.JoinSession("12345", User{Name: "Tigger"})
 
 	assert.Error(t, err)
 	assert.Equal(t, "Some error", err.Error())
-	m.MethodCalled("JoinSession", "12345", "Tigger")
+	m.MethodCalled("JoinSession", "12345", User{Name: "Tigger"})
 }
 
 func TestLeaveSessionNoError(t *testing.T) {
@@ -66,12 +67,12 @@ func TestLeaveSessionNoError(t *testing.T) {
 	m := new(MockDatastore)
 	ds = m
 
-	m.On("LeaveSession", "12345", "Tigger").Return(nil)
+	m.On("LeaveSession", "12345", "tigger").Return(nil)
 
-	err := ds.LeaveSession("12345", "Tigger")
+	err := ds.LeaveSession("12345", "tigger")
 
 	assert.NoError(t, err)
-	m.MethodCalled("LeaveSession", "12345", "Tigger")
+	m.MethodCalled("LeaveSession", "12345", "tigger")
 }
 
 func TestLeaveSessionError(t *testing.T) {
@@ -79,13 +80,40 @@ func TestLeaveSessionError(t *testing.T) {
 	m := new(MockDatastore)
 	ds = m
 
-	m.On("LeaveSession", "12345", "Tigger").Return(fmt.Errorf("Some error"))
+	m.On("LeaveSession", "12345", "tigger").Return(fmt.Errorf("Some error"))
 
-	err := ds.LeaveSession("12345", "Tigger")
+	err := ds.LeaveSession("12345", "tigger")
 
 	assert.Error(t, err)
 	assert.Equal(t, "Some error", err.Error())
-	m.MethodCalled("LeaveSession", "12345", "Tigger")
+	m.MethodCalled("LeaveSession", "12345", "tigger")
+}
+
+func TestUpdateUserNoError(t *testing.T) {
+	var ds DataStore
+	m := new(MockDatastore)
+	ds = m
+
+	m.On("UpdateUser", "12345", User{ID: "tigger", Name: "Tigger"}).Return(nil)
+
+	err := ds.UpdateUser("12345", User{ID: "tigger", Name: "Tigger"})
+
+	assert.NoError(t, err)
+	m.MethodCalled("UpdateUser", "12345", User{ID: "tigger", Name: "Tigger"})
+}
+
+func TestUpdateUserError(t *testing.T) {
+	var ds DataStore
+	m := new(MockDatastore)
+	ds = m
+
+	m.On("UpdateUser", "12345", User{ID: "tigger", Name: "Tigger"}).Return(fmt.Errorf("Some error"))
+
+	err := ds.UpdateUser("12345", User{ID: "tigger", Name: "Tigger"})
+
+	assert.Error(t, err)
+	assert.Equal(t, "Some error", err.Error())
+	m.MethodCalled("UpdateUser", "12345", User{ID: "tigger", Name: "Tigger"})
 }
 
 func TestRemoveSessionNoError(t *testing.T) {
@@ -115,6 +143,34 @@ func TestRemoveSessionError(t *testing.T) {
 	m.MethodCalled("RemoveSession", "12345")
 }
 
+func TestGetSessionsNoError(t *testing.T) {
+	var ds DataStore
+	m := new(MockDatastore)
+	ds = m
+
+	m.On("GetSessions").Return([]string{"12345"}, nil)
+
+	sessions, err := ds.GetSessions()
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"12345"}, sessions)
+	m.MethodCalled("GetSessions")
+}
+
+func TestGetSessionsError(t *testing.T) {
+	var ds DataStore
+	m := new(MockDatastore)
+	ds = m
+
+	m.On("GetSessions").Return([]string{}, fmt.Errorf("Some error"))
+
+	_, err := ds.GetSessions()
+
+	assert.Error(t, err)
+	assert.Equal(t, "Some error", err.Error())
+	m.MethodCalled("GetSessions")
+}
+
 func TestAddTaskNoError(t *testing.T) {
 	var ds DataStore
 	m := new(MockDatastore)
@@ -228,12 +284,12 @@ func TestGetUsersNoError(t *testing.T) {
 	m := new(MockDatastore)
 	ds = m
 
-	m.On("GetUsers", "12345").Return([]string{"Tigger"}, nil)
+	m.On("GetUsers", "12345").Return([]User{{ID: "tigger", Name: "Tigger"}}, nil)
 
 	res, err := ds.GetUsers("12345")
 
 	assert.NoError(t, err)
-	assert.Equal(t, []string{"Tigger"}, res)
+	assert.Equal(t, []User{{ID: "tigger", Name: "Tigger"}}, res)
 	m.MethodCalled("GetUsers", "12345")
 }
 
@@ -242,7 +298,7 @@ func TestGetUsersError(t *testing.T) {
 	m := new(MockDatastore)
 	ds = m
 
-	m.On("GetUsers", "12345").Return([]string{}, fmt.Errorf("Some error"))
+	m.On("GetUsers", "12345").Return([]User{}, fmt.Errorf("Some error"))
 
 	_, err := ds.GetUsers("12345")
 
@@ -360,3 +416,30 @@ func TestGetEstimatesError(t *testing.T) {
 	assert.Equal(t, "Some error", err.Error())
 	m.MethodCalled("GetEstimates", "12345")
 }
+
+func TestPingNoError(t *testing.T) {
+	var ds DataStore
+	m := new(MockDatastore)
+	ds = m
+
+	m.On("Ping").Return(nil)
+
+	err := ds.Ping()
+
+	assert.NoError(t, err)
+	m.MethodCalled("Ping")
+}
+
+func TestPingError(t *testing.T) {
+	var ds DataStore
+	m := new(MockDatastore)
+	ds = m
+
+	m.On("Ping").Return(fmt.Errorf("Some error"))
+
+	err := ds.Ping()
+
+	assert.Error(t, err)
+	assert.Equal(t, "Some error", err.Error())
+	m.MethodCalled("Ping")
+}
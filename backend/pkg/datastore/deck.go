@@ -0,0 +1,36 @@
+package datastore
+
+import (
+	dbestimate "github.com/haro87/dokerb/pkg/estimate"
+)
+
+// DeckDataStore wraps a datastore and rejects estimates with
+// values which are not part of the deck of the session
+type DeckDataStore struct {
+	DataStore
+	settings SettingsStore
+}
+
+// NewDeckDataStore wraps the provided datastore so that estimates
+// are checked against the deck kept in the provided settings store
+func NewDeckDataStore(ds DataStore, settings SettingsStore) DataStore {
+	return &DeckDataStore{
+		DataStore: ds,
+		settings:  settings,
+	}
+}
+
+// AddEstimate implements the Datastore interface
+func (d *DeckDataStore) AddEstimate(token string, estimate Estimate) error {
+	settings, err := d.settings.GetSettings(token)
+	if err != nil {
+		return err
+	}
+
+	for _, v := range []float64{estimate.BestCase, estimate.MostLikelyCase, estimate.WorstCase} {
+		if !dbestimate.InDeck(settings.Cards, v) {
+			return invalidf("Estimate value %g is not part of the session deck", v)
+		}
+	}
+	return d.DataStore.AddEstimate(token, estimate)
+}
@@ -0,0 +1,47 @@
+package datastore
+
+import (
+	"fmt"
+	dbestimate "github.com/haro87/dokerb/pkg/estimate"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestDeckAddEstimate(t *testing.T) {
+	m := new(MockDatastore)
+	s := new(MockSettingsStore)
+	ds := NewDeckDataStore(m, s)
+	cards, _ := dbestimate.Deck("fibonacci")
+	s.On("GetSettings", "12345").Return(Settings{Deck: "fibonacci", Cards: cards}, nil)
+
+	ok := Estimate{TaskID: "TEST01", UserID: "tigger", BestCase: 2, MostLikelyCase: 3, WorstCase: 8}
+	m.On("AddEstimate", "12345", ok).Return(nil)
+	assert.NoError(t, ds.AddEstimate("12345", ok))
+
+	invalid := Estimate{TaskID: "TEST01", UserID: "rabbit", BestCase: 2, MostLikelyCase: 4, WorstCase: 8}
+	err := ds.AddEstimate("12345", invalid)
+	assert.Equal(t, "Estimate value 4 is not part of the session deck", err.Error())
+	m.AssertNotCalled(t, "AddEstimate", "12345", invalid)
+}
+
+func TestDeckAddEstimateWithoutDeck(t *testing.T) {
+	m := new(MockDatastore)
+	s := new(MockSettingsStore)
+	ds := NewDeckDataStore(m, s)
+	s.On("GetSettings", "12345").Return(Settings{Anonymity: AnonymityOff}, nil)
+
+	est := Estimate{TaskID: "TEST01", UserID: "tigger", BestCase: 1.2, MostLikelyCase: 3.4, WorstCase: 5.6}
+	m.On("AddEstimate", "12345", est).Return(nil)
+	assert.NoError(t, ds.AddEstimate("12345", est))
+}
+
+func TestDeckAddEstimatePassesSettingsErrors(t *testing.T) {
+	m := new(MockDatastore)
+	s := new(MockSettingsStore)
+	ds := NewDeckDataStore(m, s)
+	s.On("GetSettings", "12345").Return(Settings{}, fmt.Errorf("Unable to query settings"))
+
+	err := ds.AddEstimate("12345", Estimate{TaskID: "TEST01"})
+	assert.Equal(t, "Unable to query settings", err.Error())
+	m.AssertNotCalled(t, "AddEstimate", "12345", Estimate{TaskID: "TEST01"})
+}
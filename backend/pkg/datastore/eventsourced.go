@@ -0,0 +1,618 @@
+package datastore
+
+import (
+	"fmt"
+	"github.com/genjidb/genji"
+	"github.com/genjidb/genji/database"
+	"github.com/genjidb/genji/document"
+	dbestimate "github.com/haro87/dokerb/pkg/estimate"
+	"go.uber.org/zap"
+	"time"
+)
+
+// SessionReplayer defines the interface for restoring the state of
+// a session as of an earlier point in time
+type SessionReplayer interface {
+	ReplaySession(token string, query ReplayQuery) (SessionState, error)
+}
+
+// ReplayQuery defines up to which event a session is replayed, a
+// Seq of 0 and a zero Time don't restrict the replay
+type ReplayQuery struct {
+	Seq  int
+	Time time.Time
+}
+
+// SessionState defines the state of a session after the event
+// with the sequence number Seq
+type SessionState struct {
+	Token     string
+	Seq       int
+	Time      string
+	Removed   bool
+	Users     []User
+	Tasks     []Task
+	Estimates []Estimate
+}
+
+// Event defines a single change of a session, depending on the
+// type only some of the fields are set. Removal keeps the removal
+// policy users left and tasks were removed with, so that replays
+// don't depend on the current policy. Estimates are only set for
+// the baseline of sessions, which existed before their events.
+type Event struct {
+	Seq       int
+	Type      string
+	Time      string
+	User      User
+	Task      Task
+	Estimate  Estimate
+	Users     []User
+	Tasks     []Task
+	Estimates []Estimate
+	Removal   string
+}
+
+// Types of session events
+const (
+	EventSessionCreated  = "session.created"
+	EventSessionRemoved  = "session.removed"
+	EventUserJoined      = "user.joined"
+	EventUserLeft        = "user.left"
+	EventUserUpdated     = "user.updated"
+	EventTaskAdded       = "task.added"
+	EventTaskRemoved     = "task.removed"
+	EventTaskFinalized   = "task.finalized"
+	EventTaskReset       = "task.reset"
+	EventEstimateAdded   = "estimate.added"
+	EventEstimateRemoved = "estimate.removed"
+)
+
+// EventSourcedDatastore derives the state of sessions from an
+// ordered log of events. Every change appends an event and updates
+// the projection inside the sessions table within one transaction,
+// reads are served from the projection.
+type EventSourcedDatastore struct {
+	db           GenjiDB
+	logger       *zap.Logger
+	redactTokens bool
+	removal      string
+}
+
+// EventSourcedOption configures optional behaviour of the
+// EventSourcedDatastore
+type EventSourcedOption func(e *EventSourcedDatastore)
+
+// WithEventRemovalPolicy sets whether the estimates of users leaving
+// a session and of removed tasks are removed as well or kept as
+// orphaned history, estimates are removed by default
+func WithEventRemovalPolicy(policy string) EventSourcedOption {
+	return func(e *EventSourcedDatastore) {
+		e.removal = policy
+	}
+}
+
+// WithEventLogger sets the logger used for reporting datastore errors
+func WithEventLogger(logger *zap.Logger) EventSourcedOption {
+	return func(e *EventSourcedDatastore) {
+		e.logger = logger
+	}
+}
+
+// WithEventRedactedTokens enables or disables the redaction of
+// session tokens inside log messages
+func WithEventRedactedTokens(redact bool) EventSourcedOption {
+	return func(e *EventSourcedDatastore) {
+		e.redactTokens = redact
+	}
+}
+
+type eventRow struct {
+	Token string
+	Event
+}
+
+// projection is the current state of a session as stored
+// inside the sessions table
+type projection struct {
+	Token     string
+	Seq       int
+	Users     []User
+	Tasks     []Task
+	Estimates []Estimate
+}
+
+// NewEventSourcedDatastore creates a new EventSourcedDatastore and
+// the tables it requires
+func NewEventSourcedDatastore(db GenjiDB, opts ...EventSourcedOption) (*EventSourcedDatastore, error) {
+	if db == nil {
+		return nil, fmt.Errorf("Proper DB must be provided and not nil")
+	}
+
+	e := &EventSourcedDatastore{db: db, removal: RemovalCascade}
+	for _, opt := range opts {
+		opt(e)
+	}
+	if e.logger == nil {
+		e.logger = zap.NewNop()
+	}
+
+	if err := validateRemovalPolicy(e.removal); err != nil {
+		return nil, err
+	}
+
+	for _, table := range []string{"events", "sessions"} {
+		if err := db.Exec("CREATE TABLE " + table); err != nil && err.Error() != "table already exists" {
+			return nil, fmt.Errorf("Unable to create %s table", table)
+		}
+	}
+
+	return e, nil
+}
+
+// CreateSession implements the Datastore interface
+func (e *EventSourcedDatastore) CreateSession() (string, error) {
+	token, err := generateToken(defaultTokenLength)
+	if err != nil {
+		return "", fmt.Errorf("Unable to create session token")
+	}
+
+	err = e.db.Update(func(tx *genji.Tx) error {
+		return insertSessionInTx(tx, token, []User{}, nil)
+	})
+
+	if err != nil {
+		return "", fmt.Errorf("Unable to store session token")
+	}
+	return token, nil
+}
+
+// JoinSession implements the Datastore interface, the ID of a
+// new user is part of the event, so that replays yield the same ID
+func (e *EventSourcedDatastore) JoinSession(token string, user User) (string, error) {
+	if user.ID == "" {
+		id, err := generateToken(defaultUserIDLength)
+		if err != nil {
+			return "", fmt.Errorf("Unable to create user ID")
+		}
+		user.ID = id
+	}
+
+	if err := e.append(token, Event{Type: EventUserJoined, User: user}); err != nil {
+		return "", err
+	}
+	return user.ID, nil
+}
+
+// LeaveSession implements the Datastore interface
+func (e *EventSourcedDatastore) LeaveSession(token, id string) error {
+	return e.append(token, Event{Type: EventUserLeft, User: User{ID: id}, Removal: e.removal})
+}
+
+// UpdateUser implements the Datastore interface
+func (e *EventSourcedDatastore) UpdateUser(token string, user User) error {
+	return e.append(token, Event{Type: EventUserUpdated, User: user})
+}
+
+// RemoveSession implements the Datastore interface, the events
+// of the session are kept, so that it can still be replayed
+func (e *EventSourcedDatastore) RemoveSession(token string) error {
+	return e.append(token, Event{Type: EventSessionRemoved})
+}
+
+// GetSessions implements the Datastore interface
+func (e *EventSourcedDatastore) GetSessions() ([]string, error) {
+	tokens := []string{}
+
+	res, err := e.db.Query("SELECT token FROM sessions")
+	if err != nil {
+		return tokens, fmt.Errorf("Unable to get sessions")
+	}
+
+	defer res.Close()
+
+	err = res.Iterate(func(d document.Document) error {
+		var token string
+		if err := document.Scan(d, &token); err != nil {
+			return err
+		}
+		tokens = append(tokens, token)
+		return nil
+	})
+
+	if err != nil {
+		return []string{}, fmt.Errorf("Unable to get sessions")
+	}
+	return tokens, nil
+}
+
+// CountUsers implements the UserCounter interface
+func (e *EventSourcedDatastore) CountUsers() (map[string]int, error) {
+	counts, err := countUsers(e.db)
+	if err != nil {
+		return map[string]int{}, fmt.Errorf("Unable to count users")
+	}
+	return counts, nil
+}
+
+// AddTask implements the Datastore interface
+func (e *EventSourcedDatastore) AddTask(token, id, summary string) error {
+	return e.append(token, Event{Type: EventTaskAdded, Task: Task{ID: id, Summary: summary}})
+}
+
+// RemoveTask implements the Datastore interface
+func (e *EventSourcedDatastore) RemoveTask(token, id string) error {
+	return e.append(token, Event{Type: EventTaskRemoved, Task: Task{ID: id}, Removal: e.removal})
+}
+
+// AddEstimateToTask implements the Datastore interface
+func (e *EventSourcedDatastore) AddEstimateToTask(token, id string, effort, standardDeviation float64) error {
+	return e.append(token, Event{Type: EventTaskFinalized, Task: Task{ID: id, Effort: effort, StandardDeviation: standardDeviation}})
+}
+
+// RemoveEstimateFromTask implements the Datastore interface
+func (e *EventSourcedDatastore) RemoveEstimateFromTask(token, id string) error {
+	return e.append(token, Event{Type: EventTaskReset, Task: Task{ID: id}})
+}
+
+// GetUsers implements the Datastore interface
+func (e *EventSourcedDatastore) GetUsers(token string) ([]User, error) {
+	p, err := e.projection(token)
+	return p.Users, err
+}
+
+// GetTasks implements the Datastore interface
+func (e *EventSourcedDatastore) GetTasks(token string) ([]Task, error) {
+	p, err := e.projection(token)
+	return p.Tasks, err
+}
+
+// AddEstimate implements the Datastore interface
+func (e *EventSourcedDatastore) AddEstimate(token string, estimate Estimate) error {
+	return e.append(token, Event{Type: EventEstimateAdded, Estimate: estimate})
+}
+
+// RemoveEstimate implements the Datastore interface
+func (e *EventSourcedDatastore) RemoveEstimate(token string, estimate Estimate) error {
+	return e.append(token, Event{Type: EventEstimateRemoved, Estimate: estimate})
+}
+
+// GetEstimates implements the Datastore interface
+func (e *EventSourcedDatastore) GetEstimates(token string) ([]Estimate, error) {
+	p, err := e.projection(token)
+	return withUserNames(p.Estimates, p.Users), err
+}
+
+// Ping implements the Datastore interface
+func (e *EventSourcedDatastore) Ping() error {
+	res, err := e.db.Query("SELECT token FROM sessions LIMIT 1")
+	if err != nil {
+		return fmt.Errorf("Unable to query sessions table")
+	}
+	return res.Close()
+}
+
+// ReplaySession restores the state of the session by applying all
+// its events up to the requested sequence number and time
+func (e *EventSourcedDatastore) ReplaySession(token string, query ReplayQuery) (SessionState, error) {
+	state := SessionState{Token: token}
+
+	if len(token) != defaultTokenLength {
+		return state, invalidf("Session token does not match desired length")
+	}
+	if query.Seq < 0 {
+		return state, invalidf("Sequence number must be >= 0, provided: %d", query.Seq)
+	}
+
+	res, err := e.db.Query("SELECT * FROM events WHERE token = ? ORDER BY seq", token)
+	if err != nil {
+		e.logSessionError("Unable to query events", token, err)
+		return state, fmt.Errorf("Unable to query events")
+	}
+
+	defer res.Close()
+
+	found := false
+	err = res.Iterate(func(d document.Document) error {
+		var ev Event
+		if err := document.StructScan(d, &ev); err != nil {
+			return err
+		}
+		found = true
+
+		if query.Seq > 0 && ev.Seq > query.Seq {
+			return nil
+		}
+		if !query.Time.IsZero() {
+			t, err := time.Parse(time.RFC3339Nano, ev.Time)
+			if err != nil || t.After(query.Time) {
+				return err
+			}
+		}
+		return applyEvent(&state, ev)
+	})
+
+	if err != nil {
+		return state, fmt.Errorf("Unable to replay session")
+	}
+	if !found {
+		return state, fmt.Errorf("Specified session does not exist")
+	}
+	if state.Seq == 0 {
+		return state, fmt.Errorf("Specified session did not exist yet")
+	}
+	state.Estimates = withUserNames(state.Estimates, state.Users)
+	return state, nil
+}
+
+// append validates the event against the current state of the
+// session and stores it together with the new state
+func (e *EventSourcedDatastore) append(token string, ev Event) error {
+	if len(token) != defaultTokenLength {
+		return invalidf("Session token does not match desired length")
+	}
+	if err := checkEvent(&ev); err != nil {
+		return err
+	}
+
+	return e.db.Update(func(tx *genji.Tx) error {
+		d, err := tx.QueryDocument("SELECT * FROM sessions WHERE token = ?", token)
+		if err == database.ErrDocumentNotFound {
+			return fmt.Errorf("Specified session does not exist")
+		}
+		if err != nil {
+			e.logSessionError("Unable to query session", token, err)
+			return fmt.Errorf("Unable to query session")
+		}
+
+		var p projection
+		if err := document.StructScan(d, &p); err != nil {
+			e.logSessionError("Unable to scan session", token, err)
+			return fmt.Errorf("Unable to query session")
+		}
+
+		state := SessionState{Token: token, Seq: p.Seq, Users: p.Users, Tasks: p.Tasks, Estimates: p.Estimates}
+
+		// Sessions created without event sourcing start with a
+		// baseline, so that they can be replayed
+		if p.Seq == 0 {
+			if state, err = e.insertBaseline(tx, token, p); err != nil {
+				return err
+			}
+		}
+
+		ev.Seq = state.Seq + 1
+		ev.Time = time.Now().UTC().Format(time.RFC3339Nano)
+		if err := applyEvent(&state, ev); err != nil {
+			return err
+		}
+
+		if err := tx.Exec("INSERT INTO events VALUES ?", &eventRow{Token: token, Event: ev}); err != nil {
+			e.logSessionError("Unable to store event", token, err)
+			return fmt.Errorf("Unable to store event")
+		}
+		if err := tx.Exec("DELETE FROM sessions WHERE token = ?", token); err != nil {
+			e.logSessionError("Unable to remove projection", token, err)
+			return fmt.Errorf("Unable to store session")
+		}
+		if state.Removed {
+			return nil
+		}
+		return insertProjection(tx, state)
+	})
+}
+
+// insertBaseline stores the session.created event of a session,
+// which was created without events, out of its current state
+func (e *EventSourcedDatastore) insertBaseline(tx *genji.Tx, token string, p projection) (SessionState, error) {
+	ev := Event{
+		Seq:       1,
+		Type:      EventSessionCreated,
+		Time:      time.Now().UTC().Format(time.RFC3339Nano),
+		Users:     p.Users,
+		Tasks:     p.Tasks,
+		Estimates: p.Estimates,
+	}
+	state := SessionState{Token: token}
+	if err := applyEvent(&state, ev); err != nil {
+		return state, err
+	}
+
+	if err := tx.Exec("INSERT INTO events VALUES ?", &eventRow{Token: token, Event: ev}); err != nil {
+		e.logSessionError("Unable to store baseline event", token, err)
+		return state, fmt.Errorf("Unable to store event")
+	}
+	return state, nil
+}
+
+func (e *EventSourcedDatastore) logSessionError(msg, token string, err error) {
+	if e.redactTokens {
+		token = RedactToken(token)
+	}
+	e.logger.Error(msg, zap.String("session", token), zap.Error(err))
+}
+
+func (e *EventSourcedDatastore) projection(token string) (projection, error) {
+	var p projection
+
+	if len(token) != defaultTokenLength {
+		return p, invalidf("Session token does not match desired length")
+	}
+
+	res, err := e.db.Query("SELECT * FROM sessions WHERE token = ?", token)
+	if err != nil {
+		e.logSessionError("Unable to query session", token, err)
+		return p, fmt.Errorf("Unable to query session")
+	}
+
+	defer res.Close()
+
+	found := false
+	err = res.Iterate(func(d document.Document) error {
+		found = true
+		return document.StructScan(d, &p)
+	})
+
+	if err != nil {
+		return p, fmt.Errorf("Unable to query session")
+	}
+	if !found {
+		return p, fmt.Errorf("Specified session does not exist")
+	}
+	return p, nil
+}
+
+// insertSessionInTx stores a new session with the provided users and
+// tasks together with its session.created event, so that sessions
+// created from templates can be replayed as well
+func insertSessionInTx(tx *genji.Tx, token string, users []User, tasks []Task) error {
+	ev := Event{
+		Seq:   1,
+		Type:  EventSessionCreated,
+		Time:  time.Now().UTC().Format(time.RFC3339Nano),
+		Users: users,
+		Tasks: tasks,
+	}
+	state := SessionState{Token: token}
+	if err := applyEvent(&state, ev); err != nil {
+		return err
+	}
+
+	if err := tx.Exec("INSERT INTO events VALUES ?", &eventRow{Token: token, Event: ev}); err != nil {
+		return fmt.Errorf("Unable to store event")
+	}
+	return insertProjection(tx, state)
+}
+
+func insertProjection(tx *genji.Tx, state SessionState) error {
+	p := projection{
+		Token:     state.Token,
+		Seq:       state.Seq,
+		Users:     state.Users,
+		Tasks:     state.Tasks,
+		Estimates: state.Estimates,
+	}
+	if err := tx.Exec("INSERT INTO sessions VALUES ?", &p); err != nil {
+		return fmt.Errorf("Unable to store session")
+	}
+	return nil
+}
+
+// checkEvent validates the event independent of the session state
+// and completes the profile of joining and updated users
+func checkEvent(ev *Event) error {
+	switch ev.Type {
+	case EventUserJoined:
+		return validateUser(&ev.User)
+	case EventUserLeft, EventUserUpdated:
+		if ev.User.ID == "" {
+			return invalidf("User ID should not be empty")
+		}
+		if ev.Type == EventUserUpdated {
+			return validateUser(&ev.User)
+		}
+	case EventTaskAdded, EventTaskRemoved, EventTaskReset:
+		if ev.Task.ID == "" {
+			return invalidf("ID should not be empty")
+		}
+	case EventTaskFinalized:
+		if ev.Task.ID == "" {
+			return invalidf("ID should not be empty")
+		}
+		if ev.Task.Effort < 0 {
+			return invalidf("Effort < 0 not allowed")
+		}
+		if ev.Task.StandardDeviation < 0 {
+			return invalidf("Standard deviation < 0 not allowed")
+		}
+	case EventEstimateAdded:
+		est := &ev.Estimate
+		if est.TaskID == "" {
+			return invalidf("Task ID should not be empty")
+		}
+		if est.UserID == "" {
+			return invalidf("User ID should not be empty")
+		}
+		if _, err := dbestimate.NewDelphiEstimate(est.BestCase, est.MostLikelyCase, est.WorstCase); err != nil {
+			return err
+		}
+		return sanitizeReasoning(est)
+	}
+	return nil
+}
+
+// applyEvent applies the event to the state, if the event is
+// valid for the state
+func applyEvent(state *SessionState, ev Event) error {
+	if state.Removed {
+		return fmt.Errorf("Specified session does not exist")
+	}
+
+	var err error
+	switch ev.Type {
+	case EventSessionCreated:
+		state.Users = append([]User{}, ev.Users...)
+		state.Tasks = append([]Task{}, ev.Tasks...)
+		state.Estimates = append([]Estimate{}, ev.Estimates...)
+	case EventSessionRemoved:
+		state.Removed = true
+	case EventUserJoined:
+		user, err := newUser(state.Users, ev.User)
+		if err != nil {
+			return err
+		}
+		state.Users = append(state.Users, user)
+	case EventUserLeft:
+		if state.Users, err = removeUser(state.Users, ev.User.ID); err != nil {
+			return fmt.Errorf("Unable to remove user: %s from session", ev.User.ID)
+		}
+		state.Estimates = removeEstimatesOf(state.Estimates, ev.Removal, ofUser(ev.User.ID))
+	case EventUserUpdated:
+		if state.Users, err = updateUser(state.Users, ev.User); err != nil {
+			return err
+		}
+	case EventTaskAdded:
+		if taskExists(state.Tasks, ev.Task.ID) {
+			return invalidf("Task with ID: %s already part of session", ev.Task.ID)
+		}
+		state.Tasks = append(state.Tasks, Task{ID: ev.Task.ID, Summary: ev.Task.Summary})
+	case EventTaskRemoved:
+		if state.Tasks, err = removeTask(state.Tasks, ev.Task.ID); err != nil {
+			return fmt.Errorf("Unable to remove Task: %s from session", ev.Task.ID)
+		}
+		state.Estimates = removeEstimatesOf(state.Estimates, ev.Removal, ofTask(ev.Task.ID))
+	case EventTaskFinalized, EventTaskReset:
+		if !taskExists(state.Tasks, ev.Task.ID) {
+			return fmt.Errorf("Task with ID: %s does not exist", ev.Task.ID)
+		}
+		for i, t := range state.Tasks {
+			if t.ID == ev.Task.ID {
+				state.Tasks[i].Effort = ev.Task.Effort
+				state.Tasks[i].StandardDeviation = ev.Task.StandardDeviation
+				state.Tasks[i].Finalized = ev.Type == EventTaskFinalized
+			}
+		}
+	case EventEstimateAdded:
+		est := ev.Estimate
+		if estimateExists(state.Estimates, est) {
+			return invalidf("Specified estimate already exists")
+		}
+		if err := checkEstimator(state.Users, est); err != nil {
+			return err
+		}
+		if !taskExists(state.Tasks, est.TaskID) {
+			return invalidf("Task with ID: %s is not part of session", est.TaskID)
+		}
+		state.Estimates = append(state.Estimates, withUserNames([]Estimate{est}, state.Users)...)
+	case EventEstimateRemoved:
+		if state.Estimates, err = removeEstimate(state.Estimates, ev.Estimate); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("Unknown event type: %s", ev.Type)
+	}
+
+	state.Seq = ev.Seq
+	state.Time = ev.Time
+	return nil
+}
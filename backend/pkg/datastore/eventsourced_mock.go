@@ -0,0 +1,16 @@
+package datastore
+
+import (
+	"github.com/stretchr/testify/mock"
+)
+
+// MockSessionReplayer represents the mocked object
+type MockSessionReplayer struct {
+	mock.Mock
+}
+
+// ReplaySession implements the SessionReplayer interface
+func (m *MockSessionReplayer) ReplaySession(t string, q ReplayQuery) (SessionState, error) {
+	arguments := m.Called(t, q)
+	return arguments.Get(0).(SessionState), arguments.Error(1)
+}
@@ -0,0 +1,326 @@
+package datastore
+
+import (
+	"errors"
+	"fmt"
+	"github.com/genjidb/genji/sql/query"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewEventSourcedDatastoreNilDB(t *testing.T) {
+	_, err := NewEventSourcedDatastore(nil)
+	assert.Equal(t, "Proper DB must be provided and not nil", err.Error())
+}
+
+func TestNewEventSourcedDatastoreFailsDueToCreateTable(t *testing.T) {
+	setupAndTearDown := setupTestCaseForMock(t)
+	defer setupAndTearDown(t)
+	m.On("Exec", "CREATE TABLE events").Return(fmt.Errorf("Ooops, something went wrong"))
+
+	_, err := NewEventSourcedDatastore(m)
+	assert.Equal(t, "Unable to create events table", err.Error())
+}
+
+func TestEventSourcedDatastoreWithRealDB(t *testing.T) {
+	setupAndTearDown := setupTestCaseForRealDB(t)
+	defer setupAndTearDown(t)
+	es, err := NewEventSourcedDatastore(db)
+	assert.NoError(t, err)
+	assert.NoError(t, es.Ping())
+
+	token, err := es.CreateSession()
+	assert.NoError(t, err)
+	est := Estimate{TaskID: "T1", UserID: "tigger", BestCase: 1, MostLikelyCase: 2, WorstCase: 3}
+
+	join(t, es, token, "Tigger")
+	join(t, es, token, "Pooh")
+	assert.NoError(t, es.LeaveSession(token, "pooh"))
+	assert.NoError(t, es.AddTask(token, "T1", "Login"))
+	assert.NoError(t, es.AddTask(token, "T2", "Logout"))
+	assert.NoError(t, es.RemoveTask(token, "T2"))
+	assert.NoError(t, es.AddEstimate(token, est))
+	assert.NoError(t, es.AddEstimateToTask(token, "T1", 2, 0.3))
+
+	sessions, err := es.GetSessions()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{token}, sessions)
+
+	counts, err := es.CountUsers()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{token: 1}, counts)
+
+	users, err := es.GetUsers(token)
+	assert.NoError(t, err)
+	assert.Equal(t, []User{{ID: "tigger", Name: "Tigger", Role: RoleEstimator}}, users)
+
+	tasks, err := es.GetTasks(token)
+	assert.NoError(t, err)
+	assert.Equal(t, []Task{{ID: "T1", Summary: "Login", Effort: 2, StandardDeviation: 0.3, Finalized: true}}, tasks)
+
+	ests, err := es.GetEstimates(token)
+	assert.NoError(t, err)
+	est.UserName = "Tigger"
+	assert.Equal(t, []Estimate{est}, ests)
+
+	assert.NoError(t, es.RemoveEstimateFromTask(token, "T1"))
+	assert.NoError(t, es.RemoveEstimate(token, est))
+
+	tasks, _ = es.GetTasks(token)
+	assert.Equal(t, []Task{{ID: "T1", Summary: "Login"}}, tasks)
+	ests, _ = es.GetEstimates(token)
+	assert.Empty(t, ests)
+
+	assert.NoError(t, es.RemoveSession(token))
+	_, err = es.GetUsers(token)
+	assert.Equal(t, "Specified session does not exist", err.Error())
+	_, err = es.JoinSession(token, User{Name: "Tigger"})
+	assert.Equal(t, "Specified session does not exist", err.Error())
+}
+
+func TestEventSourcedDatastoreUsersWithRealDB(t *testing.T) {
+	setupAndTearDown := setupTestCaseForRealDB(t)
+	defer setupAndTearDown(t)
+	es, err := NewEventSourcedDatastore(db)
+	assert.NoError(t, err)
+
+	token, err := es.CreateSession()
+	assert.NoError(t, err)
+	id, err := es.JoinSession(token, User{Name: "Alex"})
+	assert.NoError(t, err)
+	assert.Len(t, id, 16)
+	join(t, es, token, "Pooh")
+	assert.NoError(t, es.UpdateUser(token, User{ID: "pooh", Name: "Pooh", Role: RoleObserver}))
+	assert.NoError(t, es.AddTask(token, "T1", "Login"))
+	assert.NoError(t, es.AddEstimate(token, Estimate{TaskID: "T1", UserID: id, BestCase: 1, MostLikelyCase: 2, WorstCase: 3}))
+	assert.NoError(t, es.UpdateUser(token, User{ID: id, Name: "Alexandra"}))
+
+	err = es.AddEstimate(token, Estimate{TaskID: "T1", UserID: "pooh", BestCase: 1, MostLikelyCase: 2, WorstCase: 3})
+	assert.True(t, errors.Is(err, ErrObserver))
+
+	ests, err := es.GetEstimates(token)
+	assert.NoError(t, err)
+	assert.Equal(t, "Alexandra", ests[0].UserName)
+
+	// Replays yield the same IDs and the names as of the event
+	state, err := es.ReplaySession(token, ReplayQuery{Seq: 6})
+	assert.NoError(t, err)
+	assert.Equal(t, []User{{ID: id, Name: "Alex", Role: RoleEstimator}, {ID: "pooh", Name: "Pooh", Role: RoleObserver}}, state.Users)
+	assert.Equal(t, "Alex", state.Estimates[0].UserName)
+}
+
+func TestEventSourcedDatastoreErrorsWithRealDB(t *testing.T) {
+	setupAndTearDown := setupTestCaseForRealDB(t)
+	defer setupAndTearDown(t)
+	es, err := NewEventSourcedDatastore(db)
+	assert.NoError(t, err)
+
+	token, err := es.CreateSession()
+	assert.NoError(t, err)
+	join(t, es, token, "Tigger")
+	assert.NoError(t, es.AddTask(token, "T1", "Login"))
+
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"token length", joinErr(es, "12345", User{Name: "Pooh"}), "Session token does not match desired length"},
+		{"unknown session", joinErr(es, "eaf27c59ecdf0db4e165c4f940e176ec", User{Name: "Pooh"}), "Specified session does not exist"},
+		{"empty user", joinErr(es, token, User{}), "User name should not be empty"},
+		{"duplicate user", joinErr(es, token, User{ID: "tigger", Name: "Tigger"}), "User with ID: tigger already part of session"},
+		{"unknown user", es.LeaveSession(token, "pooh"), "Unable to remove user: pooh from session"},
+		{"update unknown user", es.UpdateUser(token, User{ID: "pooh", Name: "Pooh"}), "User: pooh is not part of session"},
+		{"update without ID", es.UpdateUser(token, User{Name: "Pooh"}), "User ID should not be empty"},
+		{"empty task", es.AddTask(token, "", "Login"), "ID should not be empty"},
+		{"duplicate task", es.AddTask(token, "T1", "Login"), "Task with ID: T1 already part of session"},
+		{"unknown task", es.RemoveTask(token, "T2"), "Unable to remove Task: T2 from session"},
+		{"negative effort", es.AddEstimateToTask(token, "T1", -1, 0), "Effort < 0 not allowed"},
+		{"negative deviation", es.AddEstimateToTask(token, "T1", 1, -1), "Standard deviation < 0 not allowed"},
+		{"finalize unknown task", es.AddEstimateToTask(token, "T2", 1, 0), "Task with ID: T2 does not exist"},
+		{"estimate unknown user", es.AddEstimate(token, Estimate{TaskID: "T1", UserID: "pooh", BestCase: 1, MostLikelyCase: 2, WorstCase: 3}), "User: pooh is not part of session"},
+		{"estimate unknown task", es.AddEstimate(token, Estimate{TaskID: "T2", UserID: "tigger", BestCase: 1, MostLikelyCase: 2, WorstCase: 3}), "Task with ID: T2 is not part of session"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, tc.err.Error())
+		})
+	}
+
+	// Failed mutations don't append events
+	state, err := es.ReplaySession(token, ReplayQuery{})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, state.Seq)
+}
+
+func TestReplaySessionWithRealDB(t *testing.T) {
+	setupAndTearDown := setupTestCaseForRealDB(t)
+	defer setupAndTearDown(t)
+	es, err := NewEventSourcedDatastore(db)
+	assert.NoError(t, err)
+
+	before := time.Now()
+	token, err := es.CreateSession()
+	assert.NoError(t, err)
+	join(t, es, token, "Tigger")
+	assert.NoError(t, es.AddTask(token, "T1", "Login"))
+	assert.NoError(t, es.AddEstimateToTask(token, "T1", 2, 0.3))
+	assert.NoError(t, es.RemoveSession(token))
+
+	state, err := es.ReplaySession(token, ReplayQuery{Seq: 3})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, state.Seq)
+	assert.False(t, state.Removed)
+	assert.Equal(t, []User{{ID: "tigger", Name: "Tigger", Role: RoleEstimator}}, state.Users)
+	assert.Equal(t, []Task{{ID: "T1", Summary: "Login"}}, state.Tasks)
+	assert.Equal(t, []Estimate{}, state.Estimates)
+
+	state, err = es.ReplaySession(token, ReplayQuery{})
+	assert.NoError(t, err)
+	assert.Equal(t, 5, state.Seq)
+	assert.True(t, state.Removed)
+	assert.Equal(t, []Task{{ID: "T1", Summary: "Login", Effort: 2, StandardDeviation: 0.3, Finalized: true}}, state.Tasks)
+
+	state, err = es.ReplaySession(token, ReplayQuery{Time: time.Now()})
+	assert.NoError(t, err)
+	assert.Equal(t, 5, state.Seq)
+
+	_, err = es.ReplaySession(token, ReplayQuery{Time: before.Add(-time.Hour)})
+	assert.Equal(t, "Specified session did not exist yet", err.Error())
+
+	_, err = es.ReplaySession("eaf27c59ecdf0db4e165c4f940e176ec", ReplayQuery{})
+	assert.Equal(t, "Specified session does not exist", err.Error())
+
+	_, err = es.ReplaySession(token, ReplayQuery{Seq: -1})
+	assert.Equal(t, "Sequence number must be >= 0, provided: -1", err.Error())
+
+	_, err = es.ReplaySession("12345", ReplayQuery{})
+	assert.Equal(t, "Session token does not match desired length", err.Error())
+}
+
+func TestReplaySessionCreatedWithoutEventsWithRealDB(t *testing.T) {
+	setupAndTearDown := setupTestCaseForRealDB(t)
+	defer setupAndTearDown(t)
+	gds, err := NewGenjiDatastore(db)
+	assert.NoError(t, err)
+
+	// Session created before event sourcing was enabled
+	token, err := gds.CreateSession()
+	assert.NoError(t, err)
+	join(t, gds, token, "Tigger")
+	assert.NoError(t, gds.AddTask(token, "T1", "Login"))
+	assert.NoError(t, gds.AddEstimate(token, Estimate{TaskID: "T1", UserID: "tigger", BestCase: 1, MostLikelyCase: 2, WorstCase: 3}))
+
+	es, err := NewEventSourcedDatastore(db)
+	assert.NoError(t, err)
+	join(t, es, token, "Pooh")
+
+	state, err := es.ReplaySession(token, ReplayQuery{Seq: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, state.Seq)
+	assert.Equal(t, []User{{ID: "tigger", Name: "Tigger", Role: RoleEstimator}}, state.Users)
+	assert.Equal(t, []Task{{ID: "T1", Summary: "Login"}}, state.Tasks)
+	assert.Equal(t, []Estimate{{TaskID: "T1", UserID: "tigger", UserName: "Tigger", BestCase: 1, MostLikelyCase: 2, WorstCase: 3}}, state.Estimates)
+
+	state, err = es.ReplaySession(token, ReplayQuery{})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, state.Seq)
+	assert.Len(t, state.Users, 2)
+	assert.Len(t, state.Estimates, 1)
+
+	// The baseline is only stored once
+	join(t, es, token, "Rabbit")
+	state, err = es.ReplaySession(token, ReplayQuery{})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, state.Seq)
+	assert.Len(t, state.Users, 3)
+}
+
+func TestEventSourcedDatastoreLogsErrors(t *testing.T) {
+	setupAndTearDown := setupTestCaseForMock(t)
+	defer setupAndTearDown(t)
+	m.On("Exec", "CREATE TABLE events").Return(nil)
+	m.On("Exec", "CREATE TABLE sessions").Return(nil)
+	m.On("Query", "SELECT * FROM sessions WHERE token = ?").Return(new(query.Result), fmt.Errorf("Ooops, something went wrong"))
+	core, logs := observer.New(zapcore.ErrorLevel)
+	es, err := NewEventSourcedDatastore(m, WithEventLogger(zap.New(core)), WithEventRedactedTokens(true))
+	assert.NoError(t, err)
+
+	_, err = es.GetUsers("eaf27c59ecdf0db4e165c4f940e176ec")
+	assert.Equal(t, "Unable to query session", err.Error())
+
+	entries := logs.FilterMessage("Unable to query session").All()
+	assert.Len(t, entries, 1)
+	assert.Equal(t, "eaf2****", entries[0].ContextMap()["session"])
+}
+
+func TestReplaySessionCreatedFromTemplateWithRealDB(t *testing.T) {
+	setupAndTearDown := setupTestCaseForRealDB(t)
+	defer setupAndTearDown(t)
+	es, err := NewEventSourcedDatastore(db)
+	assert.NoError(t, err)
+	ts, err := NewGenjiTemplateStore(db, WithTemplateEvents(true))
+	assert.NoError(t, err)
+
+	assert.NoError(t, ts.SaveTemplate(Template{Name: "sprint", Users: []string{"Tigger"}, Tasks: []Task{{ID: "T1", Summary: "Login"}}}))
+	token, err := ts.CreateSessionFromTemplate("sprint")
+	assert.NoError(t, err)
+	join(t, es, token, "Pooh")
+
+	state, err := es.ReplaySession(token, ReplayQuery{Seq: 1})
+	assert.NoError(t, err)
+	assert.Len(t, state.Users, 1)
+	assert.Equal(t, "Tigger", state.Users[0].Name)
+	assert.Equal(t, []Task{{ID: "T1", Summary: "Login"}}, state.Tasks)
+
+	users, err := es.GetUsers(token)
+	assert.NoError(t, err)
+	assert.Equal(t, []User{state.Users[0], {ID: "pooh", Name: "Pooh", Role: RoleEstimator}}, users)
+}
+
+func join(t *testing.T, ds DataStore, token, name string) {
+	_, err := ds.JoinSession(token, User{ID: strings.ToLower(name), Name: name})
+	assert.NoError(t, err)
+}
+
+func joinErr(ds DataStore, token string, user User) error {
+	_, err := ds.JoinSession(token, user)
+	return err
+}
+
+func TestReplaySessionCreatedFromTemplateWithoutEventsWithRealDB(t *testing.T) {
+	setupAndTearDown := setupTestCaseForRealDB(t)
+	defer setupAndTearDown(t)
+	ts, err := NewGenjiTemplateStore(db)
+	assert.NoError(t, err)
+
+	assert.NoError(t, ts.SaveTemplate(Template{Name: "sprint", Users: []string{"Tigger"}}))
+	token, err := ts.CreateSessionFromTemplate("sprint")
+	assert.NoError(t, err)
+	clone, err := ts.CloneSession(token, false)
+	assert.NoError(t, err)
+
+	es, err := NewEventSourcedDatastore(db)
+	assert.NoError(t, err)
+	for _, token := range []string{token, clone} {
+		_, err = es.ReplaySession(token, ReplayQuery{})
+		assert.Equal(t, "Specified session does not exist", err.Error())
+	}
+
+	// The first change records the baseline of the session
+	join(t, es, clone, "Pooh")
+	state, err := es.ReplaySession(clone, ReplayQuery{Seq: 1})
+	assert.NoError(t, err)
+	assert.Len(t, state.Users, 1)
+	assert.Equal(t, "Tigger", state.Users[0].Name)
+
+	state, err = es.ReplaySession(clone, ReplayQuery{})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, state.Seq)
+}
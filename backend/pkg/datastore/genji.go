@@ -8,6 +8,7 @@ import (
 	"github.com/genjidb/genji/document"
 	"github.com/genjidb/genji/sql/query"
 	dbestimate "github.com/haro87/dokerb/pkg/estimate"
+	"go.uber.org/zap"
 	"sync"
 )
 
@@ -21,13 +22,44 @@ type GenjiDB interface {
 
 // GenjiDatastore struct which holds the actual database
 type GenjiDatastore struct {
-	db GenjiDB
+	db           GenjiDB
+	logger       *zap.Logger
+	redactTokens bool
+	removal      string
+}
+
+// Option configures optional behaviour of the GenjiDatastore
+type Option func(g *GenjiDatastore)
+
+// WithLogger sets the logger used for reporting datastore errors
+func WithLogger(logger *zap.Logger) Option {
+	return func(g *GenjiDatastore) {
+		g.logger = logger
+	}
+}
+
+// WithRedactedTokens enables or disables the redaction of session
+// tokens inside log messages
+func WithRedactedTokens(redact bool) Option {
+	return func(g *GenjiDatastore) {
+		g.redactTokens = redact
+	}
+}
+
+// WithRemovalPolicy sets whether the estimates of users leaving a
+// session and of removed tasks are removed as well or kept as
+// orphaned history, estimates are removed by default
+func WithRemovalPolicy(policy string) Option {
+	return func(g *GenjiDatastore) {
+		g.removal = policy
+	}
 }
 
 type session struct {
-	Token string
-	Users []string
-	Tasks []Task
+	Token     string
+	Users     []User
+	Tasks     []Task
+	Estimates []Estimate
 }
 
 var lock = &sync.Mutex{}
@@ -38,7 +70,7 @@ const defaultTokenLength int = 32
 
 // NewGenjiDatastore creates a new GenjiDatastore following
 // the singleton design pattern
-func NewGenjiDatastore(db GenjiDB) (DataStore, error) {
+func NewGenjiDatastore(db GenjiDB, opts ...Option) (DataStore, error) {
 	if si == nil {
 		lock.Lock()
 		defer lock.Unlock()
@@ -50,11 +82,23 @@ func NewGenjiDatastore(db GenjiDB) (DataStore, error) {
 	}
 
 	si.db = db
+	si.logger = zap.NewNop()
+	si.redactTokens = false
+	si.removal = RemovalCascade
+
+	for _, opt := range opts {
+		opt(si)
+	}
+
+	if err := validateRemovalPolicy(si.removal); err != nil {
+		return nil, err
+	}
 
 	err := si.db.Exec("CREATE TABLE sessions")
 
 	if err != nil {
 		if err.Error() != "table already exists" {
+			si.logger.Error("Unable to create sessions table", zap.Error(err))
 			return nil, fmt.Errorf("Unable to create sessions table")
 		}
 	}
@@ -71,51 +115,51 @@ func (g GenjiDatastore) CreateSession() (string, error) {
 	}
 	s := session{
 		Token: st,
-		Users: []string{},
+		Users: []User{},
 	}
-	err = si.db.Exec("INSERT INTO sessions VALUES ?", &s)
+	err = execForSession(st, "INSERT INTO sessions VALUES ?", &s)
 	if err != nil {
 		return "", fmt.Errorf("Unable to store session token")
 	}
 	return st, nil
 }
 
-// JoinSession allows a user with the specified name to join a
-// session identified by the given token
-func (g GenjiDatastore) JoinSession(token, name string) error {
+// JoinSession allows the provided user to join a session
+// identified by the given token and returns the ID of the user
+func (g GenjiDatastore) JoinSession(token string, user User) (string, error) {
 	if len(token) != defaultTokenLength {
-		return fmt.Errorf("Session token does not match desired length")
+		return "", invalidf("Session token does not match desired length")
 	}
-	if name == "" {
-		return fmt.Errorf("User name should not be empty")
+	if err := validateUser(&user); err != nil {
+		return "", err
 	}
 
 	se, err := sessionExists(token)
 	if !se {
-		return fmt.Errorf("Specified session does not exist")
+		return "", fmt.Errorf("Specified session does not exist")
 	}
-	var u []string
+	var u []User
 	u, err = getUsersFromSession(token)
 
-	if !userExists(u, name) {
-		u = append(u, name)
-	} else {
-		return fmt.Errorf("User with name: %s already part of session", name)
+	user, err = newUser(u, user)
+	if err != nil {
+		return "", err
 	}
+	u = append(u, user)
 
-	err = si.db.Exec("UPDATE sessions SET users = ? WHERE token = ?", u, token)
+	err = execForSession(token, "UPDATE sessions SET users = ? WHERE token = ?", u, token)
 
-	return err
+	return user.ID, err
 }
 
-// LeaveSession allows a user with the specified name to leave a
+// LeaveSession allows the user with the specified ID to leave a
 // session identified by the provided token
-func (g GenjiDatastore) LeaveSession(token, name string) error {
+func (g GenjiDatastore) LeaveSession(token, id string) error {
 	if len(token) != defaultTokenLength {
-		return fmt.Errorf("Session token does not match desired length")
+		return invalidf("Session token does not match desired length")
 	}
-	if name == "" {
-		return fmt.Errorf("User name should not be empty")
+	if id == "" {
+		return invalidf("User ID should not be empty")
 	}
 
 	se, err := sessionExists(token)
@@ -123,7 +167,7 @@ func (g GenjiDatastore) LeaveSession(token, name string) error {
 		return fmt.Errorf("Specified session does not exist")
 	}
 
-	var u []string
+	var u []User
 
 	u, err = getUsersFromSession(token)
 
@@ -131,13 +175,56 @@ func (g GenjiDatastore) LeaveSession(token, name string) error {
 		return fmt.Errorf("Unable to get Users from session")
 	}
 
-	u, err = removeUser(u, name)
+	u, err = removeUser(u, id)
 
 	if err != nil {
-		return fmt.Errorf("Unable to remove user: %s from session", name)
+		return fmt.Errorf("Unable to remove user: %s from session", id)
 	}
 
-	err = si.db.Exec("UPDATE sessions SET users = ? WHERE token = ?", u, token)
+	est, err := getEstimatesFromSession(token)
+
+	if err != nil {
+		return fmt.Errorf("Unable to get estimates from session")
+	}
+
+	est = removeEstimatesOf(est, g.removal, ofUser(id))
+
+	err = execForSession(token, "UPDATE sessions SET users = ?, estimates = ? WHERE token = ?", u, est, token)
+
+	return err
+}
+
+// UpdateUser replaces the name, avatar, email and role of the user
+// with the same ID inside the session identified by the given token
+func (g GenjiDatastore) UpdateUser(token string, user User) error {
+	if len(token) != defaultTokenLength {
+		return invalidf("Session token does not match desired length")
+	}
+	if user.ID == "" {
+		return invalidf("User ID should not be empty")
+	}
+	if err := validateUser(&user); err != nil {
+		return err
+	}
+
+	se, err := sessionExists(token)
+	if !se {
+		return fmt.Errorf("Specified session does not exist")
+	}
+
+	var u []User
+
+	u, err = getUsersFromSession(token)
+
+	if err != nil {
+		return fmt.Errorf("Unable to get Users from session")
+	}
+
+	if u, err = updateUser(u, user); err != nil {
+		return err
+	}
+
+	err = execForSession(token, "UPDATE sessions SET users = ? WHERE token = ?", u, token)
 
 	return err
 }
@@ -145,7 +232,7 @@ func (g GenjiDatastore) LeaveSession(token, name string) error {
 // RemoveSession deletes a session from the datastore
 func (g GenjiDatastore) RemoveSession(token string) error {
 	if len(token) != defaultTokenLength {
-		return fmt.Errorf("Session token does not match desired length")
+		return invalidf("Session token does not match desired length")
 	}
 
 	se, err := sessionExists(token)
@@ -153,19 +240,41 @@ func (g GenjiDatastore) RemoveSession(token string) error {
 		return fmt.Errorf("Specified session does not exist")
 	}
 
-	err = si.db.Exec("DELETE FROM sessions WHERE token = ?", token)
+	err = execForSession(token, "DELETE FROM sessions WHERE token = ?", token)
 
 	return err
 }
 
+// GetSessions returns the tokens of all existing sessions
+func (g GenjiDatastore) GetSessions() ([]string, error) {
+	tokens, err := getSessionTokens()
+
+	if err != nil {
+		return []string{}, fmt.Errorf("Unable to get sessions")
+	}
+
+	return tokens, nil
+}
+
+// CountUsers implements the UserCounter interface
+func (g GenjiDatastore) CountUsers() (map[string]int, error) {
+	counts, err := countUsers(g.db)
+
+	if err != nil {
+		return map[string]int{}, fmt.Errorf("Unable to count users")
+	}
+
+	return counts, nil
+}
+
 // AddTask adds a new task to the specified session
 // identified by the provided ID and with an optional summary
 func (g GenjiDatastore) AddTask(token, id, summary string) error {
 	if len(token) != defaultTokenLength {
-		return fmt.Errorf("Session token does not match desired length")
+		return invalidf("Session token does not match desired length")
 	}
 	if id == "" {
-		return fmt.Errorf("ID should not be empty")
+		return invalidf("ID should not be empty")
 	}
 
 	se, err := sessionExists(token)
@@ -178,10 +287,10 @@ func (g GenjiDatastore) AddTask(token, id, summary string) error {
 	if !taskExists(tasks, id) {
 		tasks = append(tasks, Task{ID: id, Summary: summary})
 	} else {
-		return fmt.Errorf("Task with ID: %s already part of session", id)
+		return invalidf("Task with ID: %s already part of session", id)
 	}
 
-	err = si.db.Exec("UPDATE sessions SET tasks = ? WHERE token = ?", tasks, token)
+	err = execForSession(token, "UPDATE sessions SET tasks = ? WHERE token = ?", tasks, token)
 
 	return err
 }
@@ -191,10 +300,10 @@ func (g GenjiDatastore) AddTask(token, id, summary string) error {
 // ID
 func (g GenjiDatastore) RemoveTask(token, id string) error {
 	if len(token) != defaultTokenLength {
-		return fmt.Errorf("Session token does not match desired length")
+		return invalidf("Session token does not match desired length")
 	}
 	if id == "" {
-		return fmt.Errorf("ID should not be empty")
+		return invalidf("ID should not be empty")
 	}
 
 	se, err := sessionExists(token)
@@ -216,7 +325,15 @@ func (g GenjiDatastore) RemoveTask(token, id string) error {
 		return fmt.Errorf("Unable to remove Task: %s from session", id)
 	}
 
-	err = si.db.Exec("UPDATE sessions SET tasks = ? WHERE token = ?", tasks, token)
+	est, err := getEstimatesFromSession(token)
+
+	if err != nil {
+		return fmt.Errorf("Unable to get estimates from session")
+	}
+
+	est = removeEstimatesOf(est, g.removal, ofTask(id))
+
+	err = execForSession(token, "UPDATE sessions SET tasks = ?, estimates = ? WHERE token = ?", tasks, est, token)
 
 	return err
 }
@@ -226,16 +343,16 @@ func (g GenjiDatastore) RemoveTask(token, id string) error {
 // session identified by the given token
 func (g GenjiDatastore) AddEstimateToTask(token, id string, effort, standardDeviation float64) error {
 	if len(token) != defaultTokenLength {
-		return fmt.Errorf("Session token does not match desired length")
+		return invalidf("Session token does not match desired length")
 	}
 	if id == "" {
-		return fmt.Errorf("ID should not be empty")
+		return invalidf("ID should not be empty")
 	}
 	if effort < 0 {
-		return fmt.Errorf("Effort < 0 not allowed")
+		return invalidf("Effort < 0 not allowed")
 	}
 	if standardDeviation < 0 {
-		return fmt.Errorf("Standard deviation < 0 not allowed")
+		return invalidf("Standard deviation < 0 not allowed")
 	}
 
 	se, err := sessionExists(token)
@@ -259,12 +376,13 @@ func (g GenjiDatastore) AddEstimateToTask(token, id string, effort, standardDevi
 		if task.ID == id {
 			task.Effort = effort
 			task.StandardDeviation = standardDeviation
+			task.Finalized = true
 			tasks[i] = task
 			break
 		}
 	}
 
-	err = si.db.Exec("UPDATE sessions SET tasks = ? WHERE token = ?", tasks, token)
+	err = execForSession(token, "UPDATE sessions SET tasks = ? WHERE token = ?", tasks, token)
 
 	return err
 }
@@ -274,10 +392,10 @@ func (g GenjiDatastore) AddEstimateToTask(token, id string, effort, standardDevi
 // session identified by the given token
 func (g GenjiDatastore) RemoveEstimateFromTask(token, id string) error {
 	if len(token) != defaultTokenLength {
-		return fmt.Errorf("Session token does not match desired length")
+		return invalidf("Session token does not match desired length")
 	}
 	if id == "" {
-		return fmt.Errorf("ID should not be empty")
+		return invalidf("ID should not be empty")
 	}
 
 	se, err := sessionExists(token)
@@ -301,25 +419,26 @@ func (g GenjiDatastore) RemoveEstimateFromTask(token, id string) error {
 		if task.ID == id {
 			task.Effort = 0.0
 			task.StandardDeviation = 0.0
+			task.Finalized = false
 			tasks[i] = task
 			break
 		}
 	}
 
-	err = si.db.Exec("UPDATE sessions SET tasks = ? WHERE token = ?", tasks, token)
+	err = execForSession(token, "UPDATE sessions SET tasks = ? WHERE token = ?", tasks, token)
 
 	return err
 }
 
 // GetUsers returns all users of a given session
-func (g GenjiDatastore) GetUsers(token string) ([]string, error) {
+func (g GenjiDatastore) GetUsers(token string) ([]User, error) {
 	if len(token) != defaultTokenLength {
-		return []string{}, fmt.Errorf("Session token does not match desired length")
+		return []User{}, invalidf("Session token does not match desired length")
 	}
 
 	se, err := sessionExists(token)
 	if !se {
-		return []string{}, fmt.Errorf("Specified session does not exist")
+		return []User{}, fmt.Errorf("Specified session does not exist")
 	}
 
 	users, err := getUsersFromSession(token)
@@ -330,7 +449,7 @@ func (g GenjiDatastore) GetUsers(token string) ([]string, error) {
 // GetTasks returns all tasks of a given session
 func (g GenjiDatastore) GetTasks(token string) ([]Task, error) {
 	if len(token) != defaultTokenLength {
-		return []Task{}, fmt.Errorf("Session token does not match desired length")
+		return []Task{}, invalidf("Session token does not match desired length")
 	}
 
 	se, err := sessionExists(token)
@@ -346,21 +465,25 @@ func (g GenjiDatastore) GetTasks(token string) ([]Task, error) {
 // AddEstimate adds a new estimate to the specified session
 func (g GenjiDatastore) AddEstimate(token string, estimate Estimate) error {
 	if len(token) != defaultTokenLength {
-		return fmt.Errorf("Session token does not match desired length")
+		return invalidf("Session token does not match desired length")
 	}
 
 	if estimate.TaskID == "" {
-		return fmt.Errorf("Task ID should not be empty")
+		return invalidf("Task ID should not be empty")
 	}
 
-	if estimate.UserName == "" {
-		return fmt.Errorf("User name should not be empty")
+	if estimate.UserID == "" {
+		return invalidf("User ID should not be empty")
 	}
 
 	if _, e := dbestimate.NewDelphiEstimate(estimate.BestCase, estimate.MostLikelyCase, estimate.WorstCase); e != nil {
 		return e
 	}
 
+	if e := sanitizeReasoning(&estimate); e != nil {
+		return e
+	}
+
 	se, err := sessionExists(token)
 	if !se {
 		return fmt.Errorf("Specified session does not exist")
@@ -371,15 +494,15 @@ func (g GenjiDatastore) AddEstimate(token string, estimate Estimate) error {
 	est, err = getEstimatesFromSession(token)
 
 	if estimateExists(est, estimate) {
-		return fmt.Errorf("Specified estimate already exists")
+		return invalidf("Specified estimate already exists")
 	}
 
-	var users []string
+	var users []User
 
 	users, err = getUsersFromSession(token)
 
-	if !userExists(users, estimate.UserName) {
-		return fmt.Errorf("User: %s is not part of session", estimate.UserName)
+	if err = checkEstimator(users, estimate); err != nil {
+		return err
 	}
 
 	var tasks []Task
@@ -387,12 +510,12 @@ func (g GenjiDatastore) AddEstimate(token string, estimate Estimate) error {
 	tasks, err = getTasksFromSession(token)
 
 	if !taskExists(tasks, estimate.TaskID) {
-		return fmt.Errorf("Task with ID: %s is not part of session", estimate.TaskID)
+		return invalidf("Task with ID: %s is not part of session", estimate.TaskID)
 	}
 
-	est = append(est, estimate)
+	est = append(est, withUserNames([]Estimate{estimate}, users)...)
 
-	err = si.db.Exec("UPDATE sessions SET estimates = ? WHERE token = ?", est, token)
+	err = execForSession(token, "UPDATE sessions SET estimates = ? WHERE token = ?", est, token)
 
 	return err
 }
@@ -400,7 +523,7 @@ func (g GenjiDatastore) AddEstimate(token string, estimate Estimate) error {
 // RemoveEstimate removes a existing estimate from the specified session
 func (g GenjiDatastore) RemoveEstimate(token string, estimate Estimate) error {
 	if len(token) != defaultTokenLength {
-		return fmt.Errorf("Session token does not match desired length")
+		return invalidf("Session token does not match desired length")
 	}
 
 	se, err := sessionExists(token)
@@ -422,7 +545,7 @@ func (g GenjiDatastore) RemoveEstimate(token string, estimate Estimate) error {
 		return err
 	}
 
-	err = si.db.Exec("UPDATE sessions SET estimates = ? WHERE token = ?", est, token)
+	err = execForSession(token, "UPDATE sessions SET estimates = ? WHERE token = ?", est, token)
 
 	return err
 }
@@ -430,7 +553,7 @@ func (g GenjiDatastore) RemoveEstimate(token string, estimate Estimate) error {
 // GetEstimates returns all estimates of a specified session
 func (g GenjiDatastore) GetEstimates(token string) ([]Estimate, error) {
 	if len(token) != defaultTokenLength {
-		return []Estimate{}, fmt.Errorf("Session token does not match desired length")
+		return []Estimate{}, invalidf("Session token does not match desired length")
 	}
 
 	se, err := sessionExists(token)
@@ -439,8 +562,51 @@ func (g GenjiDatastore) GetEstimates(token string) ([]Estimate, error) {
 	}
 
 	est, err := getEstimatesFromSession(token)
+	if err != nil {
+		return est, err
+	}
 
-	return est, err
+	users, err := getUsersFromSession(token)
+
+	return withUserNames(est, users), err
+}
+
+// Ping checks whether the datastore can be queried, which
+// also requires the sessions table to exist
+func (g GenjiDatastore) Ping() error {
+	res, err := si.db.Query("SELECT token FROM sessions LIMIT 1")
+
+	if err != nil {
+		si.logger.Error("Unable to query sessions table", zap.Error(err))
+		return fmt.Errorf("Unable to query sessions table")
+	}
+
+	return res.Close()
+}
+
+// RedactToken shortens the provided session token so that it can
+// be logged without exposing the session
+func RedactToken(token string) string {
+	if len(token) <= 4 {
+		return "****"
+	}
+	return token[:4] + "****"
+}
+
+func execForSession(t, q string, args ...interface{}) error {
+	err := si.db.Exec(q, args...)
+	if err != nil {
+		logSessionError("Unable to execute statement: "+q, t, err)
+	}
+	return err
+}
+
+func logSessionError(msg, t string, err error) {
+	token := t
+	if si.redactTokens {
+		token = RedactToken(t)
+	}
+	si.logger.Error(msg, zap.String("session", token), zap.Error(err))
 }
 
 func generateToken(l int) (string, error) {
@@ -457,9 +623,28 @@ func generateToken(l int) (string, error) {
 }
 
 func sessionExists(t string) (bool, error) {
-	var tokens []string
 	sessionExists := false
+	tokens, err := getSessionTokens()
+
+	for _, elem := range tokens {
+		if elem == t {
+			sessionExists = true
+			break
+		}
+	}
+
+	return sessionExists, err
+}
+
+func getSessionTokens() ([]string, error) {
+	tokens := []string{}
+
 	res, err := si.db.Query("SELECT token FROM sessions")
+
+	if err != nil {
+		return tokens, err
+	}
+
 	defer res.Close()
 
 	err = res.Iterate(func(d document.Document) error {
@@ -472,22 +657,44 @@ func sessionExists(t string) (bool, error) {
 		return nil
 	})
 
-	for _, elem := range tokens {
-		if elem == t {
-			sessionExists = true
-			break
-		}
+	return tokens, err
+}
+
+// countUsers returns the number of users of every session,
+// keyed by session token, with a single query
+func countUsers(db GenjiDB) (map[string]int, error) {
+	counts := map[string]int{}
+
+	res, err := db.Query("SELECT token, users FROM sessions")
+
+	if err != nil {
+		return counts, err
 	}
 
-	return sessionExists, err
+	defer res.Close()
+
+	err = res.Iterate(func(d document.Document) error {
+		var s struct {
+			Token string
+			Users []User
+		}
+		if err := document.StructScan(d, &s); err != nil {
+			return err
+		}
+		counts[s.Token] = len(s.Users)
+		return nil
+	})
+
+	return counts, err
 }
 
-func getUsersFromSession(t string) ([]string, error) {
-	var users []string
+func getUsersFromSession(t string) ([]User, error) {
+	var users []User
 
 	res, err := si.db.Query("SELECT users FROM sessions WHERE token = ?", t)
 
 	if err != nil {
+		logSessionError("Unable to query users", t, err)
 		return users, err
 	}
 
@@ -510,6 +717,7 @@ func getTasksFromSession(t string) ([]Task, error) {
 	res, err := si.db.Query("SELECT tasks FROM sessions WHERE token = ?", t)
 
 	if err != nil {
+		logSessionError("Unable to query tasks", t, err)
 		return tasks, err
 	}
 
@@ -532,6 +740,7 @@ func getEstimatesFromSession(t string) ([]Estimate, error) {
 	res, err := si.db.Query("SELECT estimates FROM sessions WHERE token = ?", t)
 
 	if err != nil {
+		logSessionError("Unable to query estimates", t, err)
 		return est, err
 	}
 
@@ -548,11 +757,11 @@ func getEstimatesFromSession(t string) ([]Estimate, error) {
 	return est, err
 }
 
-func userExists(users []string, user string) bool {
+func userExists(users []User, id string) bool {
 	userExists := false
 
 	for _, elem := range users {
-		if elem == user {
+		if elem.ID == id {
 			userExists = true
 			break
 		}
@@ -574,11 +783,13 @@ func taskExists(tasks []Task, id string) bool {
 	return taskExists
 }
 
+// estimateExists reports whether the user already provided an
+// estimate for the task, orphaned estimates are not considered
 func estimateExists(estimates []Estimate, estimate Estimate) bool {
 	estimateExists := false
 
 	for _, elem := range estimates {
-		if elem.TaskID == estimate.TaskID && elem.UserName == estimate.UserName {
+		if elem.TaskID == estimate.TaskID && elem.UserID == estimate.UserID && !elem.Orphaned {
 			estimateExists = true
 			break
 		}
@@ -587,16 +798,33 @@ func estimateExists(estimates []Estimate, estimate Estimate) bool {
 	return estimateExists
 }
 
-func removeUser(users []string, user string) ([]string, error) {
-	if userExists(users, user) {
+// findEstimate returns the index of the estimate of the user for
+// the task, preferring current over orphaned estimates, or -1
+func findEstimate(estimates []Estimate, estimate Estimate) int {
+	found := -1
+	for i, e := range estimates {
+		if e.TaskID == estimate.TaskID && e.UserID == estimate.UserID {
+			if !e.Orphaned {
+				return i
+			}
+			if found < 0 {
+				found = i
+			}
+		}
+	}
+	return found
+}
+
+func removeUser(users []User, id string) ([]User, error) {
+	if userExists(users, id) {
 		for i, e := range users {
-			if e == user {
+			if e.ID == id {
 				users = append(users[:i], users[i+1:]...)
 				break
 			}
 		}
 	} else {
-		return users, fmt.Errorf("User with name: %s is not part of session", user)
+		return users, fmt.Errorf("User with ID: %s is not part of session", id)
 	}
 
 	return users, nil
@@ -618,17 +846,12 @@ func removeTask(tasks []Task, id string) ([]Task, error) {
 }
 
 func removeEstimate(estimates []Estimate, estimate Estimate) ([]Estimate, error) {
-	if estimateExists(estimates, estimate) {
-		for i, e := range estimates {
-			if e.TaskID == estimate.TaskID && e.UserName == estimate.UserName {
-				estimates = append(estimates[:i], estimates[i+1:]...)
-				break
-			}
-		}
+	if i := findEstimate(estimates, estimate); i >= 0 {
+		estimates = append(estimates[:i], estimates[i+1:]...)
 	} else {
-		return estimates, fmt.Errorf("Estimate with ID: %s and user name: %s is not part of session",
+		return estimates, fmt.Errorf("Estimate with ID: %s and user ID: %s is not part of session",
 			estimate.TaskID,
-			estimate.UserName)
+			estimate.UserID)
 	}
 
 	return estimates, nil
@@ -2,9 +2,14 @@ package datastore
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/genjidb/genji"
+	"github.com/genjidb/genji/sql/query"
 	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
 	"io/ioutil"
 	"os"
 	"testing"
@@ -136,7 +141,7 @@ func TestJoinSessionFailsDueToEmptyName(t *testing.T) {
 	m.On("Exec", "CREATE TABLE sessions").Return(nil)
 	gds, err := NewGenjiDatastore(m)
 	assert.NoError(t, err)
-	err2 := gds.JoinSession("12345678901234567890123456789012", "")
+	_, err2 := gds.JoinSession("12345678901234567890123456789012", User{})
 	assert.Equal(t, "User name should not be empty", err2.Error())
 }
 
@@ -146,7 +151,7 @@ func TestJoinSessionFailsDueToWrongTokenLength(t *testing.T) {
 	m.On("Exec", "CREATE TABLE sessions").Return(nil)
 	gds, err := NewGenjiDatastore(m)
 	assert.NoError(t, err)
-	err2 := gds.JoinSession("1234567890123456789012345678901212", "")
+	_, err2 := gds.JoinSession("1234567890123456789012345678901212", User{})
 	assert.Equal(t, "Session token does not match desired length", err2.Error())
 }
 
@@ -157,7 +162,7 @@ func TestJoinSessionFailsDueToNonExistingSessionWithRealDB(t *testing.T) {
 	assert.NoError(t, err)
 	_, err2 := gds.CreateSession()
 	assert.NoError(t, err2)
-	err3 := gds.JoinSession("12345678901234567890123456789012", "Tigger")
+	_, err3 := gds.JoinSession("12345678901234567890123456789012", User{ID: "tigger", Name: "Tigger"})
 	assert.Equal(t, "Specified session does not exist", err3.Error())
 }
 
@@ -168,7 +173,7 @@ func TestJoinSessionSuccessWithRealDB(t *testing.T) {
 	assert.NoError(t, err)
 	token, err2 := gds.CreateSession()
 	assert.NoError(t, err2)
-	err3 := gds.JoinSession(token, "Tigger")
+	_, err3 := gds.JoinSession(token, User{ID: "tigger", Name: "Tigger"})
 	assert.NoError(t, err3)
 }
 
@@ -179,31 +184,121 @@ func TestJoinSessionErrorWhileTryingToAddUserTwiceWithRealDB(t *testing.T) {
 	assert.NoError(t, err)
 	token, err2 := gds.CreateSession()
 	assert.NoError(t, err2)
-	err3 := gds.JoinSession(token, "Tigger")
+	_, err3 := gds.JoinSession(token, User{ID: "tigger", Name: "Tigger"})
 	assert.NoError(t, err3)
-	err4 := gds.JoinSession(token, "Tigger")
-	assert.Equal(t, "User with name: Tigger already part of session", err4.Error())
+	_, err4 := gds.JoinSession(token, User{ID: "tigger", Name: "Tigger"})
+	assert.Equal(t, "User with ID: tigger already part of session", err4.Error())
+}
+
+func TestJoinSessionWithSameNameTwiceWithRealDB(t *testing.T) {
+	setupAndTearDown := setupTestCaseForRealDB(t)
+	defer setupAndTearDown(t)
+	gds, err := NewGenjiDatastore(db)
+	assert.NoError(t, err)
+	token, err2 := gds.CreateSession()
+	assert.NoError(t, err2)
+	id1, err3 := gds.JoinSession(token, User{Name: "Alex"})
+	assert.NoError(t, err3)
+	id2, err4 := gds.JoinSession(token, User{Name: "Alex", Email: "alex@example.com", Role: RoleObserver})
+	assert.NoError(t, err4)
+	assert.Len(t, id1, 16)
+	assert.NotEqual(t, id1, id2)
+
+	users, err5 := gds.GetUsers(token)
+	assert.NoError(t, err5)
+	assert.Equal(t, []User{
+		{ID: id1, Name: "Alex", Role: RoleEstimator},
+		{ID: id2, Name: "Alex", Email: "alex@example.com", Role: RoleObserver},
+	}, users)
+}
+
+func TestJoinSessionFailsDueToInvalidProfile(t *testing.T) {
+	setupAndTearDown := setupTestCaseForMock(t)
+	defer setupAndTearDown(t)
+	m.On("Exec", "CREATE TABLE sessions").Return(nil)
+	gds, err := NewGenjiDatastore(m)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name string
+		user User
+		want string
+	}{
+		{"role", User{Name: "Tigger", Role: "moderator"}, "Role must be one of estimator or observer"},
+		{"email", User{Name: "Tigger", Email: "tigger"}, "Email must be a valid address, provided: tigger"},
+		{"avatar", User{Name: "Tigger", AvatarURL: "/tigger.png"}, "Avatar URL must be an absolute http or https URL, provided: /tigger.png"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := gds.JoinSession("12345678901234567890123456789012", tc.user)
+			assert.Equal(t, tc.want, err.Error())
+		})
+	}
+}
+
+func TestUpdateUserWithRealDB(t *testing.T) {
+	setupAndTearDown := setupTestCaseForRealDB(t)
+	defer setupAndTearDown(t)
+	gds, err := NewGenjiDatastore(db)
+	assert.NoError(t, err)
+	token, err2 := gds.CreateSession()
+	assert.NoError(t, err2)
+	_, err3 := gds.JoinSession(token, User{ID: "tigger", Name: "Tigger"})
+	assert.NoError(t, err3)
+	assert.NoError(t, gds.AddTask(token, "TEST01", ""))
+	assert.NoError(t, gds.AddEstimate(token, Estimate{TaskID: "TEST01", UserID: "tigger", BestCase: 1, MostLikelyCase: 2, WorstCase: 3}))
+
+	assert.NoError(t, gds.UpdateUser(token, User{ID: "tigger", Name: "Tigger Too", AvatarURL: "https://example.com/tigger.png"}))
+
+	users, err4 := gds.GetUsers(token)
+	assert.NoError(t, err4)
+	assert.Equal(t, []User{{ID: "tigger", Name: "Tigger Too", AvatarURL: "https://example.com/tigger.png", Role: RoleEstimator}}, users)
+
+	ests, err5 := gds.GetEstimates(token)
+	assert.NoError(t, err5)
+	assert.Equal(t, "Tigger Too", ests[0].UserName)
+
+	assert.Equal(t, "User: rabbit is not part of session", gds.UpdateUser(token, User{ID: "rabbit", Name: "Rabbit"}).Error())
+	assert.Equal(t, "User ID should not be empty", gds.UpdateUser(token, User{Name: "Rabbit"}).Error())
+	assert.Equal(t, "User name should not be empty", gds.UpdateUser(token, User{ID: "tigger"}).Error())
+}
+
+func TestAddEstimateFailsForObserverWithRealDB(t *testing.T) {
+	setupAndTearDown := setupTestCaseForRealDB(t)
+	defer setupAndTearDown(t)
+	gds, err := NewGenjiDatastore(db)
+	assert.NoError(t, err)
+	token, err2 := gds.CreateSession()
+	assert.NoError(t, err2)
+	_, err3 := gds.JoinSession(token, User{ID: "pooh", Name: "Pooh", Role: RoleObserver})
+	assert.NoError(t, err3)
+	assert.NoError(t, gds.AddTask(token, "TEST01", ""))
+
+	err4 := gds.AddEstimate(token, Estimate{TaskID: "TEST01", UserID: "pooh", BestCase: 1, MostLikelyCase: 2, WorstCase: 3})
+	assert.True(t, errors.Is(err4, ErrObserver))
+	assert.Equal(t, "Observers can't provide estimates: Pooh is an observer", err4.Error())
 }
 
 func TestRemoveUserFromEmptyList(t *testing.T) {
-	l, err := removeUser([]string{}, "Tigger")
-	assert.Equal(t, "User with name: Tigger is not part of session", err.Error())
+	l, err := removeUser([]User{}, "tigger")
+	assert.Equal(t, "User with ID: tigger is not part of session", err.Error())
 	assert.Len(t, l, 0)
 }
 
 func TestRemoveUserFromListWithoutThatUserBeingPartOfThatList(t *testing.T) {
-	users := []string{"Tigger", "Rabbit", "Piglet"}
-	l, err := removeUser(users, "Winnie-the-Pooh")
-	assert.Equal(t, "User with name: Winnie-the-Pooh is not part of session", err.Error())
+	users := []User{{ID: "tigger"}, {ID: "rabbit"}, {ID: "piglet"}}
+	l, err := removeUser(users, "pooh")
+	assert.Equal(t, "User with ID: pooh is not part of session", err.Error())
 	assert.Len(t, l, 3)
 }
 
 func TestRemoveUserSuccess(t *testing.T) {
-	users := []string{"Tigger", "Rabbit", "Piglet"}
-	l, err := removeUser(users, "Tigger")
+	users := []User{{ID: "tigger"}, {ID: "rabbit"}, {ID: "piglet"}}
+	l, err := removeUser(users, "tigger")
 	assert.NoError(t, err)
 	assert.Len(t, l, 2)
-	assert.NotContains(t, l, "Tigger")
+	assert.NotContains(t, l, User{ID: "tigger"})
 }
 
 func TestRemoveTaskFromEmptyList(t *testing.T) {
@@ -248,80 +343,80 @@ func TestRemoveTaskSuccess(t *testing.T) {
 }
 
 func TestRemoveEstimateFromEmptyList(t *testing.T) {
-	l, err := removeEstimate([]Estimate{}, Estimate{TaskID: "TEST01", UserName: "Tigger"})
-	assert.Equal(t, "Estimate with ID: TEST01 and user name: Tigger is not part of session", err.Error())
+	l, err := removeEstimate([]Estimate{}, Estimate{TaskID: "TEST01", UserID: "tigger"})
+	assert.Equal(t, "Estimate with ID: TEST01 and user ID: tigger is not part of session", err.Error())
 	assert.Len(t, l, 0)
 }
 
 func TestRemoveEstimateFromListWithoutThatEstimateBeingPartOfThatListDueToIDAndUserName(t *testing.T) {
 	est := []Estimate{
 		Estimate{
-			TaskID:   "TEST01",
-			UserName: "Tigger",
+			TaskID: "TEST01",
+			UserID: "tigger",
 		},
 		Estimate{
-			TaskID:   "TEST02",
-			UserName: "Rabbit",
+			TaskID: "TEST02",
+			UserID: "rabbit",
 		},
 		Estimate{
-			TaskID:   "TEST03",
-			UserName: "Piglet",
+			TaskID: "TEST03",
+			UserID: "piglet",
 		},
 	}
-	l, err := removeEstimate(est, Estimate{TaskID: "TEST04", UserName: "Tigger"})
-	assert.Equal(t, "Estimate with ID: TEST04 and user name: Tigger is not part of session", err.Error())
+	l, err := removeEstimate(est, Estimate{TaskID: "TEST04", UserID: "tigger"})
+	assert.Equal(t, "Estimate with ID: TEST04 and user ID: tigger is not part of session", err.Error())
 	assert.Len(t, l, 3)
 }
 
 func TestRemoveEstimateFromListWithoutThatEstimateBeingPartOfThatListDueToUserName(t *testing.T) {
 	est := []Estimate{
 		Estimate{
-			TaskID:   "TEST01",
-			UserName: "Tigger",
+			TaskID: "TEST01",
+			UserID: "tigger",
 		},
 		Estimate{
-			TaskID:   "TEST02",
-			UserName: "Rabbit",
+			TaskID: "TEST02",
+			UserID: "rabbit",
 		},
 		Estimate{
-			TaskID:   "TEST03",
-			UserName: "Piglet",
+			TaskID: "TEST03",
+			UserID: "piglet",
 		},
 	}
-	l, err := removeEstimate(est, Estimate{TaskID: "TEST01", UserName: "Piglet"})
-	assert.Equal(t, "Estimate with ID: TEST01 and user name: Piglet is not part of session", err.Error())
+	l, err := removeEstimate(est, Estimate{TaskID: "TEST01", UserID: "piglet"})
+	assert.Equal(t, "Estimate with ID: TEST01 and user ID: piglet is not part of session", err.Error())
 	assert.Len(t, l, 3)
 }
 
 func TestRemoveEstimateSuccess(t *testing.T) {
 	est := []Estimate{
 		Estimate{
-			TaskID:   "TEST01",
-			UserName: "Tigger",
+			TaskID: "TEST01",
+			UserID: "tigger",
 		},
 		Estimate{
-			TaskID:   "TEST02",
-			UserName: "Rabbit",
+			TaskID: "TEST02",
+			UserID: "rabbit",
 		},
 		Estimate{
-			TaskID:   "TEST03",
-			UserName: "Piglet",
+			TaskID: "TEST03",
+			UserID: "piglet",
 		},
 	}
-	l, err := removeEstimate(est, Estimate{TaskID: "TEST01", UserName: "Tigger"})
+	l, err := removeEstimate(est, Estimate{TaskID: "TEST01", UserID: "tigger"})
 	assert.NoError(t, err)
 	assert.Len(t, l, 2)
-	assert.NotContains(t, l, Estimate{TaskID: "TEST01", UserName: "Tigger"})
+	assert.NotContains(t, l, Estimate{TaskID: "TEST01", UserID: "tigger"})
 }
 
-func TestLeaveSessionFailsDueToEmptyName(t *testing.T) {
+func TestLeaveSessionFailsDueToEmptyID(t *testing.T) {
 	setupAndTearDown := setupTestCaseForMock(t)
 	defer setupAndTearDown(t)
 	m.On("Exec", "CREATE TABLE sessions").Return(nil)
 	gds, err := NewGenjiDatastore(m)
 	assert.NoError(t, err)
 	err2 := gds.LeaveSession("12345678901234567890123456789012", "")
-	assert.Equal(t, "User name should not be empty", err2.Error())
+	assert.Equal(t, "User ID should not be empty", err2.Error())
 }
 
 func TestLeaveSessionFailsDueToWrongTokenLength(t *testing.T) {
@@ -341,7 +436,7 @@ func TestLeaveSessionFailsDueToNonExistingSessionWithRealDB(t *testing.T) {
 	assert.NoError(t, err)
 	_, err2 := gds.CreateSession()
 	assert.NoError(t, err2)
-	err3 := gds.LeaveSession("12345678901234567890123456789012", "Tigger")
+	err3 := gds.LeaveSession("12345678901234567890123456789012", "tigger")
 	assert.Equal(t, "Specified session does not exist", err3.Error())
 }
 
@@ -352,11 +447,11 @@ func TestLeaveSessionSuccessWithRealDB(t *testing.T) {
 	assert.NoError(t, err)
 	token, err2 := gds.CreateSession()
 	assert.NoError(t, err2)
-	err3 := gds.JoinSession(token, "Tigger")
+	_, err3 := gds.JoinSession(token, User{ID: "tigger", Name: "Tigger"})
 	assert.NoError(t, err3)
-	err4 := gds.JoinSession(token, "Rabbit")
+	_, err4 := gds.JoinSession(token, User{ID: "rabbit", Name: "Rabbit"})
 	assert.NoError(t, err4)
-	err5 := gds.LeaveSession(token, "Tigger")
+	err5 := gds.LeaveSession(token, "tigger")
 	assert.NoError(t, err5)
 }
 
@@ -392,6 +487,50 @@ func TestRemoveSessionSuccessWithRealDB(t *testing.T) {
 	assert.NoError(t, err3)
 }
 
+func TestGetSessionsEmptyWithRealDB(t *testing.T) {
+	setupAndTearDown := setupTestCaseForRealDB(t)
+	defer setupAndTearDown(t)
+	gds, err := NewGenjiDatastore(db)
+	assert.NoError(t, err)
+	sessions, err2 := gds.GetSessions()
+	assert.NoError(t, err2)
+	assert.Empty(t, sessions)
+}
+
+func TestGetSessionsSuccessWithRealDB(t *testing.T) {
+	setupAndTearDown := setupTestCaseForRealDB(t)
+	defer setupAndTearDown(t)
+	gds, err := NewGenjiDatastore(db)
+	assert.NoError(t, err)
+	token1, err2 := gds.CreateSession()
+	assert.NoError(t, err2)
+	token2, err3 := gds.CreateSession()
+	assert.NoError(t, err3)
+	err4 := gds.RemoveSession(token1)
+	assert.NoError(t, err4)
+	sessions, err5 := gds.GetSessions()
+	assert.NoError(t, err5)
+	assert.Equal(t, []string{token2}, sessions)
+}
+
+func TestCountUsersWithRealDB(t *testing.T) {
+	setupAndTearDown := setupTestCaseForRealDB(t)
+	defer setupAndTearDown(t)
+	gds, err := NewGenjiDatastore(db)
+	assert.NoError(t, err)
+	token1, err := gds.CreateSession()
+	assert.NoError(t, err)
+	token2, err := gds.CreateSession()
+	assert.NoError(t, err)
+	_, err = gds.JoinSession(token1, User{Name: "Tigger"})
+	assert.NoError(t, err)
+	_, err = gds.JoinSession(token1, User{Name: "Pooh"})
+	assert.NoError(t, err)
+	counts, err := gds.(UserCounter).CountUsers()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{token1: 2, token2: 0}, counts)
+}
+
 func TestAddTaskToSessionFailsDueToEmptyID(t *testing.T) {
 	setupAndTearDown := setupTestCaseForMock(t)
 	defer setupAndTearDown(t)
@@ -547,11 +686,11 @@ func TestGetUsersFromSessionSuccessWithRealDB(t *testing.T) {
 	assert.NoError(t, err)
 	token, err2 := gds.CreateSession()
 	assert.NoError(t, err2)
-	err3 := gds.JoinSession(token, "Tigger")
+	_, err3 := gds.JoinSession(token, User{ID: "tigger", Name: "Tigger"})
 	assert.NoError(t, err3)
 	users, err4 := gds.GetUsers(token)
 	assert.NoError(t, err4)
-	assert.Equal(t, []string{"Tigger"}, users)
+	assert.Equal(t, []User{{ID: "tigger", Name: "Tigger", Role: RoleEstimator}}, users)
 }
 
 func TestGetTasksFromSessionFailsDueToWrongTokenLength(t *testing.T) {
@@ -736,18 +875,18 @@ func TestAddEstimateToSessionFailsDueToEmptyTaskID(t *testing.T) {
 	m.On("Exec", "CREATE TABLE sessions").Return(nil)
 	gds, err := NewGenjiDatastore(m)
 	assert.NoError(t, err)
-	err2 := gds.AddEstimate("12345678901234567890123456789012", Estimate{TaskID: "", UserName: "Tigger"})
+	err2 := gds.AddEstimate("12345678901234567890123456789012", Estimate{TaskID: "", UserID: "tigger"})
 	assert.Equal(t, "Task ID should not be empty", err2.Error())
 }
 
-func TestAddEstimateToSessionFailsDueToEmptyUserName(t *testing.T) {
+func TestAddEstimateToSessionFailsDueToEmptyUserID(t *testing.T) {
 	setupAndTearDown := setupTestCaseForMock(t)
 	defer setupAndTearDown(t)
 	m.On("Exec", "CREATE TABLE sessions").Return(nil)
 	gds, err := NewGenjiDatastore(m)
 	assert.NoError(t, err)
 	err2 := gds.AddEstimate("12345678901234567890123456789012", Estimate{TaskID: "TEST01", UserName: ""})
-	assert.Equal(t, "User name should not be empty", err2.Error())
+	assert.Equal(t, "User ID should not be empty", err2.Error())
 }
 
 func TestAddEstimateToSessionFailsDueToWrongTokenLength(t *testing.T) {
@@ -756,7 +895,7 @@ func TestAddEstimateToSessionFailsDueToWrongTokenLength(t *testing.T) {
 	m.On("Exec", "CREATE TABLE sessions").Return(nil)
 	gds, err := NewGenjiDatastore(m)
 	assert.NoError(t, err)
-	err2 := gds.AddEstimate("1234567890123456789012345678901212", Estimate{TaskID: "TEST01", UserName: "Tigger"})
+	err2 := gds.AddEstimate("1234567890123456789012345678901212", Estimate{TaskID: "TEST01", UserID: "tigger"})
 	assert.Equal(t, "Session token does not match desired length", err2.Error())
 }
 
@@ -766,7 +905,7 @@ func TestAddEstimateToSessionFailsDueToWrongValueForBestCase(t *testing.T) {
 	m.On("Exec", "CREATE TABLE sessions").Return(nil)
 	gds, err := NewGenjiDatastore(m)
 	assert.NoError(t, err)
-	err2 := gds.AddEstimate("12345678901234567890123456789012", Estimate{TaskID: "TEST01", UserName: "Tigger", BestCase: -0.1})
+	err2 := gds.AddEstimate("12345678901234567890123456789012", Estimate{TaskID: "TEST01", UserID: "tigger", BestCase: -0.1})
 	assert.Equal(t, "Best case must be >= 0, provided: -0.1", err2.Error())
 }
 
@@ -777,7 +916,7 @@ func TestAddEstimateToSessionFailsDueToNonExistingSessionWithRealDB(t *testing.T
 	assert.NoError(t, err)
 	_, err2 := gds.CreateSession()
 	assert.NoError(t, err2)
-	err3 := gds.AddEstimate("12345678901234567890123456789012", Estimate{TaskID: "TEST01", UserName: "Tigger", BestCase: 0.1, MostLikelyCase: 0.5, WorstCase: 1.0})
+	err3 := gds.AddEstimate("12345678901234567890123456789012", Estimate{TaskID: "TEST01", UserID: "tigger", BestCase: 0.1, MostLikelyCase: 0.5, WorstCase: 1.0})
 	assert.Equal(t, "Specified session does not exist", err3.Error())
 }
 
@@ -788,13 +927,13 @@ func TestAddEstimateToSessionFailsDueToEstimateAlreadyExistingWithRealDB(t *test
 	assert.NoError(t, err)
 	token, err2 := gds.CreateSession()
 	assert.NoError(t, err2)
-	err3 := gds.JoinSession(token, "Tigger")
+	_, err3 := gds.JoinSession(token, User{ID: "tigger", Name: "Tigger"})
 	assert.NoError(t, err3)
 	err4 := gds.AddTask(token, "TEST01", "")
 	assert.NoError(t, err4)
-	err5 := gds.AddEstimate(token, Estimate{TaskID: "TEST01", UserName: "Tigger", BestCase: 0.1, MostLikelyCase: 0.5, WorstCase: 1.0})
+	err5 := gds.AddEstimate(token, Estimate{TaskID: "TEST01", UserID: "tigger", BestCase: 0.1, MostLikelyCase: 0.5, WorstCase: 1.0})
 	assert.NoError(t, err5)
-	err6 := gds.AddEstimate(token, Estimate{TaskID: "TEST01", UserName: "Tigger", BestCase: 0.1, MostLikelyCase: 0.5, WorstCase: 1.0})
+	err6 := gds.AddEstimate(token, Estimate{TaskID: "TEST01", UserID: "tigger", BestCase: 0.1, MostLikelyCase: 0.5, WorstCase: 1.0})
 	assert.Equal(t, "Specified estimate already exists", err6.Error())
 }
 
@@ -807,8 +946,8 @@ func TestAddEstimateToSessionFailsDueToUserNotPartOfSessionWithRealDB(t *testing
 	assert.NoError(t, err2)
 	err3 := gds.AddTask(token, "TEST01", "")
 	assert.NoError(t, err3)
-	err4 := gds.AddEstimate(token, Estimate{TaskID: "TEST01", UserName: "Tigger", BestCase: 0.1, MostLikelyCase: 0.5, WorstCase: 1.0})
-	assert.Equal(t, "User: Tigger is not part of session", err4.Error())
+	err4 := gds.AddEstimate(token, Estimate{TaskID: "TEST01", UserID: "tigger", BestCase: 0.1, MostLikelyCase: 0.5, WorstCase: 1.0})
+	assert.Equal(t, "User: tigger is not part of session", err4.Error())
 }
 
 func TestAddEstimateToSessionFailsDueToTaskNotPartOfSessionWithRealDB(t *testing.T) {
@@ -818,9 +957,9 @@ func TestAddEstimateToSessionFailsDueToTaskNotPartOfSessionWithRealDB(t *testing
 	assert.NoError(t, err)
 	token, err2 := gds.CreateSession()
 	assert.NoError(t, err2)
-	err3 := gds.JoinSession(token, "Tigger")
+	_, err3 := gds.JoinSession(token, User{ID: "tigger", Name: "Tigger"})
 	assert.NoError(t, err3)
-	err4 := gds.AddEstimate(token, Estimate{TaskID: "TEST01", UserName: "Tigger", BestCase: 0.1, MostLikelyCase: 0.5, WorstCase: 1.0})
+	err4 := gds.AddEstimate(token, Estimate{TaskID: "TEST01", UserID: "tigger", BestCase: 0.1, MostLikelyCase: 0.5, WorstCase: 1.0})
 	assert.Equal(t, "Task with ID: TEST01 is not part of session", err4.Error())
 }
 
@@ -852,17 +991,17 @@ func TestAddEstimateToSessionAndGetEstimatesSuccessWithRealDB(t *testing.T) {
 	assert.NoError(t, err)
 	token, err2 := gds.CreateSession()
 	assert.NoError(t, err2)
-	err3 := gds.JoinSession(token, "Tigger")
+	_, err3 := gds.JoinSession(token, User{ID: "tigger", Name: "Tigger"})
 	assert.NoError(t, err3)
-	err4 := gds.JoinSession(token, "Rabbit")
+	_, err4 := gds.JoinSession(token, User{ID: "rabbit", Name: "Rabbit"})
 	assert.NoError(t, err4)
 	err5 := gds.AddTask(token, "TEST01", "")
 	assert.NoError(t, err5)
 	err6 := gds.AddTask(token, "TEST02", "eat honey")
 	assert.NoError(t, err6)
-	err7 := gds.AddEstimate(token, Estimate{TaskID: "TEST01", UserName: "Tigger", BestCase: 0.1, MostLikelyCase: 0.5, WorstCase: 1.0})
+	err7 := gds.AddEstimate(token, Estimate{TaskID: "TEST01", UserID: "tigger", BestCase: 0.1, MostLikelyCase: 0.5, WorstCase: 1.0})
 	assert.NoError(t, err7)
-	err8 := gds.AddEstimate(token, Estimate{TaskID: "TEST01", UserName: "Rabbit", BestCase: 0.5, MostLikelyCase: 1.5, WorstCase: 2.5})
+	err8 := gds.AddEstimate(token, Estimate{TaskID: "TEST01", UserID: "rabbit", BestCase: 0.5, MostLikelyCase: 1.5, WorstCase: 2.5})
 	assert.NoError(t, err8)
 	ests, err9 := gds.GetEstimates(token)
 	assert.NoError(t, err9)
@@ -884,7 +1023,7 @@ func TestRemoveEstimateFromSessionFailsDueToWrongTokenLength(t *testing.T) {
 	m.On("Exec", "CREATE TABLE sessions").Return(nil)
 	gds, err := NewGenjiDatastore(m)
 	assert.NoError(t, err)
-	err2 := gds.RemoveEstimate("1234567890123456789012345678901212", Estimate{TaskID: "TEST01", UserName: "Tigger", BestCase: 0.1, MostLikelyCase: 0.5, WorstCase: 1.0})
+	err2 := gds.RemoveEstimate("1234567890123456789012345678901212", Estimate{TaskID: "TEST01", UserID: "tigger", BestCase: 0.1, MostLikelyCase: 0.5, WorstCase: 1.0})
 	assert.Equal(t, "Session token does not match desired length", err2.Error())
 }
 
@@ -895,7 +1034,7 @@ func TestRemoveEstimateFromSessionFailsDueToNonExistingSessionWithRealDB(t *test
 	assert.NoError(t, err)
 	_, err2 := gds.CreateSession()
 	assert.NoError(t, err2)
-	err3 := gds.RemoveEstimate("12345678901234567890123456789012", Estimate{TaskID: "TEST01", UserName: "Tigger", BestCase: 0.1, MostLikelyCase: 0.5, WorstCase: 1.0})
+	err3 := gds.RemoveEstimate("12345678901234567890123456789012", Estimate{TaskID: "TEST01", UserID: "tigger", BestCase: 0.1, MostLikelyCase: 0.5, WorstCase: 1.0})
 	assert.Equal(t, "Specified session does not exist", err3.Error())
 }
 
@@ -906,23 +1045,23 @@ func TestRemoveEstimateFromSessionFailsDueTOEstimateNotBeingPartOfSessionWithRea
 	assert.NoError(t, err)
 	token, err2 := gds.CreateSession()
 	assert.NoError(t, err2)
-	err3 := gds.JoinSession(token, "Tigger")
+	_, err3 := gds.JoinSession(token, User{ID: "tigger", Name: "Tigger"})
 	assert.NoError(t, err3)
-	err4 := gds.JoinSession(token, "Rabbit")
+	_, err4 := gds.JoinSession(token, User{ID: "rabbit", Name: "Rabbit"})
 	assert.NoError(t, err4)
 	err5 := gds.AddTask(token, "TEST01", "")
 	assert.NoError(t, err5)
 	err6 := gds.AddTask(token, "TEST02", "eat honey")
 	assert.NoError(t, err6)
-	err7 := gds.AddEstimate(token, Estimate{TaskID: "TEST01", UserName: "Tigger", BestCase: 0.1, MostLikelyCase: 0.5, WorstCase: 1.0})
+	err7 := gds.AddEstimate(token, Estimate{TaskID: "TEST01", UserID: "tigger", BestCase: 0.1, MostLikelyCase: 0.5, WorstCase: 1.0})
 	assert.NoError(t, err7)
-	err8 := gds.AddEstimate(token, Estimate{TaskID: "TEST01", UserName: "Rabbit", BestCase: 0.5, MostLikelyCase: 1.5, WorstCase: 2.5})
+	err8 := gds.AddEstimate(token, Estimate{TaskID: "TEST01", UserID: "rabbit", BestCase: 0.5, MostLikelyCase: 1.5, WorstCase: 2.5})
 	assert.NoError(t, err8)
 	ests, err9 := gds.GetEstimates(token)
 	assert.NoError(t, err9)
 	assert.Len(t, ests, 2)
-	err10 := gds.RemoveEstimate(token, Estimate{TaskID: "TEST02", UserName: "Rabbit", BestCase: 0.5, MostLikelyCase: 1.5, WorstCase: 2.5})
-	assert.Equal(t, "Estimate with ID: TEST02 and user name: Rabbit is not part of session", err10.Error())
+	err10 := gds.RemoveEstimate(token, Estimate{TaskID: "TEST02", UserID: "rabbit", BestCase: 0.5, MostLikelyCase: 1.5, WorstCase: 2.5})
+	assert.Equal(t, "Estimate with ID: TEST02 and user ID: rabbit is not part of session", err10.Error())
 	ests2, err11 := gds.GetEstimates(token)
 	assert.NoError(t, err11)
 	assert.Len(t, ests2, 2)
@@ -935,24 +1074,66 @@ func TestRemoveEstimateFromSessionSuccessWithRealDB(t *testing.T) {
 	assert.NoError(t, err)
 	token, err2 := gds.CreateSession()
 	assert.NoError(t, err2)
-	err3 := gds.JoinSession(token, "Tigger")
+	_, err3 := gds.JoinSession(token, User{ID: "tigger", Name: "Tigger"})
 	assert.NoError(t, err3)
-	err4 := gds.JoinSession(token, "Rabbit")
+	_, err4 := gds.JoinSession(token, User{ID: "rabbit", Name: "Rabbit"})
 	assert.NoError(t, err4)
 	err5 := gds.AddTask(token, "TEST01", "")
 	assert.NoError(t, err5)
 	err6 := gds.AddTask(token, "TEST02", "eat honey")
 	assert.NoError(t, err6)
-	err7 := gds.AddEstimate(token, Estimate{TaskID: "TEST01", UserName: "Tigger", BestCase: 0.1, MostLikelyCase: 0.5, WorstCase: 1.0})
+	err7 := gds.AddEstimate(token, Estimate{TaskID: "TEST01", UserID: "tigger", BestCase: 0.1, MostLikelyCase: 0.5, WorstCase: 1.0})
 	assert.NoError(t, err7)
-	err8 := gds.AddEstimate(token, Estimate{TaskID: "TEST01", UserName: "Rabbit", BestCase: 0.5, MostLikelyCase: 1.5, WorstCase: 2.5})
+	err8 := gds.AddEstimate(token, Estimate{TaskID: "TEST01", UserID: "rabbit", BestCase: 0.5, MostLikelyCase: 1.5, WorstCase: 2.5})
 	assert.NoError(t, err8)
 	ests, err9 := gds.GetEstimates(token)
 	assert.NoError(t, err9)
 	assert.Len(t, ests, 2)
-	err10 := gds.RemoveEstimate(token, Estimate{TaskID: "TEST01", UserName: "Rabbit", BestCase: 0.5, MostLikelyCase: 1.5, WorstCase: 2.5})
+	err10 := gds.RemoveEstimate(token, Estimate{TaskID: "TEST01", UserID: "rabbit", BestCase: 0.5, MostLikelyCase: 1.5, WorstCase: 2.5})
 	assert.NoError(t, err10)
 	ests2, err11 := gds.GetEstimates(token)
 	assert.NoError(t, err11)
 	assert.Len(t, ests2, 1)
 }
+
+func TestPingFailsDueToQueryError(t *testing.T) {
+	setupAndTearDown := setupTestCaseForMock(t)
+	defer setupAndTearDown(t)
+	m.On("Exec", "CREATE TABLE sessions").Return(nil)
+	gds, err := NewGenjiDatastore(m)
+	assert.NoError(t, err)
+	m.On("Query", "SELECT token FROM sessions LIMIT 1").Return(new(query.Result), fmt.Errorf("Ooops, something went wrong"))
+	err2 := gds.Ping()
+	assert.Equal(t, "Unable to query sessions table", err2.Error())
+}
+
+func TestPingSuccessWithRealDB(t *testing.T) {
+	setupAndTearDown := setupTestCaseForRealDB(t)
+	defer setupAndTearDown(t)
+	gds, err := NewGenjiDatastore(db)
+	assert.NoError(t, err)
+	err2 := gds.Ping()
+	assert.NoError(t, err2)
+}
+
+func TestRedactToken(t *testing.T) {
+	assert.Equal(t, "1234****", RedactToken("12345678901234567890123456789012"))
+	assert.Equal(t, "****", RedactToken("123"))
+}
+
+func TestDatastoreErrorsAreLogged(t *testing.T) {
+	setupAndTearDown := setupTestCaseForMock(t)
+	defer setupAndTearDown(t)
+	core, logs := observer.New(zapcore.InfoLevel)
+	m.On("Exec", "CREATE TABLE sessions").Return(nil)
+	gds, err := NewGenjiDatastore(m, WithLogger(zap.New(core)), WithRedactedTokens(true))
+	assert.NoError(t, err)
+	m.On("Exec", "INSERT INTO sessions VALUES ?").Return(fmt.Errorf("Ooops, something went wrong"))
+	_, err2 := gds.CreateSession()
+	assert.Error(t, err2)
+	assert.Equal(t, 1, logs.Len())
+	entry := logs.All()[0]
+	assert.Equal(t, "Unable to execute statement: INSERT INTO sessions VALUES ?", entry.Message)
+	assert.Regexp(t, "^[0-9a-f]{4}\\*{4}$", entry.ContextMap()["session"])
+	assert.Equal(t, "Ooops, something went wrong", entry.ContextMap()["error"])
+}
@@ -0,0 +1,452 @@
+package datastore
+
+import (
+	"errors"
+	"fmt"
+	"github.com/genjidb/genji"
+	"github.com/genjidb/genji/document"
+	"go.uber.org/zap"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// LifecycleStore defines the interface for storing the lifecycle
+// state of sessions and how it changed
+type LifecycleStore interface {
+	GetLifecycle(token string) (Lifecycle, error)
+	ChangeState(token string, change StateChange) (Lifecycle, error)
+	RemoveLifecycle(token string) error
+}
+
+// Lifecycle states of a session, closed and archived sessions
+// don't accept any changes
+const (
+	StateOpen       = "open"
+	StateEstimating = "estimating"
+	StateClosed     = "closed"
+	StateArchived   = "archived"
+)
+
+// ErrSessionClosed is returned if a closed or archived
+// session should be changed
+var ErrSessionClosed = errors.New("Session does not accept changes")
+
+// MaxReasonLength is the max number of characters of the
+// reason of a state change
+const MaxReasonLength int = 500
+
+// transitions defines the states a session can change to from its
+// current state, changing to open reopens a closed session
+var transitions = map[string][]string{
+	StateOpen:       {StateEstimating, StateClosed},
+	StateEstimating: {StateClosed},
+	StateClosed:     {StateOpen, StateArchived},
+	StateArchived:   {StateOpen},
+}
+
+// Lifecycle defines the state of a session and the changes
+// leading to it, oldest first
+type Lifecycle struct {
+	State   string
+	Changes []StateChange
+}
+
+// Closed reports whether the session rejects changes
+func (l Lifecycle) Closed() bool {
+	return l.State == StateClosed || l.State == StateArchived
+}
+
+// StateChange defines a change of the lifecycle state of a session,
+// Actor is the ID of the user who requested it. Reopening a session
+// requires a Reason.
+type StateChange struct {
+	From   string
+	To     string
+	Actor  string
+	Reason string
+	Time   string
+}
+
+// GenjiLifecycleStore stores the lifecycle of sessions in
+// their own Genji table
+type GenjiLifecycleStore struct {
+	db GenjiDB
+}
+
+type lifecycleRow struct {
+	Token string
+	Lifecycle
+}
+
+// NewGenjiLifecycleStore creates a new GenjiLifecycleStore and
+// the table it requires
+func NewGenjiLifecycleStore(db GenjiDB) (LifecycleStore, error) {
+	if db == nil {
+		return nil, fmt.Errorf("Proper DB must be provided and not nil")
+	}
+
+	if err := db.Exec("CREATE TABLE lifecycles"); err != nil && err.Error() != "table already exists" {
+		return nil, fmt.Errorf("Unable to create lifecycles table")
+	}
+
+	return &GenjiLifecycleStore{db: db}, nil
+}
+
+// GetLifecycle returns the lifecycle of the session, which is
+// open if its state was never changed
+func (g *GenjiLifecycleStore) GetLifecycle(token string) (Lifecycle, error) {
+	res, err := g.db.Query("SELECT * FROM lifecycles WHERE token = ?", token)
+	if err != nil {
+		return Lifecycle{}, fmt.Errorf("Unable to query lifecycle")
+	}
+
+	defer res.Close()
+
+	return scanLifecycle(res.Iterate)
+}
+
+// ChangeState changes the state of the session, if the current
+// state allows it, and records the change
+func (g *GenjiLifecycleStore) ChangeState(token string, change StateChange) (Lifecycle, error) {
+	change.Reason = sanitizeText(change.Reason, false)
+	if n := utf8.RuneCountInString(change.Reason); n > MaxReasonLength {
+		return Lifecycle{}, fmt.Errorf("Reason must not be longer than %d characters, provided: %d", MaxReasonLength, n)
+	}
+	if _, ok := transitions[change.To]; !ok {
+		return Lifecycle{}, fmt.Errorf("State must be one of open, estimating, closed or archived")
+	}
+
+	var lifecycle Lifecycle
+	err := g.db.Update(func(tx *genji.Tx) error {
+		res, err := tx.Query("SELECT * FROM lifecycles WHERE token = ?", token)
+		if err != nil {
+			return fmt.Errorf("Unable to query lifecycle")
+		}
+		lifecycle, err = scanLifecycle(res.Iterate)
+		res.Close()
+		if err != nil {
+			return err
+		}
+
+		if !canChange(lifecycle.State, change.To) {
+			return fmt.Errorf("Session can't change from %s to %s", lifecycle.State, change.To)
+		}
+		if change.To == StateOpen && change.Reason == "" {
+			return fmt.Errorf("Reason should not be empty when reopening a session")
+		}
+
+		change.From = lifecycle.State
+		change.Time = time.Now().UTC().Format(time.RFC3339)
+		lifecycle.State = change.To
+		lifecycle.Changes = append(lifecycle.Changes, change)
+
+		if err := tx.Exec("DELETE FROM lifecycles WHERE token = ?", token); err != nil {
+			return fmt.Errorf("Unable to store lifecycle")
+		}
+		if err := tx.Exec("INSERT INTO lifecycles VALUES ?", &lifecycleRow{Token: token, Lifecycle: lifecycle}); err != nil {
+			return fmt.Errorf("Unable to store lifecycle")
+		}
+		return nil
+	})
+
+	if err != nil {
+		return Lifecycle{}, err
+	}
+	return lifecycle, nil
+}
+
+// RemoveLifecycle removes the lifecycle of the session, which
+// is open afterwards
+func (g *GenjiLifecycleStore) RemoveLifecycle(token string) error {
+	if err := g.db.Exec("DELETE FROM lifecycles WHERE token = ?", token); err != nil {
+		return fmt.Errorf("Unable to remove lifecycle")
+	}
+	return nil
+}
+
+func scanLifecycle(iterate func(fn func(d document.Document) error) error) (Lifecycle, error) {
+	lifecycle := Lifecycle{State: StateOpen, Changes: []StateChange{}}
+	err := iterate(func(d document.Document) error {
+		return document.StructScan(d, &lifecycle)
+	})
+	if lifecycle.Changes == nil {
+		lifecycle.Changes = []StateChange{}
+	}
+	return lifecycle, err
+}
+
+func canChange(from, to string) bool {
+	for _, s := range transitions[from] {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
+
+// LockingDataStore wraps a datastore and rejects all changes
+// of closed or archived sessions. It implements the LifecycleStore
+// interface as well, so that the state of a session can't change
+// while a change of the session is in progress.
+type LockingDataStore struct {
+	DataStore
+	lifecycles LifecycleStore
+	logger     *zap.Logger
+	mu         sync.RWMutex
+}
+
+// NewLockingDataStore wraps the provided datastore so that sessions
+// are locked according to the provided lifecycle store, state changes
+// have to be done via the returned store. A nil logger disables logging.
+func NewLockingDataStore(ds DataStore, lifecycles LifecycleStore, logger *zap.Logger) *LockingDataStore {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &LockingDataStore{
+		DataStore:  ds,
+		lifecycles: lifecycles,
+		logger:     logger,
+	}
+}
+
+// GetLifecycle implements the LifecycleStore interface
+func (l *LockingDataStore) GetLifecycle(token string) (Lifecycle, error) {
+	return l.lifecycles.GetLifecycle(token)
+}
+
+// ChangeState implements the LifecycleStore interface, it waits
+// for changes of sessions in progress
+func (l *LockingDataStore) ChangeState(token string, change StateChange) (Lifecycle, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.lifecycles.ChangeState(token, change)
+}
+
+// RemoveLifecycle implements the LifecycleStore interface
+func (l *LockingDataStore) RemoveLifecycle(token string) error {
+	return l.lifecycles.RemoveLifecycle(token)
+}
+
+// RemoveSession implements the Datastore interface, the lifecycle
+// is removed together with the session
+func (l *LockingDataStore) RemoveSession(token string) error {
+	return l.guard(token, func() error {
+		if err := l.DataStore.RemoveSession(token); err != nil {
+			return err
+		}
+		if err := l.lifecycles.RemoveLifecycle(token); err != nil {
+			l.logger.Error("Unable to remove lifecycle", zap.String("session", RedactToken(token)), zap.Error(err))
+		}
+		return nil
+	})
+}
+
+// JoinSession implements the Datastore interface
+func (l *LockingDataStore) JoinSession(token string, user User) (string, error) {
+	var id string
+	err := l.guard(token, func() error {
+		var err error
+		id, err = l.DataStore.JoinSession(token, user)
+		return err
+	})
+	return id, err
+}
+
+// LeaveSession implements the Datastore interface
+func (l *LockingDataStore) LeaveSession(token, id string) error {
+	return l.guard(token, func() error {
+		return l.DataStore.LeaveSession(token, id)
+	})
+}
+
+// UpdateUser implements the Datastore interface
+func (l *LockingDataStore) UpdateUser(token string, user User) error {
+	return l.guard(token, func() error {
+		return l.DataStore.UpdateUser(token, user)
+	})
+}
+
+// AddTask implements the Datastore interface
+func (l *LockingDataStore) AddTask(token, id, summary string) error {
+	return l.guard(token, func() error {
+		return l.DataStore.AddTask(token, id, summary)
+	})
+}
+
+// RemoveTask implements the Datastore interface
+func (l *LockingDataStore) RemoveTask(token, id string) error {
+	return l.guard(token, func() error {
+		return l.DataStore.RemoveTask(token, id)
+	})
+}
+
+// AddEstimateToTask implements the Datastore interface
+func (l *LockingDataStore) AddEstimateToTask(token, id string, effort, standardDeviation float64) error {
+	return l.guard(token, func() error {
+		return l.DataStore.AddEstimateToTask(token, id, effort, standardDeviation)
+	})
+}
+
+// RemoveEstimateFromTask implements the Datastore interface
+func (l *LockingDataStore) RemoveEstimateFromTask(token, id string) error {
+	return l.guard(token, func() error {
+		return l.DataStore.RemoveEstimateFromTask(token, id)
+	})
+}
+
+// AddEstimate implements the Datastore interface
+func (l *LockingDataStore) AddEstimate(token string, estimate Estimate) error {
+	return l.guard(token, func() error {
+		return l.DataStore.AddEstimate(token, estimate)
+	})
+}
+
+// RemoveEstimate implements the Datastore interface
+func (l *LockingDataStore) RemoveEstimate(token string, estimate Estimate) error {
+	return l.guard(token, func() error {
+		return l.DataStore.RemoveEstimate(token, estimate)
+	})
+}
+
+// guard runs the change of the session unless the session is closed,
+// the state of the session can't change until the change is done
+func (l *LockingDataStore) guard(token string, change func() error) error {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	lifecycle, err := l.lifecycles.GetLifecycle(token)
+	if err != nil {
+		return err
+	}
+	if lifecycle.Closed() {
+		return fmt.Errorf("%w: it is %s and must be reopened first", ErrSessionClosed, lifecycle.State)
+	}
+	return change()
+}
+
+// LockingSettingsStore wraps a settings store and rejects
+// changes of the settings of closed or archived sessions
+type LockingSettingsStore struct {
+	SettingsStore
+	locking *LockingDataStore
+}
+
+// NewLockingSettingsStore wraps the provided settings store so that
+// settings are locked together with the sessions of the provided store
+func NewLockingSettingsStore(ss SettingsStore, locking *LockingDataStore) SettingsStore {
+	return &LockingSettingsStore{
+		SettingsStore: ss,
+		locking:       locking,
+	}
+}
+
+// SetSettings implements the SettingsStore interface
+func (l *LockingSettingsStore) SetSettings(token string, settings Settings) error {
+	return l.locking.guard(token, func() error {
+		return l.SettingsStore.SetSettings(token, settings)
+	})
+}
+
+// LockingCommentStore wraps a comment store and rejects changes
+// of the comments of closed or archived sessions
+type LockingCommentStore struct {
+	CommentStore
+	locking *LockingDataStore
+}
+
+// NewLockingCommentStore wraps the provided comment store so that
+// comments are locked together with the sessions of the provided store
+func NewLockingCommentStore(cs CommentStore, locking *LockingDataStore) CommentStore {
+	return &LockingCommentStore{
+		CommentStore: cs,
+		locking:      locking,
+	}
+}
+
+// AddComment implements the CommentStore interface
+func (l *LockingCommentStore) AddComment(token string, comment Comment) (string, error) {
+	var id string
+	err := l.locking.guard(token, func() error {
+		var err error
+		id, err = l.CommentStore.AddComment(token, comment)
+		return err
+	})
+	return id, err
+}
+
+// UpdateComment implements the CommentStore interface
+func (l *LockingCommentStore) UpdateComment(token, id, text string) error {
+	return l.locking.guard(token, func() error {
+		return l.CommentStore.UpdateComment(token, id, text)
+	})
+}
+
+// RemoveComment implements the CommentStore interface
+func (l *LockingCommentStore) RemoveComment(token, id string) error {
+	return l.locking.guard(token, func() error {
+		return l.CommentStore.RemoveComment(token, id)
+	})
+}
+
+// LockingWebhookStore wraps a webhook store and rejects changes
+// of the webhooks of closed or archived sessions, deliveries are
+// still recorded
+type LockingWebhookStore struct {
+	WebhookStore
+	locking *LockingDataStore
+}
+
+// NewLockingWebhookStore wraps the provided webhook store so that
+// webhooks are locked together with the sessions of the provided store
+func NewLockingWebhookStore(ws WebhookStore, locking *LockingDataStore) WebhookStore {
+	return &LockingWebhookStore{
+		WebhookStore: ws,
+		locking:      locking,
+	}
+}
+
+// AddWebhook implements the WebhookStore interface
+func (l *LockingWebhookStore) AddWebhook(token string, webhook Webhook) (string, error) {
+	var id string
+	err := l.locking.guard(token, func() error {
+		var err error
+		id, err = l.WebhookStore.AddWebhook(token, webhook)
+		return err
+	})
+	return id, err
+}
+
+// RemoveWebhook implements the WebhookStore interface
+func (l *LockingWebhookStore) RemoveWebhook(token, id string) error {
+	return l.locking.guard(token, func() error {
+		return l.WebhookStore.RemoveWebhook(token, id)
+	})
+}
+
+// LockingTemplateStore wraps a template store and rejects
+// cloning closed or archived sessions
+type LockingTemplateStore struct {
+	TemplateStore
+	locking *LockingDataStore
+}
+
+// NewLockingTemplateStore wraps the provided template store so that
+// sessions of the provided store are only cloned while open
+func NewLockingTemplateStore(ts TemplateStore, locking *LockingDataStore) TemplateStore {
+	return &LockingTemplateStore{
+		TemplateStore: ts,
+		locking:       locking,
+	}
+}
+
+// CloneSession implements the TemplateStore interface
+func (l *LockingTemplateStore) CloneSession(token string, withTasks bool) (string, error) {
+	var clone string
+	err := l.locking.guard(token, func() error {
+		var err error
+		clone, err = l.TemplateStore.CloneSession(token, withTasks)
+		return err
+	})
+	return clone, err
+}
@@ -0,0 +1,28 @@
+package datastore
+
+import (
+	"github.com/stretchr/testify/mock"
+)
+
+// MockLifecycleStore represents the mocked object
+type MockLifecycleStore struct {
+	mock.Mock
+}
+
+// GetLifecycle implements the LifecycleStore interface
+func (m *MockLifecycleStore) GetLifecycle(t string) (Lifecycle, error) {
+	arguments := m.Called(t)
+	return arguments.Get(0).(Lifecycle), arguments.Error(1)
+}
+
+// ChangeState implements the LifecycleStore interface
+func (m *MockLifecycleStore) ChangeState(t string, c StateChange) (Lifecycle, error) {
+	arguments := m.Called(t, c)
+	return arguments.Get(0).(Lifecycle), arguments.Error(1)
+}
+
+// RemoveLifecycle implements the LifecycleStore interface
+func (m *MockLifecycleStore) RemoveLifecycle(t string) error {
+	arguments := m.Called(t)
+	return arguments.Error(0)
+}
@@ -0,0 +1,241 @@
+package datastore
+
+import (
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"strings"
+	"testing"
+)
+
+func TestNewGenjiLifecycleStoreNilDB(t *testing.T) {
+	_, err := NewGenjiLifecycleStore(nil)
+	assert.Equal(t, "Proper DB must be provided and not nil", err.Error())
+}
+
+func TestNewGenjiLifecycleStoreFailsDueToCreateTable(t *testing.T) {
+	setupAndTearDown := setupTestCaseForMock(t)
+	defer setupAndTearDown(t)
+	m.On("Exec", "CREATE TABLE lifecycles").Return(fmt.Errorf("Ooops, something went wrong"))
+
+	_, err := NewGenjiLifecycleStore(m)
+	assert.Equal(t, "Unable to create lifecycles table", err.Error())
+}
+
+func TestLifecycleWithRealDB(t *testing.T) {
+	setupAndTearDown := setupTestCaseForRealDB(t)
+	defer setupAndTearDown(t)
+	ls, err := NewGenjiLifecycleStore(db)
+	assert.NoError(t, err)
+
+	lc, err := ls.GetLifecycle("12345")
+	assert.NoError(t, err)
+	assert.Equal(t, Lifecycle{State: StateOpen, Changes: []StateChange{}}, lc)
+
+	for _, c := range []StateChange{
+		{To: StateEstimating, Actor: "pooh"},
+		{To: StateClosed, Actor: "pooh", Reason: "Planning done"},
+		{To: StateOpen, Actor: "pooh", Reason: " Forgot\x00 a task "},
+	} {
+		_, err := ls.ChangeState("12345", c)
+		assert.NoError(t, err)
+	}
+
+	lc, err = ls.GetLifecycle("12345")
+	assert.NoError(t, err)
+	assert.Equal(t, StateOpen, lc.State)
+	assert.False(t, lc.Closed())
+	assert.Len(t, lc.Changes, 3)
+	for i, want := range []StateChange{
+		{From: StateOpen, To: StateEstimating, Actor: "pooh"},
+		{From: StateEstimating, To: StateClosed, Actor: "pooh", Reason: "Planning done"},
+		{From: StateClosed, To: StateOpen, Actor: "pooh", Reason: "Forgot a task"},
+	} {
+		assert.NotEmpty(t, lc.Changes[i].Time)
+		lc.Changes[i].Time = ""
+		assert.Equal(t, want, lc.Changes[i])
+	}
+
+	other, err := ls.GetLifecycle("67890")
+	assert.NoError(t, err)
+	assert.Equal(t, StateOpen, other.State)
+}
+
+func TestRemoveLifecycleWithRealDB(t *testing.T) {
+	setupAndTearDown := setupTestCaseForRealDB(t)
+	defer setupAndTearDown(t)
+	ls, err := NewGenjiLifecycleStore(db)
+	assert.NoError(t, err)
+	for _, token := range []string{"12345", "67890"} {
+		_, err := ls.ChangeState(token, StateChange{To: StateClosed})
+		assert.NoError(t, err)
+	}
+
+	assert.NoError(t, ls.RemoveLifecycle("12345"))
+
+	lc, err := ls.GetLifecycle("12345")
+	assert.NoError(t, err)
+	assert.Equal(t, Lifecycle{State: StateOpen, Changes: []StateChange{}}, lc)
+	other, err := ls.GetLifecycle("67890")
+	assert.NoError(t, err)
+	assert.Equal(t, StateClosed, other.State)
+}
+
+func TestChangeStateFailsWithRealDB(t *testing.T) {
+	tests := []struct {
+		name   string
+		states []string
+		change StateChange
+		reason string
+	}{
+		{"unknown state", nil, StateChange{To: "frozen"}, "State must be one of open, estimating, closed or archived"},
+		{"same state", nil, StateChange{To: StateOpen}, "Session can't change from open to open"},
+		{"archive open session", nil, StateChange{To: StateArchived}, "Session can't change from open to archived"},
+		{"back to estimating", []string{StateClosed}, StateChange{To: StateEstimating}, "Session can't change from closed to estimating"},
+		{"reopen without reason", []string{StateClosed, StateArchived}, StateChange{To: StateOpen, Reason: " \n"}, "Reason should not be empty when reopening a session"},
+		{"too long reason", []string{StateClosed}, StateChange{To: StateOpen, Reason: strings.Repeat("a", MaxReasonLength+1)}, "Reason must not be longer than 500 characters, provided: 501"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupAndTearDown := setupTestCaseForRealDB(t)
+			defer setupAndTearDown(t)
+			ls, err := NewGenjiLifecycleStore(db)
+			assert.NoError(t, err)
+			for _, s := range tt.states {
+				_, err := ls.ChangeState("12345", StateChange{To: s})
+				assert.NoError(t, err)
+			}
+
+			_, err = ls.ChangeState("12345", tt.change)
+			assert.Equal(t, tt.reason, err.Error())
+
+			lc, err := ls.GetLifecycle("12345")
+			assert.NoError(t, err)
+			assert.Len(t, lc.Changes, len(tt.states))
+		})
+	}
+}
+
+func TestLockingDataStoreRejectsChangesOfClosedSessions(t *testing.T) {
+	m := new(MockDatastore)
+	l := new(MockLifecycleStore)
+	ds := NewLockingDataStore(m, l, nil)
+	l.On("GetLifecycle", "12345").Return(Lifecycle{State: StateClosed}, nil)
+	m.On("GetEstimates", "12345").Return([]Estimate{}, nil)
+
+	for name, err := range map[string]error{
+		"RemoveSession":          ds.RemoveSession("12345"),
+		"JoinSession":            joinErr(ds, "12345", User{Name: "Tigger"}),
+		"LeaveSession":           ds.LeaveSession("12345", "tigger"),
+		"UpdateUser":             ds.UpdateUser("12345", User{ID: "tigger", Name: "Tigger"}),
+		"AddTask":                ds.AddTask("12345", "TEST01", "Login"),
+		"RemoveTask":             ds.RemoveTask("12345", "TEST01"),
+		"AddEstimateToTask":      ds.AddEstimateToTask("12345", "TEST01", 3, 1),
+		"RemoveEstimateFromTask": ds.RemoveEstimateFromTask("12345", "TEST01"),
+		"AddEstimate":            ds.AddEstimate("12345", Estimate{TaskID: "TEST01", UserID: "tigger"}),
+		"RemoveEstimate":         ds.RemoveEstimate("12345", Estimate{TaskID: "TEST01", UserID: "tigger"}),
+	} {
+		assert.True(t, errors.Is(err, ErrSessionClosed), name)
+		assert.Equal(t, "Session does not accept changes: it is closed and must be reopened first", err.Error(), name)
+	}
+
+	// Reads keep working
+	ests, err := ds.GetEstimates("12345")
+	assert.NoError(t, err)
+	assert.Equal(t, []Estimate{}, ests)
+}
+
+func TestLockingDataStorePassesChangesOfOpenSessions(t *testing.T) {
+	for _, state := range []string{StateOpen, StateEstimating} {
+		m := new(MockDatastore)
+		l := new(MockLifecycleStore)
+		ds := NewLockingDataStore(m, l, nil)
+		l.On("GetLifecycle", "12345").Return(Lifecycle{State: state}, nil)
+		m.On("AddTask", "12345", "TEST01", "Login").Return(nil)
+
+		assert.NoError(t, ds.AddTask("12345", "TEST01", "Login"))
+		m.AssertCalled(t, "AddTask", "12345", "TEST01", "Login")
+	}
+}
+
+func TestLockingDataStorePassesLifecycleErrors(t *testing.T) {
+	m := new(MockDatastore)
+	l := new(MockLifecycleStore)
+	ds := NewLockingDataStore(m, l, nil)
+	l.On("GetLifecycle", "12345").Return(Lifecycle{}, fmt.Errorf("Unable to query lifecycle"))
+
+	err := ds.AddTask("12345", "TEST01", "Login")
+	assert.Equal(t, "Unable to query lifecycle", err.Error())
+	m.AssertNotCalled(t, "AddTask", "12345", "TEST01", "Login")
+}
+
+func TestLockingDataStoreRemovesLifecycleWithSession(t *testing.T) {
+	m := new(MockDatastore)
+	l := new(MockLifecycleStore)
+	core, logs := observer.New(zapcore.ErrorLevel)
+	ds := NewLockingDataStore(m, l, zap.New(core))
+	l.On("GetLifecycle", mock.Anything).Return(Lifecycle{State: StateOpen}, nil)
+	m.On("RemoveSession", "12345").Return(nil)
+	m.On("RemoveSession", "54321").Return(fmt.Errorf("Specified session does not exist"))
+	l.On("RemoveLifecycle", "12345").Return(fmt.Errorf("Unable to remove lifecycle"))
+
+	assert.NoError(t, ds.RemoveSession("12345"))
+	l.AssertCalled(t, "RemoveLifecycle", "12345")
+	assert.Equal(t, 1, logs.FilterMessage("Unable to remove lifecycle").Len())
+
+	assert.Error(t, ds.RemoveSession("54321"))
+	l.AssertNotCalled(t, "RemoveLifecycle", "54321")
+}
+
+func TestLockingStoresRejectChangesOfClosedSessions(t *testing.T) {
+	l := new(MockLifecycleStore)
+	locking := NewLockingDataStore(new(MockDatastore), l, nil)
+	l.On("GetLifecycle", "12345").Return(Lifecycle{State: StateArchived}, nil)
+
+	_, addCommentErr := NewLockingCommentStore(new(MockCommentStore), locking).AddComment("12345", Comment{Text: "Hi"})
+	_, addWebhookErr := NewLockingWebhookStore(new(MockWebhookStore), locking).AddWebhook("12345", Webhook{})
+	_, cloneErr := NewLockingTemplateStore(new(MockTemplateStore), locking).CloneSession("12345", true)
+	for name, err := range map[string]error{
+		"SetSettings":   NewLockingSettingsStore(new(MockSettingsStore), locking).SetSettings("12345", Settings{}),
+		"AddComment":    addCommentErr,
+		"UpdateComment": NewLockingCommentStore(new(MockCommentStore), locking).UpdateComment("12345", "c1", "Hi"),
+		"RemoveComment": NewLockingCommentStore(new(MockCommentStore), locking).RemoveComment("12345", "c1"),
+		"AddWebhook":    addWebhookErr,
+		"RemoveWebhook": NewLockingWebhookStore(new(MockWebhookStore), locking).RemoveWebhook("12345", "abcd"),
+		"CloneSession":  cloneErr,
+	} {
+		assert.True(t, errors.Is(err, ErrSessionClosed), name)
+		assert.Equal(t, "Session does not accept changes: it is archived and must be reopened first", err.Error(), name)
+	}
+}
+
+func TestLockingStoresPassChangesOfOpenSessions(t *testing.T) {
+	l := new(MockLifecycleStore)
+	locking := NewLockingDataStore(new(MockDatastore), l, nil)
+	l.On("GetLifecycle", "12345").Return(Lifecycle{State: StateOpen}, nil)
+	ss := new(MockSettingsStore)
+	ss.On("SetSettings", "12345", Settings{Unit: "days"}).Return(nil)
+	ts := new(MockTemplateStore)
+	ts.On("CloneSession", "12345", false).Return("67890", nil)
+
+	assert.NoError(t, NewLockingSettingsStore(ss, locking).SetSettings("12345", Settings{Unit: "days"}))
+	token, err := NewLockingTemplateStore(ts, locking).CloneSession("12345", false)
+	assert.NoError(t, err)
+	assert.Equal(t, "67890", token)
+}
+
+func TestLockingDataStoreChangesState(t *testing.T) {
+	l := new(MockLifecycleStore)
+	ds := NewLockingDataStore(new(MockDatastore), l, nil)
+	change := StateChange{To: StateClosed, Actor: "pooh"}
+	l.On("ChangeState", "12345", change).Return(Lifecycle{State: StateClosed}, nil)
+
+	lc, err := ds.ChangeState("12345", change)
+	assert.NoError(t, err)
+	assert.Equal(t, StateClosed, lc.State)
+	l.AssertCalled(t, "ChangeState", "12345", change)
+}
@@ -0,0 +1,103 @@
+package datastore
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrLimitExceeded is returned if an operation would exceed
+// one of the configured per session limits
+var ErrLimitExceeded = errors.New("Limit exceeded")
+
+// Limits defines the maximum number of users, tasks and
+// estimates per session, a value of 0 means unlimited
+type Limits struct {
+	MaxUsers     int
+	MaxTasks     int
+	MaxEstimates int
+}
+
+// LimitedDataStore wraps a datastore and rejects additions
+// exceeding the configured per session limits
+type LimitedDataStore struct {
+	DataStore
+	limits Limits
+}
+
+// NewLimitedDataStore wraps the provided datastore so that the
+// provided limits are enforced for every session
+func NewLimitedDataStore(ds DataStore, limits Limits) DataStore {
+	return &LimitedDataStore{
+		DataStore: ds,
+		limits:    limits,
+	}
+}
+
+// JoinSession implements the Datastore interface
+func (l *LimitedDataStore) JoinSession(token string, user User) (string, error) {
+	if l.limits.MaxUsers > 0 {
+		users, err := l.DataStore.GetUsers(token)
+		if err != nil {
+			return "", err
+		}
+		if len(users) >= l.limits.MaxUsers {
+			return "", fmt.Errorf("%w: at most %d users per session", ErrLimitExceeded, l.limits.MaxUsers)
+		}
+	}
+	return l.DataStore.JoinSession(token, user)
+}
+
+// AddTask implements the Datastore interface
+func (l *LimitedDataStore) AddTask(token, id, summary string) error {
+	if l.limits.MaxTasks > 0 {
+		tasks, err := l.DataStore.GetTasks(token)
+		if err != nil {
+			return err
+		}
+		if len(tasks) >= l.limits.MaxTasks {
+			return fmt.Errorf("%w: at most %d tasks per session", ErrLimitExceeded, l.limits.MaxTasks)
+		}
+	}
+	return l.DataStore.AddTask(token, id, summary)
+}
+
+// AddEstimate implements the Datastore interface
+func (l *LimitedDataStore) AddEstimate(token string, estimate Estimate) error {
+	if l.limits.MaxEstimates > 0 {
+		estimates, err := l.DataStore.GetEstimates(token)
+		if err != nil {
+			return err
+		}
+		if len(estimates) >= l.limits.MaxEstimates {
+			return fmt.Errorf("%w: at most %d estimates per session", ErrLimitExceeded, l.limits.MaxEstimates)
+		}
+	}
+	return l.DataStore.AddEstimate(token, estimate)
+}
+
+// LimitedTemplateStore wraps a template store and rejects
+// templates exceeding the configured per session limits
+type LimitedTemplateStore struct {
+	TemplateStore
+	limits Limits
+}
+
+// NewLimitedTemplateStore wraps the provided template store so that
+// sessions created from templates respect the provided limits
+func NewLimitedTemplateStore(ts TemplateStore, limits Limits) TemplateStore {
+	return &LimitedTemplateStore{
+		TemplateStore: ts,
+		limits:        limits,
+	}
+}
+
+// SaveTemplate implements the TemplateStore interface
+func (l *LimitedTemplateStore) SaveTemplate(template Template) error {
+	if l.limits.MaxUsers > 0 && len(template.Users) > l.limits.MaxUsers {
+		return fmt.Errorf("%w: at most %d users per session", ErrLimitExceeded, l.limits.MaxUsers)
+	}
+	if l.limits.MaxTasks > 0 && len(template.Tasks) > l.limits.MaxTasks {
+		return fmt.Errorf("%w: at most %d tasks per session", ErrLimitExceeded, l.limits.MaxTasks)
+	}
+	return l.TemplateStore.SaveTemplate(template)
+}
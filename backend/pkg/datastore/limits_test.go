@@ -0,0 +1,91 @@
+package datastore
+
+import (
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestLimitedJoinSession(t *testing.T) {
+	m := new(MockDatastore)
+	ds := NewLimitedDataStore(m, Limits{MaxUsers: 2})
+
+	m.On("GetUsers", "12345").Return([]User{{ID: "tigger", Name: "Tigger"}}, nil).Once()
+	m.On("JoinSession", "12345", User{Name: "Rabbit"}).Return("rabbit", nil)
+	id, err := ds.JoinSession("12345", User{Name: "Rabbit"})
+	assert.NoError(t, err)
+	assert.Equal(t, "rabbit", id)
+
+	m.On("GetUsers", "12345").Return([]User{{ID: "tigger", Name: "Tigger"}, {ID: "rabbit", Name: "Rabbit"}}, nil).Once()
+	_, err = ds.JoinSession("12345", User{Name: "Pooh"})
+	assert.True(t, errors.Is(err, ErrLimitExceeded))
+	assert.Equal(t, "Limit exceeded: at most 2 users per session", err.Error())
+	m.AssertNotCalled(t, "JoinSession", "12345", User{Name: "Pooh"})
+}
+
+func TestLimitedAddTask(t *testing.T) {
+	m := new(MockDatastore)
+	ds := NewLimitedDataStore(m, Limits{MaxTasks: 1})
+
+	m.On("GetTasks", "12345").Return([]Task{{ID: "TEST01"}}, nil)
+	err := ds.AddTask("12345", "TEST02", "another task")
+	assert.True(t, errors.Is(err, ErrLimitExceeded))
+	assert.Equal(t, "Limit exceeded: at most 1 tasks per session", err.Error())
+	m.AssertNotCalled(t, "AddTask", "12345", "TEST02", "another task")
+}
+
+func TestLimitedAddEstimate(t *testing.T) {
+	m := new(MockDatastore)
+	ds := NewLimitedDataStore(m, Limits{MaxEstimates: 1})
+	est := Estimate{TaskID: "TEST01", UserID: "rabbit"}
+
+	m.On("GetEstimates", "12345").Return([]Estimate{{TaskID: "TEST01", UserID: "tigger"}}, nil)
+	err := ds.AddEstimate("12345", est)
+	assert.True(t, errors.Is(err, ErrLimitExceeded))
+	assert.Equal(t, "Limit exceeded: at most 1 estimates per session", err.Error())
+	m.AssertNotCalled(t, "AddEstimate", "12345", est)
+}
+
+func TestLimitedPassesLookupErrors(t *testing.T) {
+	m := new(MockDatastore)
+	ds := NewLimitedDataStore(m, Limits{MaxUsers: 1})
+
+	m.On("GetUsers", "12345").Return([]User{}, fmt.Errorf("Specified session does not exist"))
+	_, err := ds.JoinSession("12345", User{Name: "Tigger"})
+	assert.Equal(t, "Specified session does not exist", err.Error())
+	assert.False(t, errors.Is(err, ErrLimitExceeded))
+}
+
+func TestUnlimitedSkipsLookups(t *testing.T) {
+	m := new(MockDatastore)
+	ds := NewLimitedDataStore(m, Limits{})
+
+	m.On("JoinSession", "12345", User{Name: "Tigger"}).Return("tigger", nil)
+	m.On("AddTask", "12345", "TEST01", "a task").Return(nil)
+	m.On("RemoveSession", "12345").Return(nil)
+
+	_, err := ds.JoinSession("12345", User{Name: "Tigger"})
+	assert.NoError(t, err)
+	assert.NoError(t, ds.AddTask("12345", "TEST01", "a task"))
+	assert.NoError(t, ds.RemoveSession("12345"))
+	m.AssertNotCalled(t, "GetUsers", "12345")
+	m.AssertNotCalled(t, "GetTasks", "12345")
+}
+
+func TestLimitedSaveTemplate(t *testing.T) {
+	m := new(MockTemplateStore)
+	ts := NewLimitedTemplateStore(m, Limits{MaxUsers: 1, MaxTasks: 1})
+	ok := Template{Name: "sprint", Users: []string{"Tigger"}, Tasks: []Task{{ID: "TEST01"}}}
+
+	m.On("SaveTemplate", ok).Return(nil)
+	assert.NoError(t, ts.SaveTemplate(ok))
+
+	err := ts.SaveTemplate(Template{Name: "sprint", Users: []string{"Tigger", "Rabbit"}})
+	assert.True(t, errors.Is(err, ErrLimitExceeded))
+	assert.Equal(t, "Limit exceeded: at most 1 users per session", err.Error())
+
+	err = ts.SaveTemplate(Template{Name: "sprint", Tasks: []Task{{ID: "TEST01"}, {ID: "TEST02"}}})
+	assert.Equal(t, "Limit exceeded: at most 1 tasks per session", err.Error())
+	m.AssertNumberOfCalls(t, "SaveTemplate", 1)
+}
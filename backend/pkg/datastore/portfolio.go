@@ -0,0 +1,205 @@
+package datastore
+
+import (
+	"fmt"
+	"github.com/genjidb/genji"
+	"github.com/genjidb/genji/database"
+	"github.com/genjidb/genji/document"
+)
+
+// PortfolioStore defines the interface for storing named
+// portfolios grouping several sessions
+type PortfolioStore interface {
+	CreatePortfolio(portfolio Portfolio) (string, error)
+	SavePortfolio(portfolio Portfolio) error
+	RemovePortfolio(id string) error
+	GetPortfolio(id string) (Portfolio, error)
+	GetPortfolios() ([]Portfolio, error)
+}
+
+// Portfolio defines a named group of sessions, e.g. one
+// session per team planning the same roadmap. Like session
+// tokens, the ID is secret and grants access to the portfolio.
+type Portfolio struct {
+	ID       string
+	Name     string
+	Sessions []string
+}
+
+// GenjiPortfolioStore stores portfolios in their own Genji table
+type GenjiPortfolioStore struct {
+	db GenjiDB
+}
+
+// NewGenjiPortfolioStore creates a new GenjiPortfolioStore and the
+// tables it requires
+func NewGenjiPortfolioStore(db GenjiDB) (PortfolioStore, error) {
+	if db == nil {
+		return nil, fmt.Errorf("Proper DB must be provided and not nil")
+	}
+
+	for _, table := range []string{"portfolios", "sessions"} {
+		if err := db.Exec("CREATE TABLE " + table); err != nil && err.Error() != "table already exists" {
+			return nil, fmt.Errorf("Unable to create %s table", table)
+		}
+	}
+
+	return &GenjiPortfolioStore{db: db}, nil
+}
+
+// CreatePortfolio stores the portfolio under a newly generated ID,
+// which is returned, all sessions must exist
+func (g *GenjiPortfolioStore) CreatePortfolio(portfolio Portfolio) (string, error) {
+	if err := validatePortfolio(portfolio); err != nil {
+		return "", err
+	}
+
+	id, err := generateToken(defaultTokenLength)
+	if err != nil {
+		return "", fmt.Errorf("Unable to generate portfolio ID")
+	}
+	portfolio.ID = id
+
+	missing := ""
+	err = g.db.Update(func(tx *genji.Tx) error {
+		var err error
+		if missing, err = missingSession(tx, portfolio.Sessions); err != nil || missing != "" {
+			return err
+		}
+		return tx.Exec("INSERT INTO portfolios VALUES ?", &portfolio)
+	})
+
+	if missing != "" {
+		return "", fmt.Errorf("Session: %s does not exist", missing)
+	}
+	if err != nil {
+		return "", fmt.Errorf("Unable to store portfolio")
+	}
+	return id, nil
+}
+
+// SavePortfolio replaces the existing portfolio with the same ID,
+// all sessions must exist
+func (g *GenjiPortfolioStore) SavePortfolio(portfolio Portfolio) error {
+	if err := validatePortfolio(portfolio); err != nil {
+		return err
+	}
+
+	missing, notFound := "", false
+	err := g.db.Update(func(tx *genji.Tx) error {
+		_, err := tx.QueryDocument("SELECT id FROM portfolios WHERE id = ?", portfolio.ID)
+		if err == database.ErrDocumentNotFound {
+			notFound = true
+		}
+		if err != nil {
+			return err
+		}
+
+		if missing, err = missingSession(tx, portfolio.Sessions); err != nil || missing != "" {
+			return err
+		}
+
+		if err := tx.Exec("DELETE FROM portfolios WHERE id = ?", portfolio.ID); err != nil {
+			return err
+		}
+		return tx.Exec("INSERT INTO portfolios VALUES ?", &portfolio)
+	})
+
+	if notFound {
+		return fmt.Errorf("Portfolio with ID: %s does not exist", portfolio.ID)
+	}
+	if missing != "" {
+		return fmt.Errorf("Session: %s does not exist", missing)
+	}
+	if err != nil {
+		return fmt.Errorf("Unable to store portfolio")
+	}
+	return nil
+}
+
+// RemovePortfolio removes the portfolio with the specified ID,
+// its sessions are kept
+func (g *GenjiPortfolioStore) RemovePortfolio(id string) error {
+	if _, err := g.GetPortfolio(id); err != nil {
+		return err
+	}
+	return g.db.Exec("DELETE FROM portfolios WHERE id = ?", id)
+}
+
+// GetPortfolio returns the portfolio with the specified ID
+func (g *GenjiPortfolioStore) GetPortfolio(id string) (Portfolio, error) {
+	portfolios, err := g.queryPortfolios("SELECT * FROM portfolios WHERE id = ?", id)
+	if err != nil {
+		return Portfolio{}, err
+	}
+	if len(portfolios) == 0 || id == "" {
+		return Portfolio{}, fmt.Errorf("Portfolio with ID: %s does not exist", id)
+	}
+	return portfolios[0], nil
+}
+
+// GetPortfolios returns all portfolios
+func (g *GenjiPortfolioStore) GetPortfolios() ([]Portfolio, error) {
+	return g.queryPortfolios("SELECT * FROM portfolios")
+}
+
+func (g *GenjiPortfolioStore) queryPortfolios(q string, args ...interface{}) ([]Portfolio, error) {
+	portfolios := []Portfolio{}
+
+	res, err := g.db.Query(q, args...)
+	if err != nil {
+		return portfolios, fmt.Errorf("Unable to query portfolios")
+	}
+
+	defer res.Close()
+
+	err = res.Iterate(func(d document.Document) error {
+		var p Portfolio
+		if err := document.StructScan(d, &p); err != nil {
+			return err
+		}
+		if p.Sessions == nil {
+			p.Sessions = []string{}
+		}
+		portfolios = append(portfolios, p)
+		return nil
+	})
+
+	return portfolios, err
+}
+
+// missingSession returns the first of the sessions, which does
+// not exist
+func missingSession(tx *genji.Tx, tokens []string) (string, error) {
+	for _, token := range tokens {
+		_, err := tx.QueryDocument("SELECT token FROM sessions WHERE token = ?", token)
+		if err == database.ErrDocumentNotFound {
+			return token, nil
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+	return "", nil
+}
+
+func validatePortfolio(portfolio Portfolio) error {
+	if portfolio.Name == "" {
+		return fmt.Errorf("Portfolio name should not be empty")
+	}
+	if len(portfolio.Sessions) == 0 {
+		return fmt.Errorf("Portfolio must contain at least one session")
+	}
+
+	seen := map[string]bool{}
+	for _, token := range portfolio.Sessions {
+		if len(token) != defaultTokenLength {
+			return fmt.Errorf("Session token does not match desired length")
+		}
+		if seen[token] {
+			return fmt.Errorf("Session: %s already part of portfolio", token)
+		}
+		seen[token] = true
+	}
+	return nil
+}
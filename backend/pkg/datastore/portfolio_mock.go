@@ -0,0 +1,40 @@
+package datastore
+
+import (
+	"github.com/stretchr/testify/mock"
+)
+
+// MockPortfolioStore represents the mocked object
+type MockPortfolioStore struct {
+	mock.Mock
+}
+
+// CreatePortfolio implements the PortfolioStore interface
+func (m *MockPortfolioStore) CreatePortfolio(p Portfolio) (string, error) {
+	arguments := m.Called(p)
+	return arguments.String(0), arguments.Error(1)
+}
+
+// SavePortfolio implements the PortfolioStore interface
+func (m *MockPortfolioStore) SavePortfolio(p Portfolio) error {
+	arguments := m.Called(p)
+	return arguments.Error(0)
+}
+
+// RemovePortfolio implements the PortfolioStore interface
+func (m *MockPortfolioStore) RemovePortfolio(id string) error {
+	arguments := m.Called(id)
+	return arguments.Error(0)
+}
+
+// GetPortfolio implements the PortfolioStore interface
+func (m *MockPortfolioStore) GetPortfolio(id string) (Portfolio, error) {
+	arguments := m.Called(id)
+	return arguments.Get(0).(Portfolio), arguments.Error(1)
+}
+
+// GetPortfolios implements the PortfolioStore interface
+func (m *MockPortfolioStore) GetPortfolios() ([]Portfolio, error) {
+	arguments := m.Called()
+	return arguments.Get(0).([]Portfolio), arguments.Error(1)
+}
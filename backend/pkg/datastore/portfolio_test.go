@@ -0,0 +1,96 @@
+package datastore
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestNewGenjiPortfolioStoreNilDB(t *testing.T) {
+	_, err := NewGenjiPortfolioStore(nil)
+	assert.Equal(t, "Proper DB must be provided and not nil", err.Error())
+}
+
+func TestNewGenjiPortfolioStoreFailsDueToCreateTable(t *testing.T) {
+	setupAndTearDown := setupTestCaseForMock(t)
+	defer setupAndTearDown(t)
+	m.On("Exec", "CREATE TABLE portfolios").Return(fmt.Errorf("Ooops, something went wrong"))
+
+	_, err := NewGenjiPortfolioStore(m)
+	assert.Equal(t, "Unable to create portfolios table", err.Error())
+}
+
+func TestSavePortfolioFailsDueToInvalidPortfolioWithRealDB(t *testing.T) {
+	setupAndTearDown := setupTestCaseForRealDB(t)
+	defer setupAndTearDown(t)
+	ps, err := NewGenjiPortfolioStore(db)
+	assert.NoError(t, err)
+
+	token := strings.Repeat("a", defaultTokenLength)
+	tests := []struct {
+		name      string
+		portfolio Portfolio
+		wantErr   string
+	}{
+		{"no name", Portfolio{Sessions: []string{token}}, "Portfolio name should not be empty"},
+		{"no sessions", Portfolio{Name: "roadmap"}, "Portfolio must contain at least one session"},
+		{"invalid token", Portfolio{Name: "roadmap", Sessions: []string{"12345"}}, "Session token does not match desired length"},
+		{"duplicate session", Portfolio{Name: "roadmap", Sessions: []string{token, token}}, "Session: " + token + " already part of portfolio"},
+		{"unknown session", Portfolio{Name: "roadmap", Sessions: []string{token}}, "Session: " + token + " does not exist"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ps.CreatePortfolio(tt.portfolio)
+			assert.Equal(t, tt.wantErr, err.Error())
+		})
+	}
+}
+
+func TestPortfoliosWithRealDB(t *testing.T) {
+	setupAndTearDown := setupTestCaseForRealDB(t)
+	defer setupAndTearDown(t)
+	ds, err := NewGenjiDatastore(db)
+	assert.NoError(t, err)
+	ps, err := NewGenjiPortfolioStore(db)
+	assert.NoError(t, err)
+
+	first, err := ds.CreateSession()
+	assert.NoError(t, err)
+	second, err := ds.CreateSession()
+	assert.NoError(t, err)
+
+	roadmap, err := ps.CreatePortfolio(Portfolio{Name: "roadmap", Sessions: []string{first}})
+	assert.NoError(t, err)
+	assert.Equal(t, defaultTokenLength, len(roadmap))
+	assert.NoError(t, ps.SavePortfolio(Portfolio{ID: roadmap, Name: "roadmap", Sessions: []string{first, second}}))
+	// Names are not unique, the ID identifies the portfolio
+	team, err := ps.CreatePortfolio(Portfolio{Name: "roadmap", Sessions: []string{second}})
+	assert.NoError(t, err)
+	assert.NotEqual(t, roadmap, team)
+
+	p, err := ps.GetPortfolio(roadmap)
+	assert.NoError(t, err)
+	assert.Equal(t, Portfolio{ID: roadmap, Name: "roadmap", Sessions: []string{first, second}}, p)
+
+	portfolios, err := ps.GetPortfolios()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(portfolios))
+
+	// Only existing portfolios may be overwritten
+	unknown := strings.Repeat("b", defaultTokenLength)
+	err = ps.SavePortfolio(Portfolio{ID: unknown, Name: "roadmap", Sessions: []string{first}})
+	assert.Equal(t, "Portfolio with ID: "+unknown+" does not exist", err.Error())
+	err = ps.SavePortfolio(Portfolio{ID: team, Name: "team", Sessions: []string{unknown}})
+	assert.Equal(t, "Session: "+unknown+" does not exist", err.Error())
+
+	assert.NoError(t, ps.RemovePortfolio(roadmap))
+	err = ps.RemovePortfolio(roadmap)
+	assert.Equal(t, "Portfolio with ID: "+roadmap+" does not exist", err.Error())
+	_, err = ps.GetPortfolio(roadmap)
+	assert.Equal(t, "Portfolio with ID: "+roadmap+" does not exist", err.Error())
+
+	// Sessions are kept
+	_, err = ds.GetTasks(first)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,58 @@
+package datastore
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Limits of the reasoning provided with an estimate
+const (
+	MaxRationaleLength  int = 1000
+	MaxAssumptions      int = 10
+	MaxAssumptionLength int = 200
+)
+
+// sanitizeReasoning cleans up the rationale and the assumptions of
+// the estimate and checks them against their limits. Invalid UTF-8,
+// control and formatting characters are removed, except for line
+// breaks and tabs in the rationale, surrounding whitespace is trimmed
+// and empty assumptions are dropped.
+func sanitizeReasoning(estimate *Estimate) error {
+	estimate.Rationale = sanitizeText(estimate.Rationale, true)
+	if n := utf8.RuneCountInString(estimate.Rationale); n > MaxRationaleLength {
+		return invalidf("Rationale must not be longer than %d characters, provided: %d", MaxRationaleLength, n)
+	}
+
+	var assumptions []string
+	for _, a := range estimate.Assumptions {
+		if a = sanitizeText(a, false); a != "" {
+			assumptions = append(assumptions, a)
+		}
+	}
+	if len(assumptions) > MaxAssumptions {
+		return invalidf("Not more than %d assumptions allowed, provided: %d", MaxAssumptions, len(assumptions))
+	}
+	for _, a := range assumptions {
+		if n := utf8.RuneCountInString(a); n > MaxAssumptionLength {
+			return invalidf("Assumption must not be longer than %d characters, provided: %d", MaxAssumptionLength, n)
+		}
+	}
+	estimate.Assumptions = assumptions
+	return nil
+}
+
+func sanitizeText(s string, multiline bool) string {
+	s = strings.ToValidUTF8(s, "")
+	s = strings.Replace(s, "\r\n", "\n", -1)
+	s = strings.Map(func(r rune) rune {
+		if multiline && (r == '\n' || r == '\t') {
+			return r
+		}
+		if unicode.IsControl(r) || unicode.Is(unicode.Cf, r) {
+			return -1
+		}
+		return r
+	}, s)
+	return strings.TrimSpace(s)
+}
@@ -0,0 +1,89 @@
+package datastore
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestSanitizeReasoning(t *testing.T) {
+	est := Estimate{
+		Rationale:   "  Needs a\r\npassword\treset\u202e\x00 \xff ",
+		Assumptions: []string{" SSO stays out of scope\n", "", " \u200b ", "Designs are\x07 ready"},
+	}
+
+	assert.NoError(t, sanitizeReasoning(&est))
+	assert.Equal(t, "Needs a\npassword\treset", est.Rationale)
+	assert.Equal(t, []string{"SSO stays out of scope", "Designs are ready"}, est.Assumptions)
+
+	est = Estimate{Assumptions: []string{" ", "\n"}}
+	assert.NoError(t, sanitizeReasoning(&est))
+	assert.Nil(t, est.Assumptions)
+}
+
+func TestSanitizeReasoningFails(t *testing.T) {
+	tests := []struct {
+		name     string
+		estimate Estimate
+		reason   string
+	}{
+		{
+			"too long rationale",
+			Estimate{Rationale: strings.Repeat("ö", MaxRationaleLength+1)},
+			"Rationale must not be longer than 1000 characters, provided: 1001",
+		},
+		{
+			"too many assumptions",
+			Estimate{Assumptions: strings.Split(strings.Repeat("a,", MaxAssumptions+1), ",")},
+			"Not more than 10 assumptions allowed, provided: 11",
+		},
+		{
+			"too long assumption",
+			Estimate{Assumptions: []string{strings.Repeat("a", MaxAssumptionLength+1)}},
+			"Assumption must not be longer than 200 characters, provided: 201",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := sanitizeReasoning(&tt.estimate)
+			assert.Equal(t, tt.reason, err.Error())
+		})
+	}
+}
+
+func TestEstimateReasoningWithRealDB(t *testing.T) {
+	setupAndTearDown := setupTestCaseForRealDB(t)
+	defer setupAndTearDown(t)
+	gds, err := NewGenjiDatastore(db)
+	assert.NoError(t, err)
+	es, err := NewEventSourcedDatastore(db)
+	assert.NoError(t, err)
+
+	for _, ds := range []DataStore{gds, es} {
+		token, err := ds.CreateSession()
+		assert.NoError(t, err)
+		join(t, ds, token, "Tigger")
+		join(t, ds, token, "Pooh")
+		assert.NoError(t, ds.AddTask(token, "T1", "Login"))
+
+		assert.NoError(t, ds.AddEstimate(token, Estimate{
+			TaskID: "T1", UserID: "tigger", BestCase: 1, MostLikelyCase: 2, WorstCase: 8,
+			Rationale:   " Bouncing takes time ",
+			Assumptions: []string{"No SSO", " "},
+		}))
+		err = ds.AddEstimate(token, Estimate{
+			TaskID: "T1", UserID: "pooh", BestCase: 1, MostLikelyCase: 2, WorstCase: 3,
+			Rationale: strings.Repeat("honey", MaxRationaleLength),
+		})
+		assert.Equal(t, fmt.Sprintf("Rationale must not be longer than 1000 characters, provided: %d", 5*MaxRationaleLength), err.Error())
+
+		ests, err := ds.GetEstimates(token)
+		assert.NoError(t, err)
+		assert.Equal(t, []Estimate{{
+			TaskID: "T1", UserID: "tigger", UserName: "Tigger", BestCase: 1, MostLikelyCase: 2, WorstCase: 8,
+			Rationale:   "Bouncing takes time",
+			Assumptions: []string{"No SSO"},
+		}}, ests)
+	}
+}
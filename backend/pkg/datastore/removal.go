@@ -0,0 +1,46 @@
+package datastore
+
+import "fmt"
+
+// Policies for the estimates of users, who leave a session, and of
+// tasks, which are removed from a session
+const (
+	RemovalCascade = "cascade"
+	RemovalKeep    = "keep"
+)
+
+// validateRemovalPolicy checks the policy for the estimates of
+// removed users and tasks
+func validateRemovalPolicy(policy string) error {
+	if policy != RemovalCascade && policy != RemovalKeep {
+		return fmt.Errorf("Removal policy must be one of cascade or keep, provided: %s", policy)
+	}
+	return nil
+}
+
+// removeEstimatesOf applies the removal policy to the estimates
+// matching the removed user or task. Cascading removes them, any
+// other policy keeps them as orphaned history.
+func removeEstimatesOf(estimates []Estimate, policy string, match func(e Estimate) bool) []Estimate {
+	res := []Estimate{}
+	for _, e := range estimates {
+		if match(e) {
+			if policy == RemovalCascade {
+				continue
+			}
+			e.Orphaned = true
+		}
+		res = append(res, e)
+	}
+	return res
+}
+
+// ofUser matches the estimates of the user with the provided ID
+func ofUser(id string) func(e Estimate) bool {
+	return func(e Estimate) bool { return e.UserID == id }
+}
+
+// ofTask matches the estimates of the task with the provided ID
+func ofTask(id string) func(e Estimate) bool {
+	return func(e Estimate) bool { return e.TaskID == id }
+}
@@ -0,0 +1,170 @@
+package datastore
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestValidateRemovalPolicy(t *testing.T) {
+	assert.NoError(t, validateRemovalPolicy(RemovalCascade))
+	assert.NoError(t, validateRemovalPolicy(RemovalKeep))
+	err := validateRemovalPolicy("orphan")
+	assert.Equal(t, "Removal policy must be one of cascade or keep, provided: orphan", err.Error())
+}
+
+func TestRemoveEstimatesOf(t *testing.T) {
+	estimates := []Estimate{
+		{TaskID: "T1", UserID: "tigger"},
+		{TaskID: "T1", UserID: "pooh"},
+		{TaskID: "T2", UserID: "tigger"},
+	}
+
+	assert.Equal(t, []Estimate{{TaskID: "T1", UserID: "pooh"}}, removeEstimatesOf(estimates, RemovalCascade, ofUser("tigger")))
+	assert.Equal(t, []Estimate{
+		{TaskID: "T1", UserID: "tigger", Orphaned: true},
+		{TaskID: "T1", UserID: "pooh", Orphaned: true},
+		{TaskID: "T2", UserID: "tigger"},
+	}, removeEstimatesOf(estimates, RemovalKeep, ofTask("T1")))
+
+	// Events stored before the policy existed keep the estimates
+	assert.Equal(t, []Estimate{
+		{TaskID: "T1", UserID: "tigger"},
+		{TaskID: "T1", UserID: "pooh"},
+		{TaskID: "T2", UserID: "tigger", Orphaned: true},
+	}, removeEstimatesOf(estimates, "", ofTask("T2")))
+}
+
+func TestNewDatastoresFailDueToInvalidRemovalPolicyWithRealDB(t *testing.T) {
+	setupAndTearDown := setupTestCaseForRealDB(t)
+	defer setupAndTearDown(t)
+
+	_, err := NewGenjiDatastore(db, WithRemovalPolicy("orphan"))
+	assert.Equal(t, "Removal policy must be one of cascade or keep, provided: orphan", err.Error())
+	_, err = NewEventSourcedDatastore(db, WithEventRemovalPolicy("orphan"))
+	assert.Equal(t, "Removal policy must be one of cascade or keep, provided: orphan", err.Error())
+}
+
+func TestRemovalCascadesWithRealDB(t *testing.T) {
+	setupAndTearDown := setupTestCaseForRealDB(t)
+	defer setupAndTearDown(t)
+	gds, err := NewGenjiDatastore(db)
+	assert.NoError(t, err)
+	es, err := NewEventSourcedDatastore(db)
+	assert.NoError(t, err)
+
+	for _, ds := range []DataStore{gds, es} {
+		token := setupRemoval(t, ds)
+		assert.NoError(t, ds.LeaveSession(token, "pooh"))
+		assert.NoError(t, ds.RemoveTask(token, "T2"))
+
+		ests, err := ds.GetEstimates(token)
+		assert.NoError(t, err)
+		assert.Equal(t, []Estimate{
+			{TaskID: "T1", UserID: "tigger", UserName: "Tigger", BestCase: 1, MostLikelyCase: 2, WorstCase: 3},
+		}, ests)
+
+		// A user, who joins again, starts without estimates
+		_, err = ds.JoinSession(token, User{ID: "pooh", Name: "Pooh"})
+		assert.NoError(t, err)
+		assert.NoError(t, ds.AddEstimate(token, Estimate{TaskID: "T1", UserID: "pooh", BestCase: 1, MostLikelyCase: 2, WorstCase: 3}))
+	}
+}
+
+func TestRemovalKeepsOrphanedEstimatesWithRealDB(t *testing.T) {
+	setupAndTearDown := setupTestCaseForRealDB(t)
+	defer setupAndTearDown(t)
+	gds, err := NewGenjiDatastore(db, WithRemovalPolicy(RemovalKeep))
+	assert.NoError(t, err)
+	es, err := NewEventSourcedDatastore(db, WithEventRemovalPolicy(RemovalKeep))
+	assert.NoError(t, err)
+
+	for _, ds := range []DataStore{gds, es} {
+		token := setupRemoval(t, ds)
+		assert.NoError(t, ds.LeaveSession(token, "pooh"))
+		assert.NoError(t, ds.RemoveTask(token, "T2"))
+
+		ests, err := ds.GetEstimates(token)
+		assert.NoError(t, err)
+		assert.Equal(t, []Estimate{
+			{TaskID: "T1", UserID: "tigger", UserName: "Tigger", BestCase: 1, MostLikelyCase: 2, WorstCase: 3},
+			{TaskID: "T1", UserID: "pooh", UserName: "Pooh", BestCase: 2, MostLikelyCase: 3, WorstCase: 4, Orphaned: true},
+			{TaskID: "T2", UserID: "tigger", UserName: "Tigger", BestCase: 1, MostLikelyCase: 2, WorstCase: 3, Orphaned: true},
+		}, ests)
+
+		// Orphaned estimates neither block new estimates of a user,
+		// who joins again, nor of a task, which is added again
+		_, err = ds.JoinSession(token, User{ID: "pooh", Name: "Pooh"})
+		assert.NoError(t, err)
+		assert.NoError(t, ds.AddEstimate(token, Estimate{TaskID: "T1", UserID: "pooh", BestCase: 5, MostLikelyCase: 6, WorstCase: 7}))
+		assert.NoError(t, ds.AddTask(token, "T2", "Logout"))
+		assert.NoError(t, ds.AddEstimate(token, Estimate{TaskID: "T2", UserID: "tigger", BestCase: 5, MostLikelyCase: 6, WorstCase: 7}))
+
+		// Removing prefers the active estimate over the orphaned one
+		assert.NoError(t, ds.RemoveEstimate(token, Estimate{TaskID: "T1", UserID: "pooh"}))
+		ests, err = ds.GetEstimates(token)
+		assert.NoError(t, err)
+		assert.Len(t, ests, 4)
+		assert.True(t, ests[1].Orphaned)
+		assert.Equal(t, "pooh", ests[1].UserID)
+
+		// Orphaned estimates can be removed as well
+		assert.NoError(t, ds.RemoveEstimate(token, Estimate{TaskID: "T1", UserID: "pooh"}))
+		ests, err = ds.GetEstimates(token)
+		assert.NoError(t, err)
+		assert.Len(t, ests, 3)
+
+		assert.NoError(t, ds.RemoveSession(token))
+	}
+}
+
+func TestReplayAppliesRemovalPolicyOfEventWithRealDB(t *testing.T) {
+	setupAndTearDown := setupTestCaseForRealDB(t)
+	defer setupAndTearDown(t)
+	es, err := NewEventSourcedDatastore(db, WithEventRemovalPolicy(RemovalKeep))
+	assert.NoError(t, err)
+
+	token := setupRemoval(t, es)
+	assert.NoError(t, es.LeaveSession(token, "pooh"))
+
+	// Changing the policy doesn't change the replayed history
+	es, err = NewEventSourcedDatastore(db)
+	assert.NoError(t, err)
+	assert.NoError(t, es.RemoveTask(token, "T2"))
+
+	state, err := es.ReplaySession(token, ReplayQuery{})
+	assert.NoError(t, err)
+	assert.Equal(t, []Estimate{
+		{TaskID: "T1", UserID: "tigger", UserName: "Tigger", BestCase: 1, MostLikelyCase: 2, WorstCase: 3},
+		{TaskID: "T1", UserID: "pooh", UserName: "Pooh", BestCase: 2, MostLikelyCase: 3, WorstCase: 4, Orphaned: true},
+	}, state.Estimates)
+}
+
+func TestApplyLegacyRemovalEventKeepsEstimates(t *testing.T) {
+	state := SessionState{
+		Users:     []User{{ID: "tigger", Name: "Tigger"}},
+		Tasks:     []Task{{ID: "T1"}},
+		Estimates: []Estimate{{TaskID: "T1", UserID: "tigger"}},
+	}
+
+	assert.NoError(t, applyEvent(&state, Event{Type: EventUserLeft, User: User{ID: "tigger"}}))
+	assert.Equal(t, []Estimate{{TaskID: "T1", UserID: "tigger", Orphaned: true}}, state.Estimates)
+}
+
+// setupRemoval creates a session, in which Tigger estimated the tasks
+// T1 and T2 and Pooh estimated T1
+func setupRemoval(t *testing.T, ds DataStore) string {
+	token, err := ds.CreateSession()
+	assert.NoError(t, err)
+	join(t, ds, token, "Tigger")
+	join(t, ds, token, "Pooh")
+	assert.NoError(t, ds.AddTask(token, "T1", "Login"))
+	assert.NoError(t, ds.AddTask(token, "T2", "Logout"))
+	for _, e := range []Estimate{
+		{TaskID: "T1", UserID: "tigger", BestCase: 1, MostLikelyCase: 2, WorstCase: 3},
+		{TaskID: "T1", UserID: "pooh", BestCase: 2, MostLikelyCase: 3, WorstCase: 4},
+		{TaskID: "T2", UserID: "tigger", BestCase: 1, MostLikelyCase: 2, WorstCase: 3},
+	} {
+		assert.NoError(t, ds.AddEstimate(token, e))
+	}
+	return token
+}
@@ -0,0 +1,168 @@
+package datastore
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"github.com/genjidb/genji"
+	"github.com/genjidb/genji/document"
+	"go.uber.org/zap"
+)
+
+// SecretStore defines the interface for storing the secrets users
+// prove to be themselves with, only hashes of the secrets are kept
+type SecretStore interface {
+	IssueSecret(token, id string) (string, error)
+	VerifySecret(token, id, secret string) (bool, error)
+	RevokeSecrets(token string, ids ...string) error
+}
+
+const defaultSecretLength int = 32
+
+// GenjiSecretStore stores the hashed secrets of users in their
+// own Genji table
+type GenjiSecretStore struct {
+	db GenjiDB
+}
+
+type secretRow struct {
+	Token  string
+	UserID string
+	Hash   string
+}
+
+// NewGenjiSecretStore creates a new GenjiSecretStore and the
+// table it requires
+func NewGenjiSecretStore(db GenjiDB) (SecretStore, error) {
+	if db == nil {
+		return nil, fmt.Errorf("Proper DB must be provided and not nil")
+	}
+
+	if err := db.Exec("CREATE TABLE secrets"); err != nil && err.Error() != "table already exists" {
+		return nil, fmt.Errorf("Unable to create secrets table")
+	}
+
+	return &GenjiSecretStore{db: db}, nil
+}
+
+// IssueSecret generates a new secret for the user with the ID,
+// which replaces any previous secret of the user
+func (g *GenjiSecretStore) IssueSecret(token, id string) (string, error) {
+	if id == "" {
+		return "", fmt.Errorf("User ID should not be empty")
+	}
+
+	secret, err := generateToken(defaultSecretLength)
+	if err != nil {
+		return "", fmt.Errorf("Unable to generate secret")
+	}
+
+	err = g.db.Update(func(tx *genji.Tx) error {
+		if err := tx.Exec("DELETE FROM secrets WHERE token = ? AND userid = ?", token, id); err != nil {
+			return err
+		}
+		return tx.Exec("INSERT INTO secrets VALUES ?", &secretRow{Token: token, UserID: id, Hash: hashSecret(secret)})
+	})
+
+	if err != nil {
+		return "", fmt.Errorf("Unable to store secret")
+	}
+	return secret, nil
+}
+
+// VerifySecret reports whether the secret was issued to the user
+// with the ID and was not revoked since
+func (g *GenjiSecretStore) VerifySecret(token, id, secret string) (bool, error) {
+	res, err := g.db.Query("SELECT hash FROM secrets WHERE token = ? AND userid = ?", token, id)
+	if err != nil {
+		return false, fmt.Errorf("Unable to query secret")
+	}
+
+	defer res.Close()
+
+	var hash string
+	err = res.Iterate(func(d document.Document) error {
+		return document.Scan(d, &hash)
+	})
+	if err != nil {
+		return false, fmt.Errorf("Unable to query secret")
+	}
+
+	return hash != "" && subtle.ConstantTimeCompare([]byte(hash), []byte(hashSecret(secret))) == 1, nil
+}
+
+// RevokeSecrets revokes the secrets of the users with the IDs,
+// all secrets of the session are revoked if no IDs are provided
+func (g *GenjiSecretStore) RevokeSecrets(token string, ids ...string) error {
+	var err error
+	if len(ids) == 0 {
+		err = g.db.Exec("DELETE FROM secrets WHERE token = ?", token)
+	} else {
+		err = g.db.Update(func(tx *genji.Tx) error {
+			for _, id := range ids {
+				if err := tx.Exec("DELETE FROM secrets WHERE token = ? AND userid = ?", token, id); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	}
+
+	if err != nil {
+		return fmt.Errorf("Unable to revoke secrets")
+	}
+	return nil
+}
+
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// RevokingDataStore wraps a datastore and revokes the secrets of
+// users leaving a session and of all users of removed sessions
+type RevokingDataStore struct {
+	DataStore
+	secrets SecretStore
+	logger  *zap.Logger
+}
+
+// NewRevokingDataStore wraps the provided datastore so that secrets
+// of the provided store are revoked, a nil logger disables logging
+func NewRevokingDataStore(ds DataStore, secrets SecretStore, logger *zap.Logger) DataStore {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &RevokingDataStore{
+		DataStore: ds,
+		secrets:   secrets,
+		logger:    logger,
+	}
+}
+
+// RemoveSession implements the Datastore interface
+func (r *RevokingDataStore) RemoveSession(token string) error {
+	if err := r.DataStore.RemoveSession(token); err != nil {
+		return err
+	}
+	r.revoke(token)
+	return nil
+}
+
+// LeaveSession implements the Datastore interface
+func (r *RevokingDataStore) LeaveSession(token, id string) error {
+	if err := r.DataStore.LeaveSession(token, id); err != nil {
+		return err
+	}
+	r.revoke(token, id)
+	return nil
+}
+
+// revoke logs failures instead of returning them, as the
+// change they follow already succeeded
+func (r *RevokingDataStore) revoke(token string, ids ...string) {
+	if err := r.secrets.RevokeSecrets(token, ids...); err != nil {
+		r.logger.Error("Unable to revoke secrets", zap.String("session", RedactToken(token)), zap.Error(err))
+	}
+}
@@ -0,0 +1,28 @@
+package datastore
+
+import (
+	"github.com/stretchr/testify/mock"
+)
+
+// MockSecretStore represents the mocked object
+type MockSecretStore struct {
+	mock.Mock
+}
+
+// IssueSecret implements the SecretStore interface
+func (m *MockSecretStore) IssueSecret(t, id string) (string, error) {
+	arguments := m.Called(t, id)
+	return arguments.String(0), arguments.Error(1)
+}
+
+// VerifySecret implements the SecretStore interface
+func (m *MockSecretStore) VerifySecret(t, id, s string) (bool, error) {
+	arguments := m.Called(t, id, s)
+	return arguments.Bool(0), arguments.Error(1)
+}
+
+// RevokeSecrets implements the SecretStore interface
+func (m *MockSecretStore) RevokeSecrets(t string, ids ...string) error {
+	arguments := m.Called(t, ids)
+	return arguments.Error(0)
+}
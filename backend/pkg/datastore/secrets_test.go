@@ -0,0 +1,106 @@
+package datastore
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"testing"
+)
+
+func TestNewGenjiSecretStoreNilDB(t *testing.T) {
+	_, err := NewGenjiSecretStore(nil)
+	assert.Equal(t, "Proper DB must be provided and not nil", err.Error())
+}
+
+func TestNewGenjiSecretStoreFailsDueToCreateTable(t *testing.T) {
+	setupAndTearDown := setupTestCaseForMock(t)
+	defer setupAndTearDown(t)
+	m.On("Exec", "CREATE TABLE secrets").Return(fmt.Errorf("Ooops, something went wrong"))
+
+	_, err := NewGenjiSecretStore(m)
+	assert.Equal(t, "Unable to create secrets table", err.Error())
+}
+
+func TestSecretsWithRealDB(t *testing.T) {
+	setupAndTearDown := setupTestCaseForRealDB(t)
+	defer setupAndTearDown(t)
+	ss, err := NewGenjiSecretStore(db)
+	assert.NoError(t, err)
+
+	_, err = ss.IssueSecret("12345", "")
+	assert.Equal(t, "User ID should not be empty", err.Error())
+
+	tigger, err := ss.IssueSecret("12345", "tigger")
+	assert.NoError(t, err)
+	assert.Len(t, tigger, defaultSecretLength)
+	pooh, err := ss.IssueSecret("12345", "pooh")
+	assert.NoError(t, err)
+
+	for _, tt := range []struct {
+		token, id, secret string
+		valid             bool
+	}{
+		{"12345", "tigger", tigger, true},
+		{"12345", "pooh", pooh, true},
+		{"12345", "tigger", pooh, false},
+		{"12345", "tigger", "", false},
+		{"67890", "tigger", tigger, false},
+		{"12345", "rabbit", "", false},
+	} {
+		ok, err := ss.VerifySecret(tt.token, tt.id, tt.secret)
+		assert.NoError(t, err)
+		assert.Equal(t, tt.valid, ok, tt)
+	}
+
+	// Issuing again replaces the previous secret
+	renewed, err := ss.IssueSecret("12345", "tigger")
+	assert.NoError(t, err)
+	ok, _ := ss.VerifySecret("12345", "tigger", tigger)
+	assert.False(t, ok)
+	ok, _ = ss.VerifySecret("12345", "tigger", renewed)
+	assert.True(t, ok)
+
+	assert.NoError(t, ss.RevokeSecrets("12345", "tigger"))
+	ok, _ = ss.VerifySecret("12345", "tigger", renewed)
+	assert.False(t, ok)
+	ok, _ = ss.VerifySecret("12345", "pooh", pooh)
+	assert.True(t, ok)
+
+	assert.NoError(t, ss.RevokeSecrets("12345"))
+	ok, _ = ss.VerifySecret("12345", "pooh", pooh)
+	assert.False(t, ok)
+}
+
+func TestRevokingDataStore(t *testing.T) {
+	m := new(MockDatastore)
+	s := new(MockSecretStore)
+	ds := NewRevokingDataStore(m, s, nil)
+	m.On("LeaveSession", "12345", "tigger").Return(nil)
+	m.On("RemoveSession", "12345").Return(nil)
+	s.On("RevokeSecrets", "12345", mock.Anything).Return(nil)
+
+	assert.NoError(t, ds.LeaveSession("12345", "tigger"))
+	s.AssertCalled(t, "RevokeSecrets", "12345", []string{"tigger"})
+
+	assert.NoError(t, ds.RemoveSession("12345"))
+	s.AssertCalled(t, "RevokeSecrets", "12345", []string(nil))
+}
+
+func TestRevokingDataStoreLogsFailures(t *testing.T) {
+	m := new(MockDatastore)
+	s := new(MockSecretStore)
+	core, logs := observer.New(zapcore.ErrorLevel)
+	ds := NewRevokingDataStore(m, s, zap.New(core))
+	m.On("LeaveSession", "12345", "tigger").Return(nil)
+	m.On("LeaveSession", "12345", "rabbit").Return(fmt.Errorf("User with ID: rabbit is not part of session"))
+	s.On("RevokeSecrets", "12345", mock.Anything).Return(fmt.Errorf("Unable to revoke secrets"))
+
+	assert.NoError(t, ds.LeaveSession("12345", "tigger"))
+	assert.Equal(t, 1, logs.FilterMessage("Unable to revoke secrets").Len())
+
+	assert.Error(t, ds.LeaveSession("12345", "rabbit"))
+	s.AssertNumberOfCalls(t, "RevokeSecrets", 1)
+}
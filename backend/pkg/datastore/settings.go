@@ -0,0 +1,146 @@
+package datastore
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/genjidb/genji"
+	"github.com/genjidb/genji/document"
+	dbestimate "github.com/haro87/dokerb/pkg/estimate"
+)
+
+// SettingsStore defines the interface for storing the
+// settings of a session
+type SettingsStore interface {
+	SetSettings(token string, settings Settings) error
+	GetSettings(token string) (Settings, error)
+}
+
+// Anonymity modes of a session
+const (
+	AnonymityOff        = "off"
+	AnonymityPseudonyms = "pseudonyms"
+	AnonymityHidden     = "hidden"
+)
+
+// Settings defines the settings of a session, Salt is used
+// for deriving pseudonyms and never leaves the server. An empty
+// Unit stands for the default unit and estimates must match one
+// of the Cards, if a deck is set.
+type Settings struct {
+	Anonymity string
+	Moderator string
+	Salt      string
+	Unit      string
+	Deck      string
+	Cards     []dbestimate.Card
+}
+
+// Anonymize returns the name of the user with the ID as seen by
+// everybody, which is a pseudonym or empty in anonymous sessions
+func (s Settings) Anonymize(id, name string) string {
+	switch s.Anonymity {
+	case AnonymityPseudonyms:
+		return Pseudonym(s.Salt, id)
+	case AnonymityHidden:
+		return ""
+	}
+	return name
+}
+
+// Pseudonym derives a stable pseudonym of the user, which can't
+// be traced back to the user without knowing the session salt
+func Pseudonym(salt, user string) string {
+	mac := hmac.New(sha256.New, []byte(salt))
+	mac.Write([]byte(user))
+	return "Participant-" + hex.EncodeToString(mac.Sum(nil))[:8]
+}
+
+// GenjiSettingsStore stores session settings in their own
+// Genji table
+type GenjiSettingsStore struct {
+	db GenjiDB
+}
+
+type settingsRow struct {
+	Token string
+	Settings
+}
+
+const defaultSaltLength int = 32
+
+// NewGenjiSettingsStore creates a new GenjiSettingsStore and the
+// table it requires
+func NewGenjiSettingsStore(db GenjiDB) (SettingsStore, error) {
+	if db == nil {
+		return nil, fmt.Errorf("Proper DB must be provided and not nil")
+	}
+
+	if err := db.Exec("CREATE TABLE settings"); err != nil && err.Error() != "table already exists" {
+		return nil, fmt.Errorf("Unable to create settings table")
+	}
+
+	return &GenjiSettingsStore{db: db}, nil
+}
+
+// SetSettings replaces the settings of the session, a salt is
+// generated if none is provided
+func (g *GenjiSettingsStore) SetSettings(token string, settings Settings) error {
+	if err := validateSettings(settings); err != nil {
+		return err
+	}
+
+	if settings.Salt == "" {
+		salt, err := generateToken(defaultSaltLength)
+		if err != nil {
+			return fmt.Errorf("Unable to generate salt")
+		}
+		settings.Salt = salt
+	}
+
+	err := g.db.Update(func(tx *genji.Tx) error {
+		if err := tx.Exec("DELETE FROM settings WHERE token = ?", token); err != nil {
+			return err
+		}
+		return tx.Exec("INSERT INTO settings VALUES ?", &settingsRow{Token: token, Settings: settings})
+	})
+
+	if err != nil {
+		return fmt.Errorf("Unable to store settings")
+	}
+	return nil
+}
+
+// GetSettings returns the settings of the session, which has
+// anonymity turned off if no settings were stored
+func (g *GenjiSettingsStore) GetSettings(token string) (Settings, error) {
+	settings := Settings{Anonymity: AnonymityOff}
+
+	res, err := g.db.Query("SELECT * FROM settings WHERE token = ?", token)
+	if err != nil {
+		return settings, fmt.Errorf("Unable to query settings")
+	}
+
+	defer res.Close()
+
+	err = res.Iterate(func(d document.Document) error {
+		return document.StructScan(d, &settings)
+	})
+
+	return settings, err
+}
+
+func validateSettings(settings Settings) error {
+	switch settings.Anonymity {
+	case AnonymityOff, AnonymityPseudonyms, AnonymityHidden:
+	default:
+		return fmt.Errorf("Anonymity must be one of off, pseudonyms or hidden")
+	}
+
+	if settings.Unit != "" && !dbestimate.ValidUnit(settings.Unit) {
+		return fmt.Errorf("Unit must be one of hours, days, weeks or points")
+	}
+
+	return dbestimate.ValidateCards(settings.Cards)
+}
@@ -0,0 +1,22 @@
+package datastore
+
+import (
+	"github.com/stretchr/testify/mock"
+)
+
+// MockSettingsStore represents the mocked object
+type MockSettingsStore struct {
+	mock.Mock
+}
+
+// SetSettings implements the SettingsStore interface
+func (m *MockSettingsStore) SetSettings(t string, s Settings) error {
+	arguments := m.Called(t, s)
+	return arguments.Error(0)
+}
+
+// GetSettings implements the SettingsStore interface
+func (m *MockSettingsStore) GetSettings(t string) (Settings, error) {
+	arguments := m.Called(t)
+	return arguments.Get(0).(Settings), arguments.Error(1)
+}
@@ -0,0 +1,152 @@
+package datastore
+
+import (
+	"fmt"
+	dbestimate "github.com/haro87/dokerb/pkg/estimate"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestNewGenjiSettingsStoreNilDB(t *testing.T) {
+	_, err := NewGenjiSettingsStore(nil)
+	assert.Equal(t, "Proper DB must be provided and not nil", err.Error())
+}
+
+func TestNewGenjiSettingsStoreFailsDueToCreateTable(t *testing.T) {
+	setupAndTearDown := setupTestCaseForMock(t)
+	defer setupAndTearDown(t)
+	m.On("Exec", "CREATE TABLE settings").Return(fmt.Errorf("Ooops, something went wrong"))
+
+	_, err := NewGenjiSettingsStore(m)
+	assert.Equal(t, "Unable to create settings table", err.Error())
+}
+
+func TestSetSettingsFailsDueToUnknownAnonymityWithRealDB(t *testing.T) {
+	setupAndTearDown := setupTestCaseForRealDB(t)
+	defer setupAndTearDown(t)
+	ss, err := NewGenjiSettingsStore(db)
+	assert.NoError(t, err)
+
+	err = ss.SetSettings("12345", Settings{Anonymity: "masked"})
+	assert.Equal(t, "Anonymity must be one of off, pseudonyms or hidden", err.Error())
+
+	err = ss.SetSettings("12345", Settings{Anonymity: AnonymityOff, Unit: "months"})
+	assert.Equal(t, "Unit must be one of hours, days, weeks or points", err.Error())
+
+	err = ss.SetSettings("12345", Settings{Anonymity: AnonymityOff, Cards: []dbestimate.Card{{Label: "S", Value: -1}}})
+	assert.Equal(t, "Card value must be >= 0, provided: -1", err.Error())
+}
+
+func TestSettingsWithDeckWithRealDB(t *testing.T) {
+	setupAndTearDown := setupTestCaseForRealDB(t)
+	defer setupAndTearDown(t)
+	ss, err := NewGenjiSettingsStore(db)
+	assert.NoError(t, err)
+
+	cards, _ := dbestimate.Deck("t-shirt")
+	settings := Settings{Anonymity: AnonymityOff, Salt: "s4lt", Unit: dbestimate.Days, Deck: "t-shirt", Cards: cards}
+	assert.NoError(t, ss.SetSettings("12345", settings))
+
+	res, err := ss.GetSettings("12345")
+	assert.NoError(t, err)
+	assert.Equal(t, settings, res)
+}
+
+func TestSettingsWithRealDB(t *testing.T) {
+	setupAndTearDown := setupTestCaseForRealDB(t)
+	defer setupAndTearDown(t)
+	ss, err := NewGenjiSettingsStore(db)
+	assert.NoError(t, err)
+
+	settings, err := ss.GetSettings("12345")
+	assert.NoError(t, err)
+	assert.Equal(t, Settings{Anonymity: AnonymityOff}, settings)
+
+	assert.NoError(t, ss.SetSettings("12345", Settings{Anonymity: AnonymityPseudonyms, Moderator: "Tigger"}))
+	assert.NoError(t, ss.SetSettings("54321", Settings{Anonymity: AnonymityHidden}))
+
+	settings, err = ss.GetSettings("12345")
+	assert.NoError(t, err)
+	assert.Equal(t, AnonymityPseudonyms, settings.Anonymity)
+	assert.Equal(t, "Tigger", settings.Moderator)
+	assert.Equal(t, defaultSaltLength, len(settings.Salt))
+
+	// The salt is kept, when the settings are changed
+	settings.Anonymity = AnonymityHidden
+	assert.NoError(t, ss.SetSettings("12345", settings))
+
+	changed, err := ss.GetSettings("12345")
+	assert.NoError(t, err)
+	assert.Equal(t, settings, changed)
+}
+
+func TestSetSettingsNoError(t *testing.T) {
+	var ss SettingsStore
+	m := new(MockSettingsStore)
+	ss = m
+	s := Settings{Anonymity: AnonymityHidden}
+
+	m.On("SetSettings", "12345", s).Return(nil)
+
+	assert.NoError(t, ss.SetSettings("12345", s))
+	m.MethodCalled("SetSettings", "12345", s)
+}
+
+func TestSetSettingsError(t *testing.T) {
+	var ss SettingsStore
+	m := new(MockSettingsStore)
+	ss = m
+	s := Settings{Anonymity: AnonymityHidden}
+
+	m.On("SetSettings", "12345", s).Return(fmt.Errorf("Some error"))
+
+	err := ss.SetSettings("12345", s)
+
+	assert.Equal(t, "Some error", err.Error())
+	m.MethodCalled("SetSettings", "12345", s)
+}
+
+func TestGetSettingsNoError(t *testing.T) {
+	var ss SettingsStore
+	m := new(MockSettingsStore)
+	ss = m
+
+	m.On("GetSettings", "12345").Return(Settings{Anonymity: AnonymityHidden}, nil)
+
+	res, err := ss.GetSettings("12345")
+
+	assert.NoError(t, err)
+	assert.Equal(t, AnonymityHidden, res.Anonymity)
+	m.MethodCalled("GetSettings", "12345")
+}
+
+func TestGetSettingsError(t *testing.T) {
+	var ss SettingsStore
+	m := new(MockSettingsStore)
+	ss = m
+
+	m.On("GetSettings", "12345").Return(Settings{}, fmt.Errorf("Some error"))
+
+	_, err := ss.GetSettings("12345")
+
+	assert.Equal(t, "Some error", err.Error())
+	m.MethodCalled("GetSettings", "12345")
+}
+
+func TestPseudonymsAreStable(t *testing.T) {
+	assert.Equal(t, Pseudonym("s4lt", "Tigger"), Pseudonym("s4lt", "Tigger"))
+	assert.NotEqual(t, Pseudonym("s4lt", "Tigger"), Pseudonym("s4lt", "Rabbit"))
+	assert.NotEqual(t, Pseudonym("s4lt", "Tigger"), Pseudonym("other", "Tigger"))
+	assert.NotContains(t, Pseudonym("s4lt", "Tigger"), "Tigger")
+}
+
+func TestAnonymize(t *testing.T) {
+	for anonymity, want := range map[string]string{
+		AnonymityOff:        "Tigger",
+		AnonymityPseudonyms: Pseudonym("s4lt", "tigger"),
+		AnonymityHidden:     "",
+	} {
+		s := Settings{Anonymity: anonymity, Salt: "s4lt"}
+		assert.Equal(t, want, s.Anonymize("tigger", "Tigger"), anonymity)
+	}
+}
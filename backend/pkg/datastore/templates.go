@@ -0,0 +1,313 @@
+package datastore
+
+import (
+	"fmt"
+	"github.com/genjidb/genji"
+	"github.com/genjidb/genji/database"
+	"github.com/genjidb/genji/document"
+)
+
+// TemplateStore defines the interface for storing named session
+// templates and creating sessions from templates or other sessions
+type TemplateStore interface {
+	SaveTemplate(template Template) error
+	RemoveTemplate(name string) error
+	GetTemplate(name string) (Template, error)
+	GetTemplates() ([]Template, error)
+	CreateSessionFromTemplate(name string) (string, error)
+	CloneSession(token string, withTasks bool) (string, error)
+}
+
+// Template defines the users, tasks and settings a new
+// session starts with, users and the moderator are identified
+// by their names and get new IDs inside each session
+type Template struct {
+	Name     string
+	Users    []string
+	Tasks    []Task
+	Settings Settings
+}
+
+// GenjiTemplateStore stores templates in their own Genji table and
+// creates sessions within a single transaction
+type GenjiTemplateStore struct {
+	db     GenjiDB
+	events bool
+}
+
+// TemplateOption configures optional behaviour of the
+// GenjiTemplateStore
+type TemplateOption func(g *GenjiTemplateStore)
+
+// WithTemplateEvents sets whether new sessions are stored together
+// with their session.created event, which is required if sessions
+// are kept by the EventSourcedDatastore
+func WithTemplateEvents(enabled bool) TemplateOption {
+	return func(g *GenjiTemplateStore) {
+		g.events = enabled
+	}
+}
+
+// NewGenjiTemplateStore creates a new GenjiTemplateStore and the
+// tables it requires
+func NewGenjiTemplateStore(db GenjiDB, opts ...TemplateOption) (TemplateStore, error) {
+	if db == nil {
+		return nil, fmt.Errorf("Proper DB must be provided and not nil")
+	}
+
+	g := &GenjiTemplateStore{db: db}
+	for _, opt := range opts {
+		opt(g)
+	}
+
+	tables := []string{"templates", "sessions", "settings"}
+	if g.events {
+		tables = append(tables, "events")
+	}
+	for _, table := range tables {
+		if err := db.Exec("CREATE TABLE " + table); err != nil && err.Error() != "table already exists" {
+			return nil, fmt.Errorf("Unable to create %s table", table)
+		}
+	}
+
+	return g, nil
+}
+
+// SaveTemplate stores the template, replacing an existing
+// template with the same name
+func (g *GenjiTemplateStore) SaveTemplate(template Template) error {
+	if err := validateTemplate(&template); err != nil {
+		return err
+	}
+
+	err := g.db.Update(func(tx *genji.Tx) error {
+		if err := tx.Exec("DELETE FROM templates WHERE name = ?", template.Name); err != nil {
+			return err
+		}
+		return tx.Exec("INSERT INTO templates VALUES ?", &template)
+	})
+
+	if err != nil {
+		return fmt.Errorf("Unable to store template")
+	}
+	return nil
+}
+
+// RemoveTemplate removes the template with the specified name
+func (g *GenjiTemplateStore) RemoveTemplate(name string) error {
+	if _, err := g.GetTemplate(name); err != nil {
+		return err
+	}
+	return g.db.Exec("DELETE FROM templates WHERE name = ?", name)
+}
+
+// GetTemplate returns the template with the specified name
+func (g *GenjiTemplateStore) GetTemplate(name string) (Template, error) {
+	templates, err := g.queryTemplates("SELECT * FROM templates WHERE name = ?", name)
+	if err != nil {
+		return Template{}, err
+	}
+	if len(templates) == 0 {
+		return Template{}, fmt.Errorf("Template with name: %s does not exist", name)
+	}
+	return templates[0], nil
+}
+
+// GetTemplates returns all templates
+func (g *GenjiTemplateStore) GetTemplates() ([]Template, error) {
+	return g.queryTemplates("SELECT * FROM templates")
+}
+
+// CreateSessionFromTemplate creates a new session with the users,
+// tasks and settings of the template and returns its token
+func (g *GenjiTemplateStore) CreateSessionFromTemplate(name string) (string, error) {
+	template, err := g.GetTemplate(name)
+	if err != nil {
+		return "", err
+	}
+
+	users := []User{}
+	settings := template.Settings
+	for _, name := range template.Users {
+		id, err := generateToken(defaultUserIDLength)
+		if err != nil {
+			return "", fmt.Errorf("Unable to create session from template")
+		}
+		users = append(users, User{ID: id, Name: name, Role: RoleEstimator})
+		if name == template.Settings.Moderator {
+			settings.Moderator = id
+		}
+	}
+
+	var token string
+	err = g.db.Update(func(tx *genji.Tx) error {
+		token, err = g.createSessionInTx(tx, users, template.Tasks, settings)
+		return err
+	})
+
+	if err != nil {
+		return "", fmt.Errorf("Unable to create session from template")
+	}
+	return token, nil
+}
+
+// CloneSession creates a new session with the users and settings of
+// the specified session and returns its token, users keep their IDs.
+// Tasks without a final estimate are copied as well, if requested.
+func (g *GenjiTemplateStore) CloneSession(token string, withTasks bool) (string, error) {
+	if len(token) != defaultTokenLength {
+		return "", fmt.Errorf("Session token does not match desired length")
+	}
+
+	var clone string
+	notFound := false
+	err := g.db.Update(func(tx *genji.Tx) error {
+		d, err := tx.QueryDocument("SELECT * FROM sessions WHERE token = ?", token)
+		if err == database.ErrDocumentNotFound {
+			notFound = true
+			return err
+		}
+		if err != nil {
+			return err
+		}
+
+		var s session
+		if err := document.StructScan(d, &s); err != nil {
+			return err
+		}
+
+		settings := Settings{Anonymity: AnonymityOff}
+		d, err = tx.QueryDocument("SELECT * FROM settings WHERE token = ?", token)
+		if err == nil {
+			err = document.StructScan(d, &settings)
+		}
+		if err != nil && err != database.ErrDocumentNotFound {
+			return err
+		}
+
+		tasks := []Task{}
+		if withTasks {
+			for _, t := range s.Tasks {
+				if !t.Finalized {
+					tasks = append(tasks, Task{ID: t.ID, Summary: t.Summary})
+				}
+			}
+		}
+
+		clone, err = g.createSessionInTx(tx, s.Users, tasks, settings)
+		return err
+	})
+
+	if notFound {
+		return "", fmt.Errorf("Specified session does not exist")
+	}
+	if err != nil {
+		return "", fmt.Errorf("Unable to clone session")
+	}
+	return clone, nil
+}
+
+func (g *GenjiTemplateStore) queryTemplates(q string, args ...interface{}) ([]Template, error) {
+	templates := []Template{}
+
+	res, err := g.db.Query(q, args...)
+	if err != nil {
+		return templates, fmt.Errorf("Unable to query templates")
+	}
+
+	defer res.Close()
+
+	err = res.Iterate(func(d document.Document) error {
+		var t Template
+		if err := document.StructScan(d, &t); err != nil {
+			return err
+		}
+		templates = append(templates, t)
+		return nil
+	})
+
+	return templates, err
+}
+
+// createSessionInTx creates a new session inside the transaction,
+// the session gets its own salt for pseudonyms. Without events the
+// session is stored as by the GenjiDatastore.
+func (g *GenjiTemplateStore) createSessionInTx(tx *genji.Tx, users []User, tasks []Task, settings Settings) (string, error) {
+	token, err := generateToken(defaultTokenLength)
+	if err != nil {
+		return "", err
+	}
+
+	salt, err := generateToken(defaultSaltLength)
+	if err != nil {
+		return "", err
+	}
+	settings.Salt = salt
+
+	if users == nil {
+		users = []User{}
+	}
+
+	if g.events {
+		err = insertSessionInTx(tx, token, users, tasks)
+	} else {
+		err = tx.Exec("INSERT INTO sessions VALUES ?", &session{Token: token, Users: users, Tasks: tasks})
+	}
+	if err != nil {
+		return "", err
+	}
+	if err := tx.Exec("INSERT INTO settings VALUES ?", &settingsRow{Token: token, Settings: settings}); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+func validateTemplate(template *Template) error {
+	if template.Name == "" {
+		return fmt.Errorf("Template name should not be empty")
+	}
+
+	for i, u := range template.Users {
+		if u == "" {
+			return fmt.Errorf("User name should not be empty")
+		}
+		if nameExists(template.Users[:i], u) {
+			return fmt.Errorf("User with name: %s already part of template", u)
+		}
+	}
+
+	// Templates only keep the tasks, not their estimates
+	tasks := []Task{}
+	for _, t := range template.Tasks {
+		if t.ID == "" {
+			return fmt.Errorf("Task ID should not be empty")
+		}
+		if taskExists(tasks, t.ID) {
+			return fmt.Errorf("Task with ID: %s already part of template", t.ID)
+		}
+		tasks = append(tasks, Task{ID: t.ID, Summary: t.Summary})
+	}
+	template.Tasks = tasks
+
+	if template.Settings.Anonymity == "" {
+		template.Settings.Anonymity = AnonymityOff
+	}
+	if err := validateSettings(template.Settings); err != nil {
+		return err
+	}
+
+	if template.Settings.Moderator != "" && !nameExists(template.Users, template.Settings.Moderator) {
+		return fmt.Errorf("User: %s is not part of template", template.Settings.Moderator)
+	}
+	template.Settings.Salt = ""
+	return nil
+}
+
+func nameExists(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
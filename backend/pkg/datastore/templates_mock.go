@@ -0,0 +1,46 @@
+package datastore
+
+import (
+	"github.com/stretchr/testify/mock"
+)
+
+// MockTemplateStore represents the mocked object
+type MockTemplateStore struct {
+	mock.Mock
+}
+
+// SaveTemplate implements the TemplateStore interface
+func (m *MockTemplateStore) SaveTemplate(t Template) error {
+	arguments := m.Called(t)
+	return arguments.Error(0)
+}
+
+// RemoveTemplate implements the TemplateStore interface
+func (m *MockTemplateStore) RemoveTemplate(n string) error {
+	arguments := m.Called(n)
+	return arguments.Error(0)
+}
+
+// GetTemplate implements the TemplateStore interface
+func (m *MockTemplateStore) GetTemplate(n string) (Template, error) {
+	arguments := m.Called(n)
+	return arguments.Get(0).(Template), arguments.Error(1)
+}
+
+// GetTemplates implements the TemplateStore interface
+func (m *MockTemplateStore) GetTemplates() ([]Template, error) {
+	arguments := m.Called()
+	return arguments.Get(0).([]Template), arguments.Error(1)
+}
+
+// CreateSessionFromTemplate implements the TemplateStore interface
+func (m *MockTemplateStore) CreateSessionFromTemplate(n string) (string, error) {
+	arguments := m.Called(n)
+	return arguments.String(0), arguments.Error(1)
+}
+
+// CloneSession implements the TemplateStore interface
+func (m *MockTemplateStore) CloneSession(t string, withTasks bool) (string, error) {
+	arguments := m.Called(t, withTasks)
+	return arguments.String(0), arguments.Error(1)
+}
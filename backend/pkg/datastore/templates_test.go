@@ -0,0 +1,247 @@
+package datastore
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestNewGenjiTemplateStoreNilDB(t *testing.T) {
+	_, err := NewGenjiTemplateStore(nil)
+	assert.Equal(t, "Proper DB must be provided and not nil", err.Error())
+}
+
+func TestNewGenjiTemplateStoreFailsDueToCreateTable(t *testing.T) {
+	setupAndTearDown := setupTestCaseForMock(t)
+	defer setupAndTearDown(t)
+	m.On("Exec", "CREATE TABLE templates").Return(fmt.Errorf("Ooops, something went wrong"))
+
+	_, err := NewGenjiTemplateStore(m)
+	assert.Equal(t, "Unable to create templates table", err.Error())
+}
+
+func TestSaveTemplateFailsDueToInvalidTemplateWithRealDB(t *testing.T) {
+	setupAndTearDown := setupTestCaseForRealDB(t)
+	defer setupAndTearDown(t)
+	ts, err := NewGenjiTemplateStore(db)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		template Template
+		wantErr  string
+	}{
+		{"no name", Template{}, "Template name should not be empty"},
+		{"empty user", Template{Name: "sprint", Users: []string{""}}, "User name should not be empty"},
+		{"duplicate user", Template{Name: "sprint", Users: []string{"Tigger", "Tigger"}}, "User with name: Tigger already part of template"},
+		{"empty task", Template{Name: "sprint", Tasks: []Task{{Summary: "Login"}}}, "Task ID should not be empty"},
+		{"duplicate task", Template{Name: "sprint", Tasks: []Task{{ID: "T1"}, {ID: "T1"}}}, "Task with ID: T1 already part of template"},
+		{"unknown anonymity", Template{Name: "sprint", Settings: Settings{Anonymity: "masked"}}, "Anonymity must be one of off, pseudonyms or hidden"},
+		{"unknown moderator", Template{Name: "sprint", Settings: Settings{Moderator: "Pooh"}}, "User: Pooh is not part of template"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ts.SaveTemplate(tt.template)
+			assert.Equal(t, tt.wantErr, err.Error())
+		})
+	}
+}
+
+func TestTemplatesWithRealDB(t *testing.T) {
+	setupAndTearDown := setupTestCaseForRealDB(t)
+	defer setupAndTearDown(t)
+	ds, err := NewGenjiDatastore(db)
+	assert.NoError(t, err)
+	ts, err := NewGenjiTemplateStore(db)
+	assert.NoError(t, err)
+	ss, err := NewGenjiSettingsStore(db)
+	assert.NoError(t, err)
+
+	sprint := Template{
+		Name:     "sprint",
+		Users:    []string{"Tigger", "Pooh"},
+		Tasks:    []Task{{ID: "T1", Summary: "Standup notes", Effort: 2}},
+		Settings: Settings{Anonymity: AnonymityPseudonyms, Moderator: "Pooh", Salt: "s4lt"},
+	}
+	assert.NoError(t, ts.SaveTemplate(sprint))
+	assert.NoError(t, ts.SaveTemplate(Template{Name: "empty"}))
+
+	template, err := ts.GetTemplate("sprint")
+	assert.NoError(t, err)
+	assert.Equal(t, Template{
+		Name:     "sprint",
+		Users:    []string{"Tigger", "Pooh"},
+		Tasks:    []Task{{ID: "T1", Summary: "Standup notes"}},
+		Settings: Settings{Anonymity: AnonymityPseudonyms, Moderator: "Pooh"},
+	}, template)
+	// The template provided by the caller is left untouched
+	assert.Equal(t, 2.0, sprint.Tasks[0].Effort)
+
+	templates, err := ts.GetTemplates()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(templates))
+
+	token, err := ts.CreateSessionFromTemplate("sprint")
+	assert.NoError(t, err)
+	assert.Equal(t, defaultTokenLength, len(token))
+
+	users, err := ds.GetUsers(token)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(users))
+	assert.Equal(t, "Tigger", users[0].Name)
+	assert.Equal(t, "Pooh", users[1].Name)
+	assert.NotEqual(t, users[0].ID, users[1].ID)
+	tasks, err := ds.GetTasks(token)
+	assert.NoError(t, err)
+	assert.Equal(t, []Task{{ID: "T1", Summary: "Standup notes"}}, tasks)
+	settings, err := ss.GetSettings(token)
+	assert.NoError(t, err)
+	assert.Equal(t, AnonymityPseudonyms, settings.Anonymity)
+	// The moderator of the session is identified by the ID
+	assert.Equal(t, users[1].ID, settings.Moderator)
+	assert.Equal(t, defaultSaltLength, len(settings.Salt))
+
+	// The session works like any other session
+	join(t, ds, token, "Rabbit")
+
+	token, err = ts.CreateSessionFromTemplate("empty")
+	assert.NoError(t, err)
+	users, err = ds.GetUsers(token)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(users))
+
+	assert.NoError(t, ts.RemoveTemplate("sprint"))
+	err = ts.RemoveTemplate("sprint")
+	assert.Equal(t, "Template with name: sprint does not exist", err.Error())
+	_, err = ts.CreateSessionFromTemplate("sprint")
+	assert.Equal(t, "Template with name: sprint does not exist", err.Error())
+}
+
+func TestCloneSessionWithRealDB(t *testing.T) {
+	setupAndTearDown := setupTestCaseForRealDB(t)
+	defer setupAndTearDown(t)
+	ds, err := NewGenjiDatastore(db)
+	assert.NoError(t, err)
+	ts, err := NewGenjiTemplateStore(db)
+	assert.NoError(t, err)
+	ss, err := NewGenjiSettingsStore(db)
+	assert.NoError(t, err)
+
+	token, err := ds.CreateSession()
+	assert.NoError(t, err)
+	join(t, ds, token, "Tigger")
+	join(t, ds, token, "Pooh")
+	assert.NoError(t, ds.AddTask(token, "T1", "Finished"))
+	assert.NoError(t, ds.AddTask(token, "T2", "Unfinished"))
+	assert.NoError(t, ds.AddTask(token, "T3", "Finished without effort"))
+	assert.NoError(t, ds.AddTask(token, "T4", "Reset"))
+	assert.NoError(t, ds.AddEstimateToTask(token, "T1", 1.5, 0.2))
+	assert.NoError(t, ds.AddEstimateToTask(token, "T3", 0, 0))
+	assert.NoError(t, ds.AddEstimateToTask(token, "T4", 2, 0.5))
+	assert.NoError(t, ds.RemoveEstimateFromTask(token, "T4"))
+	assert.NoError(t, ds.AddEstimate(token, Estimate{TaskID: "T2", UserID: "tigger", BestCase: 1, MostLikelyCase: 2, WorstCase: 3}))
+	assert.NoError(t, ss.SetSettings(token, Settings{Anonymity: AnonymityHidden, Moderator: "pooh"}))
+
+	clone, err := ts.CloneSession(token, true)
+	assert.NoError(t, err)
+	assert.NotEqual(t, token, clone)
+
+	// Users keep their IDs, so that the moderator stays the same
+	users, err := ds.GetUsers(clone)
+	assert.NoError(t, err)
+	assert.Equal(t, []User{{ID: "tigger", Name: "Tigger", Role: RoleEstimator}, {ID: "pooh", Name: "Pooh", Role: RoleEstimator}}, users)
+	tasks, err := ds.GetTasks(clone)
+	assert.NoError(t, err)
+	assert.Equal(t, []Task{{ID: "T2", Summary: "Unfinished"}, {ID: "T4", Summary: "Reset"}}, tasks)
+	estimates, err := ds.GetEstimates(clone)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(estimates))
+
+	original, err := ss.GetSettings(token)
+	assert.NoError(t, err)
+	settings, err := ss.GetSettings(clone)
+	assert.NoError(t, err)
+	assert.Equal(t, AnonymityHidden, settings.Anonymity)
+	assert.Equal(t, "pooh", settings.Moderator)
+	assert.NotEqual(t, original.Salt, settings.Salt)
+
+	clone, err = ts.CloneSession(token, false)
+	assert.NoError(t, err)
+	tasks, err = ds.GetTasks(clone)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(tasks))
+}
+
+func TestCloneSessionWithoutSettingsWithRealDB(t *testing.T) {
+	setupAndTearDown := setupTestCaseForRealDB(t)
+	defer setupAndTearDown(t)
+	ds, err := NewGenjiDatastore(db)
+	assert.NoError(t, err)
+	ts, err := NewGenjiTemplateStore(db)
+	assert.NoError(t, err)
+	ss, err := NewGenjiSettingsStore(db)
+	assert.NoError(t, err)
+
+	token, err := ds.CreateSession()
+	assert.NoError(t, err)
+
+	clone, err := ts.CloneSession(token, true)
+	assert.NoError(t, err)
+	settings, err := ss.GetSettings(clone)
+	assert.NoError(t, err)
+	assert.Equal(t, AnonymityOff, settings.Anonymity)
+}
+
+func TestCloneSessionFailsWithRealDB(t *testing.T) {
+	setupAndTearDown := setupTestCaseForRealDB(t)
+	defer setupAndTearDown(t)
+	ts, err := NewGenjiTemplateStore(db)
+	assert.NoError(t, err)
+
+	_, err = ts.CloneSession("12345", true)
+	assert.Equal(t, "Session token does not match desired length", err.Error())
+
+	_, err = ts.CloneSession("12345678901234567890123456789012", true)
+	assert.Equal(t, "Specified session does not exist", err.Error())
+}
+
+func TestCreateSessionFromTemplateNoError(t *testing.T) {
+	var ts TemplateStore
+	m := new(MockTemplateStore)
+	ts = m
+
+	m.On("CreateSessionFromTemplate", "sprint").Return("12345", nil)
+
+	token, err := ts.CreateSessionFromTemplate("sprint")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "12345", token)
+	m.MethodCalled("CreateSessionFromTemplate", "sprint")
+}
+
+func TestCloneSessionError(t *testing.T) {
+	var ts TemplateStore
+	m := new(MockTemplateStore)
+	ts = m
+
+	m.On("CloneSession", "12345", true).Return("", fmt.Errorf("Some error"))
+
+	_, err := ts.CloneSession("12345", true)
+
+	assert.Equal(t, "Some error", err.Error())
+	m.MethodCalled("CloneSession", "12345", true)
+}
+
+func TestGetTemplatesNoError(t *testing.T) {
+	var ts TemplateStore
+	m := new(MockTemplateStore)
+	ts = m
+
+	m.On("GetTemplates").Return([]Template{{Name: "sprint"}}, nil)
+
+	res, err := ts.GetTemplates()
+
+	assert.NoError(t, err)
+	assert.Equal(t, "sprint", res[0].Name)
+	m.MethodCalled("GetTemplates")
+}
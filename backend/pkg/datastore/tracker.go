@@ -0,0 +1,89 @@
+package datastore
+
+import (
+	"fmt"
+	"github.com/genjidb/genji"
+	"github.com/genjidb/genji/document"
+)
+
+// SyncStore defines the interface for storing the status of
+// syncing finalized tasks to an issue tracker
+type SyncStore interface {
+	SetSyncStatus(token string, status SyncStatus) error
+	GetSyncStatus(token, id string) (SyncStatus, error)
+}
+
+// SyncStatus defines the outcome of the latest sync of a task,
+// Request describes the request sent to the tracker
+type SyncStatus struct {
+	TaskID  string
+	Status  string
+	Request string
+	Error   string
+	Time    string
+}
+
+// SyncStatusUnsynced is reported for tasks which were never synced
+const SyncStatusUnsynced = "unsynced"
+
+// GenjiSyncStore stores sync statuses in their own Genji table
+type GenjiSyncStore struct {
+	db GenjiDB
+}
+
+type syncStatusRow struct {
+	Token string
+	SyncStatus
+}
+
+// NewGenjiSyncStore creates a new GenjiSyncStore and the
+// table it requires
+func NewGenjiSyncStore(db GenjiDB) (SyncStore, error) {
+	if db == nil {
+		return nil, fmt.Errorf("Proper DB must be provided and not nil")
+	}
+
+	if err := db.Exec("CREATE TABLE tracker_sync"); err != nil && err.Error() != "table already exists" {
+		return nil, fmt.Errorf("Unable to create tracker_sync table")
+	}
+
+	return &GenjiSyncStore{db: db}, nil
+}
+
+// SetSyncStatus replaces the sync status of the task
+func (g *GenjiSyncStore) SetSyncStatus(token string, status SyncStatus) error {
+	if status.TaskID == "" {
+		return fmt.Errorf("Task ID should not be empty")
+	}
+
+	err := g.db.Update(func(tx *genji.Tx) error {
+		if err := tx.Exec("DELETE FROM tracker_sync WHERE token = ? AND taskid = ?", token, status.TaskID); err != nil {
+			return err
+		}
+		return tx.Exec("INSERT INTO tracker_sync VALUES ?", &syncStatusRow{Token: token, SyncStatus: status})
+	})
+
+	if err != nil {
+		return fmt.Errorf("Unable to store sync status")
+	}
+	return nil
+}
+
+// GetSyncStatus returns the sync status of the task, which is
+// unsynced if the task was never synced
+func (g *GenjiSyncStore) GetSyncStatus(token, id string) (SyncStatus, error) {
+	status := SyncStatus{TaskID: id, Status: SyncStatusUnsynced}
+
+	res, err := g.db.Query("SELECT * FROM tracker_sync WHERE token = ? AND taskid = ?", token, id)
+	if err != nil {
+		return status, fmt.Errorf("Unable to query sync status")
+	}
+
+	defer res.Close()
+
+	err = res.Iterate(func(d document.Document) error {
+		return document.StructScan(d, &status)
+	})
+
+	return status, err
+}
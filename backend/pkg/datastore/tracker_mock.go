@@ -0,0 +1,22 @@
+package datastore
+
+import (
+	"github.com/stretchr/testify/mock"
+)
+
+// MockSyncStore represents the mocked object
+type MockSyncStore struct {
+	mock.Mock
+}
+
+// SetSyncStatus implements the SyncStore interface
+func (m *MockSyncStore) SetSyncStatus(t string, s SyncStatus) error {
+	arguments := m.Called(t, s)
+	return arguments.Error(0)
+}
+
+// GetSyncStatus implements the SyncStore interface
+func (m *MockSyncStore) GetSyncStatus(t, id string) (SyncStatus, error) {
+	arguments := m.Called(t, id)
+	return arguments.Get(0).(SyncStatus), arguments.Error(1)
+}
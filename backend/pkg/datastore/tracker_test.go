@@ -0,0 +1,110 @@
+package datastore
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestNewGenjiSyncStoreNilDB(t *testing.T) {
+	_, err := NewGenjiSyncStore(nil)
+	assert.Equal(t, "Proper DB must be provided and not nil", err.Error())
+}
+
+func TestNewGenjiSyncStoreFailsDueToCreateTable(t *testing.T) {
+	setupAndTearDown := setupTestCaseForMock(t)
+	defer setupAndTearDown(t)
+	m.On("Exec", "CREATE TABLE tracker_sync").Return(fmt.Errorf("Ooops, something went wrong"))
+
+	_, err := NewGenjiSyncStore(m)
+	assert.Equal(t, "Unable to create tracker_sync table", err.Error())
+}
+
+func TestSetSyncStatusFailsDueToEmptyIDWithRealDB(t *testing.T) {
+	setupAndTearDown := setupTestCaseForRealDB(t)
+	defer setupAndTearDown(t)
+	ss, err := NewGenjiSyncStore(db)
+	assert.NoError(t, err)
+
+	err = ss.SetSyncStatus("12345", SyncStatus{Status: "synced"})
+	assert.Equal(t, "Task ID should not be empty", err.Error())
+}
+
+func TestSyncStatusWithRealDB(t *testing.T) {
+	setupAndTearDown := setupTestCaseForRealDB(t)
+	defer setupAndTearDown(t)
+	ss, err := NewGenjiSyncStore(db)
+	assert.NoError(t, err)
+
+	status, err := ss.GetSyncStatus("12345", "TEST01")
+	assert.NoError(t, err)
+	assert.Equal(t, SyncStatus{TaskID: "TEST01", Status: SyncStatusUnsynced}, status)
+
+	failed := SyncStatus{TaskID: "TEST01", Status: "failed", Request: "PUT http://tracker.local/TEST01", Error: "Unexpected status code: 500", Time: "2021-01-02T03:04:05Z"}
+	assert.NoError(t, ss.SetSyncStatus("12345", failed))
+	assert.NoError(t, ss.SetSyncStatus("54321", SyncStatus{TaskID: "TEST01", Status: "synced"}))
+
+	status, err = ss.GetSyncStatus("12345", "TEST01")
+	assert.NoError(t, err)
+	assert.Equal(t, failed, status)
+
+	synced := SyncStatus{TaskID: "TEST01", Status: "synced", Request: "PUT http://tracker.local/TEST01", Time: "2021-01-02T03:05:05Z"}
+	assert.NoError(t, ss.SetSyncStatus("12345", synced))
+
+	status, err = ss.GetSyncStatus("12345", "TEST01")
+	assert.NoError(t, err)
+	assert.Equal(t, synced, status)
+}
+
+func TestSetSyncStatusNoError(t *testing.T) {
+	var ss SyncStore
+	m := new(MockSyncStore)
+	ss = m
+	s := SyncStatus{TaskID: "TEST01"}
+
+	m.On("SetSyncStatus", "12345", s).Return(nil)
+
+	assert.NoError(t, ss.SetSyncStatus("12345", s))
+	m.MethodCalled("SetSyncStatus", "12345", s)
+}
+
+func TestSetSyncStatusError(t *testing.T) {
+	var ss SyncStore
+	m := new(MockSyncStore)
+	ss = m
+	s := SyncStatus{TaskID: "TEST01"}
+
+	m.On("SetSyncStatus", "12345", s).Return(fmt.Errorf("Some error"))
+
+	err := ss.SetSyncStatus("12345", s)
+
+	assert.Equal(t, "Some error", err.Error())
+	m.MethodCalled("SetSyncStatus", "12345", s)
+}
+
+func TestGetSyncStatusNoError(t *testing.T) {
+	var ss SyncStore
+	m := new(MockSyncStore)
+	ss = m
+
+	m.On("GetSyncStatus", "12345", "TEST01").Return(SyncStatus{TaskID: "TEST01", Status: "synced"}, nil)
+
+	res, err := ss.GetSyncStatus("12345", "TEST01")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "synced", res.Status)
+	m.MethodCalled("GetSyncStatus", "12345", "TEST01")
+}
+
+func TestGetSyncStatusError(t *testing.T) {
+	var ss SyncStore
+	m := new(MockSyncStore)
+	ss = m
+
+	m.On("GetSyncStatus", "12345", "TEST01").Return(SyncStatus{}, fmt.Errorf("Some error"))
+
+	_, err := ss.GetSyncStatus("12345", "TEST01")
+
+	assert.Equal(t, "Some error", err.Error())
+	m.MethodCalled("GetSyncStatus", "12345", "TEST01")
+}
@@ -0,0 +1,550 @@
+package datastore
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/genjidb/genji"
+	"github.com/genjidb/genji/document"
+	"net/mail"
+	"net/url"
+)
+
+// User defines a participant of a session, the ID is stable within
+// the session while the name, avatar, email and role may change.
+// Users without role are estimators.
+type User struct {
+	ID        string
+	Name      string
+	AvatarURL string
+	Email     string
+	Role      string
+}
+
+// Roles of users inside a session
+const (
+	RoleEstimator = "estimator"
+	RoleObserver  = "observer"
+)
+
+// ErrObserver is returned if an observer tries to provide
+// an estimate
+var ErrObserver = errors.New("Observers can't provide estimates")
+
+const defaultUserIDLength int = 16
+
+// validateUser checks the profile of the user and defaults
+// the role to estimator
+func validateUser(user *User) error {
+	if user.Name == "" {
+		return invalidf("User name should not be empty")
+	}
+
+	switch user.Role {
+	case "":
+		user.Role = RoleEstimator
+	case RoleEstimator, RoleObserver:
+	default:
+		return invalidf("Role must be one of estimator or observer")
+	}
+
+	if user.Email != "" {
+		if a, err := mail.ParseAddress(user.Email); err != nil || a.Address != user.Email {
+			return invalidf("Email must be a valid address, provided: %s", user.Email)
+		}
+	}
+
+	if user.AvatarURL != "" {
+		u, err := url.Parse(user.AvatarURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return invalidf("Avatar URL must be an absolute http or https URL, provided: %s", user.AvatarURL)
+		}
+	}
+	return nil
+}
+
+// newUser validates the user joining a session and assigns a new
+// ID, unless the user already has one
+func newUser(users []User, user User) (User, error) {
+	if err := validateUser(&user); err != nil {
+		return user, err
+	}
+
+	if user.ID == "" {
+		id, err := generateToken(defaultUserIDLength)
+		if err != nil {
+			return user, fmt.Errorf("Unable to create user ID")
+		}
+		user.ID = id
+	}
+
+	if userExists(users, user.ID) {
+		return user, invalidf("User with ID: %s already part of session", user.ID)
+	}
+	return user, nil
+}
+
+// updateUser replaces the profile of the user with the same ID
+func updateUser(users []User, user User) ([]User, error) {
+	if user.ID == "" {
+		return users, invalidf("User ID should not be empty")
+	}
+	if err := validateUser(&user); err != nil {
+		return users, err
+	}
+
+	for i, u := range users {
+		if u.ID == user.ID {
+			users[i] = user
+			return users, nil
+		}
+	}
+	return users, fmt.Errorf("User: %s is not part of session", user.ID)
+}
+
+// findUser returns the user with the provided ID
+func findUser(users []User, id string) (User, bool) {
+	for _, u := range users {
+		if u.ID == id {
+			return u, true
+		}
+	}
+	return User{}, false
+}
+
+// checkEstimator verifies that the user providing the estimate is
+// part of the session and not an observer
+func checkEstimator(users []User, estimate Estimate) error {
+	u, ok := findUser(users, estimate.UserID)
+	if !ok {
+		return fmt.Errorf("User: %s is not part of session", estimate.UserID)
+	}
+	if u.Role == RoleObserver {
+		return fmt.Errorf("%w: %s is an observer", ErrObserver, u.Name)
+	}
+	return nil
+}
+
+// withUserNames sets the current names of the users on their
+// estimates, estimates of users who left keep the name they
+// were given with
+func withUserNames(estimates []Estimate, users []User) []Estimate {
+	for i, e := range estimates {
+		if u, ok := findUser(users, e.UserID); ok {
+			estimates[i].UserName = u.Name
+		}
+	}
+	return estimates
+}
+
+// legacyUserID derives the ID of a user, who joined before users
+// had IDs, from the session token and the user name, so that
+// migrated sessions and their events agree on the IDs
+func legacyUserID(token, name string) string {
+	sum := sha256.Sum256([]byte(token + "/" + name))
+	return hex.EncodeToString(sum[:])[:defaultUserIDLength]
+}
+
+type legacySession struct {
+	Token string
+	Users []string
+}
+
+type legacyEvent struct {
+	Token string
+	Seq   int
+	User  string
+	Users []string
+}
+
+type unidentifiedSession struct {
+	Token     string
+	Users     []User
+	Estimates []Estimate
+}
+
+type unidentifiedEvent struct {
+	Token    string
+	Seq      int
+	User     User
+	Users    []User
+	Estimate Estimate
+}
+
+// MigrateUsers converts sessions and events, which still keep their
+// users as bare names or without IDs, so that users become entities
+// with IDs and estimates reference these IDs. Moderators are migrated
+// as well. Already migrated data is left untouched.
+func MigrateUsers(db GenjiDB) error {
+	if db == nil {
+		return fmt.Errorf("Proper DB must be provided and not nil")
+	}
+
+	for _, table := range []string{"sessions", "events", "settings"} {
+		if err := db.Exec("CREATE TABLE " + table); err != nil && err.Error() != "table already exists" {
+			return fmt.Errorf("Unable to create %s table", table)
+		}
+	}
+
+	err := db.Update(func(tx *genji.Tx) error {
+		for _, migrate := range []func(tx *genji.Tx) error{
+			migrateSessions,
+			migrateEvents,
+			migrateSessionIDs,
+			migrateEventIDs,
+			migrateModerators,
+			migrateFinalizedTasks,
+		} {
+			if err := migrate(tx); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	if err != nil {
+		return fmt.Errorf("Unable to migrate users")
+	}
+	return nil
+}
+
+func migrateSessions(tx *genji.Tx) error {
+	var sessions []legacySession
+	err := queryLegacy(tx, "SELECT token, users FROM sessions", func(d document.Document) error {
+		if !hasTextElements(d, "users") {
+			return nil
+		}
+		var s legacySession
+		if err := document.StructScan(d, &s); err != nil {
+			return err
+		}
+		sessions = append(sessions, s)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, s := range sessions {
+		if err := tx.Exec("UPDATE sessions SET users = ? WHERE token = ?", legacyUsers(s.Users), s.Token); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func migrateEvents(tx *genji.Tx) error {
+	var rows []legacyEvent
+	err := queryLegacy(tx, "SELECT token, seq, user, users FROM events", func(d document.Document) error {
+		if !isText(d, "user") && !hasTextElements(d, "users") {
+			return nil
+		}
+		var r legacyEvent
+		if err := document.StructScan(d, &r); err != nil {
+			return err
+		}
+		rows = append(rows, r)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, r := range rows {
+		user := User{}
+		if r.User != "" {
+			user = User{Name: r.User, Role: RoleEstimator}
+		}
+		err := tx.Exec("UPDATE events SET user = ?, users = ? WHERE token = ? AND seq = ?", user, legacyUsers(r.Users), r.Token, r.Seq)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrateSessionIDs assigns IDs to the users of sessions, which joined
+// before users had IDs, and references them from their estimates
+func migrateSessionIDs(tx *genji.Tx) error {
+	var sessions []unidentifiedSession
+	err := queryLegacy(tx, "SELECT token, users, estimates FROM sessions", func(d document.Document) error {
+		if !hasUsersWithoutID(d, "users") && !hasLegacyEstimates(d) {
+			return nil
+		}
+		var s unidentifiedSession
+		if err := document.StructScan(d, &s); err != nil {
+			return err
+		}
+		sessions = append(sessions, s)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, s := range sessions {
+		users := withLegacyIDs(s.Token, s.Users)
+		if err := tx.Exec("UPDATE sessions SET users = ? WHERE token = ?", users, s.Token); err != nil {
+			return err
+		}
+
+		if len(s.Estimates) == 0 {
+			continue
+		}
+		ests := make([]Estimate, len(s.Estimates))
+		for i, e := range s.Estimates {
+			ests[i] = legacyEstimate(s.Token, e)
+		}
+		if err := tx.Exec("UPDATE sessions SET estimates = ? WHERE token = ?", ests, s.Token); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrateEventIDs assigns the same IDs to the users of events, so
+// that sessions are still replayed to the migrated state
+func migrateEventIDs(tx *genji.Tx) error {
+	var rows []unidentifiedEvent
+	err := queryLegacy(tx, "SELECT token, seq, user, users, estimate FROM events", func(d document.Document) error {
+		user, uerr := d.GetByField("user")
+		estimate, eerr := d.GetByField("estimate")
+		if (uerr != nil || !missingID(user)) && !hasUsersWithoutID(d, "users") && (eerr != nil || !isLegacyEstimate(estimate)) {
+			return nil
+		}
+		var r unidentifiedEvent
+		if err := document.StructScan(d, &r); err != nil {
+			return err
+		}
+		rows = append(rows, r)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, r := range rows {
+		if r.User.Name != "" && r.User.ID == "" {
+			r.User.ID = legacyUserID(r.Token, r.User.Name)
+		}
+		if r.Estimate.TaskID != "" {
+			r.Estimate = legacyEstimate(r.Token, r.Estimate)
+		}
+
+		err := tx.Exec("UPDATE events SET user = ?, users = ?, estimate = ? WHERE token = ? AND seq = ?",
+			r.User, withLegacyIDs(r.Token, r.Users), r.Estimate, r.Token, r.Seq)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrateModerators replaces the names of moderators with their IDs,
+// settings migrated before don't match any user name
+func migrateModerators(tx *genji.Tx) error {
+	var rows []struct{ Token, Moderator string }
+	err := queryLegacy(tx, "SELECT token, moderator FROM settings", func(d document.Document) error {
+		var r struct{ Token, Moderator string }
+		if err := document.StructScan(d, &r); err != nil {
+			return err
+		}
+		if r.Moderator != "" {
+			rows = append(rows, r)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, r := range rows {
+		d, err := tx.QueryDocument("SELECT users FROM sessions WHERE token = ?", r.Token)
+		if err != nil {
+			continue
+		}
+		var users []User
+		if err := document.Scan(d, &users); err != nil {
+			return err
+		}
+		if _, ok := findUser(users, r.Moderator); ok {
+			continue
+		}
+
+		id := legacyUserID(r.Token, r.Moderator)
+		if _, ok := findUser(users, id); !ok {
+			continue
+		}
+		if err := tx.Exec("UPDATE settings SET moderator = ? WHERE token = ?", id, r.Token); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func queryLegacy(tx *genji.Tx, q string, fn func(d document.Document) error) error {
+	res, err := tx.Query(q)
+	if err != nil {
+		return err
+	}
+
+	defer res.Close()
+
+	return res.Iterate(fn)
+}
+
+func legacyUsers(names []string) []User {
+	users := []User{}
+	for _, n := range names {
+		users = append(users, User{Name: n, Role: RoleEstimator})
+	}
+	return users
+}
+
+func withLegacyIDs(token string, users []User) []User {
+	res := []User{}
+	for _, u := range users {
+		if u.ID == "" {
+			u.ID = legacyUserID(token, u.Name)
+		}
+		res = append(res, u)
+	}
+	return res
+}
+
+func legacyEstimate(token string, e Estimate) Estimate {
+	if e.UserID == "" {
+		e.UserID = legacyUserID(token, e.UserName)
+	}
+	return e
+}
+
+func isText(d document.Document, field string) bool {
+	v, err := d.GetByField(field)
+	return err == nil && v.Type == document.TextValue
+}
+
+// hasTextElements reports whether the field is an array containing
+// text values
+func hasTextElements(d document.Document, field string) bool {
+	v, err := d.GetByField(field)
+	if err != nil || v.Type != document.ArrayValue {
+		return false
+	}
+
+	found := false
+	v.V.(document.Array).Iterate(func(i int, e document.Value) error {
+		found = found || e.Type == document.TextValue
+		return nil
+	})
+	return found
+}
+
+// hasUsersWithoutID reports whether the field is an array containing
+// users without ID
+func hasUsersWithoutID(d document.Document, field string) bool {
+	v, err := d.GetByField(field)
+	if err != nil || v.Type != document.ArrayValue {
+		return false
+	}
+
+	found := false
+	v.V.(document.Array).Iterate(func(i int, e document.Value) error {
+		found = found || missingID(e)
+		return nil
+	})
+	return found
+}
+
+// migrateFinalizedTasks marks tasks stored before tasks had the
+// finalized flag as finalized, if they have an effort
+func migrateFinalizedTasks(tx *genji.Tx) error {
+	var sessions []session
+	err := queryLegacy(tx, "SELECT token, tasks FROM sessions", func(d document.Document) error {
+		if !hasTasksWithoutFinalized(d) {
+			return nil
+		}
+		var s session
+		if err := document.StructScan(d, &s); err != nil {
+			return err
+		}
+		sessions = append(sessions, s)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, s := range sessions {
+		for i, t := range s.Tasks {
+			s.Tasks[i].Finalized = t.Effort > 0
+		}
+		if err := tx.Exec("UPDATE sessions SET tasks = ? WHERE token = ?", s.Tasks, s.Token); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// hasTasksWithoutFinalized reports whether the session contains
+// tasks without the finalized field
+func hasTasksWithoutFinalized(d document.Document) bool {
+	v, err := d.GetByField("tasks")
+	if err != nil || v.Type != document.ArrayValue {
+		return false
+	}
+
+	found := false
+	v.V.(document.Array).Iterate(func(i int, e document.Value) error {
+		if e.Type != document.DocumentValue {
+			return nil
+		}
+		_, err := e.V.(document.Document).GetByField("finalized")
+		found = found || err != nil
+		return nil
+	})
+	return found
+}
+
+// missingID reports whether the value is a user with a name but
+// without ID
+func missingID(v document.Value) bool {
+	return hasNameWithout(v, "id", "name")
+}
+
+// hasLegacyEstimates reports whether the session contains estimates
+// without user ID, e.g. of users who left before the migration
+func hasLegacyEstimates(d document.Document) bool {
+	v, err := d.GetByField("estimates")
+	if err != nil || v.Type != document.ArrayValue {
+		return false
+	}
+
+	found := false
+	v.V.(document.Array).Iterate(func(i int, e document.Value) error {
+		found = found || isLegacyEstimate(e)
+		return nil
+	})
+	return found
+}
+
+func isLegacyEstimate(v document.Value) bool {
+	return hasNameWithout(v, "userid", "username")
+}
+
+// hasNameWithout reports whether the value is a document with a
+// non-empty name field but an empty or missing ID field
+func hasNameWithout(v document.Value, idField, nameField string) bool {
+	if v.Type != document.DocumentValue {
+		return false
+	}
+
+	e := v.V.(document.Document)
+	id, err := e.GetByField(idField)
+	if err == nil && id.Type == document.TextValue && id.V.(string) != "" {
+		return false
+	}
+	name, err := e.GetByField(nameField)
+	return err == nil && name.Type == document.TextValue && name.V.(string) != ""
+}
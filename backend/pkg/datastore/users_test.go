@@ -0,0 +1,177 @@
+package datastore
+
+import (
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestValidateUser(t *testing.T) {
+	u := User{Name: "Tigger"}
+	assert.NoError(t, validateUser(&u))
+	assert.Equal(t, RoleEstimator, u.Role)
+
+	u = User{Name: "Pooh", Role: RoleObserver}
+	assert.NoError(t, validateUser(&u))
+	assert.Equal(t, RoleObserver, u.Role)
+
+	assert.Equal(t, "User name should not be empty", validateUser(&User{}).Error())
+	assert.Equal(t, "Role must be one of estimator or observer", validateUser(&User{Name: "Pooh", Role: "owner"}).Error())
+	assert.True(t, errors.Is(validateUser(&User{}), ErrInvalid))
+}
+
+func TestObserversCantEstimateWithRealDB(t *testing.T) {
+	setupAndTearDown := setupTestCaseForRealDB(t)
+	defer setupAndTearDown(t)
+	gds, err := NewGenjiDatastore(db)
+	assert.NoError(t, err)
+	es, err := NewEventSourcedDatastore(db)
+	assert.NoError(t, err)
+
+	for _, ds := range []DataStore{gds, es} {
+		token, err := ds.CreateSession()
+		assert.NoError(t, err)
+		_, err = ds.JoinSession(token, User{ID: "tigger", Name: "Tigger"})
+		assert.NoError(t, err)
+		_, err = ds.JoinSession(token, User{ID: "pooh", Name: "Pooh", Role: RoleObserver})
+		assert.NoError(t, err)
+		assert.NoError(t, ds.AddTask(token, "T1", "Login"))
+
+		users, err := ds.GetUsers(token)
+		assert.NoError(t, err)
+		assert.Equal(t, []User{{ID: "tigger", Name: "Tigger", Role: RoleEstimator}, {ID: "pooh", Name: "Pooh", Role: RoleObserver}}, users)
+
+		assert.NoError(t, ds.AddEstimate(token, Estimate{TaskID: "T1", UserID: "tigger", BestCase: 1, MostLikelyCase: 2, WorstCase: 3}))
+		err = ds.AddEstimate(token, Estimate{TaskID: "T1", UserID: "pooh", BestCase: 1, MostLikelyCase: 2, WorstCase: 3})
+		assert.True(t, errors.Is(err, ErrObserver))
+		assert.Equal(t, "Observers can't provide estimates: Pooh is an observer", err.Error())
+
+		_, err = ds.JoinSession(token, User{Name: "Rabbit", Role: "owner"})
+		assert.Equal(t, "Role must be one of estimator or observer", err.Error())
+	}
+}
+
+func TestMigrateUsersNilDB(t *testing.T) {
+	err := MigrateUsers(nil)
+	assert.Equal(t, "Proper DB must be provided and not nil", err.Error())
+}
+
+func TestMigrateUsersFailsDueToCreateTable(t *testing.T) {
+	setupAndTearDown := setupTestCaseForMock(t)
+	defer setupAndTearDown(t)
+	m.On("Exec", "CREATE TABLE sessions").Return(fmt.Errorf("Ooops, something went wrong"))
+
+	err := MigrateUsers(m)
+	assert.Equal(t, "Unable to create sessions table", err.Error())
+}
+
+func TestMigrateUsersWithRealDB(t *testing.T) {
+	setupAndTearDown := setupTestCaseForRealDB(t)
+	defer setupAndTearDown(t)
+
+	token := "eaf27c59ecdf0db4e165c4f940e176ec"
+	named := "fedcba9876543210fedcba9876543210"
+	replayed := "0123456789abcdef0123456789abcdef"
+	for _, table := range []string{"sessions", "events", "settings"} {
+		assert.NoError(t, db.Exec("CREATE TABLE "+table))
+	}
+
+	// Users stored as bare names and tasks without finalized flag
+	type legacyTask struct {
+		ID     string
+		Effort float64
+	}
+	assert.NoError(t, db.Exec("INSERT INTO sessions VALUES ?", &struct {
+		Token     string
+		Users     []string
+		Tasks     []legacyTask
+		Estimates []Estimate
+	}{token, []string{"Tigger", "Pooh"}, []legacyTask{{ID: "T1"}, {ID: "T2", Effort: 2}}, []Estimate{{TaskID: "T1", UserName: "Tigger", BestCase: 1, MostLikelyCase: 2, WorstCase: 3}}}))
+	assert.NoError(t, db.Exec("INSERT INTO settings VALUES ?", &settingsRow{Token: token, Settings: Settings{Anonymity: AnonymityOff, Moderator: "Pooh"}}))
+
+	// Users stored as entities without IDs
+	assert.NoError(t, db.Exec("INSERT INTO sessions VALUES ?", &struct {
+		Token string
+		Users []User
+		Tasks []Task
+	}{named, []User{{Name: "Tigger", Role: RoleEstimator}, {Name: "Pooh", Role: RoleObserver}}, []Task{}}))
+
+	// Projection and events of an event-sourced session
+	assert.NoError(t, db.Exec("INSERT INTO sessions VALUES ?", &struct {
+		Token     string
+		Seq       int
+		Users     []string
+		Tasks     []Task
+		Estimates []Estimate
+	}{replayed, 4, []string{}, []Task{{ID: "T1"}}, []Estimate{{TaskID: "T1", UserName: "Pooh", BestCase: 1, MostLikelyCase: 2, WorstCase: 3}}}))
+	for _, ev := range []struct {
+		Token    string
+		Seq      int
+		Type     string
+		Time     string
+		User     string
+		Task     Task
+		Estimate Estimate
+		Users    []string
+	}{
+		{Seq: 1, Type: EventSessionCreated, Time: "2021-01-14T15:04:05Z", Users: []string{"Tigger"}},
+		{Seq: 2, Type: EventUserJoined, Time: "2021-01-14T15:04:06Z", User: "Pooh"},
+		{Seq: 3, Type: EventTaskAdded, Time: "2021-01-14T15:04:07Z", Task: Task{ID: "T1"}},
+		{Seq: 4, Type: EventEstimateAdded, Time: "2021-01-14T15:04:08Z", Estimate: Estimate{TaskID: "T1", UserName: "Pooh", BestCase: 1, MostLikelyCase: 2, WorstCase: 3}},
+	} {
+		ev.Token = replayed
+		assert.NoError(t, db.Exec("INSERT INTO events VALUES ?", &ev))
+	}
+
+	// Migrating twice leaves migrated data untouched
+	assert.NoError(t, MigrateUsers(db))
+	assert.NoError(t, MigrateUsers(db))
+
+	gds, err := NewGenjiDatastore(db)
+	assert.NoError(t, err)
+	users, err := gds.GetUsers(token)
+	assert.NoError(t, err)
+	assert.Equal(t, []User{
+		{ID: legacyUserID(token, "Tigger"), Name: "Tigger", Role: RoleEstimator},
+		{ID: legacyUserID(token, "Pooh"), Name: "Pooh", Role: RoleEstimator},
+	}, users)
+
+	ests, err := gds.GetEstimates(token)
+	assert.NoError(t, err)
+	assert.Equal(t, legacyUserID(token, "Tigger"), ests[0].UserID)
+
+	tasks, err := gds.GetTasks(token)
+	assert.NoError(t, err)
+	assert.Equal(t, []Task{{ID: "T1"}, {ID: "T2", Effort: 2, Finalized: true}}, tasks)
+	assert.Equal(t, "Tigger", ests[0].UserName)
+
+	users, err = gds.GetUsers(named)
+	assert.NoError(t, err)
+	assert.Equal(t, []User{
+		{ID: legacyUserID(named, "Tigger"), Name: "Tigger", Role: RoleEstimator},
+		{ID: legacyUserID(named, "Pooh"), Name: "Pooh", Role: RoleObserver},
+	}, users)
+
+	ss, err := NewGenjiSettingsStore(db)
+	assert.NoError(t, err)
+	settings, err := ss.GetSettings(token)
+	assert.NoError(t, err)
+	assert.Equal(t, legacyUserID(token, "Pooh"), settings.Moderator)
+
+	es, err := NewEventSourcedDatastore(db)
+	assert.NoError(t, err)
+	state, err := es.ReplaySession(replayed, ReplayQuery{})
+	assert.NoError(t, err)
+	assert.Equal(t, []User{
+		{ID: legacyUserID(replayed, "Tigger"), Name: "Tigger", Role: RoleEstimator},
+		{ID: legacyUserID(replayed, "Pooh"), Name: "Pooh", Role: RoleEstimator},
+	}, state.Users)
+	assert.Equal(t, legacyUserID(replayed, "Pooh"), state.Estimates[0].UserID)
+
+	// Estimates of users, who left, are migrated as well
+	ests, err = es.GetEstimates(replayed)
+	assert.NoError(t, err)
+	assert.Equal(t, legacyUserID(replayed, "Pooh"), ests[0].UserID)
+	assert.Equal(t, "Pooh", ests[0].UserName)
+}
@@ -0,0 +1,231 @@
+package datastore
+
+import (
+	"fmt"
+	"github.com/genjidb/genji/document"
+	"go.uber.org/zap"
+)
+
+// WebhookStore defines the interface for storing the webhooks
+// registered for a session and their deliveries
+type WebhookStore interface {
+	AddWebhook(token string, webhook Webhook) (string, error)
+	RemoveWebhook(token, id string) error
+	RemoveWebhooks(token string) error
+	GetWebhooks(token string) ([]Webhook, error)
+	AddDelivery(token string, delivery Delivery) error
+	GetDeliveries(token string, query DeliveryQuery) ([]Delivery, int, error)
+}
+
+// Webhook defines a receiver of session events, an empty
+// event list subscribes to all events
+type Webhook struct {
+	ID     string
+	URL    string
+	Secret string
+	Events []string
+}
+
+// Delivery defines the outcome of sending a single event
+// to a webhook
+type Delivery struct {
+	ID         string
+	WebhookID  string
+	Event      string
+	Status     string
+	Attempts   int
+	StatusCode int
+	Error      string
+	Time       string
+}
+
+// DeliveryQuery defines which page of deliveries is returned
+type DeliveryQuery struct {
+	Offset int
+	Limit  int
+}
+
+// GenjiWebhookStore stores webhooks and deliveries in
+// their own Genji tables
+type GenjiWebhookStore struct {
+	db GenjiDB
+}
+
+type webhookRow struct {
+	Token string
+	Webhook
+}
+
+type deliveryRow struct {
+	Token string
+	Delivery
+}
+
+const defaultWebhookIDLength int = 16
+
+// NewGenjiWebhookStore creates a new GenjiWebhookStore and the
+// tables it requires
+func NewGenjiWebhookStore(db GenjiDB) (WebhookStore, error) {
+	if db == nil {
+		return nil, fmt.Errorf("Proper DB must be provided and not nil")
+	}
+
+	for _, table := range []string{"webhooks", "deliveries"} {
+		if err := db.Exec("CREATE TABLE " + table); err != nil && err.Error() != "table already exists" {
+			return nil, fmt.Errorf("Unable to create %s table", table)
+		}
+	}
+
+	return &GenjiWebhookStore{db: db}, nil
+}
+
+// AddWebhook registers a new webhook for the specified session
+// and returns its ID
+func (g *GenjiWebhookStore) AddWebhook(token string, webhook Webhook) (string, error) {
+	if webhook.URL == "" {
+		return "", fmt.Errorf("Webhook URL should not be empty")
+	}
+
+	id, err := generateToken(defaultWebhookIDLength)
+	if err != nil {
+		return "", fmt.Errorf("Unable to create webhook ID")
+	}
+	webhook.ID = id
+
+	if err := g.db.Exec("INSERT INTO webhooks VALUES ?", &webhookRow{Token: token, Webhook: webhook}); err != nil {
+		return "", fmt.Errorf("Unable to store webhook")
+	}
+	return id, nil
+}
+
+// RemoveWebhook removes the webhook with the specified ID
+// from the session
+func (g *GenjiWebhookStore) RemoveWebhook(token, id string) error {
+	webhooks, err := g.GetWebhooks(token)
+	if err != nil {
+		return err
+	}
+
+	for _, w := range webhooks {
+		if w.ID == id {
+			return g.db.Exec("DELETE FROM webhooks WHERE token = ? AND id = ?", token, id)
+		}
+	}
+	return fmt.Errorf("Webhook with ID: %s does not exist", id)
+}
+
+// RemoveWebhooks removes all webhooks of the session together
+// with their deliveries
+func (g *GenjiWebhookStore) RemoveWebhooks(token string) error {
+	for _, table := range []string{"webhooks", "deliveries"} {
+		if err := g.db.Exec("DELETE FROM "+table+" WHERE token = ?", token); err != nil {
+			return fmt.Errorf("Unable to remove %s", table)
+		}
+	}
+	return nil
+}
+
+// GetWebhooks returns all webhooks registered for the session
+func (g *GenjiWebhookStore) GetWebhooks(token string) ([]Webhook, error) {
+	webhooks := []Webhook{}
+
+	res, err := g.db.Query("SELECT id, url, secret, events FROM webhooks WHERE token = ?", token)
+	if err != nil {
+		return webhooks, fmt.Errorf("Unable to query webhooks")
+	}
+
+	defer res.Close()
+
+	err = res.Iterate(func(d document.Document) error {
+		var w Webhook
+		if err := document.StructScan(d, &w); err != nil {
+			return err
+		}
+		webhooks = append(webhooks, w)
+		return nil
+	})
+
+	return webhooks, err
+}
+
+// AddDelivery records the delivery of an event for the session
+func (g *GenjiWebhookStore) AddDelivery(token string, delivery Delivery) error {
+	id, err := generateToken(defaultWebhookIDLength)
+	if err != nil {
+		return fmt.Errorf("Unable to create delivery ID")
+	}
+	delivery.ID = id
+
+	if err := g.db.Exec("INSERT INTO deliveries VALUES ?", &deliveryRow{Token: token, Delivery: delivery}); err != nil {
+		return fmt.Errorf("Unable to store delivery")
+	}
+	return nil
+}
+
+// GetDeliveries returns the requested page of deliveries of the
+// session in the order they were added together with the total
+// number of deliveries, a limit of 0 returns all remaining ones
+func (g *GenjiWebhookStore) GetDeliveries(token string, query DeliveryQuery) ([]Delivery, int, error) {
+	deliveries := []Delivery{}
+
+	if query.Offset < 0 || query.Limit < 0 {
+		return deliveries, 0, fmt.Errorf("Offset and limit must be >= 0")
+	}
+
+	res, err := g.db.Query("SELECT * FROM deliveries WHERE token = ?", token)
+	if err != nil {
+		return deliveries, 0, fmt.Errorf("Unable to query deliveries")
+	}
+
+	defer res.Close()
+
+	total := 0
+	err = res.Iterate(func(d document.Document) error {
+		total++
+		if total <= query.Offset || (query.Limit > 0 && len(deliveries) == query.Limit) {
+			return nil
+		}
+		var de Delivery
+		if err := document.StructScan(d, &de); err != nil {
+			return err
+		}
+		deliveries = append(deliveries, de)
+		return nil
+	})
+
+	return deliveries, total, err
+}
+
+// WebhookRemovingDataStore wraps a datastore and removes the
+// webhooks and deliveries of removed sessions
+type WebhookRemovingDataStore struct {
+	DataStore
+	webhooks WebhookStore
+	logger   *zap.Logger
+}
+
+// NewWebhookRemovingDataStore wraps the provided datastore so that
+// webhooks of the provided store are removed together with their
+// session, a nil logger disables logging
+func NewWebhookRemovingDataStore(ds DataStore, webhooks WebhookStore, logger *zap.Logger) DataStore {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &WebhookRemovingDataStore{
+		DataStore: ds,
+		webhooks:  webhooks,
+		logger:    logger,
+	}
+}
+
+// RemoveSession implements the Datastore interface, failures to
+// remove the webhooks are logged as the session is already gone
+func (r *WebhookRemovingDataStore) RemoveSession(token string) error {
+	if err := r.DataStore.RemoveSession(token); err != nil {
+		return err
+	}
+	if err := r.webhooks.RemoveWebhooks(token); err != nil {
+		r.logger.Error("Unable to remove webhooks", zap.String("session", RedactToken(token)), zap.Error(err))
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package datastore
+
+import (
+	"github.com/stretchr/testify/mock"
+)
+
+// MockWebhookStore represents the mocked object
+type MockWebhookStore struct {
+	mock.Mock
+}
+
+// AddWebhook implements the WebhookStore interface
+func (m *MockWebhookStore) AddWebhook(t string, w Webhook) (string, error) {
+	arguments := m.Called(t, w)
+	return arguments.Get(0).(string), arguments.Error(1)
+}
+
+// RemoveWebhook implements the WebhookStore interface
+func (m *MockWebhookStore) RemoveWebhook(t, id string) error {
+	arguments := m.Called(t, id)
+	return arguments.Error(0)
+}
+
+// RemoveWebhooks implements the WebhookStore interface
+func (m *MockWebhookStore) RemoveWebhooks(t string) error {
+	arguments := m.Called(t)
+	return arguments.Error(0)
+}
+
+// GetWebhooks implements the WebhookStore interface
+func (m *MockWebhookStore) GetWebhooks(t string) ([]Webhook, error) {
+	arguments := m.Called(t)
+	return arguments.Get(0).([]Webhook), arguments.Error(1)
+}
+
+// AddDelivery implements the WebhookStore interface
+func (m *MockWebhookStore) AddDelivery(t string, d Delivery) error {
+	arguments := m.Called(t, d)
+	return arguments.Error(0)
+}
+
+// GetDeliveries implements the WebhookStore interface
+func (m *MockWebhookStore) GetDeliveries(t string, q DeliveryQuery) ([]Delivery, int, error) {
+	arguments := m.Called(t, q)
+	return arguments.Get(0).([]Delivery), arguments.Int(1), arguments.Error(2)
+}
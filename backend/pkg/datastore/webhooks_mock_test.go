@@ -0,0 +1,143 @@
+package datastore
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestAddWebhookNoError(t *testing.T) {
+	var ws WebhookStore
+	m := new(MockWebhookStore)
+	ws = m
+	w := Webhook{URL: "http://bot.local/hook"}
+
+	m.On("AddWebhook", "12345", w).Return("abcd", nil)
+
+	id, err := ws.AddWebhook("12345", w)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "abcd", id)
+	m.MethodCalled("AddWebhook", "12345", w)
+}
+
+func TestAddWebhookError(t *testing.T) {
+	var ws WebhookStore
+	m := new(MockWebhookStore)
+	ws = m
+	w := Webhook{URL: "http://bot.local/hook"}
+
+	m.On("AddWebhook", "12345", w).Return("", fmt.Errorf("Some error"))
+
+	_, err := ws.AddWebhook("12345", w)
+
+	assert.Equal(t, "Some error", err.Error())
+	m.MethodCalled("AddWebhook", "12345", w)
+}
+
+func TestRemoveWebhookNoError(t *testing.T) {
+	var ws WebhookStore
+	m := new(MockWebhookStore)
+	ws = m
+
+	m.On("RemoveWebhook", "12345", "abcd").Return(nil)
+
+	assert.NoError(t, ws.RemoveWebhook("12345", "abcd"))
+	m.MethodCalled("RemoveWebhook", "12345", "abcd")
+}
+
+func TestRemoveWebhookError(t *testing.T) {
+	var ws WebhookStore
+	m := new(MockWebhookStore)
+	ws = m
+
+	m.On("RemoveWebhook", "12345", "abcd").Return(fmt.Errorf("Some error"))
+
+	err := ws.RemoveWebhook("12345", "abcd")
+
+	assert.Equal(t, "Some error", err.Error())
+	m.MethodCalled("RemoveWebhook", "12345", "abcd")
+}
+
+func TestGetWebhooksNoError(t *testing.T) {
+	var ws WebhookStore
+	m := new(MockWebhookStore)
+	ws = m
+
+	m.On("GetWebhooks", "12345").Return([]Webhook{{ID: "abcd"}}, nil)
+
+	res, err := ws.GetWebhooks("12345")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []Webhook{{ID: "abcd"}}, res)
+	m.MethodCalled("GetWebhooks", "12345")
+}
+
+func TestGetWebhooksError(t *testing.T) {
+	var ws WebhookStore
+	m := new(MockWebhookStore)
+	ws = m
+
+	m.On("GetWebhooks", "12345").Return([]Webhook{}, fmt.Errorf("Some error"))
+
+	_, err := ws.GetWebhooks("12345")
+
+	assert.Equal(t, "Some error", err.Error())
+	m.MethodCalled("GetWebhooks", "12345")
+}
+
+func TestAddDeliveryNoError(t *testing.T) {
+	var ws WebhookStore
+	m := new(MockWebhookStore)
+	ws = m
+	d := Delivery{WebhookID: "abcd"}
+
+	m.On("AddDelivery", "12345", d).Return(nil)
+
+	assert.NoError(t, ws.AddDelivery("12345", d))
+	m.MethodCalled("AddDelivery", "12345", d)
+}
+
+func TestAddDeliveryError(t *testing.T) {
+	var ws WebhookStore
+	m := new(MockWebhookStore)
+	ws = m
+	d := Delivery{WebhookID: "abcd"}
+
+	m.On("AddDelivery", "12345", d).Return(fmt.Errorf("Some error"))
+
+	err := ws.AddDelivery("12345", d)
+
+	assert.Equal(t, "Some error", err.Error())
+	m.MethodCalled("AddDelivery", "12345", d)
+}
+
+func TestGetDeliveriesNoError(t *testing.T) {
+	var ws WebhookStore
+	m := new(MockWebhookStore)
+	ws = m
+
+	q := DeliveryQuery{Limit: 10}
+	m.On("GetDeliveries", "12345", q).Return([]Delivery{{ID: "1"}}, 1, nil)
+
+	res, total, err := ws.GetDeliveries("12345", q)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []Delivery{{ID: "1"}}, res)
+	assert.Equal(t, 1, total)
+	m.MethodCalled("GetDeliveries", "12345", q)
+}
+
+func TestGetDeliveriesError(t *testing.T) {
+	var ws WebhookStore
+	m := new(MockWebhookStore)
+	ws = m
+
+	q := DeliveryQuery{Limit: 10}
+	m.On("GetDeliveries", "12345", q).Return([]Delivery{}, 0, fmt.Errorf("Some error"))
+
+	_, _, err := ws.GetDeliveries("12345", q)
+
+	assert.Equal(t, "Some error", err.Error())
+	m.MethodCalled("GetDeliveries", "12345", q)
+}
@@ -0,0 +1,162 @@
+package datastore
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"testing"
+)
+
+func TestNewGenjiWebhookStoreNilDB(t *testing.T) {
+	_, err := NewGenjiWebhookStore(nil)
+	assert.Equal(t, "Proper DB must be provided and not nil", err.Error())
+}
+
+func TestNewGenjiWebhookStoreFailsDueToCreateTable(t *testing.T) {
+	setupAndTearDown := setupTestCaseForMock(t)
+	defer setupAndTearDown(t)
+	m.On("Exec", "CREATE TABLE webhooks").Return(nil)
+	m.On("Exec", "CREATE TABLE deliveries").Return(fmt.Errorf("Ooops, something went wrong"))
+
+	_, err := NewGenjiWebhookStore(m)
+	assert.Equal(t, "Unable to create deliveries table", err.Error())
+}
+
+func TestAddWebhookFailsDueToEmptyURLWithRealDB(t *testing.T) {
+	setupAndTearDown := setupTestCaseForRealDB(t)
+	defer setupAndTearDown(t)
+	ws, err := NewGenjiWebhookStore(db)
+	assert.NoError(t, err)
+
+	_, err = ws.AddWebhook("12345", Webhook{})
+	assert.Equal(t, "Webhook URL should not be empty", err.Error())
+}
+
+func TestWebhooksWithRealDB(t *testing.T) {
+	setupAndTearDown := setupTestCaseForRealDB(t)
+	defer setupAndTearDown(t)
+	ws, err := NewGenjiWebhookStore(db)
+	assert.NoError(t, err)
+
+	id1, err := ws.AddWebhook("12345", Webhook{URL: "http://bot.local/hook", Secret: "s3cr3t", Events: []string{"task.finalized"}})
+	assert.NoError(t, err)
+	assert.Equal(t, defaultWebhookIDLength, len(id1))
+	id2, err := ws.AddWebhook("12345", Webhook{URL: "http://tracker.local/hook"})
+	assert.NoError(t, err)
+	_, err = ws.AddWebhook("54321", Webhook{URL: "http://other.local/hook"})
+	assert.NoError(t, err)
+
+	webhooks, err := ws.GetWebhooks("12345")
+	assert.NoError(t, err)
+	assert.Equal(t, []Webhook{
+		{ID: id1, URL: "http://bot.local/hook", Secret: "s3cr3t", Events: []string{"task.finalized"}},
+		{ID: id2, URL: "http://tracker.local/hook"},
+	}, webhooks)
+
+	assert.NoError(t, ws.RemoveWebhook("12345", id1))
+	err = ws.RemoveWebhook("12345", id1)
+	assert.Equal(t, fmt.Sprintf("Webhook with ID: %s does not exist", id1), err.Error())
+
+	webhooks, err = ws.GetWebhooks("12345")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(webhooks))
+	assert.Equal(t, id2, webhooks[0].ID)
+}
+
+func TestDeliveriesWithRealDB(t *testing.T) {
+	setupAndTearDown := setupTestCaseForRealDB(t)
+	defer setupAndTearDown(t)
+	ws, err := NewGenjiWebhookStore(db)
+	assert.NoError(t, err)
+
+	deliveries, total, err := ws.GetDeliveries("12345", DeliveryQuery{})
+	assert.NoError(t, err)
+	assert.Empty(t, deliveries)
+	assert.Equal(t, 0, total)
+
+	d := Delivery{
+		WebhookID:  "abcd",
+		Event:      "session.removed",
+		Status:     "failed",
+		Attempts:   3,
+		StatusCode: 500,
+		Error:      "Unexpected status code: 500",
+		Time:       "2021-01-02T03:04:05Z",
+	}
+	assert.NoError(t, ws.AddDelivery("12345", d))
+
+	deliveries, total, err = ws.GetDeliveries("12345", DeliveryQuery{})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(deliveries))
+	assert.Equal(t, 1, total)
+	assert.NotEmpty(t, deliveries[0].ID)
+	d.ID = deliveries[0].ID
+	assert.Equal(t, d, deliveries[0])
+}
+
+func TestDeliveriesPagesWithRealDB(t *testing.T) {
+	setupAndTearDown := setupTestCaseForRealDB(t)
+	defer setupAndTearDown(t)
+	ws, err := NewGenjiWebhookStore(db)
+	assert.NoError(t, err)
+
+	for _, code := range []int{200, 201, 202} {
+		assert.NoError(t, ws.AddDelivery("12345", Delivery{WebhookID: "abcd", StatusCode: code}))
+	}
+
+	deliveries, total, err := ws.GetDeliveries("12345", DeliveryQuery{Offset: 1, Limit: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, total)
+	assert.Equal(t, 1, len(deliveries))
+	assert.Equal(t, 201, deliveries[0].StatusCode)
+
+	_, _, err = ws.GetDeliveries("12345", DeliveryQuery{Offset: -1})
+	assert.Equal(t, "Offset and limit must be >= 0", err.Error())
+}
+
+func TestRemoveWebhooksWithRealDB(t *testing.T) {
+	setupAndTearDown := setupTestCaseForRealDB(t)
+	defer setupAndTearDown(t)
+	ws, err := NewGenjiWebhookStore(db)
+	assert.NoError(t, err)
+	for _, token := range []string{"12345", "54321"} {
+		_, err := ws.AddWebhook(token, Webhook{URL: "http://bot.local/hook"})
+		assert.NoError(t, err)
+		assert.NoError(t, ws.AddDelivery(token, Delivery{WebhookID: "hook1", Status: "delivered"}))
+	}
+
+	assert.NoError(t, ws.RemoveWebhooks("12345"))
+
+	webhooks, err := ws.GetWebhooks("12345")
+	assert.NoError(t, err)
+	assert.Empty(t, webhooks)
+	_, total, err := ws.GetDeliveries("12345", DeliveryQuery{})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, total)
+
+	webhooks, err = ws.GetWebhooks("54321")
+	assert.NoError(t, err)
+	assert.Len(t, webhooks, 1)
+	_, total, err = ws.GetDeliveries("54321", DeliveryQuery{})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, total)
+}
+
+func TestWebhookRemovingDataStore(t *testing.T) {
+	m := new(MockDatastore)
+	ws := new(MockWebhookStore)
+	core, logs := observer.New(zapcore.ErrorLevel)
+	ds := NewWebhookRemovingDataStore(m, ws, zap.New(core))
+	m.On("RemoveSession", "12345").Return(nil)
+	m.On("RemoveSession", "54321").Return(fmt.Errorf("Specified session does not exist"))
+	ws.On("RemoveWebhooks", "12345").Return(fmt.Errorf("Unable to remove webhooks"))
+
+	assert.NoError(t, ds.RemoveSession("12345"))
+	ws.AssertCalled(t, "RemoveWebhooks", "12345")
+	assert.Equal(t, 1, logs.FilterMessage("Unable to remove webhooks").Len())
+
+	assert.Error(t, ds.RemoveSession("54321"))
+	ws.AssertNotCalled(t, "RemoveWebhooks", "54321")
+}
@@ -0,0 +1,75 @@
+package estimate
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Card defines a single card of a deck, Label is shown to
+// users and Value is used for calculations
+type Card struct {
+	Label string
+	Value float64
+}
+
+// Decks contains the predefined decks by name
+var Decks = map[string][]Card{
+	"fibonacci":          numberCards(0, 1, 2, 3, 5, 8, 13, 21, 34, 55, 89),
+	"modified-fibonacci": numberCards(0, 0.5, 1, 2, 3, 5, 8, 13, 20, 40, 100),
+	"powers-of-two":      numberCards(0, 1, 2, 4, 8, 16, 32, 64),
+	"t-shirt": {
+		{Label: "XS", Value: 1},
+		{Label: "S", Value: 2},
+		{Label: "M", Value: 3},
+		{Label: "L", Value: 5},
+		{Label: "XL", Value: 8},
+		{Label: "XXL", Value: 13},
+	},
+}
+
+// Deck returns a copy of the predefined deck with the provided name
+func Deck(name string) ([]Card, error) {
+	cards, ok := Decks[name]
+	if !ok {
+		return nil, fmt.Errorf("Unknown deck: %s", name)
+	}
+	return append([]Card{}, cards...), nil
+}
+
+// ValidateCards checks that all cards have a non-negative value and
+// that neither labels nor values are used twice
+func ValidateCards(cards []Card) error {
+	for i, c := range cards {
+		if c.Value < 0 {
+			return fmt.Errorf("Card value must be >= 0, provided: %g", c.Value)
+		}
+		for _, o := range cards[:i] {
+			if o.Value == c.Value || (c.Label != "" && o.Label == c.Label) {
+				return fmt.Errorf("Card %s is part of the deck twice", c.Label)
+			}
+		}
+	}
+	return nil
+}
+
+// InDeck checks whether the value is the value of one of the
+// cards, every value is allowed for an empty deck
+func InDeck(cards []Card, value float64) bool {
+	if len(cards) == 0 {
+		return true
+	}
+	for _, c := range cards {
+		if c.Value == value {
+			return true
+		}
+	}
+	return false
+}
+
+func numberCards(values ...float64) []Card {
+	cards := []Card{}
+	for _, v := range values {
+		cards = append(cards, Card{Label: strconv.FormatFloat(v, 'g', -1, 64), Value: v})
+	}
+	return cards
+}
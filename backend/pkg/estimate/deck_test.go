@@ -0,0 +1,47 @@
+package estimate
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestDeck(t *testing.T) {
+	cards, err := Deck("t-shirt")
+	assert.NoError(t, err)
+	assert.Equal(t, Card{Label: "M", Value: 3}, cards[2])
+
+	cards, err = Deck("modified-fibonacci")
+	assert.NoError(t, err)
+	assert.Equal(t, Card{Label: "0.5", Value: 0.5}, cards[1])
+
+	// Changing the returned deck keeps the predefined one
+	cards[1].Value = 42
+	assert.Equal(t, 0.5, Decks["modified-fibonacci"][1].Value)
+
+	_, err = Deck("tarot")
+	assert.Equal(t, "Unknown deck: tarot", err.Error())
+}
+
+func TestPredefinedDecksAreValid(t *testing.T) {
+	for name, cards := range Decks {
+		assert.NoError(t, ValidateCards(cards), name)
+	}
+}
+
+func TestValidateCards(t *testing.T) {
+	err := ValidateCards([]Card{{Label: "S", Value: -1}})
+	assert.Equal(t, "Card value must be >= 0, provided: -1", err.Error())
+
+	err = ValidateCards([]Card{{Label: "S", Value: 1}, {Label: "M", Value: 1}})
+	assert.Equal(t, "Card M is part of the deck twice", err.Error())
+
+	err = ValidateCards([]Card{{Label: "S", Value: 1}, {Label: "S", Value: 2}})
+	assert.Equal(t, "Card S is part of the deck twice", err.Error())
+}
+
+func TestInDeck(t *testing.T) {
+	cards := []Card{{Label: "S", Value: 1}, {Label: "M", Value: 2}}
+	assert.True(t, InDeck(cards, 2))
+	assert.False(t, InDeck(cards, 1.5))
+	assert.True(t, InDeck(nil, 1.5))
+}
@@ -0,0 +1,96 @@
+package estimate
+
+import (
+	"fmt"
+)
+
+// Units efforts can be estimated in, days and weeks are person-days
+// and person-weeks
+const (
+	Hours  = "hours"
+	Days   = "days"
+	Weeks  = "weeks"
+	Points = "points"
+)
+
+// Units contains all supported units
+var Units = []string{Hours, Days, Weeks, Points}
+
+// ValidUnit checks whether the unit is supported
+func ValidUnit(unit string) bool {
+	for _, u := range Units {
+		if u == unit {
+			return true
+		}
+	}
+	return false
+}
+
+// Calendar defines how many working hours make up a person-day
+// and how many person-days make up a person-week
+type Calendar struct {
+	HoursPerDay float64
+	DaysPerWeek float64
+}
+
+// DefaultCalendar is a calendar with 8 hour days and 5 day weeks
+var DefaultCalendar = Calendar{HoursPerDay: 8, DaysPerWeek: 5}
+
+// Validate checks whether the calendar can be used for conversions
+func (c Calendar) Validate() error {
+	if c.HoursPerDay <= 0 || c.HoursPerDay > 24 {
+		return fmt.Errorf("Hours per day must be > 0 and <= 24, provided: %g", c.HoursPerDay)
+	}
+	if c.DaysPerWeek <= 0 || c.DaysPerWeek > 7 {
+		return fmt.Errorf("Days per week must be > 0 and <= 7, provided: %g", c.DaysPerWeek)
+	}
+	return nil
+}
+
+// ConvertEffort converts an effort or standard deviation between
+// hours, days and weeks, points can only be converted to points
+func (c Calendar) ConvertEffort(value float64, from, to string) (float64, error) {
+	if !ValidUnit(from) || !ValidUnit(to) {
+		return 0, fmt.Errorf("Unit must be one of hours, days, weeks or points")
+	}
+	if from == to {
+		return value, nil
+	}
+	if from == Points || to == Points {
+		return 0, fmt.Errorf("Unable to convert %s to %s", from, to)
+	}
+	if err := c.Validate(); err != nil {
+		return 0, err
+	}
+	return value * c.hours(from) / c.hours(to), nil
+}
+
+// ConvertDelphiEstimate converts all cases of the estimate, since
+// effort and standard deviation are linear in the cases, they are
+// converted as well
+func (c Calendar) ConvertDelphiEstimate(d DelphiEstimate, from, to string) (DelphiEstimate, error) {
+	var res DelphiEstimate
+	var err error
+
+	if res.BestCase, err = c.ConvertEffort(d.BestCase, from, to); err != nil {
+		return res, err
+	}
+	if res.MostLikely, err = c.ConvertEffort(d.MostLikely, from, to); err != nil {
+		return res, err
+	}
+	if res.WorstCase, err = c.ConvertEffort(d.WorstCase, from, to); err != nil {
+		return res, err
+	}
+	return res, nil
+}
+
+// hours returns the number of hours a single unit stands for
+func (c Calendar) hours(unit string) float64 {
+	switch unit {
+	case Days:
+		return c.HoursPerDay
+	case Weeks:
+		return c.HoursPerDay * c.DaysPerWeek
+	}
+	return 1
+}
@@ -0,0 +1,71 @@
+package estimate
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestConvertEffort(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   float64
+		from    string
+		to      string
+		want    float64
+		wantErr string
+	}{
+		{"hours to days", 12, Hours, Days, 1.5, ""},
+		{"days to hours", 1.5, Days, Hours, 12, ""},
+		{"hours to weeks", 20, Hours, Weeks, 0.5, ""},
+		{"weeks to days", 2, Weeks, Days, 10, ""},
+		{"days to weeks", 2.5, Days, Weeks, 0.5, ""},
+		{"same unit", 5, Points, Points, 5, ""},
+		{"points to hours", 5, Points, Hours, 0, "Unable to convert points to hours"},
+		{"days to points", 5, Days, Points, 0, "Unable to convert days to points"},
+		{"unknown unit", 5, "months", Hours, 0, "Unit must be one of hours, days, weeks or points"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := DefaultCalendar.ConvertEffort(tt.value, tt.from, tt.to)
+			if tt.wantErr != "" {
+				assert.Equal(t, tt.wantErr, err.Error())
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, res)
+		})
+	}
+}
+
+func TestConvertEffortUsesCalendar(t *testing.T) {
+	res, err := Calendar{HoursPerDay: 6, DaysPerWeek: 4}.ConvertEffort(1, Weeks, Hours)
+	assert.NoError(t, err)
+	assert.Equal(t, 24.0, res)
+}
+
+func TestConvertEffortFailsDueToCalendar(t *testing.T) {
+	_, err := Calendar{HoursPerDay: 0, DaysPerWeek: 5}.ConvertEffort(8, Hours, Days)
+	assert.Equal(t, "Hours per day must be > 0 and <= 24, provided: 0", err.Error())
+
+	_, err = Calendar{HoursPerDay: 8, DaysPerWeek: 8}.ConvertEffort(8, Hours, Days)
+	assert.Equal(t, "Days per week must be > 0 and <= 7, provided: 8", err.Error())
+}
+
+func TestConvertDelphiEstimate(t *testing.T) {
+	d := DelphiEstimate{BestCase: 4, MostLikely: 8, WorstCase: 16}
+
+	res, err := DefaultCalendar.ConvertDelphiEstimate(d, Hours, Days)
+	assert.NoError(t, err)
+	assert.Equal(t, DelphiEstimate{BestCase: 0.5, MostLikely: 1, WorstCase: 2}, res)
+	assert.Equal(t, d.GetEffort()/8, res.GetEffort())
+	assert.Equal(t, d.GetStandardDeviation()/8, res.GetStandardDeviation())
+
+	_, err = DefaultCalendar.ConvertDelphiEstimate(d, Hours, Points)
+	assert.Equal(t, "Unable to convert hours to points", err.Error())
+}
+
+func TestValidUnit(t *testing.T) {
+	assert.True(t, ValidUnit(Days))
+	assert.True(t, ValidUnit(Weeks))
+	assert.False(t, ValidUnit("months"))
+}
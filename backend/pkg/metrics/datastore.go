@@ -0,0 +1,160 @@
+package metrics
+
+import (
+	"github.com/haro87/dokerb/pkg/datastore"
+	"time"
+)
+
+// InstrumentedDataStore wraps a datastore and records the latency
+// and the errors of every operation
+type InstrumentedDataStore struct {
+	ds      datastore.DataStore
+	metrics *Metrics
+}
+
+// NewInstrumentedDataStore wraps the provided datastore so that all
+// operations are recorded by the provided metrics
+func NewInstrumentedDataStore(ds datastore.DataStore, m *Metrics) datastore.DataStore {
+	return &InstrumentedDataStore{
+		ds:      ds,
+		metrics: m,
+	}
+}
+
+// CreateSession implements the Datastore interface
+func (i *InstrumentedDataStore) CreateSession() (string, error) {
+	start := time.Now()
+	token, err := i.ds.CreateSession()
+	i.observe("CreateSession", start, err)
+	return token, err
+}
+
+// JoinSession implements the Datastore interface
+func (i *InstrumentedDataStore) JoinSession(token string, user datastore.User) (string, error) {
+	start := time.Now()
+	id, err := i.ds.JoinSession(token, user)
+	i.observe("JoinSession", start, err)
+	return id, err
+}
+
+// LeaveSession implements the Datastore interface
+func (i *InstrumentedDataStore) LeaveSession(token, id string) error {
+	start := time.Now()
+	err := i.ds.LeaveSession(token, id)
+	i.observe("LeaveSession", start, err)
+	return err
+}
+
+// UpdateUser implements the Datastore interface
+func (i *InstrumentedDataStore) UpdateUser(token string, user datastore.User) error {
+	start := time.Now()
+	err := i.ds.UpdateUser(token, user)
+	i.observe("UpdateUser", start, err)
+	return err
+}
+
+// RemoveSession implements the Datastore interface
+func (i *InstrumentedDataStore) RemoveSession(token string) error {
+	start := time.Now()
+	err := i.ds.RemoveSession(token)
+	i.observe("RemoveSession", start, err)
+	return err
+}
+
+// GetSessions implements the Datastore interface
+func (i *InstrumentedDataStore) GetSessions() ([]string, error) {
+	start := time.Now()
+	tokens, err := i.ds.GetSessions()
+	i.observe("GetSessions", start, err)
+	return tokens, err
+}
+
+// AddTask implements the Datastore interface
+func (i *InstrumentedDataStore) AddTask(token, id, summary string) error {
+	start := time.Now()
+	err := i.ds.AddTask(token, id, summary)
+	i.observe("AddTask", start, err)
+	return err
+}
+
+// RemoveTask implements the Datastore interface
+func (i *InstrumentedDataStore) RemoveTask(token, id string) error {
+	start := time.Now()
+	err := i.ds.RemoveTask(token, id)
+	i.observe("RemoveTask", start, err)
+	return err
+}
+
+// AddEstimateToTask implements the Datastore interface
+func (i *InstrumentedDataStore) AddEstimateToTask(token, id string, effort, standardDeviation float64) error {
+	start := time.Now()
+	err := i.ds.AddEstimateToTask(token, id, effort, standardDeviation)
+	i.observe("AddEstimateToTask", start, err)
+	return err
+}
+
+// RemoveEstimateFromTask implements the Datastore interface
+func (i *InstrumentedDataStore) RemoveEstimateFromTask(token, id string) error {
+	start := time.Now()
+	err := i.ds.RemoveEstimateFromTask(token, id)
+	i.observe("RemoveEstimateFromTask", start, err)
+	return err
+}
+
+// GetUsers implements the Datastore interface
+func (i *InstrumentedDataStore) GetUsers(token string) ([]datastore.User, error) {
+	start := time.Now()
+	users, err := i.ds.GetUsers(token)
+	i.observe("GetUsers", start, err)
+	return users, err
+}
+
+// GetTasks implements the Datastore interface
+func (i *InstrumentedDataStore) GetTasks(token string) ([]datastore.Task, error) {
+	start := time.Now()
+	tasks, err := i.ds.GetTasks(token)
+	i.observe("GetTasks", start, err)
+	return tasks, err
+}
+
+// AddEstimate implements the Datastore interface
+func (i *InstrumentedDataStore) AddEstimate(token string, estimate datastore.Estimate) error {
+	start := time.Now()
+	err := i.ds.AddEstimate(token, estimate)
+	i.observe("AddEstimate", start, err)
+	if err == nil {
+		i.metrics.estimatesSubmitted.Inc()
+	}
+	return err
+}
+
+// RemoveEstimate implements the Datastore interface
+func (i *InstrumentedDataStore) RemoveEstimate(token string, estimate datastore.Estimate) error {
+	start := time.Now()
+	err := i.ds.RemoveEstimate(token, estimate)
+	i.observe("RemoveEstimate", start, err)
+	return err
+}
+
+// GetEstimates implements the Datastore interface
+func (i *InstrumentedDataStore) GetEstimates(token string) ([]datastore.Estimate, error) {
+	start := time.Now()
+	ests, err := i.ds.GetEstimates(token)
+	i.observe("GetEstimates", start, err)
+	return ests, err
+}
+
+// Ping implements the Datastore interface
+func (i *InstrumentedDataStore) Ping() error {
+	start := time.Now()
+	err := i.ds.Ping()
+	i.observe("Ping", start, err)
+	return err
+}
+
+func (i *InstrumentedDataStore) observe(operation string, start time.Time, err error) {
+	i.metrics.operationDuration.WithLabelValues(operation).Observe(time.Since(start).Seconds())
+	if err != nil {
+		i.metrics.operationErrors.WithLabelValues(operation).Inc()
+	}
+}
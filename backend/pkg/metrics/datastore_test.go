@@ -0,0 +1,126 @@
+package metrics
+
+import (
+	"fmt"
+	"github.com/haro87/dokerb/pkg/datastore"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+var m *datastore.MockDatastore
+var mt *Metrics
+var ids datastore.DataStore
+
+func setupTestCaseForMock(t *testing.T) func(t *testing.T) {
+	m = new(datastore.MockDatastore)
+	mt = NewMetrics(m)
+	ids = NewInstrumentedDataStore(m, mt)
+	return func(t *testing.T) {
+		m = nil
+		mt = nil
+		ids = nil
+	}
+}
+
+func TestInstrumentedCreateSession(t *testing.T) {
+	setupAndTearDown := setupTestCaseForMock(t)
+	defer setupAndTearDown(t)
+
+	m.On("CreateSession").Return("12345", nil)
+
+	token, err := ids.CreateSession()
+
+	assert.NoError(t, err)
+	assert.Equal(t, "12345", token)
+	assert.Equal(t, 1, testutil.CollectAndCount(mt.operationDuration))
+	assert.Equal(t, 0.0, testutil.ToFloat64(mt.operationErrors.WithLabelValues("CreateSession")))
+}
+
+func TestInstrumentedJoinAndLeaveSession(t *testing.T) {
+	setupAndTearDown := setupTestCaseForMock(t)
+	defer setupAndTearDown(t)
+
+	m.On("JoinSession", "12345", datastore.User{Name: "Tigger"}).Return("tigger", nil)
+	m.On("LeaveSession", "12345", "tigger").Return(fmt.Errorf("Some error"))
+	m.On("UpdateUser", "12345", datastore.User{ID: "tigger", Name: "Tigger"}).Return(nil)
+
+	id, err := ids.JoinSession("12345", datastore.User{Name: "Tigger"})
+	assert.NoError(t, err)
+	assert.Equal(t, "tigger", id)
+	assert.NoError(t, ids.UpdateUser("12345", datastore.User{ID: "tigger", Name: "Tigger"}))
+	assert.Equal(t, "Some error", ids.LeaveSession("12345", "tigger").Error())
+	assert.Equal(t, 0.0, testutil.ToFloat64(mt.operationErrors.WithLabelValues("JoinSession")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(mt.operationErrors.WithLabelValues("LeaveSession")))
+}
+
+func TestInstrumentedSessions(t *testing.T) {
+	setupAndTearDown := setupTestCaseForMock(t)
+	defer setupAndTearDown(t)
+
+	m.On("RemoveSession", "12345").Return(nil)
+	m.On("GetSessions").Return([]string{"12345"}, nil)
+
+	assert.NoError(t, ids.RemoveSession("12345"))
+	sessions, err := ids.GetSessions()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"12345"}, sessions)
+	assert.Equal(t, 2, testutil.CollectAndCount(mt.operationDuration))
+}
+
+func TestInstrumentedTasks(t *testing.T) {
+	setupAndTearDown := setupTestCaseForMock(t)
+	defer setupAndTearDown(t)
+
+	m.On("AddTask", "12345", "TEST01", "").Return(nil)
+	m.On("RemoveTask", "12345", "TEST01").Return(nil)
+	m.On("AddEstimateToTask", "12345", "TEST01", 1.0, 0.5).Return(nil)
+	m.On("RemoveEstimateFromTask", "12345", "TEST01").Return(fmt.Errorf("Some error"))
+	m.On("GetTasks", "12345").Return([]datastore.Task{{ID: "TEST01"}}, nil)
+
+	assert.NoError(t, ids.AddTask("12345", "TEST01", ""))
+	assert.NoError(t, ids.RemoveTask("12345", "TEST01"))
+	assert.NoError(t, ids.AddEstimateToTask("12345", "TEST01", 1.0, 0.5))
+	assert.Error(t, ids.RemoveEstimateFromTask("12345", "TEST01"))
+	tasks, err := ids.GetTasks("12345")
+	assert.NoError(t, err)
+	assert.Len(t, tasks, 1)
+	assert.Equal(t, 5, testutil.CollectAndCount(mt.operationDuration))
+	assert.Equal(t, 1.0, testutil.ToFloat64(mt.operationErrors.WithLabelValues("RemoveEstimateFromTask")))
+}
+
+func TestInstrumentedEstimates(t *testing.T) {
+	setupAndTearDown := setupTestCaseForMock(t)
+	defer setupAndTearDown(t)
+
+	good := datastore.Estimate{TaskID: "TEST01", UserID: "tigger"}
+	bad := datastore.Estimate{TaskID: "TEST01", UserID: "rabbit"}
+
+	m.On("AddEstimate", "12345", good).Return(nil)
+	m.On("AddEstimate", "12345", bad).Return(fmt.Errorf("Some error"))
+	m.On("RemoveEstimate", "12345", good).Return(nil)
+	m.On("GetEstimates", "12345").Return([]datastore.Estimate{good}, nil)
+	m.On("GetUsers", "12345").Return([]datastore.User{{ID: "tigger", Name: "Tigger"}}, nil)
+
+	assert.NoError(t, ids.AddEstimate("12345", good))
+	assert.Error(t, ids.AddEstimate("12345", bad))
+	assert.NoError(t, ids.RemoveEstimate("12345", good))
+	ests, err := ids.GetEstimates("12345")
+	assert.NoError(t, err)
+	assert.Len(t, ests, 1)
+	users, err := ids.GetUsers("12345")
+	assert.NoError(t, err)
+	assert.Len(t, users, 1)
+	assert.Equal(t, 1.0, testutil.ToFloat64(mt.estimatesSubmitted))
+	assert.Equal(t, 1.0, testutil.ToFloat64(mt.operationErrors.WithLabelValues("AddEstimate")))
+}
+
+func TestInstrumentedPing(t *testing.T) {
+	setupAndTearDown := setupTestCaseForMock(t)
+	defer setupAndTearDown(t)
+
+	m.On("Ping").Return(fmt.Errorf("Some error"))
+
+	assert.Error(t, ids.Ping())
+	assert.Equal(t, 1.0, testutil.ToFloat64(mt.operationErrors.WithLabelValues("Ping")))
+}
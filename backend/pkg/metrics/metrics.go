@@ -0,0 +1,79 @@
+package metrics
+
+import (
+	"github.com/haro87/dokerb/pkg/datastore"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"net/http"
+)
+
+const namespace = "dokerb"
+
+// Metrics holds all Prometheus collectors of the Doker backend
+// together with the registry they are registered with
+type Metrics struct {
+	registry           *prometheus.Registry
+	requests           *prometheus.CounterVec
+	requestDuration    *prometheus.HistogramVec
+	operationDuration  *prometheus.HistogramVec
+	operationErrors    *prometheus.CounterVec
+	estimatesSubmitted prometheus.Counter
+}
+
+// NewMetrics creates a new set of collectors where the domain
+// gauges are gathered from the provided datastore on each scrape
+func NewMetrics(ds datastore.DataStore) *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "requests_total",
+			Help:      "Number of handled HTTP requests per route and status.",
+		}, []string{"method", "route", "status"}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "Latency of handled HTTP requests per route and status.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		operationDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "datastore",
+			Name:      "operation_duration_seconds",
+			Help:      "Latency of datastore operations per method.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"operation"}),
+		operationErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "datastore",
+			Name:      "errors_total",
+			Help:      "Number of failed datastore operations per method.",
+		}, []string{"operation"}),
+		estimatesSubmitted: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "estimates_submitted_total",
+			Help:      "Number of user estimates submitted successfully.",
+		}),
+	}
+
+	m.registry.MustRegister(
+		prometheus.NewGoCollector(),
+		prometheus.NewProcessCollector(prometheus.ProcessCollectorOpts{}),
+		m.requests,
+		m.requestDuration,
+		m.operationDuration,
+		m.operationErrors,
+		m.estimatesSubmitted,
+		newSessionCollector(ds),
+	)
+
+	return m
+}
+
+// Handler returns the HTTP handler serving the metrics
+// in the Prometheus exposition format
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
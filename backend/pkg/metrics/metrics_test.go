@@ -0,0 +1,29 @@
+package metrics
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHandlerExposesMetrics(t *testing.T) {
+	setupAndTearDown := setupTestCaseForMock(t)
+	defer setupAndTearDown(t)
+
+	m.On("GetSessions").Return([]string{}, nil)
+	m.On("CreateSession").Return("12345", nil)
+
+	_, err := ids.CreateSession()
+	assert.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	mt.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+
+	body, _ := ioutil.ReadAll(rec.Body)
+	assert.Equal(t, 200, rec.Code)
+	assert.Contains(t, string(body), "dokerb_sessions_active 0")
+	assert.Contains(t, string(body), `dokerb_datastore_operation_duration_seconds_count{operation="CreateSession"} 1`)
+	assert.Contains(t, string(body), "dokerb_estimates_submitted_total 0")
+	assert.Contains(t, string(body), "go_goroutines")
+}
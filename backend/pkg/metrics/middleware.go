@@ -0,0 +1,31 @@
+package metrics
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"strconv"
+	"time"
+)
+
+// Middleware returns a Fiber handler which counts and times
+// every request by method, matched route and response status
+func (m *Metrics) Middleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+
+		err := c.Next()
+
+		status := c.Response().StatusCode()
+		if err != nil {
+			status = fiber.StatusInternalServerError
+			if e, ok := err.(*fiber.Error); ok {
+				status = e.Code
+			}
+		}
+
+		labels := []string{c.Method(), c.Route().Path, strconv.Itoa(status)}
+		m.requests.WithLabelValues(labels...).Inc()
+		m.requestDuration.WithLabelValues(labels...).Observe(time.Since(start).Seconds())
+
+		return err
+	}
+}
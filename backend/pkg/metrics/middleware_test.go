@@ -0,0 +1,33 @@
+package metrics
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+func TestMiddlewareRecordsRouteAndStatus(t *testing.T) {
+	setupAndTearDown := setupTestCaseForMock(t)
+	defer setupAndTearDown(t)
+
+	app := fiber.New()
+	app.Use(mt.Middleware())
+	app.Get("/api/sessions/:token/users", func(c *fiber.Ctx) error {
+		return c.Status(200).SendString("ok")
+	})
+	app.Get("/api/fail", func(c *fiber.Ctx) error {
+		return fiber.NewError(fiber.StatusTeapot, "teapot")
+	})
+
+	for _, route := range []string{"/api/sessions/12345/users", "/api/sessions/67890/users", "/api/fail"} {
+		req, _ := http.NewRequest("GET", route, nil)
+		_, err := app.Test(req, -1)
+		assert.NoError(t, err)
+	}
+
+	assert.Equal(t, 2.0, testutil.ToFloat64(mt.requests.WithLabelValues("GET", "/api/sessions/:token/users", "200")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(mt.requests.WithLabelValues("GET", "/api/fail", "418")))
+	assert.Equal(t, 2, testutil.CollectAndCount(mt.requestDuration))
+}
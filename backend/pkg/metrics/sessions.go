@@ -0,0 +1,88 @@
+package metrics
+
+import (
+	"github.com/haro87/dokerb/pkg/datastore"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// sessionUserBuckets are the upper bounds used for the
+// users per session histogram
+var sessionUserBuckets = []float64{1, 2, 3, 5, 8, 13, 21}
+
+// sessionCollector gathers the session related gauges
+// directly from the datastore whenever metrics are scraped
+type sessionCollector struct {
+	ds       datastore.DataStore
+	sessions *prometheus.Desc
+	users    *prometheus.Desc
+}
+
+func newSessionCollector(ds datastore.DataStore) *sessionCollector {
+	return &sessionCollector{
+		ds: ds,
+		sessions: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "sessions_active"),
+			"Number of currently existing sessions.",
+			nil, nil,
+		),
+		users: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "session_users"),
+			"Distribution of the number of users per session.",
+			nil, nil,
+		),
+	}
+}
+
+// Describe implements the prometheus.Collector interface
+func (s *sessionCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- s.sessions
+	ch <- s.users
+}
+
+// Collect implements the prometheus.Collector interface
+func (s *sessionCollector) Collect(ch chan<- prometheus.Metric) {
+	counts, err := s.countUsers()
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(s.sessions, err)
+		return
+	}
+
+	ch <- prometheus.MustNewConstMetric(s.sessions, prometheus.GaugeValue, float64(len(counts)))
+
+	var sum float64
+	buckets := make(map[float64]uint64, len(sessionUserBuckets))
+	for _, c := range counts {
+		count := float64(c)
+		sum += count
+		for _, b := range sessionUserBuckets {
+			if count <= b {
+				buckets[b]++
+			}
+		}
+	}
+
+	ch <- prometheus.MustNewConstHistogram(s.users, uint64(len(counts)), sum, buckets)
+}
+
+// countUsers returns the number of users per session, with a single
+// query if the datastore supports it and per session otherwise
+func (s *sessionCollector) countUsers() (map[string]int, error) {
+	if uc, ok := s.ds.(datastore.UserCounter); ok {
+		return uc.CountUsers()
+	}
+
+	tokens, err := s.ds.GetSessions()
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int, len(tokens))
+	for _, token := range tokens {
+		users, err := s.ds.GetUsers(token)
+		if err != nil {
+			return nil, err
+		}
+		counts[token] = len(users)
+	}
+	return counts, nil
+}
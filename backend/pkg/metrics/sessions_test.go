@@ -0,0 +1,91 @@
+package metrics
+
+import (
+	"fmt"
+	"github.com/haro87/dokerb/pkg/datastore"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestSessionCollectorSuccess(t *testing.T) {
+	setupAndTearDown := setupTestCaseForMock(t)
+	defer setupAndTearDown(t)
+
+	m.On("GetSessions").Return([]string{"12345", "67890"}, nil)
+	m.On("GetUsers", "12345").Return([]datastore.User{{ID: "tigger", Name: "Tigger"}, {ID: "rabbit", Name: "Rabbit"}}, nil)
+	m.On("GetUsers", "67890").Return([]datastore.User{{ID: "piglet", Name: "Piglet"}}, nil)
+
+	expected := `
+# HELP dokerb_sessions_active Number of currently existing sessions.
+# TYPE dokerb_sessions_active gauge
+dokerb_sessions_active 2
+# HELP dokerb_session_users Distribution of the number of users per session.
+# TYPE dokerb_session_users histogram
+dokerb_session_users_bucket{le="1"} 1
+dokerb_session_users_bucket{le="2"} 2
+dokerb_session_users_bucket{le="3"} 2
+dokerb_session_users_bucket{le="5"} 2
+dokerb_session_users_bucket{le="8"} 2
+dokerb_session_users_bucket{le="13"} 2
+dokerb_session_users_bucket{le="21"} 2
+dokerb_session_users_bucket{le="+Inf"} 2
+dokerb_session_users_sum 3
+dokerb_session_users_count 2
+`
+
+	err := testutil.CollectAndCompare(newSessionCollector(m), strings.NewReader(expected))
+	assert.NoError(t, err)
+}
+
+// countingDatastore adds the UserCounter interface to the mock
+type countingDatastore struct {
+	*datastore.MockDatastore
+}
+
+func (c countingDatastore) CountUsers() (map[string]int, error) {
+	arguments := c.Called()
+	return arguments.Get(0).(map[string]int), arguments.Error(1)
+}
+
+func TestSessionCollectorCountsUsersAtOnce(t *testing.T) {
+	setupAndTearDown := setupTestCaseForMock(t)
+	defer setupAndTearDown(t)
+
+	m.On("CountUsers").Return(map[string]int{"12345": 2, "67890": 1}, nil)
+
+	expected := `
+# HELP dokerb_sessions_active Number of currently existing sessions.
+# TYPE dokerb_sessions_active gauge
+dokerb_sessions_active 2
+# HELP dokerb_session_users Distribution of the number of users per session.
+# TYPE dokerb_session_users histogram
+dokerb_session_users_bucket{le="1"} 1
+dokerb_session_users_bucket{le="2"} 2
+dokerb_session_users_bucket{le="3"} 2
+dokerb_session_users_bucket{le="5"} 2
+dokerb_session_users_bucket{le="8"} 2
+dokerb_session_users_bucket{le="13"} 2
+dokerb_session_users_bucket{le="21"} 2
+dokerb_session_users_bucket{le="+Inf"} 2
+dokerb_session_users_sum 3
+dokerb_session_users_count 2
+`
+
+	err := testutil.CollectAndCompare(newSessionCollector(countingDatastore{m}), strings.NewReader(expected))
+	assert.NoError(t, err)
+	m.AssertNotCalled(t, "GetSessions")
+	m.AssertNotCalled(t, "GetUsers", "12345")
+}
+
+func TestSessionCollectorFailsDueToDatastoreError(t *testing.T) {
+	setupAndTearDown := setupTestCaseForMock(t)
+	defer setupAndTearDown(t)
+
+	m.On("GetSessions").Return([]string{}, fmt.Errorf("Unable to get sessions"))
+
+	err := testutil.CollectAndCompare(newSessionCollector(m), strings.NewReader(""))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "Unable to get sessions")
+}
@@ -0,0 +1,135 @@
+package presence
+
+import (
+	"sync"
+	"time"
+)
+
+// States of a participant
+const (
+	StateOnline  = "online"
+	StateIdle    = "idle"
+	StateOffline = "offline"
+)
+
+// Participant defines the presence of the user with the ID inside
+// a session, LastSeen is zero if the user never sent a heartbeat
+type Participant struct {
+	ID       string
+	State    string
+	LastSeen time.Time
+}
+
+// Tracker keeps the time of the last heartbeat of every user in
+// memory. Users are idle once no heartbeat was received for the
+// idle timeout and offline after the offline timeout.
+type Tracker struct {
+	idleTimeout    time.Duration
+	offlineTimeout time.Duration
+	now            func() time.Time
+
+	mu       sync.Mutex
+	sessions map[string]map[string]time.Time
+}
+
+// Option configures optional behaviour of the Tracker
+type Option func(t *Tracker)
+
+// WithTimeouts sets after which time without heartbeat users
+// are considered idle and offline
+func WithTimeouts(idle, offline time.Duration) Option {
+	return func(t *Tracker) {
+		t.idleTimeout = idle
+		t.offlineTimeout = offline
+	}
+}
+
+// WithClock sets the function returning the current time
+func WithClock(now func() time.Time) Option {
+	return func(t *Tracker) {
+		t.now = now
+	}
+}
+
+// NewTracker returns a new Tracker without any heartbeats
+func NewTracker(opts ...Option) *Tracker {
+	t := &Tracker{
+		idleTimeout:    30 * time.Second,
+		offlineTimeout: 2 * time.Minute,
+		now:            time.Now,
+		sessions:       map[string]map[string]time.Time{},
+	}
+
+	for _, opt := range opts {
+		opt(t)
+	}
+
+	return t
+}
+
+// Heartbeat records that the user is present in the session, users
+// offline in any session are forgotten on the way
+func (t *Tracker) Heartbeat(token, user string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.now()
+	t.prune(now)
+
+	if t.sessions[token] == nil {
+		t.sessions[token] = map[string]time.Time{}
+	}
+	t.sessions[token][user] = now
+}
+
+// Participants returns the presence of the provided users of the
+// session in the same order
+func (t *Tracker) Participants(token string, users []string) []Participant {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.now()
+	res := make([]Participant, 0, len(users))
+	for _, u := range users {
+		seen := t.sessions[token][u]
+		res = append(res, Participant{ID: u, State: t.state(now, seen), LastSeen: seen})
+	}
+	return res
+}
+
+// Present returns the users of the session, which are online or idle
+func (t *Tracker) Present(token string, users []string) []string {
+	res := []string{}
+	for _, p := range t.Participants(token, users) {
+		if p.State != StateOffline {
+			res = append(res, p.ID)
+		}
+	}
+	return res
+}
+
+func (t *Tracker) state(now, seen time.Time) string {
+	switch {
+	case seen.IsZero() || now.Sub(seen) >= t.offlineTimeout:
+		return StateOffline
+	case now.Sub(seen) >= t.idleTimeout:
+		return StateIdle
+	default:
+		return StateOnline
+	}
+}
+
+// prune removes all users, which are offline, so that memory isn't
+// kept for sessions nobody takes part in anymore
+func (t *Tracker) prune(now time.Time) {
+	for token, users := range t.sessions {
+		for u, seen := range users {
+			if now.Sub(seen) >= t.offlineTimeout {
+				delete(users, u)
+			}
+		}
+		if len(users) == 0 {
+			delete(t.sessions, token)
+		}
+	}
+}
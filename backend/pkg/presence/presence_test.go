@@ -0,0 +1,44 @@
+package presence
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestParticipants(t *testing.T) {
+	now := time.Date(2021, 1, 14, 15, 4, 5, 0, time.UTC)
+	tr := NewTracker(WithTimeouts(30*time.Second, 2*time.Minute), WithClock(func() time.Time { return now }))
+
+	tr.Heartbeat("12345", "tigger")
+	tr.Heartbeat("12345", "pooh")
+	tr.Heartbeat("67890", "rabbit")
+
+	now = now.Add(time.Minute)
+	tr.Heartbeat("12345", "pooh")
+
+	assert.Equal(t, []Participant{
+		{ID: "pooh", State: StateOnline, LastSeen: now},
+		{ID: "tigger", State: StateIdle, LastSeen: now.Add(-time.Minute)},
+		{ID: "rabbit", State: StateOffline},
+	}, tr.Participants("12345", []string{"pooh", "tigger", "rabbit"}))
+
+	now = now.Add(90 * time.Second)
+	assert.Equal(t, []string{"pooh"}, tr.Present("12345", []string{"pooh", "tigger", "rabbit"}))
+
+	now = now.Add(time.Minute)
+	assert.Equal(t, []string{}, tr.Present("12345", []string{"pooh", "tigger"}))
+}
+
+func TestHeartbeatPrunesOfflineUsers(t *testing.T) {
+	now := time.Date(2021, 1, 14, 15, 4, 5, 0, time.UTC)
+	tr := NewTracker(WithClock(func() time.Time { return now }))
+
+	tr.Heartbeat("12345", "tigger")
+	tr.Heartbeat("67890", "rabbit")
+
+	now = now.Add(5 * time.Minute)
+	tr.Heartbeat("12345", "pooh")
+
+	assert.Equal(t, map[string]map[string]time.Time{"12345": {"pooh": now}}, tr.sessions)
+}
@@ -0,0 +1,49 @@
+package tracker
+
+import (
+	"github.com/haro87/dokerb/pkg/datastore"
+)
+
+// TaskSyncer defines where finalized tasks are synced to
+type TaskSyncer interface {
+	Sync(token string, task datastore.Task)
+}
+
+// SyncingDataStore wraps a datastore and syncs every task, once
+// its effort was stored
+type SyncingDataStore struct {
+	datastore.DataStore
+	syncer TaskSyncer
+}
+
+// NewSyncingDataStore wraps the provided datastore so that
+// finalized tasks are synced with the provided syncer
+func NewSyncingDataStore(ds datastore.DataStore, s TaskSyncer) datastore.DataStore {
+	return &SyncingDataStore{
+		DataStore: ds,
+		syncer:    s,
+	}
+}
+
+// AddEstimateToTask implements the Datastore interface
+func (s *SyncingDataStore) AddEstimateToTask(token, id string, effort, standardDeviation float64) error {
+	if err := s.DataStore.AddEstimateToTask(token, id, effort, standardDeviation); err != nil {
+		return err
+	}
+
+	task := datastore.Task{ID: id}
+	// The summary is optional for the sink, so failing to get it
+	// does not prevent the sync
+	if tasks, err := s.DataStore.GetTasks(token); err == nil {
+		for _, t := range tasks {
+			if t.ID == id {
+				task = t
+			}
+		}
+	}
+	task.Effort = effort
+	task.StandardDeviation = standardDeviation
+
+	s.syncer.Sync(token, task)
+	return nil
+}
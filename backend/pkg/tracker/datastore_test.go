@@ -0,0 +1,64 @@
+package tracker
+
+import (
+	"fmt"
+	"github.com/haro87/dokerb/pkg/datastore"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type synced struct {
+	token string
+	task  datastore.Task
+}
+
+type recordingSyncer struct {
+	tasks []synced
+}
+
+func (r *recordingSyncer) Sync(token string, task datastore.Task) {
+	r.tasks = append(r.tasks, synced{token, task})
+}
+
+func TestSyncingDataStoreSyncsFinalizedTasks(t *testing.T) {
+	m := new(datastore.MockDatastore)
+	r := &recordingSyncer{}
+	ds := NewSyncingDataStore(m, r)
+
+	m.On("AddEstimateToTask", "12345", "TEST01", 2.0, 0.3).Return(nil)
+	m.On("GetTasks", "12345").Return([]datastore.Task{
+		{ID: "TEST00", Summary: "Other"},
+		{ID: "TEST01", Summary: "Login page", Effort: 2.0, StandardDeviation: 0.3},
+	}, nil)
+
+	assert.NoError(t, ds.AddEstimateToTask("12345", "TEST01", 2.0, 0.3))
+	assert.Equal(t, []synced{
+		{"12345", datastore.Task{ID: "TEST01", Summary: "Login page", Effort: 2.0, StandardDeviation: 0.3}},
+	}, r.tasks)
+}
+
+func TestSyncingDataStoreSyncsWithoutSummary(t *testing.T) {
+	m := new(datastore.MockDatastore)
+	r := &recordingSyncer{}
+	ds := NewSyncingDataStore(m, r)
+
+	m.On("AddEstimateToTask", "12345", "TEST01", 2.0, 0.3).Return(nil)
+	m.On("GetTasks", "12345").Return([]datastore.Task{}, fmt.Errorf("Ooops, something went wrong"))
+
+	assert.NoError(t, ds.AddEstimateToTask("12345", "TEST01", 2.0, 0.3))
+	assert.Equal(t, []synced{
+		{"12345", datastore.Task{ID: "TEST01", Effort: 2.0, StandardDeviation: 0.3}},
+	}, r.tasks)
+}
+
+func TestSyncingDataStoreSkipsFailedOperations(t *testing.T) {
+	m := new(datastore.MockDatastore)
+	r := &recordingSyncer{}
+	ds := NewSyncingDataStore(m, r)
+
+	m.On("AddEstimateToTask", "12345", "TEST01", 2.0, 0.3).Return(fmt.Errorf("Task with ID: TEST01 does not exist"))
+
+	assert.Error(t, ds.AddEstimateToTask("12345", "TEST01", 2.0, 0.3))
+	assert.Empty(t, r.tasks)
+	m.AssertNotCalled(t, "GetTasks", "12345")
+}
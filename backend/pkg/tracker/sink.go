@@ -0,0 +1,194 @@
+package tracker
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/haro87/dokerb/pkg/datastore"
+	"net/http"
+	"net/url"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// Sink defines where finalized tasks are pushed to
+type Sink interface {
+	// Describe returns a description of the request Push would send,
+	// which is recorded as part of the sync status
+	Describe(task datastore.Task) (string, error)
+	Push(task datastore.Task) error
+}
+
+// RESTConfig configures a RESTSink, URL and Body are text/template
+// templates executed with the datastore.Task, which additionally
+// provide the functions json and pathescape. Text printed by the
+// URL template is path escaped, text printed by the Body template
+// is JSON escaped and the rendered Body must be JSON.
+type RESTConfig struct {
+	Method     string
+	URL        string
+	Body       string
+	AuthHeader string
+	AuthValue  string
+	Timeout    time.Duration
+}
+
+// RESTSink pushes finalized tasks to a generic REST API, typically
+// updating the issue matching the task ID
+type RESTSink struct {
+	config RESTConfig
+	url    *template.Template
+	body   *template.Template
+	client *http.Client
+}
+
+// jsonText is a text field of the body template, which is JSON
+// escaped when printed, so that it can't break out of a string
+type jsonText string
+
+// String implements the fmt.Stringer interface
+func (t jsonText) String() string {
+	b, _ := json.Marshal(string(t))
+	return string(b[1 : len(b)-1])
+}
+
+// MarshalJSON implements the json.Marshaler interface, so that the
+// json function quotes the text only once
+func (t jsonText) MarshalJSON() ([]byte, error) {
+	return json.Marshal(string(t))
+}
+
+// pathText is a text field of the URL template, which is path
+// escaped when printed, so that it can't change the called URL
+type pathText string
+
+// String implements the fmt.Stringer interface
+func (t pathText) String() string {
+	return url.PathEscape(string(t))
+}
+
+// urlData is the datastore.Task as seen by the URL template
+type urlData struct {
+	ID                pathText
+	Summary           pathText
+	Effort            float64
+	StandardDeviation float64
+}
+
+// bodyData is the datastore.Task as seen by the body template
+type bodyData struct {
+	ID                jsonText
+	Summary           jsonText
+	Effort            float64
+	StandardDeviation float64
+}
+
+var funcs = template.FuncMap{
+	"json": func(v interface{}) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+	"pathescape": func(v interface{}) string {
+		// Path text is already escaped when printed
+		if t, ok := v.(pathText); ok {
+			return t.String()
+		}
+		return url.PathEscape(fmt.Sprint(v))
+	},
+}
+
+// NewRESTSink returns a new RESTSink, failing if the URL or body
+// template cannot be parsed
+func NewRESTSink(config RESTConfig) (*RESTSink, error) {
+	u, err := template.New("url").Funcs(funcs).Parse(config.URL)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to parse URL template: %s", err)
+	}
+
+	b, err := template.New("body").Funcs(funcs).Parse(config.Body)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to parse body template: %s", err)
+	}
+
+	if config.Method == "" {
+		config.Method = "PUT"
+	}
+	if config.Timeout == 0 {
+		config.Timeout = 10 * time.Second
+	}
+
+	return &RESTSink{
+		config: config,
+		url:    u,
+		body:   b,
+		client: &http.Client{Timeout: config.Timeout},
+	}, nil
+}
+
+// Describe implements the Sink interface, the auth header value
+// is never part of the description
+func (r *RESTSink) Describe(task datastore.Task) (string, error) {
+	u, body, err := r.render(task)
+	if err != nil {
+		return "", err
+	}
+	if body == "" {
+		return r.config.Method + " " + u, nil
+	}
+	return r.config.Method + " " + u + " " + body, nil
+}
+
+// Push implements the Sink interface
+func (r *RESTSink) Push(task datastore.Task) error {
+	u, body, err := r.render(task)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(r.config.Method, u, strings.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if r.config.AuthHeader != "" {
+		req.Header.Set(r.config.AuthHeader, r.config.AuthValue)
+	}
+
+	res, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("Unexpected status code: %d", res.StatusCode)
+	}
+	return nil
+}
+
+func (r *RESTSink) render(task datastore.Task) (string, string, error) {
+	var u, body bytes.Buffer
+	path := urlData{
+		ID:                pathText(task.ID),
+		Summary:           pathText(task.Summary),
+		Effort:            task.Effort,
+		StandardDeviation: task.StandardDeviation,
+	}
+	if err := r.url.Execute(&u, path); err != nil {
+		return "", "", fmt.Errorf("Unable to render URL: %s", err)
+	}
+	data := bodyData{
+		ID:                jsonText(task.ID),
+		Summary:           jsonText(task.Summary),
+		Effort:            task.Effort,
+		StandardDeviation: task.StandardDeviation,
+	}
+	if err := r.body.Execute(&body, data); err != nil {
+		return "", "", fmt.Errorf("Unable to render body: %s", err)
+	}
+	if body.Len() > 0 && !json.Valid(body.Bytes()) {
+		return "", "", fmt.Errorf("Unable to render body: not valid JSON: %s", body.String())
+	}
+	return u.String(), body.String(), nil
+}
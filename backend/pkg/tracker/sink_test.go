@@ -0,0 +1,134 @@
+package tracker
+
+import (
+	"github.com/haro87/dokerb/pkg/datastore"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type request struct {
+	method string
+	path   string
+	header http.Header
+	body   string
+}
+
+// setupTestCaseForTracker starts a local stand-in for the issue
+// tracker answering every request with the provided status code
+func setupTestCaseForTracker(t *testing.T, code int) (*httptest.Server, chan request, func(t *testing.T)) {
+	requests := make(chan request, 10)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		requests <- request{method: r.Method, path: r.URL.EscapedPath(), header: r.Header, body: string(body)}
+		w.WriteHeader(code)
+	}))
+	return srv, requests, func(t *testing.T) {
+		srv.Close()
+	}
+}
+
+var task = datastore.Task{ID: "PROJ-1", Summary: `Say "hello"`, Effort: 2.5, StandardDeviation: 0.5}
+
+func TestRESTSinkPush(t *testing.T) {
+	srv, requests, teardown := setupTestCaseForTracker(t, 204)
+	defer teardown(t)
+
+	sink, err := NewRESTSink(RESTConfig{
+		URL:        srv.URL + "/rest/api/2/issue/{{.ID | pathescape}}",
+		Body:       `{"fields":{"summary":{{json .Summary}},"estimate":{{.Effort}},"deviation":{{.StandardDeviation}}}}`,
+		AuthHeader: "Authorization",
+		AuthValue:  "Bearer s3cr3t",
+	})
+	assert.NoError(t, err)
+
+	assert.NoError(t, sink.Push(task))
+
+	r := <-requests
+	assert.Equal(t, "PUT", r.method)
+	assert.Equal(t, "/rest/api/2/issue/PROJ-1", r.path)
+	assert.Equal(t, "Bearer s3cr3t", r.header.Get("Authorization"))
+	assert.Equal(t, "application/json", r.header.Get("Content-Type"))
+	assert.JSONEq(t, `{"fields":{"summary":"Say \"hello\"","estimate":2.5,"deviation":0.5}}`, r.body)
+}
+
+func TestRESTSinkEscapesBody(t *testing.T) {
+	sink, err := NewRESTSink(RESTConfig{
+		URL:  "https://tracker.example.com/issues/{{.ID}}",
+		Body: `{"summary":"{{.Summary}}","quoted":{{json .Summary}},"id":"{{.ID}}"}`,
+	})
+	assert.NoError(t, err)
+
+	injected := datastore.Task{ID: "PROJ-1", Summary: `x","admin":true,"y":"`}
+	_, body, err := sink.render(injected)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"summary":"x\",\"admin\":true,\"y\":\"","quoted":"x\",\"admin\":true,\"y\":\"","id":"PROJ-1"}`, body)
+}
+
+func TestRESTSinkEscapesURL(t *testing.T) {
+	for _, tmpl := range []string{"{{.ID}}", "{{.ID | pathescape}}", "{{pathescape .ID}}"} {
+		sink, err := NewRESTSink(RESTConfig{URL: "https://tracker.example.com/issues/" + tmpl + "?notify=false"})
+		assert.NoError(t, err)
+
+		injected := datastore.Task{ID: "../admin/users?x=1#"}
+		u, _, err := sink.render(injected)
+		assert.NoError(t, err)
+		assert.Equal(t, "https://tracker.example.com/issues/..%2Fadmin%2Fusers%3Fx=1%23?notify=false", u, tmpl)
+	}
+}
+
+func TestRESTSinkRejectsInvalidBody(t *testing.T) {
+	sink, err := NewRESTSink(RESTConfig{
+		URL:  "https://tracker.example.com/issues/{{.ID}}",
+		Body: `{"summary":{{.Summary}}}`,
+	})
+	assert.NoError(t, err)
+
+	_, err = sink.Describe(task)
+	assert.Contains(t, err.Error(), "Unable to render body: not valid JSON")
+}
+
+func TestRESTSinkPushFails(t *testing.T) {
+	srv, _, teardown := setupTestCaseForTracker(t, 404)
+	defer teardown(t)
+
+	sink, err := NewRESTSink(RESTConfig{Method: "POST", URL: srv.URL + "/issues/{{.ID}}"})
+	assert.NoError(t, err)
+
+	err = sink.Push(task)
+	assert.Equal(t, "Unexpected status code: 404", err.Error())
+}
+
+func TestRESTSinkDescribe(t *testing.T) {
+	sink, err := NewRESTSink(RESTConfig{
+		Method:     "PATCH",
+		URL:        "https://tracker.example.com/issues/{{.ID}}",
+		Body:       `{"effort":{{.Effort}}}`,
+		AuthHeader: "Authorization",
+		AuthValue:  "Bearer s3cr3t",
+	})
+	assert.NoError(t, err)
+
+	d, err := sink.Describe(task)
+	assert.NoError(t, err)
+	assert.Equal(t, `PATCH https://tracker.example.com/issues/PROJ-1 {"effort":2.5}`, d)
+	assert.NotContains(t, d, "s3cr3t")
+}
+
+func TestNewRESTSinkFails(t *testing.T) {
+	_, err := NewRESTSink(RESTConfig{URL: "https://tracker.example.com/{{.ID"})
+	assert.Contains(t, err.Error(), "Unable to parse URL template")
+
+	_, err = NewRESTSink(RESTConfig{URL: "https://tracker.example.com", Body: "{{json}"})
+	assert.Contains(t, err.Error(), "Unable to parse body template")
+}
+
+func TestRESTSinkRenderFails(t *testing.T) {
+	sink, err := NewRESTSink(RESTConfig{URL: "https://tracker.example.com/{{.Key}}"})
+	assert.NoError(t, err)
+
+	_, err = sink.Describe(task)
+	assert.Contains(t, err.Error(), "Unable to render URL")
+}
@@ -0,0 +1,146 @@
+package tracker
+
+import (
+	"github.com/haro87/dokerb/pkg/datastore"
+	"go.uber.org/zap"
+	"sync"
+	"time"
+)
+
+// Statuses of a task sync
+const (
+	StatusPending = "pending"
+	StatusSynced  = "synced"
+	StatusFailed  = "failed"
+	StatusDryRun  = "dry-run"
+)
+
+type job struct {
+	token string
+	task  datastore.Task
+}
+
+// Syncer pushes finalized tasks to a sink in the background and
+// records the outcome in the provided store
+type Syncer struct {
+	store  datastore.SyncStore
+	sink   Sink
+	logger *zap.Logger
+	dryRun bool
+	queue  chan job
+	quit   chan struct{}
+	wg     sync.WaitGroup
+}
+
+// Option configures optional behaviour of the Syncer
+type Option func(s *Syncer)
+
+// WithLogger sets the logger used for reporting failed syncs
+func WithLogger(logger *zap.Logger) Option {
+	return func(s *Syncer) {
+		s.logger = logger
+	}
+}
+
+// WithDryRun only records the requests, which would be sent,
+// instead of sending them
+func WithDryRun(dryRun bool) Option {
+	return func(s *Syncer) {
+		s.dryRun = dryRun
+	}
+}
+
+// WithQueueSize sets the number of tasks kept for syncing,
+// tasks synced with a full queue are dropped
+func WithQueueSize(size int) Option {
+	return func(s *Syncer) {
+		s.queue = make(chan job, size)
+	}
+}
+
+// NewSyncer returns a new Syncer pushing tasks to the provided sink
+func NewSyncer(store datastore.SyncStore, sink Sink, opts ...Option) *Syncer {
+	s := &Syncer{
+		store:  store,
+		sink:   sink,
+		logger: zap.NewNop(),
+		queue:  make(chan job, 100),
+		quit:   make(chan struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// Start starts the background sync
+func (s *Syncer) Start() {
+	s.wg.Add(1)
+	go s.work()
+}
+
+// Stop stops the background sync after the current task
+func (s *Syncer) Stop() {
+	close(s.quit)
+	s.wg.Wait()
+}
+
+// Sync marks the task of the session as pending and queues it
+// for pushing to the sink
+func (s *Syncer) Sync(token string, task datastore.Task) {
+	s.record(token, datastore.SyncStatus{TaskID: task.ID, Status: StatusPending})
+
+	select {
+	case s.queue <- job{token: token, task: task}:
+	default:
+		s.logger.Warn("Tracker queue full, dropping task", zap.String("task", task.ID))
+		s.record(token, datastore.SyncStatus{TaskID: task.ID, Status: StatusFailed, Error: "Tracker queue full"})
+	}
+}
+
+func (s *Syncer) work() {
+	defer s.wg.Done()
+	for {
+		select {
+		case <-s.quit:
+			return
+		case j := <-s.queue:
+			s.record(j.token, s.push(j.task))
+		}
+	}
+}
+
+func (s *Syncer) push(task datastore.Task) datastore.SyncStatus {
+	status := datastore.SyncStatus{TaskID: task.ID, Status: StatusFailed}
+
+	request, err := s.sink.Describe(task)
+	status.Request = request
+	switch {
+	case err != nil:
+		status.Error = err.Error()
+	case s.dryRun:
+		status.Status = StatusDryRun
+	default:
+		if err := s.sink.Push(task); err != nil {
+			status.Error = err.Error()
+		} else {
+			status.Status = StatusSynced
+		}
+	}
+
+	if status.Status == StatusFailed {
+		s.logger.Warn("Tracker sync failed",
+			zap.String("task", task.ID),
+			zap.String("error", status.Error))
+	}
+	return status
+}
+
+func (s *Syncer) record(token string, status datastore.SyncStatus) {
+	status.Time = time.Now().UTC().Format(time.RFC3339)
+	if err := s.store.SetSyncStatus(token, status); err != nil {
+		s.logger.Error("Unable to store sync status", zap.Error(err))
+	}
+}
@@ -0,0 +1,120 @@
+package tracker
+
+import (
+	"github.com/haro87/dokerb/pkg/datastore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"testing"
+	"time"
+)
+
+// expectStatuses records all sync statuses stored for the session
+func expectStatuses(m *datastore.MockSyncStore, token string) chan datastore.SyncStatus {
+	statuses := make(chan datastore.SyncStatus, 10)
+	m.On("SetSyncStatus", token, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		statuses <- args.Get(1).(datastore.SyncStatus)
+	})
+	return statuses
+}
+
+func waitForStatus(t *testing.T, statuses chan datastore.SyncStatus) datastore.SyncStatus {
+	select {
+	case s := <-statuses:
+		return s
+	case <-time.After(5 * time.Second):
+		t.Fatal("No sync status recorded")
+	}
+	return datastore.SyncStatus{}
+}
+
+func TestSyncerPushesTasks(t *testing.T) {
+	srv, requests, teardown := setupTestCaseForTracker(t, 200)
+	defer teardown(t)
+
+	m := new(datastore.MockSyncStore)
+	statuses := expectStatuses(m, "12345")
+	sink, err := NewRESTSink(RESTConfig{URL: srv.URL + "/issues/{{.ID}}", Body: `{"effort":{{.Effort}}}`})
+	assert.NoError(t, err)
+
+	s := NewSyncer(m, sink)
+	s.Start()
+	defer s.Stop()
+
+	s.Sync("12345", task)
+
+	pending := waitForStatus(t, statuses)
+	assert.Equal(t, "PROJ-1", pending.TaskID)
+	assert.Equal(t, StatusPending, pending.Status)
+
+	status := waitForStatus(t, statuses)
+	assert.Equal(t, "PROJ-1", status.TaskID)
+	assert.Equal(t, StatusSynced, status.Status)
+	assert.Equal(t, "PUT "+srv.URL+`/issues/PROJ-1 {"effort":2.5}`, status.Request)
+	assert.Empty(t, status.Error)
+	assert.NotEmpty(t, status.Time)
+
+	r := <-requests
+	assert.Equal(t, `{"effort":2.5}`, r.body)
+}
+
+func TestSyncerRecordsFailures(t *testing.T) {
+	srv, _, teardown := setupTestCaseForTracker(t, 500)
+	defer teardown(t)
+
+	core, logs := observer.New(zapcore.WarnLevel)
+	m := new(datastore.MockSyncStore)
+	statuses := expectStatuses(m, "12345")
+	sink, err := NewRESTSink(RESTConfig{URL: srv.URL + "/issues/{{.ID}}"})
+	assert.NoError(t, err)
+
+	s := NewSyncer(m, sink, WithLogger(zap.New(core)))
+	s.Start()
+	defer s.Stop()
+
+	s.Sync("12345", task)
+
+	waitForStatus(t, statuses)
+	status := waitForStatus(t, statuses)
+	assert.Equal(t, StatusFailed, status.Status)
+	assert.Equal(t, "Unexpected status code: 500", status.Error)
+	assert.Equal(t, "Tracker sync failed", logs.All()[0].Message)
+}
+
+func TestSyncerDryRun(t *testing.T) {
+	srv, requests, teardown := setupTestCaseForTracker(t, 200)
+	defer teardown(t)
+
+	m := new(datastore.MockSyncStore)
+	statuses := expectStatuses(m, "12345")
+	sink, err := NewRESTSink(RESTConfig{URL: srv.URL + "/issues/{{.ID}}"})
+	assert.NoError(t, err)
+
+	s := NewSyncer(m, sink, WithDryRun(true))
+	s.Start()
+	defer s.Stop()
+
+	s.Sync("12345", task)
+
+	waitForStatus(t, statuses)
+	status := waitForStatus(t, statuses)
+	assert.Equal(t, StatusDryRun, status.Status)
+	assert.Equal(t, "PUT "+srv.URL+"/issues/PROJ-1", status.Request)
+	assert.Equal(t, 0, len(requests))
+}
+
+func TestSyncDoesNotBlockOnFullQueue(t *testing.T) {
+	core, logs := observer.New(zapcore.WarnLevel)
+	m := new(datastore.MockSyncStore)
+	m.On("SetSyncStatus", "12345", mock.Anything).Return(nil)
+	s := NewSyncer(m, nil, WithQueueSize(1), WithLogger(zap.New(core)))
+
+	s.Sync("12345", task)
+	s.Sync("12345", task)
+
+	assert.Equal(t, 1, len(s.queue))
+	assert.Equal(t, "Tracker queue full, dropping task", logs.All()[0].Message)
+	m.AssertNumberOfCalls(t, "SetSyncStatus", 3)
+}
@@ -0,0 +1,96 @@
+package webhook
+
+import (
+	"github.com/haro87/dokerb/pkg/datastore"
+)
+
+// Publisher defines where session events are published to
+type Publisher interface {
+	Publish(token, eventType string, data interface{})
+}
+
+// TaskFinalized defines the data of the task.finalized event
+type TaskFinalized struct {
+	TaskID            string  `json:"id"`
+	Effort            float64 `json:"effort"`
+	StandardDeviation float64 `json:"standarddeviation"`
+}
+
+// EstimateSubmitted defines the data of the estimate.submitted event,
+// the user is anonymized as in the session
+type EstimateSubmitted struct {
+	TaskID   string `json:"id"`
+	UserID   string `json:"userid"`
+	UserName string `json:"user"`
+}
+
+// NotifyingDataStore wraps a datastore and publishes an event
+// for every successful operation webhooks can subscribe to
+type NotifyingDataStore struct {
+	datastore.DataStore
+	publisher Publisher
+	settings  datastore.SettingsStore
+}
+
+// NewNotifyingDataStore wraps the provided datastore so that session
+// events are published to the provided publisher, users are anonymized
+// according to the provided settings, which may be nil
+func NewNotifyingDataStore(ds datastore.DataStore, p Publisher, settings datastore.SettingsStore) datastore.DataStore {
+	return &NotifyingDataStore{
+		DataStore: ds,
+		publisher: p,
+		settings:  settings,
+	}
+}
+
+// RemoveSession implements the Datastore interface
+func (n *NotifyingDataStore) RemoveSession(token string) error {
+	if err := n.DataStore.RemoveSession(token); err != nil {
+		return err
+	}
+	n.publisher.Publish(token, EventSessionRemoved, nil)
+	return nil
+}
+
+// AddEstimateToTask implements the Datastore interface
+func (n *NotifyingDataStore) AddEstimateToTask(token, id string, effort, standardDeviation float64) error {
+	if err := n.DataStore.AddEstimateToTask(token, id, effort, standardDeviation); err != nil {
+		return err
+	}
+	n.publisher.Publish(token, EventTaskFinalized, TaskFinalized{
+		TaskID:            id,
+		Effort:            effort,
+		StandardDeviation: standardDeviation,
+	})
+	return nil
+}
+
+// AddEstimate implements the Datastore interface
+func (n *NotifyingDataStore) AddEstimate(token string, estimate datastore.Estimate) error {
+	if err := n.DataStore.AddEstimate(token, estimate); err != nil {
+		return err
+	}
+	n.publisher.Publish(token, EventEstimateSubmitted, n.estimateSubmitted(token, estimate))
+	return nil
+}
+
+// estimateSubmitted returns the event data, receivers only see who
+// submitted the estimate if the session isn't anonymous. If the
+// settings are unavailable the user is left out.
+func (n *NotifyingDataStore) estimateSubmitted(token string, estimate datastore.Estimate) EstimateSubmitted {
+	data := EstimateSubmitted{TaskID: estimate.TaskID}
+	if n.settings == nil {
+		data.UserID, data.UserName = estimate.UserID, estimate.UserName
+		return data
+	}
+
+	s, err := n.settings.GetSettings(token)
+	if err != nil {
+		return data
+	}
+	if s.Anonymity == datastore.AnonymityOff {
+		data.UserID = estimate.UserID
+	}
+	data.UserName = s.Anonymize(estimate.UserID, estimate.UserName)
+	return data
+}
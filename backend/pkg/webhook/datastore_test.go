@@ -0,0 +1,87 @@
+package webhook
+
+import (
+	"fmt"
+	"github.com/haro87/dokerb/pkg/datastore"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type published struct {
+	token     string
+	eventType string
+	data      interface{}
+}
+
+type recordingPublisher struct {
+	events []published
+}
+
+func (r *recordingPublisher) Publish(token, eventType string, data interface{}) {
+	r.events = append(r.events, published{token, eventType, data})
+}
+
+func TestNotifyingDataStorePublishesEvents(t *testing.T) {
+	m := new(datastore.MockDatastore)
+	p := &recordingPublisher{}
+	ds := NewNotifyingDataStore(m, p, nil)
+	est := datastore.Estimate{TaskID: "TEST01", UserID: "tigger", UserName: "Tigger", BestCase: 1, MostLikelyCase: 2, WorstCase: 3}
+
+	m.On("AddEstimate", "12345", est).Return(nil)
+	m.On("AddEstimateToTask", "12345", "TEST01", 2.0, 0.3).Return(nil)
+	m.On("RemoveSession", "12345").Return(nil)
+
+	assert.NoError(t, ds.AddEstimate("12345", est))
+	assert.NoError(t, ds.AddEstimateToTask("12345", "TEST01", 2.0, 0.3))
+	assert.NoError(t, ds.RemoveSession("12345"))
+
+	assert.Equal(t, []published{
+		{"12345", EventEstimateSubmitted, EstimateSubmitted{TaskID: "TEST01", UserID: "tigger", UserName: "Tigger"}},
+		{"12345", EventTaskFinalized, TaskFinalized{TaskID: "TEST01", Effort: 2.0, StandardDeviation: 0.3}},
+		{"12345", EventSessionRemoved, nil},
+	}, p.events)
+}
+
+func TestNotifyingDataStoreAnonymizesEstimates(t *testing.T) {
+	est := datastore.Estimate{TaskID: "TEST01", UserID: "tigger", UserName: "Tigger", BestCase: 1, MostLikelyCase: 2, WorstCase: 3}
+	tests := []struct {
+		name     string
+		settings datastore.Settings
+		err      error
+		want     EstimateSubmitted
+	}{
+		{"off", datastore.Settings{Anonymity: datastore.AnonymityOff}, nil, EstimateSubmitted{TaskID: "TEST01", UserID: "tigger", UserName: "Tigger"}},
+		{"pseudonyms", datastore.Settings{Anonymity: datastore.AnonymityPseudonyms, Salt: "s4lt"}, nil, EstimateSubmitted{TaskID: "TEST01", UserName: datastore.Pseudonym("s4lt", "tigger")}},
+		{"hidden", datastore.Settings{Anonymity: datastore.AnonymityHidden, Salt: "s4lt"}, nil, EstimateSubmitted{TaskID: "TEST01"}},
+		{"settings unavailable", datastore.Settings{}, fmt.Errorf("Unable to query settings"), EstimateSubmitted{TaskID: "TEST01"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := new(datastore.MockDatastore)
+			ss := new(datastore.MockSettingsStore)
+			p := &recordingPublisher{}
+			ds := NewNotifyingDataStore(m, p, ss)
+			m.On("AddEstimate", "12345", est).Return(nil)
+			ss.On("GetSettings", "12345").Return(tt.settings, tt.err)
+
+			assert.NoError(t, ds.AddEstimate("12345", est))
+			assert.Equal(t, []published{{"12345", EventEstimateSubmitted, tt.want}}, p.events)
+		})
+	}
+}
+
+func TestNotifyingDataStoreSkipsFailedOperations(t *testing.T) {
+	m := new(datastore.MockDatastore)
+	p := &recordingPublisher{}
+	ds := NewNotifyingDataStore(m, p, nil)
+
+	m.On("RemoveSession", "12345").Return(fmt.Errorf("Specified session does not exist"))
+	m.On("AddEstimateToTask", "12345", "TEST01", 2.0, 0.3).Return(fmt.Errorf("Task with ID: TEST01 does not exist"))
+	m.On("JoinSession", "12345", datastore.User{Name: "Tigger"}).Return("tigger", nil)
+
+	assert.Error(t, ds.RemoveSession("12345"))
+	assert.Error(t, ds.AddEstimateToTask("12345", "TEST01", 2.0, 0.3))
+	_, err := ds.JoinSession("12345", datastore.User{Name: "Tigger"})
+	assert.NoError(t, err)
+	assert.Empty(t, p.events)
+}
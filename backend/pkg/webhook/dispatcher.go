@@ -0,0 +1,314 @@
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/haro87/dokerb/pkg/datastore"
+	"go.uber.org/zap"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Names of the events sent to webhooks
+const (
+	EventTaskFinalized     = "task.finalized"
+	EventEstimateSubmitted = "estimate.submitted"
+	EventSessionRemoved    = "session.removed"
+)
+
+// Headers added to every webhook request
+const (
+	HeaderEvent     = "X-Doker-Event"
+	HeaderDelivery  = "X-Doker-Delivery"
+	HeaderSignature = "X-Doker-Signature"
+)
+
+// Statuses of a delivery
+const (
+	StatusDelivered = "delivered"
+	StatusFailed    = "failed"
+)
+
+// Events contains all events webhooks can subscribe to
+var Events = []string{EventTaskFinalized, EventEstimateSubmitted, EventSessionRemoved}
+
+// Event defines the JSON payload sent to webhooks
+type Event struct {
+	ID      string      `json:"id"`
+	Type    string      `json:"type"`
+	Session string      `json:"session"`
+	Time    time.Time   `json:"time"`
+	Data    interface{} `json:"data"`
+
+	// webhooks are the receivers of events of removed sessions,
+	// taken when publishing as they are removed with the session
+	webhooks []datastore.Webhook
+}
+
+// Dispatcher sends session events to the registered webhooks
+// in the background, so that publishing never blocks
+type Dispatcher struct {
+	store       datastore.WebhookStore
+	client      *http.Client
+	logger      *zap.Logger
+	workers     int
+	maxAttempts int
+	backoff     time.Duration
+	queue       chan Event
+	quit        chan struct{}
+	wg          sync.WaitGroup
+}
+
+// Option configures optional behaviour of the Dispatcher
+type Option func(d *Dispatcher)
+
+// WithLogger sets the logger used for reporting failed deliveries
+func WithLogger(logger *zap.Logger) Option {
+	return func(d *Dispatcher) {
+		d.logger = logger
+	}
+}
+
+// WithClient sets the HTTP client used for sending events, see
+// NewClient for a client only connecting to public addresses
+func WithClient(client *http.Client) Option {
+	return func(d *Dispatcher) {
+		d.client = client
+	}
+}
+
+// WithWorkers sets the number of concurrent senders
+func WithWorkers(workers int) Option {
+	return func(d *Dispatcher) {
+		d.workers = workers
+	}
+}
+
+// WithRetries sets the maximum number of attempts per delivery and
+// the backoff before the first retry, which doubles on every retry
+func WithRetries(maxAttempts int, backoff time.Duration) Option {
+	return func(d *Dispatcher) {
+		d.maxAttempts = maxAttempts
+		d.backoff = backoff
+	}
+}
+
+// WithQueueSize sets the number of events kept for sending,
+// events published to a full queue are dropped
+func WithQueueSize(size int) Option {
+	return func(d *Dispatcher) {
+		d.queue = make(chan Event, size)
+	}
+}
+
+// NewDispatcher returns a new Dispatcher sending events to the
+// webhooks of the provided store
+func NewDispatcher(store datastore.WebhookStore, opts ...Option) *Dispatcher {
+	d := &Dispatcher{
+		store:       store,
+		client:      NewClient(10*time.Second, false),
+		logger:      zap.NewNop(),
+		workers:     4,
+		maxAttempts: 5,
+		backoff:     time.Second,
+		queue:       make(chan Event, 1000),
+		quit:        make(chan struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(d)
+	}
+
+	return d
+}
+
+// Start starts the background senders
+func (d *Dispatcher) Start() {
+	for i := 0; i < d.workers; i++ {
+		d.wg.Add(1)
+		go d.work()
+	}
+}
+
+// Stop stops the background senders after the current deliveries,
+// pending retries are given up
+func (d *Dispatcher) Stop() {
+	close(d.quit)
+	d.wg.Wait()
+}
+
+// Publish queues an event of the session for sending
+func (d *Dispatcher) Publish(token, eventType string, data interface{}) {
+	id, err := newID()
+	if err != nil {
+		d.logger.Error("Unable to create event ID", zap.Error(err))
+		return
+	}
+
+	e := Event{
+		ID:      id,
+		Type:    eventType,
+		Session: token,
+		Time:    time.Now().UTC(),
+		Data:    data,
+	}
+
+	if eventType == EventSessionRemoved {
+		if e.webhooks, err = d.store.GetWebhooks(token); err != nil {
+			d.logger.Error("Unable to get webhooks", zap.Error(err))
+			return
+		}
+	}
+
+	select {
+	case d.queue <- e:
+	default:
+		d.logger.Warn("Webhook queue full, dropping event", zap.String("event", eventType))
+	}
+}
+
+// Sign returns the signature of the payload, which is sent in the
+// X-Doker-Signature header
+func Sign(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func (d *Dispatcher) work() {
+	defer d.wg.Done()
+	for {
+		select {
+		case <-d.quit:
+			return
+		case e := <-d.queue:
+			d.dispatch(e)
+		}
+	}
+}
+
+func (d *Dispatcher) dispatch(e Event) {
+	webhooks := e.webhooks
+	if e.Type != EventSessionRemoved {
+		var err error
+		if webhooks, err = d.store.GetWebhooks(e.Session); err != nil {
+			d.logger.Error("Unable to get webhooks", zap.Error(err))
+			return
+		}
+	}
+
+	payload, err := json.Marshal(e)
+	if err != nil {
+		d.logger.Error("Unable to encode event", zap.String("event", e.Type), zap.Error(err))
+		return
+	}
+
+	for _, w := range webhooks {
+		if !subscribed(w, e.Type) {
+			continue
+		}
+
+		delivery := d.deliver(w, e, payload)
+		if e.Type == EventSessionRemoved {
+			// The deliveries of removed sessions are gone as well
+			continue
+		}
+		if err := d.store.AddDelivery(e.Session, delivery); err != nil {
+			d.logger.Error("Unable to store delivery", zap.Error(err))
+		}
+	}
+}
+
+// deliver sends the event to the webhook, retrying with exponential
+// backoff until it is accepted or all attempts are used
+func (d *Dispatcher) deliver(w datastore.Webhook, e Event, payload []byte) datastore.Delivery {
+	delivery := datastore.Delivery{
+		WebhookID: w.ID,
+		Event:     e.Type,
+		Status:    StatusFailed,
+	}
+
+	backoff := d.backoff
+	for attempt := 1; attempt <= d.maxAttempts; attempt++ {
+		delivery.Attempts = attempt
+		code, err := d.send(w, e, payload)
+		delivery.StatusCode = code
+		if err == nil {
+			delivery.Status = StatusDelivered
+			delivery.Error = ""
+			break
+		}
+		delivery.Error = err.Error()
+
+		if attempt == d.maxAttempts {
+			break
+		}
+		select {
+		case <-d.quit:
+			delivery.Error += ", retries aborted on shutdown"
+			attempt = d.maxAttempts
+		case <-time.After(backoff):
+			backoff *= 2
+		}
+	}
+
+	delivery.Time = time.Now().UTC().Format(time.RFC3339)
+	if delivery.Status == StatusFailed {
+		d.logger.Warn("Webhook delivery failed",
+			zap.String("webhook", w.ID),
+			zap.String("event", e.Type),
+			zap.Int("attempts", delivery.Attempts),
+			zap.String("error", delivery.Error))
+	}
+	return delivery
+}
+
+func (d *Dispatcher) send(w datastore.Webhook, e Event, payload []byte) (int, error) {
+	req, err := http.NewRequest("POST", w.URL, bytes.NewReader(payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, e.Type)
+	req.Header.Set(HeaderDelivery, e.ID)
+	if w.Secret != "" {
+		req.Header.Set(HeaderSignature, Sign(w.Secret, payload))
+	}
+
+	res, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return res.StatusCode, fmt.Errorf("Unexpected status code: %d", res.StatusCode)
+	}
+	return res.StatusCode, nil
+}
+
+func subscribed(w datastore.Webhook, event string) bool {
+	if len(w.Events) == 0 {
+		return true
+	}
+	for _, e := range w.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+func newID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
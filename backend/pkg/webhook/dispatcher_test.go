@@ -0,0 +1,197 @@
+package webhook
+
+import (
+	"encoding/json"
+	"github.com/haro87/dokerb/pkg/datastore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type received struct {
+	header http.Header
+	body   []byte
+}
+
+// setupTestCaseForReceiver starts a local webhook receiver answering
+// with the provided status codes in order, the last one is repeated
+func setupTestCaseForReceiver(t *testing.T, codes ...int) (*httptest.Server, chan received, func(t *testing.T)) {
+	requests := make(chan received, 10)
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		requests <- received{header: r.Header, body: body}
+
+		i := int(atomic.AddInt32(&calls, 1)) - 1
+		if i >= len(codes) {
+			i = len(codes) - 1
+		}
+		w.WriteHeader(codes[i])
+	}))
+	return srv, requests, func(t *testing.T) {
+		srv.Close()
+	}
+}
+
+// withLocalTargets allows sending to the local receivers
+var withLocalTargets = WithClient(NewClient(time.Second, true))
+
+func expectDelivery(m *datastore.MockWebhookStore, token string) chan datastore.Delivery {
+	deliveries := make(chan datastore.Delivery, 10)
+	m.On("AddDelivery", token, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		deliveries <- args.Get(1).(datastore.Delivery)
+	})
+	return deliveries
+}
+
+func waitForDelivery(t *testing.T, deliveries chan datastore.Delivery) datastore.Delivery {
+	select {
+	case d := <-deliveries:
+		return d
+	case <-time.After(5 * time.Second):
+		t.Fatal("No delivery recorded")
+	}
+	return datastore.Delivery{}
+}
+
+func TestDispatcherSendsSignedEvents(t *testing.T) {
+	srv, requests, teardown := setupTestCaseForReceiver(t, 200)
+	defer teardown(t)
+
+	m := new(datastore.MockWebhookStore)
+	m.On("GetWebhooks", "12345").Return([]datastore.Webhook{
+		{ID: "hook1", URL: srv.URL, Secret: "s3cr3t", Events: []string{EventTaskFinalized}},
+		{ID: "hook2", URL: srv.URL, Events: []string{EventSessionRemoved}},
+	}, nil)
+	deliveries := expectDelivery(m, "12345")
+
+	d := NewDispatcher(m, withLocalTargets, WithWorkers(1))
+	d.Start()
+	defer d.Stop()
+
+	d.Publish("12345", EventTaskFinalized, TaskFinalized{TaskID: "TEST01", Effort: 1.5, StandardDeviation: 0.2})
+
+	delivery := waitForDelivery(t, deliveries)
+	assert.Equal(t, "hook1", delivery.WebhookID)
+	assert.Equal(t, EventTaskFinalized, delivery.Event)
+	assert.Equal(t, StatusDelivered, delivery.Status)
+	assert.Equal(t, 1, delivery.Attempts)
+	assert.Equal(t, 200, delivery.StatusCode)
+	assert.NotEmpty(t, delivery.Time)
+
+	r := <-requests
+	assert.Equal(t, "application/json", r.header.Get("Content-Type"))
+	assert.Equal(t, EventTaskFinalized, r.header.Get(HeaderEvent))
+	assert.NotEmpty(t, r.header.Get(HeaderDelivery))
+	assert.Equal(t, Sign("s3cr3t", r.body), r.header.Get(HeaderSignature))
+
+	var e map[string]interface{}
+	assert.NoError(t, json.Unmarshal(r.body, &e))
+	assert.Equal(t, EventTaskFinalized, e["type"])
+	assert.Equal(t, "12345", e["session"])
+	assert.Equal(t, map[string]interface{}{"id": "TEST01", "effort": 1.5, "standarddeviation": 0.2}, e["data"])
+
+	// The second webhook isn't subscribed to the event
+	assert.Equal(t, 0, len(requests))
+	m.AssertNumberOfCalls(t, "AddDelivery", 1)
+}
+
+func TestDispatcherRetriesWithBackoff(t *testing.T) {
+	srv, requests, teardown := setupTestCaseForReceiver(t, 500, 503, 204)
+	defer teardown(t)
+
+	m := new(datastore.MockWebhookStore)
+	m.On("GetWebhooks", "12345").Return([]datastore.Webhook{{ID: "hook1", URL: srv.URL}}, nil)
+	deliveries := expectDelivery(m, "12345")
+
+	d := NewDispatcher(m, withLocalTargets, WithRetries(5, 10*time.Millisecond))
+	d.Start()
+	defer d.Stop()
+
+	start := time.Now()
+	d.Publish("12345", EventTaskFinalized, nil)
+
+	delivery := waitForDelivery(t, deliveries)
+	assert.Equal(t, StatusDelivered, delivery.Status)
+	assert.Equal(t, 3, delivery.Attempts)
+	assert.Equal(t, 204, delivery.StatusCode)
+	assert.Empty(t, delivery.Error)
+	assert.Equal(t, 3, len(requests))
+	// 10ms before the first and 20ms before the second retry
+	assert.True(t, time.Since(start) >= 30*time.Millisecond)
+}
+
+func TestDispatcherGivesUpAfterMaxAttempts(t *testing.T) {
+	srv, requests, teardown := setupTestCaseForReceiver(t, 500)
+	defer teardown(t)
+
+	core, logs := observer.New(zapcore.WarnLevel)
+	m := new(datastore.MockWebhookStore)
+	m.On("GetWebhooks", "12345").Return([]datastore.Webhook{{ID: "hook1", URL: srv.URL}}, nil)
+	deliveries := expectDelivery(m, "12345")
+
+	d := NewDispatcher(m, withLocalTargets, WithRetries(2, time.Millisecond), WithLogger(zap.New(core)))
+	d.Start()
+	defer d.Stop()
+
+	d.Publish("12345", EventEstimateSubmitted, EstimateSubmitted{TaskID: "TEST01", UserName: "Tigger"})
+
+	delivery := waitForDelivery(t, deliveries)
+	assert.Equal(t, StatusFailed, delivery.Status)
+	assert.Equal(t, 2, delivery.Attempts)
+	assert.Equal(t, 500, delivery.StatusCode)
+	assert.Equal(t, "Unexpected status code: 500", delivery.Error)
+	assert.Equal(t, 2, len(requests))
+	assert.Equal(t, "Webhook delivery failed", logs.All()[0].Message)
+}
+
+func TestDispatcherSendsEventsOfRemovedSessions(t *testing.T) {
+	srv, requests, teardown := setupTestCaseForReceiver(t, 200)
+	defer teardown(t)
+
+	m := new(datastore.MockWebhookStore)
+	m.On("GetWebhooks", "12345").Return([]datastore.Webhook{{ID: "hook1", URL: srv.URL}}, nil).Once()
+
+	d := NewDispatcher(m, withLocalTargets, WithWorkers(1))
+	d.Publish("12345", EventSessionRemoved, nil)
+	// The webhooks are removed with the session before sending
+	m.On("GetWebhooks", "12345").Return([]datastore.Webhook{}, nil)
+	d.Start()
+
+	select {
+	case r := <-requests:
+		assert.Equal(t, EventSessionRemoved, r.header.Get(HeaderEvent))
+	case <-time.After(5 * time.Second):
+		t.Fatal("No event received")
+	}
+	d.Stop()
+	m.AssertNumberOfCalls(t, "GetWebhooks", 1)
+	m.AssertNotCalled(t, "AddDelivery", mock.Anything, mock.Anything)
+}
+
+func TestPublishDoesNotBlockOnFullQueue(t *testing.T) {
+	core, logs := observer.New(zapcore.WarnLevel)
+	d := NewDispatcher(new(datastore.MockWebhookStore), WithQueueSize(1), WithLogger(zap.New(core)))
+
+	d.Publish("12345", EventTaskFinalized, nil)
+	d.Publish("12345", EventTaskFinalized, nil)
+
+	assert.Equal(t, 1, len(d.queue))
+	assert.Equal(t, 1, logs.Len())
+	assert.Equal(t, "Webhook queue full, dropping event", logs.All()[0].Message)
+}
+
+func TestSign(t *testing.T) {
+	assert.Equal(t,
+		"sha256=608b0c406f3dda19702d71a048483b8c331283106d80a208e3cf43dbde505286",
+		Sign("s3cr3t", []byte(`{}`)))
+	assert.NotEqual(t, Sign("s3cr3t", []byte(`{}`)), Sign("other", []byte(`{}`)))
+}
@@ -0,0 +1,100 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// ErrPrivateTarget is returned if a webhook targets a loopback,
+// private or link-local address, which are only reachable from
+// the server itself
+var ErrPrivateTarget = fmt.Errorf("Webhook URL must not target a loopback, private or link-local address")
+
+// privateNetworks are the address ranges, which are not reachable
+// from the internet, loopback and link-local ones are checked
+// separately
+var privateNetworks = mustParseCIDRs(
+	"10.0.0.0/8",
+	"172.16.0.0/12",
+	"192.168.0.0/16",
+	"100.64.0.0/10",
+	"fc00::/7",
+)
+
+// CheckHost returns ErrPrivateTarget if the host of a webhook URL
+// is localhost or an address, which is not public. Host names are
+// checked once they are resolved, when connecting.
+func CheckHost(host string) error {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return ErrPrivateTarget
+	}
+	if ip := net.ParseIP(host); ip != nil && !publicIP(ip) {
+		return ErrPrivateTarget
+	}
+	return nil
+}
+
+// NewClient returns an HTTP client for sending events, which refuses
+// to connect to addresses, which are not public, unless allowed
+func NewClient(timeout time.Duration, allowPrivate bool) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout}
+	if !allowPrivate {
+		dialer.Control = checkDial
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return dialer.DialContext(ctx, network, addr)
+	}
+	if !allowPrivate {
+		// A proxy would connect on our behalf, bypassing the check
+		transport.Proxy = nil
+	}
+
+	return &http.Client{Timeout: timeout, Transport: transport}
+}
+
+// checkDial checks the resolved address right before connecting,
+// so that host names can't be rebound to private addresses
+func checkDial(network, address string, c syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || !publicIP(ip) {
+		return ErrPrivateTarget
+	}
+	return nil
+}
+
+func publicIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsUnspecified() || ip.IsMulticast() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() {
+		return false
+	}
+	for _, n := range privateNetworks {
+		if n.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+func mustParseCIDRs(cidrs ...string) []*net.IPNet {
+	nets := make([]*net.IPNet, 0, len(cidrs))
+	for _, c := range cidrs {
+		_, n, err := net.ParseCIDR(c)
+		if err != nil {
+			panic(err)
+		}
+		nets = append(nets, n)
+	}
+	return nets
+}
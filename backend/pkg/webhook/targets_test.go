@@ -0,0 +1,49 @@
+package webhook
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCheckHost(t *testing.T) {
+	tests := []struct {
+		host string
+		err  error
+	}{
+		{"bot.example.com", nil},
+		{"203.0.113.7", nil},
+		{"2001:db8::1", nil},
+		{"localhost", ErrPrivateTarget},
+		{"api.localhost.", ErrPrivateTarget},
+		{"127.0.0.1", ErrPrivateTarget},
+		{"::1", ErrPrivateTarget},
+		{"0.0.0.0", ErrPrivateTarget},
+		{"10.0.0.1", ErrPrivateTarget},
+		{"172.20.1.1", ErrPrivateTarget},
+		{"192.168.178.1", ErrPrivateTarget},
+		{"169.254.169.254", ErrPrivateTarget},
+		{"fd00::1", ErrPrivateTarget},
+		{"fe80::1", ErrPrivateTarget},
+	}
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			assert.Equal(t, tt.err, CheckHost(tt.host))
+		})
+	}
+}
+
+func TestClientRefusesPrivateAddresses(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	_, err := NewClient(time.Second, false).Get(srv.URL)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), ErrPrivateTarget.Error())
+
+	res, err := NewClient(time.Second, true).Get(srv.URL)
+	assert.NoError(t, err)
+	res.Body.Close()
+}